@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/jkaberg/byd-hass/internal/config"
+	"github.com/jkaberg/byd-hass/internal/update"
+	"github.com/sirupsen/logrus"
+)
+
+// checkUpdateTrial runs the self-test when this version was just installed
+// by -auto-update. A version that fails it, or keeps dying before finishing
+// it, is replaced by the previous binary, which is then started in place of
+// this process.
+func checkUpdateTrial(cfg *config.Config, logger *logrus.Logger) {
+	if cfg.StateDir == "" {
+		return
+	}
+	path := filepath.Join(cfg.StateDir, update.StateFileName)
+	st, err := update.LoadState(path)
+	if err != nil {
+		logger.WithError(err).Warn("update: cannot read update state")
+		return
+	}
+	trial := st.Pending
+	if trial == nil {
+		return
+	}
+	if trial.To != version {
+		// Replaced by hand (or the install never happened); nothing to judge.
+		st.Pending = nil
+		_ = st.Save(path)
+		return
+	}
+
+	trial.Starts++
+	if err := st.Save(path); err != nil {
+		logger.WithError(err).Warn("update: cannot record trial start")
+	}
+	log := logger.WithFields(logrus.Fields{"from": trial.From, "to": trial.To})
+	if trial.Starts <= update.MaxTrialStarts {
+		log.Info("update: running self-test of the new version")
+		level := logger.GetLevel()
+		passed := runSelfTest(cfg, logger) == 0
+		logger.SetLevel(level)
+		if passed {
+			st.Pending = nil
+			if err := st.Save(path); err != nil {
+				logger.WithError(err).Warn("update: cannot record passed self-test")
+			}
+			log.Info("update: new version passed its self-test")
+			return
+		}
+	}
+
+	log.Error("update: new version failed its self-test, rolling back")
+	st.Pending = nil
+	st.RolledBack = append(st.RolledBack, trial.To)
+	if err := st.Save(path); err != nil {
+		logger.WithError(err).Warn("update: cannot record rollback")
+	}
+	if err := update.Rollback(trial.Target); err != nil {
+		log.WithError(err).Error("update: rollback failed, keeping the new version")
+		return
+	}
+	_ = update.RefreshMirror(trial.Target, filepath.Join(cfg.StateDir, "byd-hass"))
+	if err := syscall.Exec(trial.Target, os.Args, os.Environ()); err != nil {
+		// Exit instead; the keep-alive script starts the restored binary.
+		log.WithError(err).Fatal("update: cannot start the previous version")
+	}
+}
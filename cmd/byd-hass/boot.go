@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jkaberg/byd-hass/internal/config"
+	"github.com/jkaberg/byd-hass/internal/update"
+)
+
+// bootScriptName is the Termux:Boot script written by install-boot;
+// installerBootScriptName is the one install.sh writes.
+const (
+	bootScriptName          = "byd-hass.sh"
+	installerBootScriptName = "byd-hass-starter.sh"
+)
+
+// bootLogMaxBytes is the log size at which the boot script rotates the log
+// before (re)starting the bridge.
+const bootLogMaxBytes = 10 << 20
+
+// bootScript starts the bridge from Termux:Boot: it holds a wake lock so
+// Android doesn't suspend Termux, exports config.env from the state
+// directory, appends output to a log rotated at bootLogMaxBytes and
+// restarts the bridge whenever it exits.
+const bootScript = `#!/data/data/com.termux/files/usr/bin/sh
+# Written by "byd-hass install-boot"; run it again instead of editing.
+termux-wake-lock
+
+BIN=%[1]s
+CONFIG=%[2]s
+LOG=%[3]s
+
+while true; do
+    if [ -f "$CONFIG" ]; then
+        set -a
+        . "$CONFIG"
+        set +a
+    fi
+    if [ -f "$LOG" ] && [ "$(wc -c < "$LOG")" -gt %[4]d ]; then
+        mv -f "$LOG" "$LOG.old"
+    fi
+    "$BIN" >> "$LOG" 2>&1
+    echo "[$(date)] byd-hass exited with status $?, restarting in 10s" >> "$LOG"
+    sleep 10
+done
+`
+
+// runInstallBoot writes the Termux:Boot script for the running binary.
+func runInstallBoot(cfg *config.Config) int {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: cannot find the home directory: %v\n", err)
+		return 1
+	}
+	bin, err := update.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if strings.HasPrefix(bin, "/storage/") || strings.HasPrefix(bin, "/sdcard/") {
+		fmt.Fprintf(os.Stderr, "warning: %s is on shared storage, which Android mounts noexec; copy the binary into Termux's home first\n", bin)
+	}
+	stateDir := cfg.StateDir
+	if stateDir == "" {
+		stateDir = filepath.Dir(bin)
+	}
+
+	bootDir := filepath.Join(home, ".termux", "boot")
+	if err := os.MkdirAll(bootDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "error: cannot create %s: %v\n", bootDir, err)
+		return 1
+	}
+	path := filepath.Join(bootDir, bootScriptName)
+	script := fmt.Sprintf(bootScript, shellQuote(bin), shellQuote(filepath.Join(stateDir, "config.env")),
+		shellQuote(filepath.Join(stateDir, "byd-hass.log")), bootLogMaxBytes)
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "error: cannot write %s: %v\n", path, err)
+		return 1
+	}
+	fmt.Printf("Wrote %s; Termux:Boot starts byd-hass from it after the next reboot\n", path)
+
+	if _, err := os.Stat(filepath.Join(bootDir, installerBootScriptName)); err == nil {
+		fmt.Fprintf(os.Stderr, "warning: %s from install.sh also starts byd-hass; remove one of them\n", filepath.Join(bootDir, installerBootScriptName))
+	}
+	return 0
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
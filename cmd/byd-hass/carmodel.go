@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jkaberg/byd-hass/internal/config"
+	"github.com/jkaberg/byd-hass/internal/vehicle"
+	"github.com/sirupsen/logrus"
+)
+
+// resolveCarModel returns the -car-model model, or the one detected from the
+// head unit. ok is false when neither names a single known variant; a
+// family with several battery sizes is never guessed, since a wrong capacity
+// skews ABRP and the battery energy estimates.
+func resolveCarModel(ctx context.Context, cfg *config.Config, logger *logrus.Logger) (vehicle.Model, bool) {
+	if cfg.CarModel != "" {
+		m, ok := vehicle.ByKey(cfg.CarModel)
+		return m, ok
+	}
+	hints := vehicle.HeadUnitHints(ctx)
+	variants := vehicle.Detect(hints)
+	switch len(variants) {
+	case 0:
+		logger.WithField("head_unit", hints).Info("Car model not detected, set -car-model to pick one")
+		return vehicle.Model{}, false
+	case 1:
+		m := variants[0]
+		logger.WithFields(logrus.Fields{"model": m.Name, "key": m.Key}).Info("Detected car model (override with -car-model)")
+		return m, true
+	}
+	keys := make([]string, len(variants))
+	for i, m := range variants {
+		keys[i] = m.Key
+	}
+	logger.WithFields(logrus.Fields{"family": variants[0].Family, "variants": keys}).
+		Warn("Car model detected, but not its battery size: set -car-model (or -usable-capacity and -abrp-car-model) for ABRP and the battery estimates")
+	return vehicle.Model{}, false
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/api"
+	"github.com/jkaberg/byd-hass/internal/config"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/jkaberg/byd-hass/internal/update"
+	"github.com/sirupsen/logrus"
+)
+
+// commandUsage lists the maintenance subcommands.
+const commandUsage = `Commands:
+  sensors verify      check the built-in sensor table for inconsistencies
+  sensors audit       print one snapshot's raw and scaled values, flagging odd magnitudes
+  sensors reprobe     forget the detected sensor capabilities so the next start probes again
+  self-update         install the latest release and restart the running bridge
+  self-update check   only report whether a newer release exists
+  install-boot        write a Termux:Boot script that starts byd-hass at boot
+`
+
+// runCommand executes a maintenance subcommand (positional arguments after
+// the flags) and returns the process exit code.
+func runCommand(cfg *config.Config, args []string) int {
+	switch strings.Join(args, " ") {
+	case "sensors verify":
+		return runSensorsVerify()
+	case "sensors audit":
+		return runSensorsAudit(cfg)
+	case "sensors reprobe":
+		return runSensorsReprobe(cfg)
+	case "self-update":
+		return runSelfUpdate(cfg, false)
+	case "self-update check":
+		return runSelfUpdate(cfg, true)
+	case "install-boot":
+		return runInstallBoot(cfg)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", strings.Join(args, " "), commandUsage)
+		return 2
+	}
+}
+
+// runSelfUpdate installs the latest release over the running binary, keeps
+// the old one next to it and stops running instances so their supervisor
+// starts the new version.
+func runSelfUpdate(cfg *config.Config, checkOnly bool) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	u := update.New()
+	rel, err := u.Latest(ctx, cfg.UpdateChannel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if rel.Tag == version {
+		fmt.Printf("Up to date (%s)\n", version)
+		return 0
+	}
+	fmt.Printf("Release %s available (running %s)\n", rel.Tag, version)
+	if checkOnly {
+		return 0
+	}
+
+	target, err := update.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	// The keep-alive script restores /data/local/tmp from the copy in the
+	// state directory, so that one is refreshed too.
+	var mirror string
+	if cfg.StateDir != "" {
+		mirror = filepath.Join(cfg.StateDir, "byd-hass")
+	}
+	if err := u.Apply(ctx, rel, target); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Installed %s to %s (previous binary kept as %s)\n", rel.Tag, target, target+update.PreviousSuffix)
+	if err := update.RefreshMirror(target, mirror); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	if n := update.StopRunning(target); n > 0 {
+		fmt.Printf("Stopped %d running instance(s); the keep-alive script restarts them\n", n)
+	}
+	return 0
+}
+
+// runSensorsReprobe removes the stored capability map, so the next start
+// probes every sensor again, e.g. after a Diplus update.
+func runSensorsReprobe(cfg *config.Config) int {
+	path := filepath.Join(cfg.StateDir, sensors.CapabilitiesFileName)
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			fmt.Println("No sensor capabilities stored; the next start probes every sensor")
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Removed %s; restart byd-hass to probe every sensor again\n", path)
+	return 0
+}
+
+// runSensorsVerify checks sensors.AllSensors against SensorData and exits
+// non-zero when the table is inconsistent, so it can run in CI.
+func runSensorsVerify() int {
+	problems, warnings := sensors.VerifyTable()
+	for _, w := range warnings {
+		fmt.Printf("warning: %s\n", w)
+	}
+	for _, p := range problems {
+		fmt.Printf("error: %s\n", p)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("%d problem(s) in %d sensor definitions\n", len(problems), len(sensors.AllSensors))
+		return 1
+	}
+	fmt.Printf("OK: %d sensor definitions are consistent\n", len(sensors.AllSensors))
+	return 0
+}
+
+// runSensorsAudit polls every known sensor once and prints the raw value
+// next to the scaled one, so owners of new models can report wrong scale
+// factors.
+func runSensorsAudit(cfg *config.Config) int {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	client := api.NewDiplusClient(fmt.Sprintf("http://%s/api/getDiPars", cfg.DiplusURL), logger)
+	client.SetTimeout(cfg.GetAPITimeout())
+	raw, err := client.GetRawValues(ctx, sensors.GetAllSensorIDs())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	entries := sensors.Audit(raw)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSENSOR\tRAW\tSCALE\tVALUE\tUNIT\tNOTE")
+	var flagged int
+	for _, e := range entries {
+		value := "-"
+		if e.Numeric {
+			value = strconv.FormatFloat(e.Value, 'f', -1, 64)
+		}
+		if e.Note != "" {
+			flagged++
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%g\t%s\t%s\t%s\n", e.Def.ID, sensors.ToSnakeCase(e.Def.FieldName), e.Raw, e.Scale, value, e.Def.UnitOfMeasurement, e.Note)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d sensor(s) reported, %d look off\n", len(entries), flagged)
+	if flagged > 0 {
+		fmt.Println("Please report the flagged lines with your car model and a photo of the dashboard values in a GitHub issue.")
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/mqtt"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/jkaberg/byd-hass/internal/transmission"
+)
+
+// deviceIDFileName keeps the generated device ID in the state directory.
+const deviceIDFileName = "device_id"
+
+// legacyDeviceID was the fixed default before IDs were generated. Installs
+// that already ran with it keep it, since the ID is part of every MQTT
+// topic and Home Assistant entity ID.
+const legacyDeviceID = "byd_car"
+
+// generateDeviceID returns the default device ID: the one generated on an
+// earlier start, legacyDeviceID for installs that predate generated IDs, or
+// "byd_" plus a hash of the head unit's Android ID (random if that can't be
+// read). Diplus doesn't expose the VIN, so it can't be used.
+func generateDeviceID(stateDir string) string {
+	if stateDir == "" {
+		return legacyDeviceID
+	}
+	path := filepath.Join(stateDir, deviceIDFileName)
+	if raw, err := os.ReadFile(path); err == nil {
+		if id := strings.TrimSpace(string(raw)); id != "" {
+			return id
+		}
+	}
+
+	id := legacyDeviceID
+	if !ranBefore(stateDir) {
+		seed := androidID()
+		if seed == "" {
+			buf := make([]byte, 16)
+			if _, err := rand.Read(buf); err != nil {
+				return legacyDeviceID
+			}
+			seed = string(buf)
+		}
+		// Hashed, as the Android ID identifies the head unit to apps.
+		sum := sha256.Sum256([]byte(seed))
+		id = "byd_" + hex.EncodeToString(sum[:4])
+	}
+	if err := os.MkdirAll(stateDir, 0o755); err == nil {
+		_ = os.WriteFile(path, []byte(id+"\n"), 0o644)
+	}
+	return id
+}
+
+// installFiles are what install.sh and its keep-alive and GPS scripts put in
+// the state directory. Older releases wrote nothing else, so these are the
+// only sign of an install that has been running with legacyDeviceID.
+var installFiles = []string{"byd-hass", "config.env", "keep-alive.sh", "keep-alive.log", "byd-hass.log", "gps"}
+
+// ranBefore reports whether the state directory holds files of an existing
+// install, i.e. the install used legacyDeviceID so far. install.sh creates
+// some of them before the first start, so its new installs keep
+// legacyDeviceID too; only a fresh state directory gets a generated ID.
+func ranBefore(stateDir string) bool {
+	names := append([]string{sensors.CapabilitiesFileName, transmission.DiscoveryStateFileName, mqtt.ClientIDFileName}, installFiles...)
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(stateDir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// androidID reads Settings.Secure.ANDROID_ID, "" if unavailable (e.g. when
+// not running on Android or without shell permissions).
+func androidID() string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "settings", "get", "secure", "android_id").Output()
+	if err != nil {
+		return ""
+	}
+	id := strings.TrimSpace(string(out))
+	if id == "null" {
+		return ""
+	}
+	return id
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/api"
+	"github.com/jkaberg/byd-hass/internal/config"
+	"github.com/jkaberg/byd-hass/internal/location"
+	"github.com/jkaberg/byd-hass/internal/mqtt"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/jkaberg/byd-hass/internal/transmission"
+	"github.com/sirupsen/logrus"
+)
+
+// selfTestTimeout bounds each self-test check.
+const selfTestTimeout = 20 * time.Second
+
+// selfTestCheck is one self-test step. run returns a short detail for the
+// summary; a nil run means the check is skipped for the given reason.
+type selfTestCheck struct {
+	name string
+	skip string
+	run  func(ctx context.Context) (string, error)
+}
+
+// runSelfTest performs one poll, one MQTT publish, one ABRP health call and
+// one GPS read, prints a summary and returns the exit code: 1 if any
+// configured check failed.
+func runSelfTest(cfg *config.Config, logger *logrus.Logger) int {
+	// Only the summary should reach the terminal.
+	if !cfg.Verbose {
+		logger.SetLevel(logrus.ErrorLevel)
+	}
+
+	checks := []selfTestCheck{
+		{name: "diplus", run: func(ctx context.Context) (string, error) {
+			client := api.NewDiplusClient(fmt.Sprintf("http://%s/api/getDiPars", cfg.DiplusURL), logger)
+			client.SetTimeout(cfg.GetAPITimeout())
+			data, err := client.PollFast(ctx)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("poll returned %d values", len(sensors.GetNonNilFields(data))), nil
+		}},
+		{name: "mqtt", skip: "not configured"},
+		{name: "abrp", skip: "not configured"},
+		{name: "gps", skip: "location disabled (-abrp-location)"},
+	}
+
+	if cfg.MQTTUrl != "" {
+		checks[1].run = func(ctx context.Context) (string, error) {
+			// A separate client ID so a running bridge isn't disconnected.
+			client, err := mqtt.NewClient(cfg.MQTTUrl, cfg.DeviceID+"-selftest", "", logger)
+			if err != nil {
+				return "", err
+			}
+			defer client.Disconnect(250)
+			topic := fmt.Sprintf("byd_car/%s/selftest", cfg.DeviceID)
+			payload, _ := json.Marshal(map[string]interface{}{"version": version, "time": time.Now().UTC().Format(time.RFC3339)})
+			if err := client.Publish(topic, payload, false); err != nil {
+				return "", err
+			}
+			return "published to " + topic, nil
+		}
+	}
+	if cfg.ABRPAPIKey != "" && cfg.ABRPToken != "" {
+		checks[2].run = func(ctx context.Context) (string, error) {
+			tx := transmission.NewABRPTransmitter(cfg.ABRPAPIKey, cfg.ABRPToken, logger)
+			if err := tx.Ping(ctx); err != nil {
+				return "", err
+			}
+			return "API reachable, credentials accepted", nil
+		}
+	}
+	if cfg.ABRPLocation {
+		checks[3].run = func(ctx context.Context) (string, error) {
+			provider := location.NewTermuxLocationProvider(logger)
+			defer provider.Stop()
+			ticker := time.NewTicker(500 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				if loc, err := provider.GetLocation(); err == nil && loc.Provider != "default" {
+					return fmt.Sprintf("fix from %s, accuracy %.0f m", loc.Provider, loc.Accuracy), nil
+				}
+				select {
+				case <-ctx.Done():
+					return "", fmt.Errorf("no GPS fix within %s", selfTestTimeout)
+				case <-ticker.C:
+				}
+			}
+		}
+	}
+
+	fmt.Printf("byd-hass %s self-test\n", version)
+	failed := 0
+	for _, c := range checks {
+		if c.run == nil {
+			fmt.Printf("  SKIP  %-7s %s\n", c.name, c.skip)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), selfTestTimeout)
+		started := time.Now()
+		detail, err := c.run(ctx)
+		cancel()
+		elapsed := time.Since(started).Round(time.Millisecond)
+		if err != nil {
+			failed++
+			fmt.Printf("  FAIL  %-7s %v (%s)\n", c.name, err, elapsed)
+			continue
+		}
+		fmt.Printf("  PASS  %-7s %s (%s)\n", c.name, detail, elapsed)
+	}
+
+	if failed > 0 {
+		fmt.Printf("%d check(s) failed\n", failed)
+		return 1
+	}
+	fmt.Println("All checks passed")
+	return 0
+}
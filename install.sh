@@ -505,6 +505,10 @@ while true; do
         RAW_LON=$(echo "$LOC" | jq -r .longitude)
         SPD=$(echo "$LOC" | jq -r .speed)
         RAW_ACC=$(echo "$LOC" | jq -r .accuracy)
+        ALT=$(echo "$LOC" | jq -r '.altitude // 0')
+        BRG=$(echo "$LOC" | jq -r '.bearing // 0')
+        VACC=$(echo "$LOC" | jq -r '.vertical_accuracy // 0')
+        PROV=$(echo "$LOC" | jq -r '.provider // "gps"')
 
         # Trim coordinates
         LAT=$(trim "$RAW_LAT")
@@ -553,12 +557,20 @@ while true; do
                 --arg lon "$LON" \
                 --arg spd "$SPD" \
                 --arg acc "$ACC" \
+                --arg alt "$ALT" \
+                --arg brg "$BRG" \
+                --arg vacc "$VACC" \
+                --arg prov "$PROV" \
                 --arg ts "$TIMESTAMP" \
                 '{
                         latitude: ($lat|tonumber),
                         longitude: ($lon|tonumber),
+                        altitude: ($alt|tonumber),
+                        bearing: ($brg|tonumber),
                         speed: ($spd|tonumber),
                         accuracy: ($acc|tonumber),
+                        vertical_accuracy: ($vacc|tonumber),
+                        provider: $prov,
                         timestamp: ($ts|tonumber)
                 }')
 
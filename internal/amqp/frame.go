@@ -0,0 +1,219 @@
+package amqp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Frame types and the end marker of AMQP 0-9-1 (spec section 4.2.3).
+const (
+	frameMethod    = 1
+	frameHeader    = 2
+	frameBody      = 3
+	frameHeartbeat = 8
+	frameEnd       = 0xCE
+)
+
+// protocolHeader opens every connection and selects AMQP 0-9-1.
+var protocolHeader = []byte("AMQP\x00\x00\x09\x01")
+
+// methodID is a method's class and method ID.
+type methodID struct {
+	class, method uint16
+}
+
+// The methods a publisher sends or receives.
+var (
+	connectionStart   = methodID{10, 10}
+	connectionStartOk = methodID{10, 11}
+	connectionTune    = methodID{10, 30}
+	connectionTuneOk  = methodID{10, 31}
+	connectionOpen    = methodID{10, 40}
+	connectionOpenOk  = methodID{10, 41}
+	connectionClose   = methodID{10, 50}
+	connectionCloseOk = methodID{10, 51}
+	channelOpen       = methodID{20, 10}
+	channelOpenOk     = methodID{20, 11}
+	channelClose      = methodID{20, 40}
+	exchangeDeclare   = methodID{40, 10}
+	exchangeDeclareOk = methodID{40, 11}
+	basicPublish      = methodID{60, 40}
+	basicAck          = methodID{60, 80}
+	basicNack         = methodID{60, 120}
+	confirmSelect     = methodID{85, 10}
+	confirmSelectOk   = methodID{85, 11}
+)
+
+// classBasic is the content class of published messages.
+const classBasic = 60
+
+// framer reads and writes frames on one connection.
+type framer struct {
+	r   *bufio.Reader
+	w   *bufio.Writer
+	max uint32 // negotiated frame-max, including the 8 bytes of framing
+}
+
+func newFramer(rw io.ReadWriter, max uint32) *framer {
+	return &framer{r: bufio.NewReader(rw), w: bufio.NewWriter(rw), max: max}
+}
+
+// readFrame returns the next frame's type, channel and payload.
+func (f *framer) readFrame() (byte, uint16, []byte, error) {
+	var hdr [7]byte
+	if _, err := io.ReadFull(f.r, hdr[:]); err != nil {
+		return 0, 0, nil, err
+	}
+	if string(hdr[:4]) == "AMQP" {
+		// The broker answers an unsupported protocol header with its own.
+		return 0, 0, nil, errors.New("broker doesn't support AMQP 0-9-1")
+	}
+	size := binary.BigEndian.Uint32(hdr[3:])
+	if size > f.max {
+		return 0, 0, nil, fmt.Errorf("frame of %d bytes exceeds frame-max %d", size, f.max)
+	}
+	payload := make([]byte, size+1)
+	if _, err := io.ReadFull(f.r, payload); err != nil {
+		return 0, 0, nil, err
+	}
+	if payload[size] != frameEnd {
+		return 0, 0, nil, errors.New("malformed frame")
+	}
+	return hdr[0], binary.BigEndian.Uint16(hdr[1:3]), payload[:size], nil
+}
+
+// readMethod returns the next method frame, skipping heartbeats and content.
+func (f *framer) readMethod() (uint16, methodID, []byte, error) {
+	for {
+		typ, channel, payload, err := f.readFrame()
+		if err != nil {
+			return 0, methodID{}, nil, err
+		}
+		if typ != frameMethod {
+			continue
+		}
+		if len(payload) < 4 {
+			return 0, methodID{}, nil, errors.New("short method frame")
+		}
+		m := methodID{binary.BigEndian.Uint16(payload), binary.BigEndian.Uint16(payload[2:])}
+		return channel, m, payload[4:], nil
+	}
+}
+
+// writeFrame buffers a frame; flush sends it.
+func (f *framer) writeFrame(typ byte, channel uint16, payload []byte) error {
+	var hdr [7]byte
+	hdr[0] = typ
+	binary.BigEndian.PutUint16(hdr[1:3], channel)
+	binary.BigEndian.PutUint32(hdr[3:], uint32(len(payload)))
+	f.w.Write(hdr[:])
+	f.w.Write(payload)
+	return f.w.WriteByte(frameEnd)
+}
+
+func (f *framer) writeMethod(channel uint16, m methodID, args *encoder) error {
+	var payload encoder
+	payload.short(m.class)
+	payload.short(m.method)
+	if args != nil {
+		payload.Write(args.Bytes())
+	}
+	return f.writeFrame(frameMethod, channel, payload.Bytes())
+}
+
+func (f *framer) flush() error {
+	return f.w.Flush()
+}
+
+// encoder builds method arguments in AMQP's big-endian field encoding.
+type encoder struct {
+	bytes.Buffer
+}
+
+func (e *encoder) octet(v byte) { e.WriteByte(v) }
+
+func (e *encoder) short(v uint16) { e.Write(binary.BigEndian.AppendUint16(nil, v)) }
+
+func (e *encoder) long(v uint32) { e.Write(binary.BigEndian.AppendUint32(nil, v)) }
+
+func (e *encoder) longlong(v uint64) { e.Write(binary.BigEndian.AppendUint64(nil, v)) }
+
+func (e *encoder) shortstr(s string) {
+	n := min(len(s), 255)
+	e.octet(byte(n))
+	e.WriteString(s[:n])
+}
+
+func (e *encoder) longstr(s string) {
+	e.long(uint32(len(s)))
+	e.WriteString(s)
+}
+
+// table encodes a field table of string, bool and nested table values.
+func (e *encoder) table(t map[string]interface{}) {
+	var fields encoder
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fields.shortstr(k)
+		switch v := t[k].(type) {
+		case string:
+			fields.octet('S')
+			fields.longstr(v)
+		case bool:
+			fields.octet('t')
+			if v {
+				fields.octet(1)
+			} else {
+				fields.octet(0)
+			}
+		case map[string]interface{}:
+			fields.octet('F')
+			fields.table(v)
+		}
+	}
+	e.long(uint32(fields.Len()))
+	e.Write(fields.Bytes())
+}
+
+// decoder reads method arguments; the first short read sticks in err.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil || len(d.b) < n {
+		d.err = errors.New("truncated method arguments")
+		if n > 8 {
+			return nil // strings and tables
+		}
+		return make([]byte, n)
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) octet() byte { return d.next(1)[0] }
+
+func (d *decoder) short() uint16 { return binary.BigEndian.Uint16(d.next(2)) }
+
+func (d *decoder) long() uint32 { return binary.BigEndian.Uint32(d.next(4)) }
+
+func (d *decoder) longlong() uint64 { return binary.BigEndian.Uint64(d.next(8)) }
+
+func (d *decoder) shortstr() string { return string(d.next(int(d.octet()))) }
+
+func (d *decoder) longstr() string { return string(d.next(int(d.long()))) }
+
+// skipTable skips a field table without decoding it.
+func (d *decoder) skipTable() { d.next(int(d.long())) }
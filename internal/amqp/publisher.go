@@ -0,0 +1,418 @@
+// Package amqp is a minimal AMQP 0-9-1 publisher for RabbitMQ. It covers
+// only what byd-hass needs (PLAIN login, one channel with publisher
+// confirms, persistent messages to an existing exchange), which keeps the
+// binary free of a full client library.
+package amqp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultFrameMax is the largest frame we offer; the broker may lower it.
+	defaultFrameMax = 128 * 1024
+
+	// ioTimeout bounds a call whose context has no deadline.
+	ioTimeout = 30 * time.Second
+
+	// closeTimeout bounds the Connection.Close handshake in Close.
+	closeTimeout = 2 * time.Second
+
+	// publishChannel is the only channel opened.
+	publishChannel = 1
+)
+
+// ErrRejected is returned when the broker nacks a published message. The
+// connection stays usable.
+var ErrRejected = errors.New("broker rejected messages")
+
+// Message is one persistent message published to the exchange.
+type Message struct {
+	RoutingKey  string
+	ContentType string
+	Body        []byte
+}
+
+// Publisher publishes to one exchange over a single long-lived connection
+// and channel. The connection is opened on first use and reopened on the
+// next call after an error.
+type Publisher struct {
+	addr       string
+	serverName string // non-empty for amqps
+	username   string
+	password   string
+	vhost      string
+	exchange   string
+
+	mu      sync.Mutex
+	conn    net.Conn
+	f       *framer
+	nextTag uint64 // delivery tag of the next publish
+}
+
+// NewPublisher creates a Publisher for exchange in vhost on the broker at
+// rawURL (amqp:// or amqps://[user:pass@]host[:port]). Without credentials
+// RabbitMQ's default guest account is used.
+func NewPublisher(rawURL, vhost, exchange string) (*Publisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "amqp" && u.Scheme != "amqps") || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid AMQP URL %q", rawURL)
+	}
+	if exchange == "" {
+		return nil, errors.New("exchange must not be empty")
+	}
+
+	p := &Publisher{username: "guest", password: "guest", vhost: vhost, exchange: exchange}
+	port := u.Port()
+	if u.Scheme == "amqps" {
+		p.serverName = u.Hostname()
+		if port == "" {
+			port = "5671"
+		}
+	} else if port == "" {
+		port = "5672"
+	}
+	p.addr = net.JoinHostPort(u.Hostname(), port)
+	if u.User != nil {
+		p.username = u.User.Username()
+		p.password, _ = u.User.Password()
+	}
+	return p, nil
+}
+
+// Publish sends msgs and waits until the broker has confirmed all of them.
+// A kept connection that went stale while idle is replaced once within the
+// same call, so a message may be delivered twice but isn't lost silently.
+func (p *Publisher) Publish(ctx context.Context, msgs []Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	reused := p.conn != nil
+	err := p.publish(ctx, msgs)
+	if err != nil && reused && ctx.Err() == nil && !errors.Is(err, ErrRejected) {
+		err = p.publish(ctx, msgs)
+	}
+	return err
+}
+
+// Close ends the connection; a later Publish reconnects.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	// Say goodbye first so the broker doesn't log an abrupt disconnect.
+	p.conn.SetDeadline(time.Now().Add(closeTimeout))
+	var args encoder
+	args.short(200)
+	args.shortstr("")
+	args.short(0)
+	args.short(0)
+	_, _ = p.call(0, connectionClose, &args, connectionCloseOk)
+	return p.closeLocked()
+}
+
+func (p *Publisher) publish(ctx context.Context, msgs []Message) error {
+	if p.conn == nil {
+		if err := p.open(ctx); err != nil {
+			return err
+		}
+	}
+	stop := p.bind(ctx)
+	err := p.send(msgs)
+	stop()
+	if err != nil && !errors.Is(err, ErrRejected) {
+		p.closeLocked()
+	}
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// open connects, logs in and opens the publishing channel. Callers hold mu.
+func (p *Publisher) open(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: ioTimeout, KeepAlive: 30 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", p.addr, err)
+	}
+	if p.serverName != "" {
+		conn = tls.Client(conn, &tls.Config{ServerName: p.serverName})
+	}
+	p.conn, p.f, p.nextTag = conn, newFramer(conn, defaultFrameMax), 1
+
+	stop := p.bind(ctx)
+	err = p.handshake()
+	stop()
+	if err != nil {
+		p.closeLocked()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("AMQP handshake with %s failed: %w", p.addr, err)
+	}
+	return nil
+}
+
+// handshake runs the connection and channel setup of spec section 2.2.4.
+func (p *Publisher) handshake() error {
+	if _, err := p.f.w.Write(protocolHeader); err != nil {
+		return err
+	}
+	if err := p.f.flush(); err != nil {
+		return err
+	}
+	raw, err := p.expect(0, connectionStart)
+	if err != nil {
+		return err
+	}
+	start := decoder{b: raw}
+	start.octet() // version-major
+	start.octet() // version-minor
+	start.skipTable()
+	mechanisms := start.longstr()
+	if start.err != nil {
+		return start.err
+	}
+	if !containsField(mechanisms, "PLAIN") {
+		return fmt.Errorf("broker doesn't offer PLAIN login (%s)", mechanisms)
+	}
+
+	var args encoder
+	args.table(map[string]interface{}{
+		"product": "byd-hass",
+		// Ask for a Connection.Close with the reason on bad credentials
+		// instead of a bare disconnect.
+		"capabilities": map[string]interface{}{"authentication_failure_close": true},
+	})
+	args.shortstr("PLAIN")
+	args.longstr("\x00" + p.username + "\x00" + p.password)
+	args.shortstr("en_US")
+	raw, err = p.call(0, connectionStartOk, &args, connectionTune)
+	if err != nil {
+		return err
+	}
+	tune := decoder{b: raw}
+	tune.short() // channel-max; one channel is always allowed
+	if frameMax := tune.long(); frameMax != 0 && frameMax < p.f.max {
+		p.f.max = frameMax
+	}
+	if tune.err != nil {
+		return tune.err
+	}
+
+	// Heartbeats are turned off: snapshots keep the connection busy, TCP
+	// keep-alives cover idle periods, and a dead connection shows up as a
+	// missing confirm and is replaced on the spot.
+	args.Reset()
+	args.short(publishChannel)
+	args.long(p.f.max)
+	args.short(0)
+	if err := p.f.writeMethod(0, connectionTuneOk, &args); err != nil {
+		return err
+	}
+
+	args.Reset()
+	args.shortstr(p.vhost)
+	args.shortstr("") // reserved
+	args.octet(0)     // reserved
+	if _, err := p.call(0, connectionOpen, &args, connectionOpenOk); err != nil {
+		return err
+	}
+
+	args.Reset()
+	args.shortstr("") // reserved
+	if _, err := p.call(publishChannel, channelOpen, &args, channelOpenOk); err != nil {
+		return err
+	}
+
+	// A passive declare reports a missing exchange here, with a clear
+	// NOT_FOUND, rather than as a closed channel on the first publish.
+	args.Reset()
+	args.short(0) // reserved
+	args.shortstr(p.exchange)
+	args.shortstr("") // type, ignored when passive
+	args.octet(1)     // passive
+	args.table(nil)
+	if _, err := p.call(publishChannel, exchangeDeclare, &args, exchangeDeclareOk); err != nil {
+		return err
+	}
+
+	args.Reset()
+	args.octet(0) // no-wait off
+	_, err = p.call(publishChannel, confirmSelect, &args, confirmSelectOk)
+	return err
+}
+
+// send publishes msgs and waits for their confirms.
+func (p *Publisher) send(msgs []Message) error {
+	pending := make(map[uint64]bool, len(msgs))
+	for _, m := range msgs {
+		if err := p.writeMessage(m); err != nil {
+			return err
+		}
+		pending[p.nextTag] = true
+		p.nextTag++
+	}
+	if err := p.f.flush(); err != nil {
+		return err
+	}
+
+	rejected := 0
+	for len(pending) > 0 {
+		channel, m, raw, err := p.f.readMethod()
+		if err != nil {
+			return err
+		}
+		if m != basicAck && m != basicNack {
+			if err := p.closed(channel, m, raw); err != nil {
+				return err
+			}
+			continue
+		}
+		confirm := decoder{b: raw}
+		tag := confirm.longlong()
+		multiple := confirm.octet()&1 != 0
+		if confirm.err != nil {
+			return confirm.err
+		}
+		for t := range pending {
+			if t == tag || (multiple && t < tag) {
+				delete(pending, t)
+				if m == basicNack {
+					rejected++
+				}
+			}
+		}
+	}
+	if rejected > 0 {
+		return fmt.Errorf("%w: %d of %d", ErrRejected, rejected, len(msgs))
+	}
+	return nil
+}
+
+// writeMessage buffers the publish method, content header and body frames
+// of one persistent message.
+func (p *Publisher) writeMessage(m Message) error {
+	var args encoder
+	args.short(0) // reserved
+	args.shortstr(p.exchange)
+	args.shortstr(m.RoutingKey)
+	args.octet(0) // not mandatory, not immediate
+	if err := p.f.writeMethod(publishChannel, basicPublish, &args); err != nil {
+		return err
+	}
+
+	var header encoder
+	header.short(classBasic)
+	header.short(0) // weight
+	header.longlong(uint64(len(m.Body)))
+	header.short(0x8000 | 0x1000) // content-type, delivery-mode
+	header.shortstr(m.ContentType)
+	header.octet(2) // persistent
+	if err := p.f.writeFrame(frameHeader, publishChannel, header.Bytes()); err != nil {
+		return err
+	}
+
+	chunk := int(p.f.max) - 8
+	for body := m.Body; len(body) > 0; {
+		n := min(len(body), chunk)
+		if err := p.f.writeFrame(frameBody, publishChannel, body[:n]); err != nil {
+			return err
+		}
+		body = body[n:]
+	}
+	return nil
+}
+
+// call sends a method and waits for the reply want on the same channel.
+func (p *Publisher) call(channel uint16, m methodID, args *encoder, want methodID) ([]byte, error) {
+	if err := p.f.writeMethod(channel, m, args); err != nil {
+		return nil, err
+	}
+	if err := p.f.flush(); err != nil {
+		return nil, err
+	}
+	return p.expect(channel, want)
+}
+
+// expect reads methods until want arrives on channel, skipping anything
+// else the broker may send in between (e.g. Connection.Blocked).
+func (p *Publisher) expect(channel uint16, want methodID) ([]byte, error) {
+	for {
+		ch, m, raw, err := p.f.readMethod()
+		if err != nil {
+			return nil, err
+		}
+		if ch == channel && m == want {
+			return raw, nil
+		}
+		if err := p.closed(ch, m, raw); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// closed turns a Connection.Close or Channel.Close from the broker into an
+// error carrying its reply code and text, and returns nil for any other
+// method.
+func (p *Publisher) closed(channel uint16, m methodID, raw []byte) error {
+	if m != connectionClose && m != channelClose {
+		return nil
+	}
+	reason := decoder{b: raw}
+	code := reason.short()
+	text := reason.shortstr()
+	if m == connectionClose {
+		_ = p.f.writeMethod(0, connectionCloseOk, nil)
+		_ = p.f.flush()
+		return fmt.Errorf("broker closed the connection: %d %s", code, text)
+	}
+	return fmt.Errorf("broker closed channel %d: %d %s", channel, code, text)
+}
+
+// bind applies ctx to the connection: its deadline (or ioTimeout) bounds
+// I/O and cancelling it unblocks a pending read. The returned func undoes
+// both.
+func (p *Publisher) bind(ctx context.Context) func() {
+	conn := p.conn
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(ioTimeout)
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Unix(1, 0)) })
+	return func() {
+		stop()
+		conn.SetDeadline(time.Time{})
+	}
+}
+
+func (p *Publisher) closeLocked() error {
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn, p.f = nil, nil
+	return err
+}
+
+// containsField reports whether the space-separated list holds field.
+func containsField(list, field string) bool {
+	for _, f := range strings.Fields(list) {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
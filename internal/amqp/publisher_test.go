@@ -0,0 +1,212 @@
+package amqp
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBroker accepts one connection and answers the publisher's handshake.
+// Published messages are sent on got; missingExchange makes the passive
+// declare fail like RabbitMQ does.
+type fakeBroker struct {
+	t               *testing.T
+	ln              net.Listener
+	frameMax        uint32
+	missingExchange bool
+	got             chan Message
+	done            chan struct{}
+}
+
+func startBroker(t *testing.T, missingExchange bool) *fakeBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &fakeBroker{t: t, ln: ln, frameMax: 4096, missingExchange: missingExchange,
+		got: make(chan Message, 16), done: make(chan struct{})}
+	go b.serve()
+	t.Cleanup(func() {
+		ln.Close()
+		<-b.done
+	})
+	return b
+}
+
+func (b *fakeBroker) url() string {
+	return "amqp://user:secret@" + b.ln.Addr().String()
+}
+
+func (b *fakeBroker) serve() {
+	defer close(b.done)
+	conn, err := b.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	f := newFramer(conn, b.frameMax)
+
+	hdr := make([]byte, len(protocolHeader))
+	if _, err := io.ReadFull(f.r, hdr); err != nil || string(hdr) != string(protocolHeader) {
+		b.t.Errorf("protocol header = %q, %v", hdr, err)
+		return
+	}
+	var args encoder
+	args.octet(0)
+	args.octet(9)
+	args.table(nil)
+	args.longstr("AMQPLAIN PLAIN")
+	args.longstr("en_US")
+	b.reply(f, 0, connectionStart, &args)
+
+	raw := b.expect(f, connectionStartOk)
+	startOk := decoder{b: raw}
+	startOk.skipTable()
+	mechanism := startOk.shortstr()
+	response := startOk.longstr()
+	if mechanism != "PLAIN" || response != "\x00user\x00secret" {
+		b.t.Errorf("login = %q %q", mechanism, response)
+	}
+
+	args.Reset()
+	args.short(2047)
+	args.long(b.frameMax)
+	args.short(60)
+	b.reply(f, 0, connectionTune, &args)
+	b.expect(f, connectionTuneOk)
+
+	open := decoder{b: b.expect(f, connectionOpen)}
+	if vhost := open.shortstr(); vhost != "/cars" {
+		b.t.Errorf("vhost = %q", vhost)
+	}
+	args.Reset()
+	args.shortstr("")
+	b.reply(f, 0, connectionOpenOk, &args)
+
+	b.expect(f, channelOpen)
+	args.Reset()
+	args.longstr("")
+	b.reply(f, publishChannel, channelOpenOk, &args)
+
+	b.expect(f, exchangeDeclare)
+	if b.missingExchange {
+		args.Reset()
+		args.short(404)
+		args.shortstr("NOT_FOUND - no exchange 'byd-hass' in vhost '/cars'")
+		args.short(exchangeDeclare.class)
+		args.short(exchangeDeclare.method)
+		b.reply(f, publishChannel, channelClose, &args)
+		return
+	}
+	b.reply(f, publishChannel, exchangeDeclareOk, nil)
+
+	b.expect(f, confirmSelect)
+	b.reply(f, publishChannel, confirmSelectOk, nil)
+
+	var tag uint64
+	for {
+		_, m, raw, err := f.readMethod()
+		if err != nil {
+			return
+		}
+		switch m {
+		case basicPublish:
+			publish := decoder{b: raw}
+			publish.short()
+			publish.shortstr()
+			msg := Message{RoutingKey: publish.shortstr()}
+
+			_, _, payload, _ := f.readFrame()
+			header := decoder{b: payload}
+			header.short()
+			header.short()
+			size := header.longlong()
+			header.short()
+			msg.ContentType = header.shortstr()
+			for uint64(len(msg.Body)) < size {
+				_, _, chunk, err := f.readFrame()
+				if err != nil {
+					return
+				}
+				msg.Body = append(msg.Body, chunk...)
+			}
+			b.got <- msg
+			tag++
+			if tag%2 == 0 {
+				// Confirm in pairs to exercise the multiple flag.
+				args.Reset()
+				args.longlong(tag)
+				args.octet(1)
+				b.reply(f, publishChannel, basicAck, &args)
+			}
+		case connectionClose:
+			b.reply(f, 0, connectionCloseOk, nil)
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) expect(f *framer, want methodID) []byte {
+	_, m, raw, err := f.readMethod()
+	if err != nil || m != want {
+		b.t.Errorf("got method %v (%v), want %v", m, err, want)
+	}
+	return raw
+}
+
+func (b *fakeBroker) reply(f *framer, channel uint16, m methodID, args *encoder) {
+	f.writeMethod(channel, m, args)
+	f.flush()
+}
+
+func TestPublishWaitsForConfirms(t *testing.T) {
+	b := startBroker(t, false)
+	p, err := NewPublisher(b.url(), "/cars", "byd-hass")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	big := strings.Repeat("x", 10000) // spans three body frames
+	msgs := []Message{
+		{RoutingKey: "byd.car.tires", ContentType: "application/json", Body: []byte(`{"a":1}`)},
+		{RoutingKey: "byd.car.location", ContentType: "application/json", Body: []byte(big)},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Publish(ctx, msgs); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range msgs {
+		got := <-b.got
+		if got.RoutingKey != want.RoutingKey || got.ContentType != want.ContentType || string(got.Body) != string(want.Body) {
+			t.Errorf("broker got %q (%d bytes), want %q (%d bytes)", got.RoutingKey, len(got.Body), want.RoutingKey, len(want.Body))
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPublishReportsMissingExchange(t *testing.T) {
+	b := startBroker(t, true)
+	p, err := NewPublisher(b.url(), "/cars", "byd-hass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = p.Publish(ctx, []Message{{RoutingKey: "byd.car.tires"}})
+	if err == nil || !strings.Contains(err.Error(), "404 NOT_FOUND") {
+		t.Fatalf("Publish error = %v, want the broker's NOT_FOUND", err)
+	}
+}
+
+func TestNewPublisherRejectsHTTPURLs(t *testing.T) {
+	if _, err := NewPublisher("https://rabbit:15672", "/", "byd-hass"); err == nil {
+		t.Fatal("NewPublisher accepted a management API URL")
+	}
+}
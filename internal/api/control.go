@@ -0,0 +1,290 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+// Command names a car action sent through the Diplus control endpoint.
+type Command string
+
+// Supported commands. Each needs a template (see ParseCommands) before it
+// can be used.
+const (
+	CmdLock          Command = "lock"
+	CmdUnlock        Command = "unlock"
+	CmdSentryOn      Command = "sentry_on"
+	CmdSentryOff     Command = "sentry_off"
+	CmdACTemperature Command = "ac_temperature" // template takes {value} in °C
+	CmdClimateOn     Command = "climate_on"
+	CmdClimateOff    Command = "climate_off"
+	CmdDriveMode     Command = "drive_mode" // template takes {value}, the mode code
+	CmdRecordingOn   Command = "recording_on"
+	CmdRecordingOff  Command = "recording_off"
+	CmdADBOn         Command = "wireless_adb_on"
+	CmdADBOff        Command = "wireless_adb_off"
+	CmdFlashLights   Command = "flash_lights"
+	CmdHonk          Command = "honk"
+)
+
+var knownCommands = map[Command]bool{
+	CmdLock: true, CmdUnlock: true, CmdSentryOn: true, CmdSentryOff: true, CmdACTemperature: true,
+	CmdClimateOn: true, CmdClimateOff: true, CmdDriveMode: true,
+	CmdRecordingOn: true, CmdRecordingOff: true, CmdADBOn: true, CmdADBOff: true,
+	CmdFlashLights: true, CmdHonk: true,
+}
+
+// switchCommands maps the sensor IDs of head-unit settings to their on and
+// off commands: SentryModeStatus, PowerOffRecordingConfig and
+// WirelessADBSwitch.
+var switchCommands = map[int][2]Command{
+	1003: {CmdSentryOn, CmdSentryOff},
+	1004: {CmdRecordingOn, CmdRecordingOff},
+	1101: {CmdADBOn, CmdADBOff},
+}
+
+// DefaultControlPath is where Diplus builds with remote control accept
+// commands, relative to the Diplus host.
+const DefaultControlPath = "/api/sendCmd"
+
+// controlTimeout bounds SetSentryMode, which has no context.
+const controlTimeout = 10 * time.Second
+
+// ErrNoControlEndpoint is returned once Diplus answered the probe with 404.
+var ErrNoControlEndpoint = errors.New("Diplus has no control endpoint")
+
+// States of the control endpoint, as far as probing has shown.
+const (
+	endpointUnconfirmed = iota // not probed yet, or the last probe was inconclusive
+	endpointAvailable
+	endpointMissing
+)
+
+// ParseCommands parses "command=template" entries separated by ';'. The
+// template is what Diplus expects for the action and may contain {value}.
+func ParseCommands(spec string) (map[Command]string, error) {
+	commands := make(map[Command]string)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, template, ok := strings.Cut(entry, "=")
+		cmd := Command(strings.TrimSpace(name))
+		template = strings.TrimSpace(template)
+		if !ok || template == "" {
+			return nil, fmt.Errorf("Diplus command %q: expected command=template", entry)
+		}
+		if !knownCommands[cmd] {
+			return nil, fmt.Errorf("Diplus command %q: unknown command", cmd)
+		}
+		if _, dup := commands[cmd]; dup {
+			return nil, fmt.Errorf("Diplus command %q: defined more than once", cmd)
+		}
+		if (cmd == CmdACTemperature || cmd == CmdDriveMode) && !strings.Contains(template, "{value}") {
+			return nil, fmt.Errorf("Diplus command %q: template needs {value}", cmd)
+		}
+		commands[cmd] = template
+	}
+	return commands, nil
+}
+
+// DiplusControl wraps the Diplus control endpoint with typed methods. Not
+// every Diplus build has the endpoint, so it is probed before the first
+// command goes out, and again before later ones until a probe succeeds.
+type DiplusControl struct {
+	url        string
+	commands   map[Command]string
+	httpClient *http.Client
+	logger     *logrus.Logger
+
+	mu       sync.Mutex
+	endpoint int
+}
+
+// NewDiplusControl creates a control client for controlURL with the given
+// command templates.
+func NewDiplusControl(controlURL string, commands map[Command]string, logger *logrus.Logger) *DiplusControl {
+	return &DiplusControl{
+		url:        controlURL,
+		commands:   commands,
+		httpClient: &http.Client{Timeout: controlTimeout},
+		logger:     logger,
+	}
+}
+
+// Probe checks that the control endpoint exists, unless an earlier probe
+// already settled it. Diplus rejects the empty command, and that answer
+// still shows the endpoint is there. A 404 rules the endpoint out for good
+// (ErrNoControlEndpoint). Network errors, 401/403 and 5xx say nothing about
+// it, so the endpoint stays unconfirmed and the next command probes again.
+func (c *DiplusControl) Probe(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.endpoint {
+	case endpointAvailable:
+		return nil
+	case endpointMissing:
+		return fmt.Errorf("%w at %s", ErrNoControlEndpoint, c.url)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Diplus control endpoint unreachable: %w", err)
+	}
+	resp.Body.Close()
+	switch code := resp.StatusCode; {
+	case code == http.StatusNotFound:
+		c.endpoint = endpointMissing
+		return fmt.Errorf("%w at %s", ErrNoControlEndpoint, c.url)
+	case code == http.StatusUnauthorized, code == http.StatusForbidden, code >= 500:
+		return fmt.Errorf("Diplus control endpoint not confirmed: %w", &statusError{code: code, status: resp.Status})
+	}
+	c.endpoint = endpointAvailable
+	return nil
+}
+
+// Supports reports whether cmd can be sent: the command has a template and
+// the endpoint hasn't been ruled out by a 404. Nil-safe.
+func (c *DiplusControl) Supports(cmd Command) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	missing := c.endpoint == endpointMissing
+	c.mu.Unlock()
+	if missing {
+		return false
+	}
+	_, ok := c.commands[cmd]
+	return ok
+}
+
+// LockDoors locks (true) or unlocks (false) the doors.
+func (c *DiplusControl) LockDoors(ctx context.Context, lock bool) error {
+	if lock {
+		return c.send(ctx, CmdLock, "")
+	}
+	return c.send(ctx, CmdUnlock, "")
+}
+
+// SetSentry switches sentry mode on or off.
+func (c *DiplusControl) SetSentry(ctx context.Context, on bool) error {
+	if on {
+		return c.send(ctx, CmdSentryOn, "")
+	}
+	return c.send(ctx, CmdSentryOff, "")
+}
+
+// FlashLights flashes the exterior lights, e.g. to find the car.
+func (c *DiplusControl) FlashLights(ctx context.Context) error {
+	return c.send(ctx, CmdFlashLights, "")
+}
+
+// Honk sounds the horn.
+func (c *DiplusControl) Honk(ctx context.Context) error {
+	return c.send(ctx, CmdHonk, "")
+}
+
+// SetACTemperature sets the climate target temperature in °C.
+func (c *DiplusControl) SetACTemperature(ctx context.Context, celsius float64) error {
+	if celsius < 16 || celsius > 32 {
+		return fmt.Errorf("AC temperature %.1f °C out of range (16–32)", celsius)
+	}
+	return c.send(ctx, CmdACTemperature, strconv.FormatFloat(celsius, 'f', -1, 64))
+}
+
+// SetClimate switches the climate control on or off, e.g. to precondition
+// the cabin.
+func (c *DiplusControl) SetClimate(ctx context.Context, on bool) error {
+	if on {
+		return c.send(ctx, CmdClimateOn, "")
+	}
+	return c.send(ctx, CmdClimateOff, "")
+}
+
+// SetDriveMode selects a drive mode by name (see sensors.ModeNames); the
+// template receives its numeric code. It implements
+// transmission.DriveModeCommander.
+func (c *DiplusControl) SetDriveMode(mode string) error {
+	code, ok := sensors.ModeCode(sensors.DriveModeSensorID, mode)
+	if !ok {
+		return fmt.Errorf("unknown drive mode %q", mode)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	return c.send(ctx, CmdDriveMode, strconv.Itoa(code))
+}
+
+// SupportsCarSwitch reports whether both commands of the setting behind
+// sensorID are supported. It implements transmission.CarSwitchCommander.
+func (c *DiplusControl) SupportsCarSwitch(sensorID int) bool {
+	cmds, ok := switchCommands[sensorID]
+	return ok && c.Supports(cmds[0]) && c.Supports(cmds[1])
+}
+
+// SetCarSwitch switches the setting behind sensorID on or off. It implements
+// transmission.CarSwitchCommander.
+func (c *DiplusControl) SetCarSwitch(sensorID int, on bool) error {
+	cmds, ok := switchCommands[sensorID]
+	if !ok {
+		return fmt.Errorf("sensor %d is not a switchable setting", sensorID)
+	}
+	cmd := cmds[1]
+	if on {
+		cmd = cmds[0]
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	return c.send(ctx, cmd, "")
+}
+
+// SetSentryMode implements transmission.SentryCommander.
+func (c *DiplusControl) SetSentryMode(enabled bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	return c.SetSentry(ctx, enabled)
+}
+
+func (c *DiplusControl) send(ctx context.Context, cmd Command, value string) error {
+	if !c.Supports(cmd) {
+		return fmt.Errorf("Diplus command %q is not supported", cmd)
+	}
+	if err := c.Probe(ctx); err != nil {
+		return fmt.Errorf("Diplus command %q: %w", cmd, err)
+	}
+	template := strings.ReplaceAll(c.commands[cmd], "{value}", value)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"?cmd="+url.QueryEscape(template), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Diplus command %q failed: %w", cmd, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Diplus command %q: %w", cmd, &statusError{code: resp.StatusCode, status: resp.Status})
+	}
+
+	c.logger.WithFields(logrus.Fields{"command": cmd, "response": strings.TrimSpace(string(body))}).Info("Diplus command sent")
+	return nil
+}
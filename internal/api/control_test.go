@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestControl(url string) *DiplusControl {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewDiplusControl(url, map[Command]string{CmdHonk: "honk"}, logger)
+}
+
+func TestProbeRetriesAfterServerErrors(t *testing.T) {
+	status := http.StatusInternalServerError
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	c := newTestControl(srv.URL)
+
+	if err := c.Probe(context.Background()); err == nil {
+		t.Fatal("a 500 confirmed the endpoint")
+	}
+	if !c.Supports(CmdHonk) {
+		t.Fatal("an inconclusive probe disabled the commands")
+	}
+	status = http.StatusBadRequest // Diplus rejecting the empty command
+	if err := c.Honk(context.Background()); err == nil {
+		t.Fatal("Honk succeeded against a 400")
+	}
+	if c.endpoint != endpointAvailable {
+		t.Fatalf("endpoint = %d after the command re-probed, want available", c.endpoint)
+	}
+}
+
+func TestProbeRulesOutMissingEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	c := newTestControl(srv.URL)
+
+	if err := c.Probe(context.Background()); !errors.Is(err, ErrNoControlEndpoint) {
+		t.Fatalf("Probe error = %v, want ErrNoControlEndpoint", err)
+	}
+	if c.Supports(CmdHonk) {
+		t.Fatal("commands still supported after a 404")
+	}
+}
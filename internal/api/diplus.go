@@ -1,21 +1,56 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/jkaberg/byd-hass/internal/sensors"
 	"github.com/sirupsen/logrus"
 )
 
+// SensorSource is what the collector polls for vehicle data. DiplusClient is
+// the primary implementation; other data sources (broadcast intents, OBD
+// dongles, CAN) implement it too and are fanned in with Combine.
+type SensorSource interface {
+	// PollFast returns the fast-tier sensors.
+	PollFast(ctx context.Context) (*sensors.SensorData, error)
+	// PollSlow returns the slow-tier sensors, or nil when there are none.
+	PollSlow(ctx context.Context) (*sensors.SensorData, error)
+}
+
+var _ SensorSource = (*DiplusClient)(nil)
+
 // DiplusClient handles communication with the local Diplus API
 type DiplusClient struct {
 	baseURL    string
 	httpClient *http.Client
 	logger     *logrus.Logger
+	extended   bool
+	timeout    time.Duration // per attempt
+}
+
+// Retry policy for transient Diplus failures (connection errors, timeouts,
+// 5xx). Delays grow exponentially from retryBaseDelay with ±50% jitter.
+const (
+	maxAttempts    = 3
+	retryBaseDelay = 250 * time.Millisecond
+)
+
+// statusError is returned for non-200 Diplus responses.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("API returned status %d: %s", e.code, e.status)
 }
 
 // NewDiplusClient creates a new Diplus API client
@@ -25,42 +60,123 @@ func NewDiplusClient(baseURL string, logger *logrus.Logger) *DiplusClient {
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		logger: logger,
+		logger:   logger,
+		extended: true,
+		timeout:  10 * time.Second,
 	}
 }
 
-// GetSensorData fetches sensor data for the specified sensor IDs
-func (c *DiplusClient) GetSensorData(sensorIDs []int) (*sensors.SensorData, error) {
-	// Build the template string with Chinese sensor names
-	template := c.buildAPITemplate(sensorIDs)
-	if template == "" {
-		return nil, fmt.Errorf("no valid sensors found for IDs: %v", sensorIDs)
+// maxQueryLength caps the URL-encoded template sent in a single request. The
+// Chinese labels expand ~9x when encoded, so polling many sensors would
+// otherwise produce URLs beyond what the Diplus HTTP server accepts.
+const maxQueryLength = 1800
+
+// GetSensorData fetches sensor data for the specified sensor IDs. Large sensor
+// sets are split into several requests which run concurrently and are merged
+// into one SensorData.
+func (c *DiplusClient) GetSensorData(ctx context.Context, sensorIDs []int) (*sensors.SensorData, error) {
+	return c.getParts(ctx, c.templateParts(sensorIDs))
+}
+
+// getParts fetches template parts (key:{label}), chunked as GetSensorData.
+func (c *DiplusClient) getParts(ctx context.Context, parts []string) (*sensors.SensorData, error) {
+	templates := chunkTemplates(parts, maxQueryLength)
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("no valid sensors to request")
 	}
 
-	//c.logger.WithField("template", template).Debug("Built API template")
+	results := make([]*sensors.SensorData, len(templates))
+	errs := make([]error, len(templates))
+	var wg sync.WaitGroup
+	for i, template := range templates {
+		wg.Add(1)
+		go func(i int, template string) {
+			defer wg.Done()
+			results[i], errs[i] = c.fetchTemplate(ctx, template)
+		}(i, template)
+	}
+	wg.Wait()
+
+	var sensorData *sensors.SensorData
+	for i, data := range results {
+		if errs[i] != nil {
+			c.logger.WithError(errs[i]).WithFields(logrus.Fields{
+				"chunk":  i + 1,
+				"chunks": len(templates),
+			}).Warn("Diplus request chunk failed")
+			continue
+		}
+		if sensorData == nil {
+			sensorData = data
+		} else {
+			sensors.MergeSensorData(sensorData, data)
+		}
+	}
+	if sensorData == nil {
+		// Every chunk failed (or we were cancelled); surface the first error.
+		return nil, errs[0]
+	}
 
-	// Make the HTTP request
-	responseBody, err := c.makeRequest(template)
+	c.logger.WithFields(logrus.Fields{
+		"active_sensors": len(sensors.GetNonNilFields(sensorData)),
+		"requests":       len(templates),
+	}).Debug("Successfully parsed sensor data")
+
+	return sensorData, nil
+}
+
+// fetchTemplate performs a single Diplus request and parses the response.
+func (c *DiplusClient) fetchTemplate(ctx context.Context, template string) (*sensors.SensorData, error) {
+	responseBody, err := c.requestWithRetry(ctx, template)
 	if err != nil {
 		return nil, fmt.Errorf("API request failed: %w", err)
 	}
 
-	// Parse the response
 	sensorData, err := sensors.ParseAPIResponse(responseBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse API response: %w", err)
 	}
+	return sensorData, nil
+}
+
+// buildAPITemplates splits the template for sensorIDs into pipe-separated
+// chunks whose URL-encoded length stays below maxLen. A single sensor is never
+// split, even if it alone exceeds maxLen.
+func (c *DiplusClient) buildAPITemplates(sensorIDs []int, maxLen int) []string {
+	return chunkTemplates(c.templateParts(sensorIDs), maxLen)
+}
 
-	// Validate the data
-	if warnings := sensors.ValidateSensorData(sensorData); len(warnings) > 0 {
-		for _, warning := range warnings {
-			c.logger.Warn(warning)
+// templateParts returns one template part per known sensor ID.
+func (c *DiplusClient) templateParts(sensorIDs []int) []string {
+	parts := make([]string, 0, len(sensorIDs))
+	for _, id := range sensorIDs {
+		if part := c.buildAPITemplate([]int{id}); part != "" {
+			parts = append(parts, part)
 		}
 	}
+	return parts
+}
 
-	c.logger.WithField("active_sensors", len(sensors.GetNonNilFields(sensorData))).Debug("Successfully parsed sensor data")
-
-	return sensorData, nil
+// chunkTemplates joins parts into pipe-separated templates whose URL-encoded
+// length stays below maxLen.
+func chunkTemplates(parts []string, maxLen int) []string {
+	var templates []string
+	current := ""
+	for _, part := range parts {
+		candidate := part
+		if current != "" {
+			candidate = current + "|" + part
+		}
+		if current != "" && len(url.QueryEscape(candidate)) > maxLen {
+			templates = append(templates, current)
+			candidate = part
+		}
+		current = candidate
+	}
+	if current != "" {
+		templates = append(templates, current)
+	}
+	return templates
 }
 
 // buildAPITemplate creates the API template string using Chinese sensor names
@@ -103,8 +219,68 @@ func (c *DiplusClient) buildAPITemplate(sensorIDs []int) string {
 	return template
 }
 
+// requestWithRetry calls makeRequest, retrying transient failures up to
+// maxAttempts times with jittered exponential backoff. It gives up as soon as
+// ctx is done; see attemptTimeout for how ctx's deadline is shared.
+func (c *DiplusClient) requestWithRetry(ctx context.Context, template string) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			backoff := retryBaseDelay << (attempt - 1)
+			delay := time.Duration(rand.Int63n(int64(backoff))) + backoff/2
+			c.logger.WithError(lastErr).WithFields(logrus.Fields{
+				"attempt": attempt + 1,
+				"delay":   delay,
+			}).Debug("Retrying Diplus request")
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, c.attemptTimeout(ctx, attempt))
+		body, err := c.makeRequest(attemptCtx, template)
+		cancel()
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil || !isTransient(err) {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// attemptTimeout is the timeout of the given attempt: the configured one,
+// capped so that with a deadline on ctx (the collector's poll budget) the
+// time left is split evenly across the remaining attempts. Otherwise one
+// hung request would use up the whole budget and never be retried.
+func (c *DiplusClient) attemptTimeout(ctx context.Context, attempt int) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return c.timeout
+	}
+	share := time.Until(deadline) / time.Duration(maxAttempts-attempt)
+	if share < c.timeout {
+		return share
+	}
+	return c.timeout
+}
+
+// isTransient reports whether a failed request is worth retrying.
+func isTransient(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code >= 500 || se.code == http.StatusTooManyRequests
+	}
+	// Anything else from makeRequest is a transport error or timeout.
+	return true
+}
+
 // makeRequest makes the HTTP request to the Diplus API
-func (c *DiplusClient) makeRequest(template string) ([]byte, error) {
+func (c *DiplusClient) makeRequest(ctx context.Context, template string) ([]byte, error) {
 	// URL encode the template
 	encodedTemplate := url.QueryEscape(template)
 
@@ -114,7 +290,11 @@ func (c *DiplusClient) makeRequest(template string) ([]byte, error) {
 	//c.logger.WithField("url", fullURL).Debug("Making API request")
 
 	// Make the request
-	resp, err := c.httpClient.Get(fullURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -122,7 +302,7 @@ func (c *DiplusClient) makeRequest(template string) ([]byte, error) {
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, resp.Status)
+		return nil, &statusError{code: resp.StatusCode, status: resp.Status}
 	}
 
 	// Read response body
@@ -140,15 +320,15 @@ func (c *DiplusClient) makeRequest(template string) ([]byte, error) {
 }
 
 // GetAllSensorData fetches data for all available sensors
-func (c *DiplusClient) GetAllSensorData() (*sensors.SensorData, error) {
-	return c.GetSensorData(sensors.GetAllSensorIDs())
+func (c *DiplusClient) GetAllSensorData(ctx context.Context) (*sensors.SensorData, error) {
+	return c.GetSensorData(ctx, sensors.GetAllSensorIDs())
 }
 
 // IsHealthy checks if the Diplus API is responding
-func (c *DiplusClient) IsHealthy() bool {
+func (c *DiplusClient) IsHealthy(ctx context.Context) bool {
 	// Try to fetch a minimal sensor set to test connectivity
 	testSensorIDs := []int{33} // Just battery percentage
-	_, err := c.GetSensorData(testSensorIDs)
+	_, err := c.GetSensorData(ctx, testSensorIDs)
 	return err == nil
 }
 
@@ -162,43 +342,101 @@ func (c *DiplusClient) GetAllSensorInfo() []sensors.SensorDefinition {
 	return sensors.AllSensors
 }
 
-// SetTimeout configures the HTTP client timeout
+// SetTimeout configures the timeout of a single request attempt
 func (c *DiplusClient) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
 	c.httpClient.Timeout = timeout
 }
 
+// SetExtendedPolling selects between polling both tiers (true) and only the
+// fast tier returned by sensors.FastPollSensorIDs (false).
+func (c *DiplusClient) SetExtendedPolling(extended bool) {
+	c.extended = extended
+}
+
 // SetLogger updates the logger instance
 func (c *DiplusClient) SetLogger(logger *logrus.Logger) {
 	c.logger = logger
 }
 
 // CompareAllSensors queries all sensors and compares raw vs parsed values
-func (c *DiplusClient) CompareAllSensors() error {
+func (c *DiplusClient) CompareAllSensors(ctx context.Context) error {
 	c.logger.Debug("Diplus: querying all sensors for comparison")
 
 	// Get all sensor data
-	sensorData, err := c.GetAllSensorData()
+	sensorData, err := c.GetAllSensorData(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get sensor data: %w", err)
 	}
 
-	// Also get the raw response for comparison
+	// Also get the raw responses for comparison
 	allSensorIDs := sensors.GetAllSensorIDs()
-	template := c.buildAPITemplate(allSensorIDs)
-	responseBody, err := c.makeRequest(template)
-	if err != nil {
-		return fmt.Errorf("failed to get raw API response: %w", err)
-	}
+	for _, template := range c.buildAPITemplates(allSensorIDs, maxQueryLength) {
+		responseBody, err := c.requestWithRetry(ctx, template)
+		if err != nil {
+			return fmt.Errorf("failed to get raw API response: %w", err)
+		}
 
-	c.logger.Debug("Diplus: comparing raw vs parsed values")
-	sensors.CompareRawVsParsed(responseBody, sensorData)
+		c.logger.Debug("Diplus: comparing raw vs parsed values")
+		sensors.CompareRawVsParsed(responseBody, sensorData)
+	}
 
 	return nil
 }
 
-// Poll polls the Diplus API for sensor data
-func (c *DiplusClient) Poll() (*sensors.SensorData, error) {
+// GetRawValues polls the given sensors once and returns the values as Diplus
+// sent them, keyed by field name, before scaling.
+func (c *DiplusClient) GetRawValues(ctx context.Context, sensorIDs []int) (map[string]string, error) {
+	raw := make(map[string]string)
+	for _, template := range c.buildAPITemplates(sensorIDs, maxQueryLength) {
+		responseBody, err := c.requestWithRetry(ctx, template)
+		if err != nil {
+			return nil, fmt.Errorf("API request failed: %w", err)
+		}
+		values, err := sensors.RawValues(responseBody)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range values {
+			raw[key] = value
+		}
+	}
+	return raw, nil
+}
+
+// Poll polls the Diplus API for the fast sensor tier, or for every monitored
+// sensor when extended polling is enabled.
+func (c *DiplusClient) Poll(ctx context.Context) (*sensors.SensorData, error) {
 	c.logger.Debug("Polling Diplus API for sensor data...")
-	// For now, we use a minimal set of essential sensors.
-	return c.GetSensorData(sensors.PollSensorIDs())
+	if !c.extended {
+		return c.PollFast(ctx)
+	}
+	return c.GetSensorData(ctx, sensors.PollSensorIDs())
+}
+
+// PollFast polls the sensors on the fast tier (see sensors.FastPollSensorIDs).
+// An empty tier yields an empty snapshot rather than an error.
+func (c *DiplusClient) PollFast(ctx context.Context) (*sensors.SensorData, error) {
+	return c.pollTier(ctx, sensors.FastPollSensorIDs())
+}
+
+// PollSlow polls the sensors on the slow tier, plus the per-cell battery
+// values when configured (sensors.SetCellLayout). It returns (nil, nil) when
+// extended polling is disabled.
+func (c *DiplusClient) PollSlow(ctx context.Context) (*sensors.SensorData, error) {
+	if !c.extended {
+		return nil, nil
+	}
+	cells := sensors.CellTemplateParts()
+	if len(cells) == 0 {
+		return c.pollTier(ctx, sensors.SlowPollSensorIDs())
+	}
+	return c.getParts(ctx, append(c.templateParts(sensors.SlowPollSensorIDs()), cells...))
+}
+
+func (c *DiplusClient) pollTier(ctx context.Context, ids []int) (*sensors.SensorData, error) {
+	if len(ids) == 0 {
+		return &sensors.SensorData{Timestamp: time.Now()}, nil
+	}
+	return c.GetSensorData(ctx, ids)
 }
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestClient() *DiplusClient {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewDiplusClient("localhost:8988", logger)
+}
+
+func allSensorIDs() []int {
+	ids := make([]int, 0, len(sensors.AllSensors))
+	for _, s := range sensors.AllSensors {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func TestBuildAPITemplatesSingleChunk(t *testing.T) {
+	c := newTestClient()
+	soc, speed := sensors.GetSensorByID(33), sensors.GetSensorByID(2)
+
+	got := c.buildAPITemplates([]int{33, 2}, maxQueryLength)
+	want := fmt.Sprintf("%s:{%s}|%s:{%s}", soc.FieldName, soc.ChineseName, speed.FieldName, speed.ChineseName)
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("templates = %q, want [%q]", got, want)
+	}
+}
+
+func TestBuildAPITemplatesSkipsUnknownIDs(t *testing.T) {
+	c := newTestClient()
+	if got := c.buildAPITemplates([]int{-1}, maxQueryLength); len(got) != 0 {
+		t.Fatalf("templates = %q, want none", got)
+	}
+	got := c.buildAPITemplates([]int{-1, 33, -2}, maxQueryLength)
+	if len(got) != 1 || got[0] != c.buildAPITemplate([]int{33}) {
+		t.Fatalf("templates = %q, want only sensor 33", got)
+	}
+}
+
+func TestBuildAPITemplatesChunksLongQueries(t *testing.T) {
+	c := newTestClient()
+	ids := allSensorIDs()
+
+	templates := c.buildAPITemplates(ids, maxQueryLength)
+	if len(templates) < 2 {
+		t.Fatalf("got %d template(s) for all %d sensors, want the query split", len(templates), len(ids))
+	}
+	for i, tmpl := range templates {
+		if n := len(url.QueryEscape(tmpl)); n > maxQueryLength {
+			t.Errorf("chunk %d is %d bytes encoded, limit %d", i, n, maxQueryLength)
+		}
+	}
+	// Chunking must neither drop, duplicate nor reorder sensors.
+	if got, want := strings.Join(templates, "|"), c.buildAPITemplate(ids); got != want {
+		t.Errorf("joined chunks differ from the unchunked template:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestBuildAPITemplatesNeverSplitsASensor(t *testing.T) {
+	c := newTestClient()
+	ids := []int{33, 2, 10}
+
+	templates := c.buildAPITemplates(ids, 1)
+	if len(templates) != len(ids) {
+		t.Fatalf("got %d templates, want one per sensor: %q", len(templates), templates)
+	}
+	for i, id := range ids {
+		if want := c.buildAPITemplate([]int{id}); templates[i] != want {
+			t.Errorf("template %d = %q, want %q", i, templates[i], want)
+		}
+	}
+}
+
+func TestAttemptTimeoutSplitsThePollBudget(t *testing.T) {
+	c := newTestClient()
+	c.SetTimeout(10 * time.Second)
+
+	if got := c.attemptTimeout(context.Background(), 0); got != 10*time.Second {
+		t.Errorf("without a deadline: %v, want the configured 10s", got)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 9*time.Second)
+	defer cancel()
+	if got := c.attemptTimeout(ctx, 0); got > 3*time.Second || got < 2*time.Second {
+		t.Errorf("first of 3 attempts in a 9s budget: %v, want about 3s", got)
+	}
+	if got := c.attemptTimeout(ctx, maxAttempts-1); got > 9*time.Second || got < 8*time.Second {
+		t.Errorf("last attempt: %v, want the rest of the budget", got)
+	}
+}
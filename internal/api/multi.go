@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// DiplusSourceName names the Diplus client among the combined sources.
+const DiplusSourceName = "diplus"
+
+// NamedSource is a SensorSource with a name for logs and errors, e.g.
+// DiplusSourceName.
+type NamedSource struct {
+	Name   string
+	Source SensorSource
+}
+
+// SourceErrors holds the error of every source that failed in a
+// MultiSource poll, keyed by source name.
+type SourceErrors map[string]error
+
+func (e SourceErrors) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	msgs := make([]string, len(names))
+	for i, name := range names {
+		msgs[i] = fmt.Sprintf("%s: %v", name, e[name])
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e SourceErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// SourceError returns the error the named source contributed to err, the
+// result of a poll. It is nil when that source succeeded. Errors that don't
+// come from a MultiSource are attributed to every source, since the caller
+// polled a single source.
+func SourceError(err error, name string) error {
+	var se SourceErrors
+	if errors.As(err, &se) {
+		return se[name]
+	}
+	return err
+}
+
+// MultiSource fans in several sources into one snapshot. All sources are
+// polled concurrently and merged field by field: a field is taken from the
+// first source, in the order given, that reports it. When some sources fail
+// the snapshot merged from the others is returned together with
+// SourceErrors naming the failed ones (see SourceError); when all fail the
+// snapshot is nil.
+type MultiSource struct {
+	sources []NamedSource
+}
+
+var _ SensorSource = (*MultiSource)(nil)
+
+// Combine returns a SensorSource over sources, in order of precedence. A
+// single source is returned as is.
+func Combine(sources ...NamedSource) SensorSource {
+	if len(sources) == 1 {
+		return sources[0].Source
+	}
+	return &MultiSource{sources: sources}
+}
+
+// PollFast polls the fast tier of every source.
+func (m *MultiSource) PollFast(ctx context.Context) (*sensors.SensorData, error) {
+	return m.poll(ctx, SensorSource.PollFast)
+}
+
+// PollSlow polls the slow tier of every source. It returns nil when no
+// source has slow-tier sensors.
+func (m *MultiSource) PollSlow(ctx context.Context) (*sensors.SensorData, error) {
+	return m.poll(ctx, SensorSource.PollSlow)
+}
+
+func (m *MultiSource) poll(ctx context.Context, fn func(SensorSource, context.Context) (*sensors.SensorData, error)) (*sensors.SensorData, error) {
+	results := make([]*sensors.SensorData, len(m.sources))
+	errs := make([]error, len(m.sources))
+
+	var wg sync.WaitGroup
+	for i, s := range m.sources {
+		wg.Add(1)
+		go func(i int, s NamedSource) {
+			defer wg.Done()
+			results[i], errs[i] = fn(s.Source, ctx)
+		}(i, s)
+	}
+	wg.Wait()
+
+	var merged *sensors.SensorData
+	failed := make(SourceErrors)
+	for i, s := range m.sources {
+		if errs[i] != nil {
+			failed[s.Name] = errs[i]
+			continue
+		}
+		if merged == nil {
+			merged = results[i]
+		} else {
+			sensors.MergeSensorData(merged, results[i])
+		}
+	}
+
+	if len(failed) == 0 {
+		return merged, nil
+	}
+	return merged, failed
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+type stubSource struct {
+	data *sensors.SensorData
+	err  error
+}
+
+func (s stubSource) PollFast(context.Context) (*sensors.SensorData, error) { return s.data, s.err }
+func (s stubSource) PollSlow(context.Context) (*sensors.SensorData, error) { return s.data, s.err }
+
+func speedData(v float64) *sensors.SensorData { return &sensors.SensorData{Speed: &v} }
+
+func TestMultiSourceReportsEachFailedSource(t *testing.T) {
+	errDiplus, errOBD := errors.New("diplus down"), errors.New("adapter gone")
+	tests := []struct {
+		name                string
+		diplus, obd         stubSource
+		wantData            bool
+		wantDiplus, wantOBD error
+	}{
+		{"both up", stubSource{data: speedData(1)}, stubSource{data: speedData(2)}, true, nil, nil},
+		{"obd down", stubSource{data: speedData(1)}, stubSource{err: errOBD}, true, nil, errOBD},
+		{"diplus down", stubSource{err: errDiplus}, stubSource{data: speedData(2)}, true, errDiplus, nil},
+		{"all down", stubSource{err: errDiplus}, stubSource{err: errOBD}, false, errDiplus, errOBD},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := Combine(NamedSource{Name: DiplusSourceName, Source: tt.diplus}, NamedSource{Name: "elm327", Source: tt.obd})
+			data, err := src.PollFast(context.Background())
+			if (data != nil) != tt.wantData {
+				t.Errorf("data = %v, want data: %v", data, tt.wantData)
+			}
+			if got := SourceError(err, DiplusSourceName); got != tt.wantDiplus {
+				t.Errorf("diplus error = %v, want %v", got, tt.wantDiplus)
+			}
+			if got := SourceError(err, "elm327"); got != tt.wantOBD {
+				t.Errorf("elm327 error = %v, want %v", got, tt.wantOBD)
+			}
+		})
+	}
+}
+
+func TestMultiSourcePrefersEarlierSources(t *testing.T) {
+	src := Combine(NamedSource{Name: DiplusSourceName, Source: stubSource{data: speedData(1)}}, NamedSource{Name: "elm327", Source: stubSource{data: speedData(2)}})
+	data, err := src.PollFast(context.Background())
+	if err != nil || data.Speed == nil || *data.Speed != 1 {
+		t.Fatalf("PollFast = %v, %v; want Diplus' speed 1", data, err)
+	}
+}
+
+func TestSourceErrorSingleSource(t *testing.T) {
+	err := errors.New("timeout")
+	if got := SourceError(err, DiplusSourceName); got != err {
+		t.Errorf("SourceError = %v, want the error itself", got)
+	}
+	if got := SourceError(nil, DiplusSourceName); got != nil {
+		t.Errorf("SourceError(nil) = %v, want nil", got)
+	}
+}
@@ -3,18 +3,34 @@ package app
 import (
 	"context"
 	"fmt"
+	"math/rand"
+	"path/filepath"
 	"time"
 
+	"github.com/jkaberg/byd-hass/internal/abrpapp"
 	"github.com/jkaberg/byd-hass/internal/api"
 	"github.com/jkaberg/byd-hass/internal/bus"
+	"github.com/jkaberg/byd-hass/internal/charging"
 	"github.com/jkaberg/byd-hass/internal/config"
 	"github.com/jkaberg/byd-hass/internal/domain"
+	"github.com/jkaberg/byd-hass/internal/evcc"
+	"github.com/jkaberg/byd-hass/internal/events"
+	"github.com/jkaberg/byd-hass/internal/geofence"
+	"github.com/jkaberg/byd-hass/internal/hook"
 	"github.com/jkaberg/byd-hass/internal/location"
+	"github.com/jkaberg/byd-hass/internal/logging"
+	"github.com/jkaberg/byd-hass/internal/netutil"
+	"github.com/jkaberg/byd-hass/internal/notify"
+	"github.com/jkaberg/byd-hass/internal/precondition"
 	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/jkaberg/byd-hass/internal/sentry"
+	"github.com/jkaberg/byd-hass/internal/stats"
+	"github.com/jkaberg/byd-hass/internal/status"
 	"github.com/jkaberg/byd-hass/internal/transmission"
+	"github.com/jkaberg/byd-hass/internal/update"
+	"github.com/jkaberg/byd-hass/internal/upload"
 	"github.com/jkaberg/byd-hass/internal/wifi"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/sync/errgroup"
 )
 
 // Adaptive ABRP intervals ------------------------------------------------
@@ -39,16 +55,55 @@ func computeABRPInterval(data *sensors.SensorData) time.Duration {
 	return abrpIdleInterval
 }
 
+// Deps are the adapters Run wires together. Only Source is required; a nil
+// transmitter or service is simply not started.
+type Deps struct {
+	Source   api.SensorSource
+	Control  *api.DiplusControl
+	Location *location.TermuxLocationProvider
+	Zones    []geofence.Zone
+	Rules    []events.Rule
+	Uploader upload.Uploader
+	Hooks    *hook.Runner
+	EVCC     *evcc.Server
+	Updater  *update.Auto
+
+	MQTT      *transmission.MQTTTransmitter
+	ABRP      *transmission.ABRPTransmitter
+	Traccar   *transmission.TraccarTransmitter
+	TeslaMate *transmission.TeslaMateTransmitter
+	Kafka     *transmission.KafkaTransmitter
+	RabbitMQ  *transmission.RabbitMQTransmitter
+	CloudIoT  *transmission.CloudIoTTransmitter
+	HARest    *transmission.HARESTTransmitter
+
+	// Restart is called when a remote restart command arrives.
+	Restart func()
+}
+
 // Run launches the hexagonal architecture and blocks until ctx is cancelled.
-func Run(
-	parentCtx context.Context,
-	cfg *config.Config,
-	diplusClient *api.DiplusClient,
-	locationProvider *location.TermuxLocationProvider,
-	mqttTx *transmission.MQTTTransmitter,
-	abrpTx *transmission.ABRPTransmitter,
-	logger *logrus.Logger,
-) {
+func Run(parentCtx context.Context, cfg *config.Config, deps Deps, logger *logrus.Logger) {
+	var (
+		source           = deps.Source
+		control          = deps.Control
+		locationProvider = deps.Location
+		zones            = deps.Zones
+		rules            = deps.Rules
+		videoUploader    = deps.Uploader
+		hookRunner       = deps.Hooks
+		evccServer       = deps.EVCC
+		updater          = deps.Updater
+		mqttTx           = deps.MQTT
+		abrpTx           = deps.ABRP
+		traccarTx        = deps.Traccar
+		teslaMateTx      = deps.TeslaMate
+		kafkaTx          = deps.Kafka
+		rabbitTx         = deps.RabbitMQ
+		cloudTx          = deps.CloudIoT
+		haTx             = deps.HARest
+		restart          = deps.Restart
+	)
+
 	ctx, cancel := context.WithCancel(parentCtx)
 	go func() {
 		<-parentCtx.Done()
@@ -56,41 +111,460 @@ func Run(
 	}()
 
 	messageBus := bus.New()
-	grp, ctx := errgroup.WithContext(ctx)
+	logLevels, _ := logging.ParseLevels(cfg.LogLevels) // validated at startup
+	collectorLog := logging.Module(logger, logLevels, "collector")
+
+	health := status.New()
+	health.TrackQueue("bus", messageBus.Queued)
+	health.TrackCounter("bus_published", func() uint64 { return messageBus.Stats().Published })
+	health.TrackCounter("bus_skipped", func() uint64 { return messageBus.Stats().Skipped })
+	health.TrackCounter("rejected_values", sensors.RejectedValues)
+	if mqttTx != nil {
+		health.TrackProblems(mqttTx.Problems)
+		health.TrackCounter("mqtt_reconnects", func() uint64 { return mqttTx.Metrics().Reconnects })
+	}
+
+	// Subsystems are restarted individually when they fail (see supervisor).
+	sup := newSupervisor(ctx, health, logger)
 
 	// WiFi Monitor ---------------------------------------------------------
 	if cfg.EnableWiFiReenable {
-		grp.Go(func() error {
-			wifiManager := wifi.NewWiFiManager(logger)
+		sup.Go("wifi", func() error {
+			wifiManager := wifi.NewWiFiManager(logging.Module(logger, logLevels, "wifi"))
 			// Check WiFi every 30 seconds
 			return wifiManager.MonitorWiFi(ctx, 30*time.Second)
 		})
 	}
 
+	// Remote control ------------------------------------------------------
+	var ctrl *controller
+	var pollIntervalCh <-chan time.Duration
+	var forceCh <-chan struct{}
+	var intervalCh <-chan intervalChange
+	if cfg.RemoteControl && mqttTx != nil {
+		intervals := map[string]time.Duration{
+			"poll": config.DiplusPollInterval,
+			"mqtt": cfg.MQTTInterval,
+		}
+		if abrpTx != nil {
+			intervals["abrp"] = cfg.ABRPInterval
+		}
+		if traccarTx != nil {
+			intervals["traccar"] = cfg.TraccarInterval
+		}
+		var statePath string
+		if cfg.StateDir != "" {
+			statePath = filepath.Join(cfg.StateDir, controlStateFileName)
+		}
+		ctrl = newController(mqttTx, intervals, statePath, restart, logger)
+		ctrl.schedule = cfg.Precondition != ""
+		ctrl.control = control
+		// Pausing silences events, images and notifications too, not just
+		// the scheduled transmissions.
+		mqttTx.SetPauseCheck(ctrl.Paused)
+		if err := mqttTx.SubscribeCommands(ctrl.handle); err != nil {
+			logger.WithError(err).Warn("remote control unavailable")
+		} else {
+			pollIntervalCh, forceCh, intervalCh = ctrl.pollCh, ctrl.forceCh, ctrl.intervalCh
+			ctrl.registerSettings()
+		}
+	}
+
+	// resumeCh tells the scheduler that the collector resumed after a
+	// suspend, so the next snapshot is published to every target at once.
+	resumeCh := make(chan struct{}, 1)
+
 	// Collector -----------------------------------------------------------
-	grp.Go(func() error {
-		ticker := time.NewTicker(config.DiplusPollInterval)
+	// The Diplus link state is mirrored to MQTT so car entities go
+	// unavailable while Diplus is down.
+	diplusAvailable := func(online bool) {
+		if mqttTx == nil {
+			return
+		}
+		if err := mqttTx.PublishDiplusAvailability(online); err != nil {
+			collectorLog.WithError(err).Debug("collector: failed to publish Diplus availability")
+		}
+	}
+
+	// Fast-tier sensors are polled every DiplusPollInterval; the slow tier is
+	// refreshed every DiplusSlowPollInterval and merged into each snapshot.
+	// While Diplus is unreachable the fast poll doubles as a health probe
+	// with exponential backoff (see diplusLink).
+	sup.GoWithPolicy("collector", policyCritical, func() error {
+		pollInterval := config.DiplusPollInterval
+		var link diplusLink
+		var resume resumeDetector
+		tickEvery := pollInterval // current ticker period, for resume
+		ticker := time.NewTicker(pollInterval)
 		defer ticker.Stop()
+		resetTicker := func(d time.Duration) {
+			ticker.Reset(d)
+			tickEvery = d
+		}
+		slowTicker := time.NewTicker(config.DiplusSlowPollInterval)
+		defer slowTicker.Stop()
+
+		var slowData *sensors.SensorData
+		pollSlow := func() {
+			pollCtx, cancel := context.WithTimeout(ctx, config.DiplusSlowPollInterval/2)
+			defer cancel()
+			started := time.Now()
+			data, err := source.PollSlow(pollCtx)
+			logSecondarySourceErrors(collectorLog, err, "slow")
+			err = api.SourceError(err, api.DiplusSourceName)
+			health.Record(status.GroupPoll, "slow", time.Since(started), err)
+			if err != nil {
+				collectorLog.WithError(err).Warn("collector: slow poll failed")
+				return
+			}
+			slowData = data
+		}
+		pollSlow()
+
 		for {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
+			case <-slowTicker.C:
+				if !link.down() {
+					pollSlow()
+				}
+			case d := <-pollIntervalCh:
+				pollInterval = d
+				if !link.down() {
+					resetTicker(d)
+				}
+				collectorLog.WithField("interval", d).Info("collector: poll interval changed")
 			case <-ticker.C:
-				sensorData, err := diplusClient.Poll()
-				if err != nil {
-					logger.WithError(err).Warn("collector: poll failed")
-					continue
+				resumed := false
+				if gap, ok := resume.check(time.Now(), tickEvery); ok {
+					collectorLog.WithField("away", gap.Round(time.Second)).Info("collector: resumed after suspend, polling now")
+					resumed = true
+					link.resetBackoff()
+					resetTicker(pollInterval)
+					if !link.down() {
+						pollSlow()
+					}
+				}
+				// Never let a hung request delay the next tick.
+				pollCtx, cancel := context.WithTimeout(ctx, config.DiplusPollInterval)
+				started := time.Now()
+				sensorData, err := source.PollFast(pollCtx)
+				cancel()
+				// The link state follows Diplus alone: another source (e.g. the
+				// OBD adapter) answering doesn't make Diplus reachable, and one
+				// failing doesn't take it down.
+				logSecondarySourceErrors(collectorLog, err, "fast")
+				if err = api.SourceError(err, api.DiplusSourceName); err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					health.Record(status.GroupPoll, "fast", time.Since(started), err)
+					next, wentDown := link.failed(pollInterval)
+					if sensorData != nil {
+						// Other sources still answer: keep polling at the normal
+						// interval and publish what they report.
+						next = 0
+					}
+					switch {
+					case wentDown && next > 0:
+						collectorLog.WithError(err).WithField("retry_in", next).Warn("collector: Diplus unreachable, backing off")
+					case wentDown:
+						collectorLog.WithError(err).Warn("collector: Diplus unreachable, using the other data sources")
+					case link.down():
+						collectorLog.WithError(err).WithField("retry_in", next).Debug("collector: Diplus still unreachable")
+					default:
+						collectorLog.WithError(err).Warn("collector: poll failed")
+					}
+					if wentDown {
+						diplusAvailable(false)
+					}
+					if sensorData == nil {
+						if next > 0 {
+							resetTicker(next)
+						}
+						continue
+					}
+				} else {
+					health.Record(status.GroupPoll, "fast", time.Since(started), nil)
+					if link.succeeded() {
+						collectorLog.Info("collector: Diplus reachable again")
+						resetTicker(pollInterval)
+						pollSlow()
+					}
+					diplusAvailable(true)
+				}
+				sensors.MergeSensorData(sensorData, slowData)
+				for _, r := range sensors.Quarantine(sensorData) {
+					entry := collectorLog.WithField("sensor", r.Key)
+					if r.Action == sensors.PolicyWarn {
+						entry.Warn("collector: implausible value: " + r.String())
+					} else {
+						entry.Debug("collector: rejected value: " + r.String())
+					}
 				}
-				if cfg.ABRPLocation && locationProvider != nil {
+				if cfg.ABRPLocation && locationProvider != nil && !ctrl.LocationPrivacy() {
 					if loc, err := locationProvider.GetLocation(); err == nil {
 						sensorData.Location = loc
 					}
 				}
 				messageBus.Publish(sensorData)
+				if resumed {
+					select {
+					case resumeCh <- struct{}{}:
+					default:
+					}
+				}
 			}
 		}
 	})
 
+	alerts := &alerter{paused: ctrl.Paused, logger: logger}
+	pub := &eventPublisher{mqtt: mqttTx, hooks: hookRunner, paused: ctrl.Paused}
+	if cfg.NotifyURL != "" {
+		alerts.push, _ = notify.NewPusher(cfg.NotifyURL) // validated at startup
+		logger.WithField("service", alerts.push.String()).Info("Push notifications enabled")
+	}
+
+	// Geofence ------------------------------------------------------------
+	if len(zones) > 0 {
+		// current_zone reads the MQTT transmitter's tracker, so the events
+		// follow that one when there is one.
+		tracker := geofence.NewTracker(zones)
+		if mqttTx != nil {
+			tracker = mqttTx.ZoneTracker()
+		}
+		zoneSub := messageBus.Subscribe()
+		sup.Go("geofence", func() error {
+			return runGeofence(ctx, zoneSub, tracker, pub, logger)
+		})
+	}
+
+	// Device triggers ------------------------------------------------------
+	if cfg.DeviceTriggers && mqttTx != nil {
+		triggerSub := messageBus.Subscribe()
+		sup.Go("device_triggers", func() error {
+			return runDeviceTriggers(ctx, triggerSub, events.NewTriggerDetector(), mqttTx, logger)
+		})
+	}
+
+	// Speed check ----------------------------------------------------------
+	if cfg.SpeedCheck && cfg.ABRPLocation && locationProvider != nil && mqttTx != nil {
+		speedSub := messageBus.Subscribe()
+		sup.Go("speed_check", func() error {
+			return runSpeedCheck(ctx, speedSub, events.NewSpeedChecker(), mqttTx, logger)
+		})
+	}
+
+	// Rules ----------------------------------------------------------------
+	if len(rules) > 0 {
+		rulesSub := messageBus.Subscribe()
+		sup.Go("rules", func() error {
+			return runRules(ctx, rulesSub, events.NewRuleEngine(rules), pub, alerts, cfg.RulesNotify, logger)
+		})
+	}
+
+	// Theft alert ----------------------------------------------------------
+	if cfg.TheftAlert {
+		theftSub := messageBus.Subscribe()
+		sup.Go("theft_alert", func() error {
+			return runTheftAlert(ctx, theftSub, events.NewTheftDetector(), pub, alerts, logger)
+		})
+	}
+
+	// Parked reminders -----------------------------------------------------
+	if cfg.ParkedReminder > 0 {
+		reminderSub := messageBus.Subscribe()
+		sup.Go("parked_reminder", func() error {
+			return runParkedReminders(ctx, reminderSub, events.NewReminderDetector(cfg.ParkedReminder), pub, alerts, cfg.ParkedReminderNotify, logger)
+		})
+	}
+
+	// Charging reminders ---------------------------------------------------
+	if cfg.ChargeReminders {
+		var home *geofence.Zone
+		if cfg.Home != "" {
+			zone, _ := geofence.ParseHome(cfg.Home) // validated at startup
+			home = &zone
+		}
+		for i := range zones {
+			if home == nil && zones[i].Name == geofence.HomeZone {
+				home = &zones[i]
+			}
+		}
+		if home == nil && cfg.ChargeLowSOC > 0 {
+			logger.Warn("charging reminders: no home location (-home), the plug-in reminder is disabled")
+		}
+		detector := events.NewChargeReminderDetector(float64(cfg.ChargeCompleteSOC), float64(cfg.ChargeLowSOC))
+		chargeSub := messageBus.Subscribe()
+		sup.Go("charge_reminders", func() error {
+			return runChargeReminders(ctx, chargeSub, detector, home, pub, alerts, logger)
+		})
+	}
+
+	// Sentry alerts --------------------------------------------------------
+	if cfg.SentryEvents {
+		sentrySub := messageBus.Subscribe()
+		sup.Go("sentry", func() error {
+			return runSentry(ctx, sentrySub, sentry.NewWatcher(), pub, logger)
+		})
+	}
+
+	// Video upload ---------------------------------------------------------
+	if videoUploader != nil {
+		uploadSub := messageBus.Subscribe()
+		manager := newVideoUploadManager(ctx, cfg, videoUploader, pub, logger)
+		sup.Go("video_upload", func() error {
+			return manager.Run(ctx, 30*time.Second)
+		})
+		sup.Go("video_observer", func() error {
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case snap, ok := <-uploadSub:
+					if !ok {
+						return nil
+					}
+					manager.Observe(snap)
+				}
+			}
+		})
+	}
+
+	// Statistics -----------------------------------------------------------
+	if cfg.Statistics && mqttTx != nil {
+		path := filepath.Join(cfg.StateDir, stats.FileName)
+		tracker, err := stats.Load(path, time.Local)
+		if err != nil {
+			logger.WithError(err).Warn("stats: starting from empty statistics")
+		}
+		statsSub := messageBus.Subscribe()
+		sup.Go("statistics", func() error {
+			return runStatistics(ctx, statsSub, tracker, path, mqttTx, logger)
+		})
+	}
+
+	// Charging cost --------------------------------------------------------
+	if cfg.ChargingPrice != "" && mqttTx != nil {
+		tariff, _ := charging.ParseTariff(cfg.ChargingPrice) // validated at startup
+		path := filepath.Join(cfg.StateDir, charging.FileName)
+		tracker, err := charging.Load(path, tariff, time.Local)
+		if err != nil {
+			logger.WithError(err).Warn("charging: starting from empty cost totals")
+		}
+		var homeZone *geofence.Zone
+		for i := range zones {
+			if zones[i].Name == cfg.ChargingHomeZone {
+				homeZone = &zones[i]
+			}
+		}
+		chargingSub := messageBus.Subscribe()
+		sup.Go("charging_cost", func() error {
+			return runChargingCost(ctx, chargingSub, tracker, path, cfg.ChargingCurrency, homeZone, mqttTx, logger)
+		})
+	}
+
+	// Preconditioning -----------------------------------------------------
+	if cfg.Precondition != "" {
+		if control.Supports(api.CmdClimateOn) {
+			entries, _ := precondition.Parse(cfg.Precondition) // validated at startup
+			precondSub := messageBus.Subscribe()
+			sup.Go("precondition", func() error {
+				return runPreconditioning(ctx, precondSub, entries, control, cfg.PreconditionRequirePlug, ctrl.PreconditionEnabled, logger)
+			})
+		} else {
+			logger.Warn("precondition: schedule ignored, Diplus has no climate_on command (-diplus-commands)")
+		}
+	}
+
+	// Charging curves ------------------------------------------------------
+	if cfg.ChargingCurves {
+		dir := filepath.Join(cfg.StateDir, charging.CurveDir)
+		curveSub := messageBus.Subscribe()
+		sup.Go("charging_curves", func() error {
+			return runChargingCurves(ctx, curveSub, charging.NewCurveRecorder(), dir, mqttTx, logger)
+		})
+	}
+
+	// evcc endpoint ---------------------------------------------------------
+	if evccServer != nil {
+		evccSub := messageBus.Subscribe()
+		sup.Go("evcc", func() error {
+			return evccServer.Run(ctx)
+		})
+		sup.Go("evcc_observer", func() error {
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case snap, ok := <-evccSub:
+					if !ok {
+						return nil
+					}
+					evccServer.Observe(snap)
+				}
+			}
+		})
+	}
+
+	// Exec hook ------------------------------------------------------------
+	if hookRunner.Wants(hook.KindChange) {
+		hookSub := messageBus.Subscribe()
+		sup.Go("hook", func() error {
+			return runHook(ctx, hookSub, hookRunner, logger)
+		})
+	}
+
+	// Automatic updates ------------------------------------------------------
+	if updater != nil {
+		updateSub := messageBus.Subscribe()
+		sup.Go("auto_update", func() error {
+			return runAutoUpdate(ctx, updateSub, updater, restart, logger)
+		})
+	}
+
+	// Bridge status ----------------------------------------------------------
+	if mqttTx != nil && cfg.StatusInterval > 0 {
+		sup.Go("status", func() error {
+			ticker := time.NewTicker(cfg.StatusInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-ticker.C:
+					if err := mqttTx.PublishStatus(health.Report()); err != nil {
+						logger.WithError(err).Debug("status: publish failed")
+					}
+				}
+			}
+		})
+	}
+
+	// Connectivity probe ---------------------------------------------------
+	// Tracks the LAN (VPN) and internet paths separately so a transmitter is
+	// only held back when its own path is down.
+	var prober *netutil.Prober
+	if cfg.ProbeInterval > 0 {
+		lanProbe := cfg.LANProbe
+		if lanProbe == "" && netutil.IsPrivateHost(netutil.HostOf(cfg.MQTTUrl)) {
+			lanProbe = netutil.ProbeTargetFor(cfg.MQTTUrl)
+		}
+		var onChange func(lan, internet bool)
+		if mqttTx != nil {
+			onChange = func(lan, internet bool) {
+				if err := mqttTx.PublishConnectivity(lan, internet); err != nil {
+					logger.WithError(err).Debug("connectivity: publish failed")
+				}
+			}
+		}
+		prober = netutil.NewProber(lanProbe, cfg.InternetProbe, onChange, logger)
+		sup.Go("connectivity", func() error {
+			return prober.Run(ctx, cfg.ProbeInterval)
+		})
+	}
+
 	// Central scheduler ----------------------------------------------------
 
 	sub := messageBus.Subscribe()
@@ -100,37 +574,101 @@ func Run(
 		lastSent         time.Time
 		lastForcedUpdate time.Time
 		lastSnap         *sensors.SensorData
+		fixedInterval    bool // set remotely; disables adaptive ABRP cadence
+		coarseLocation   bool // send coordinates reduced to cfg.LocationPrecision
 		sendFn           func(context.Context, *sensors.SensorData, *logrus.Logger) error
 		name             string
+		host             string        // destination host, for connectivity gating ("" = never held back)
+		heldBack         func() bool   // optional; true skips this transmitter for now
+		jitter           time.Duration // random extra delay before the next send
 	}
 
-	var states []txState
+	// jitter spreads the sends of many bridges behind one broker so they
+	// don't synchronise; see cfg.TransmitJitter.
+	jitter := func() time.Duration {
+		if cfg.TransmitJitter <= 0 {
+			return 0
+		}
+		return time.Duration(rand.Int63n(int64(cfg.TransmitJitter)))
+	}
+	var lastNetworkSend time.Time // for cfg.MinSendSpacing
+
 	now := time.Now()
-	if mqttTx != nil {
-		states = append(states, txState{
-			interval:         cfg.MQTTInterval,
-			lastSent:         now.Add(-cfg.MQTTInterval),
+	newTxState := func(name string, interval time.Duration, host string, coarse bool, sendFn func(context.Context, *sensors.SensorData, *logrus.Logger) error) txState {
+		return txState{
+			interval:         interval,
+			lastSent:         now.Add(-interval),
 			lastForcedUpdate: now.Add(-cfg.ForceUpdateInterval), // Initialize so forced update triggers immediately on startup
-			sendFn: func(c context.Context, s *sensors.SensorData, l *logrus.Logger) error {
+			sendFn:           sendFn,
+			name:             name,
+			host:             host,
+			coarseLocation:   coarse,
+		}
+	}
+
+	var states []txState
+	if mqttTx != nil {
+		states = append(states, newTxState("MQTT", cfg.MQTTInterval, netutil.HostOf(cfg.MQTTUrl), cfg.CoarseLocationFor("mqtt"),
+			func(c context.Context, s *sensors.SensorData, l *logrus.Logger) error {
 				return transmitToMQTTAsync(c, mqttTx, s, l)
-			},
-			name: "MQTT",
-		})
+			}))
 	}
 	if abrpTx != nil {
-		states = append(states, txState{
-			interval:         cfg.ABRPInterval,
-			lastSent:         now.Add(-cfg.ABRPInterval),
-			lastForcedUpdate: now.Add(-cfg.ForceUpdateInterval), // Initialize so forced update triggers immediately on startup
-			sendFn: func(c context.Context, s *sensors.SensorData, l *logrus.Logger) error {
+		st := newTxState("ABRP", cfg.ABRPInterval, "api.iternio.com", cfg.CoarseLocationFor("abrp"),
+			func(c context.Context, s *sensors.SensorData, l *logrus.Logger) error {
 				return transmitToABRPAsync(c, abrpTx, s, l)
-			},
-			name: "ABRP",
-		})
+			})
+		st.heldBack = abrpAppGate(cfg.ABRPAppMode, abrpapp.NewChecker(logger), logger)
+		states = append(states, st)
+	}
+	if traccarTx != nil {
+		states = append(states, newTxState("Traccar", cfg.TraccarInterval, netutil.HostOf(cfg.TraccarURL), cfg.CoarseLocationFor("traccar"),
+			func(c context.Context, s *sensors.SensorData, l *logrus.Logger) error {
+				return transmitToTraccarAsync(c, traccarTx, s, l)
+			}))
+	}
+	if kafkaTx != nil {
+		states = append(states, newTxState("Kafka", cfg.KafkaInterval, netutil.HostOf(cfg.KafkaURL), cfg.CoarseLocationFor("kafka"),
+			func(c context.Context, s *sensors.SensorData, l *logrus.Logger) error {
+				return transmitToKafkaAsync(c, kafkaTx, s, l)
+			}))
+	}
+	if rabbitTx != nil {
+		states = append(states, newTxState("RabbitMQ", cfg.RabbitMQInterval, netutil.HostOf(cfg.RabbitMQURL), cfg.CoarseLocationFor("rabbitmq"),
+			func(c context.Context, s *sensors.SensorData, l *logrus.Logger) error {
+				return transmitToRabbitMQAsync(c, rabbitTx, s, l)
+			}))
+	}
+	if cloudTx != nil {
+		states = append(states, newTxState("CloudIoT", cfg.CloudIoTInterval, cloudIoTHost(cfg), cfg.CoarseLocationFor("cloudiot"),
+			func(c context.Context, s *sensors.SensorData, l *logrus.Logger) error {
+				return transmitToCloudIoTAsync(c, cloudTx, s, l)
+			}))
+	}
+	if haTx != nil {
+		states = append(states, newTxState("HomeAssistantREST", cfg.HAInterval, netutil.HostOf(cfg.HAURL), cfg.CoarseLocationFor("ha"),
+			func(c context.Context, s *sensors.SensorData, l *logrus.Logger) error {
+				return transmitToHARESTAsync(c, haTx, s, l)
+			}))
+	}
+	if teslaMateTx != nil {
+		states = append(states, newTxState("TeslaMate", cfg.MQTTInterval, netutil.HostOf(cfg.MQTTUrl), cfg.CoarseLocationFor("mqtt"),
+			func(c context.Context, s *sensors.SensorData, l *logrus.Logger) error {
+				if err := teslaMateTx.Transmit(s); err != nil {
+					return fmt.Errorf("TeslaMate transmit failed: %w", err)
+				}
+				return nil
+			}))
 	}
 
-	grp.Go(func() error {
+	for i := range states {
+		states[i].jitter = jitter()
+	}
+
+	sup.GoWithPolicy("scheduler", policyCritical, func() error {
 		var latest *sensors.SensorData
+		forceAll := false    // one-shot remote force_update
+		forceOnNext := false // force the first snapshot after a resume
 		ticker := time.NewTicker(1 * time.Second)
 		defer ticker.Stop()
 		for {
@@ -142,8 +680,24 @@ func Run(
 					return nil
 				}
 				latest = snap
+				if forceOnNext {
+					forceAll, forceOnNext = true, false
+				}
+			case <-resumeCh:
+				// The snapshot that triggered this may still be queued.
+				forceOnNext = true
+			case <-forceCh:
+				forceAll = true
+			case ch := <-intervalCh:
+				for i := range states {
+					if states[i].name == ch.transmitter {
+						states[i].interval = ch.interval
+						states[i].fixedInterval = true
+						logger.WithFields(logrus.Fields{"transmitter": ch.transmitter, "interval": ch.interval}).Info("Transmit interval changed")
+					}
+				}
 			case <-ticker.C:
-				if latest == nil {
+				if latest == nil || ctrl.Paused() {
 					continue
 				}
 				now := time.Now()
@@ -151,29 +705,54 @@ func Run(
 					st := &states[i]
 					// Dynamic interval for ABRP depending on vehicle state.
 					interval := st.interval
-					if st.name == "ABRP" {
+					if st.name == "ABRP" && !st.fixedInterval {
 						interval = computeABRPInterval(latest)
 					}
 
 					// Check if forced update interval has elapsed (if enabled)
 					forceUpdate := cfg.ForceUpdateInterval > 0 && now.Sub(st.lastForcedUpdate) >= cfg.ForceUpdateInterval
 
-					// If not forcing an update, check regular interval and change detection
-					if !forceUpdate {
-						if now.Sub(st.lastSent) < interval {
+					switch {
+					case forceAll:
+						// Remote force_update bypasses interval and change checks.
+					case !forceUpdate:
+						// If not forcing an update, check regular interval and change detection
+						if now.Sub(st.lastSent) < interval+st.jitter {
 							continue
 						}
 						if !domain.Changed(st.lastSnap, latest) {
 							continue
 						}
-					} else {
+					default:
 						// For forced updates, still respect minimum interval to avoid spam
-						if now.Sub(st.lastSent) < interval {
+						if now.Sub(st.lastSent) < interval+st.jitter {
 							continue
 						}
 					}
+					if !forceAll && cfg.MinSendSpacing > 0 && time.Since(lastNetworkSend) < cfg.MinSendSpacing {
+						continue // too soon after the previous send; retried next tick
+					}
 
-					if err := st.sendFn(ctx, latest, logger); err != nil {
+					if st.host != "" && !prober.Reachable(st.host) {
+						continue // path down; retry once the probe sees it again
+					}
+					if st.heldBack != nil && st.heldBack() {
+						continue
+					}
+
+					snap := latest
+					if st.coarseLocation && latest.Location != nil {
+						coarse := *latest
+						coarse.Location = latest.Location.Coarsened(float64(cfg.LocationPrecision))
+						snap = &coarse
+					}
+
+					started := time.Now()
+					err := st.sendFn(ctx, snap, logger)
+					lastNetworkSend = time.Now()
+					st.jitter = jitter()
+					health.Record(status.GroupTransmit, st.name, time.Since(started), err)
+					if err != nil {
 						logger.WithError(err).Warn(st.name + " transmit failed")
 						// Ensure we retry even if no data change.
 						// Reset lastSnap so Changed() will evaluate to true on the next
@@ -190,13 +769,45 @@ func Run(
 						}
 					}
 				}
+				forceAll = false
 			}
 		}
 	})
 
-	if err := grp.Wait(); err != nil && err != context.Canceled {
-		logger.WithError(err).Warn("app: background group exited")
+	sup.Wait()
+}
+
+// abrpAppGate returns the ABRP heldBack check for mode (see
+// config.ABRPAppMode), or nil when the app isn't checked. Suspends and
+// resumes are logged once each.
+func abrpAppGate(mode string, checker *abrpapp.Checker, logger *logrus.Logger) func() bool {
+	if mode == config.ABRPAppIgnore {
+		return nil
+	}
+	held := false
+	return func() bool {
+		running := checker.IsRunning()
+		hold := running == (mode == config.ABRPAppYield)
+		if hold != held {
+			held = hold
+			entry := logger.WithFields(logrus.Fields{"mode": mode, "abrp_app_running": running})
+			if hold {
+				entry.Info("ABRP telemetry suspended")
+			} else {
+				entry.Info("ABRP telemetry resumed")
+			}
+		}
+		return hold
+	}
+}
+
+// cloudIoTHost returns the host the cloud IoT transmitter talks to. Both
+// providers are on the public internet.
+func cloudIoTHost(cfg *config.Config) string {
+	if cfg.AWSIoTEndpoint != "" {
+		return cfg.AWSIoTEndpoint
 	}
+	return "azure-devices.net"
 }
 
 func transmitToABRPAsync(ctx context.Context, tx *transmission.ABRPTransmitter, data *sensors.SensorData, logger *logrus.Logger) error {
@@ -214,6 +825,68 @@ func transmitToABRPAsync(ctx context.Context, tx *transmission.ABRPTransmitter,
 	return nil
 }
 
+func transmitToTraccarAsync(ctx context.Context, tx *transmission.TraccarTransmitter, data *sensors.SensorData, logger *logrus.Logger) error {
+	if tx == nil || data == nil {
+		return nil
+	}
+	ctxTx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := tx.TransmitWithContext(ctxTx, data); err != nil {
+		return fmt.Errorf("Traccar transmit failed: %w", err)
+	}
+	return nil
+}
+
+func transmitToKafkaAsync(ctx context.Context, tx *transmission.KafkaTransmitter, data *sensors.SensorData, logger *logrus.Logger) error {
+	if tx == nil || data == nil {
+		return nil
+	}
+	ctxTx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := tx.TransmitWithContext(ctxTx, data); err != nil {
+		return fmt.Errorf("Kafka transmit failed: %w", err)
+	}
+	return nil
+}
+
+func transmitToRabbitMQAsync(ctx context.Context, tx *transmission.RabbitMQTransmitter, data *sensors.SensorData, logger *logrus.Logger) error {
+	if tx == nil || data == nil {
+		return nil
+	}
+	ctxTx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := tx.TransmitWithContext(ctxTx, data); err != nil {
+		return fmt.Errorf("RabbitMQ transmit failed: %w", err)
+	}
+	return nil
+}
+
+func transmitToCloudIoTAsync(ctx context.Context, tx *transmission.CloudIoTTransmitter, data *sensors.SensorData, logger *logrus.Logger) error {
+	if tx == nil || data == nil {
+		return nil
+	}
+	if err := tx.TransmitWithContext(ctx, data); err != nil {
+		return fmt.Errorf("cloud IoT transmit failed: %w", err)
+	}
+	return nil
+}
+
+func transmitToHARESTAsync(ctx context.Context, tx *transmission.HARESTTransmitter, data *sensors.SensorData, logger *logrus.Logger) error {
+	if tx == nil || data == nil {
+		return nil
+	}
+	ctxTx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := tx.TransmitWithContext(ctxTx, data); err != nil {
+		return fmt.Errorf("Home Assistant REST transmit failed: %w", err)
+	}
+	return nil
+}
+
 func transmitToMQTTAsync(ctx context.Context, tx *transmission.MQTTTransmitter, data *sensors.SensorData, logger *logrus.Logger) error {
 	if tx == nil || data == nil {
 		return nil
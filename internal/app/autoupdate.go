@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/jkaberg/byd-hass/internal/update"
+	"github.com/jkaberg/byd-hass/internal/wifi"
+	"github.com/sirupsen/logrus"
+)
+
+// autoUpdateStartDelay holds back the first update check after startup, so
+// a freshly started (or just updated) bridge settles first.
+const autoUpdateStartDelay = 10 * time.Minute
+
+// autoUpdateWiFiRetry is how often the WiFi connection is checked while an
+// update check is due but the car is on cellular.
+const autoUpdateWiFiRetry = 5 * time.Minute
+
+// runAutoUpdate checks for a new release every updater.Interval while the
+// car is parked and the head unit is on WiFi. After installing one it calls
+// restart; the keep-alive script then starts the new version, which confirms
+// or rolls back the update after its self-test.
+func runAutoUpdate(ctx context.Context, sub <-chan *sensors.SensorData, updater *update.Auto, restart func(), logger *logrus.Logger) error {
+	wifiManager := wifi.NewWiFiManager(logger)
+	nextCheck := time.Now().Add(autoUpdateStartDelay)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			parked := snap.Speed != nil && *snap.Speed == 0
+			if !parked || time.Now().Before(nextCheck) {
+				continue
+			}
+			if ssid, err := wifiManager.CurrentSSID(ctx); err != nil || ssid == "" {
+				nextCheck = time.Now().Add(autoUpdateWiFiRetry)
+				continue
+			}
+
+			nextCheck = time.Now().Add(updater.Interval)
+			tag, err := updater.Check(ctx)
+			if err != nil {
+				logger.WithError(err).Warn("update: check failed")
+				continue
+			}
+			if tag == "" {
+				logger.WithField("channel", updater.Channel).Debug("update: up to date")
+				continue
+			}
+			logger.WithFields(logrus.Fields{"from": updater.Current, "to": tag}).Info("update: new version installed, restarting")
+			restart()
+			return nil
+		}
+	}
+}
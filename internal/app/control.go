@@ -0,0 +1,352 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/api"
+	"github.com/jkaberg/byd-hass/internal/transmission"
+	"github.com/sirupsen/logrus"
+)
+
+// Remote control -------------------------------------------------------------
+//
+// Commands arrive on the MQTT callback goroutine; the controller only records
+// them (atomics and buffered channels) and the collector / scheduler
+// goroutines apply them, so no state is shared without synchronisation.
+
+// Interval bounds accepted from remote commands.
+const (
+	minRemoteInterval = 2 * time.Second
+	maxRemoteInterval = 24 * time.Hour
+)
+
+// carCommandTimeout bounds a Diplus command started from MQTT.
+const carCommandTimeout = 15 * time.Second
+
+// intervalChange asks the scheduler to use a new interval for a transmitter
+// ("MQTT", "ABRP", "Traccar").
+type intervalChange struct {
+	transmitter string
+	interval    time.Duration
+}
+
+// controlStateFileName holds the pause/privacy switches in the state
+// directory so they survive restarts.
+const controlStateFileName = "control.json"
+
+type controlState struct {
+	Paused          bool `json:"paused"`
+	LocationPrivacy bool `json:"location_privacy"`
+	PreconditionOff bool `json:"precondition_off,omitempty"`
+}
+
+type controller struct {
+	paused     atomic.Bool
+	privacy    atomic.Bool // location publishing suspended
+	precondOff atomic.Bool // preconditioning schedule switched off
+	forceCh    chan struct{}
+	intervalCh chan intervalChange
+	pollCh     chan time.Duration
+	mqttTx     *transmission.MQTTTransmitter
+	control    *api.DiplusControl // nil without Diplus commands
+	schedule   bool               // a preconditioning schedule is configured
+	restart    func()
+	logger     *logrus.Logger
+
+	settingsMu sync.Mutex
+	intervals  map[string]time.Duration // set_interval target → current value
+	statePath  string                   // "" = don't persist the switches
+}
+
+// newController creates a controller; intervals holds the configured value
+// of every set_interval target that exists in this setup. The pause and
+// privacy switches are restored from statePath when it exists.
+func newController(mqttTx *transmission.MQTTTransmitter, intervals map[string]time.Duration, statePath string, restart func(), logger *logrus.Logger) *controller {
+	c := &controller{
+		forceCh:    make(chan struct{}, 1),
+		intervalCh: make(chan intervalChange, 8),
+		pollCh:     make(chan time.Duration, 1),
+		mqttTx:     mqttTx,
+		restart:    restart,
+		logger:     logger,
+		intervals:  intervals,
+		statePath:  statePath,
+	}
+	c.loadState()
+	return c
+}
+
+func (c *controller) loadState() {
+	if c.statePath == "" {
+		return
+	}
+	raw, err := os.ReadFile(c.statePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			c.logger.WithError(err).Warn("Failed to read control state")
+		}
+		return
+	}
+	var st controlState
+	if err := json.Unmarshal(raw, &st); err != nil {
+		c.logger.WithError(err).Warn("Ignoring malformed control state")
+		return
+	}
+	c.paused.Store(st.Paused)
+	c.privacy.Store(st.LocationPrivacy)
+	c.precondOff.Store(st.PreconditionOff)
+	if st.Paused || st.LocationPrivacy {
+		c.logger.WithFields(logrus.Fields{
+			"paused":           st.Paused,
+			"location_privacy": st.LocationPrivacy,
+		}).Info("Restored remote control switches")
+	}
+}
+
+func (c *controller) saveState() {
+	if c.statePath == "" {
+		return
+	}
+	raw, _ := json.Marshal(controlState{Paused: c.paused.Load(), LocationPrivacy: c.privacy.Load(), PreconditionOff: c.precondOff.Load()})
+	if err := os.MkdirAll(filepath.Dir(c.statePath), 0o755); err != nil {
+		c.logger.WithError(err).Warn("Failed to persist control state")
+		return
+	}
+	if err := os.WriteFile(c.statePath, raw, 0o644); err != nil {
+		c.logger.WithError(err).Warn("Failed to persist control state")
+	}
+}
+
+// intervalNumbers describes the HA number entities for the interval targets.
+var intervalNumbers = []transmission.IntervalNumber{
+	{Target: "poll", Name: "Poll Interval", Min: 2, Max: 600},
+	{Target: "mqtt", Name: "MQTT Interval", Min: 5, Max: 3600},
+	{Target: "abrp", Name: "ABRP Interval", Min: 2, Max: 600},
+	{Target: "traccar", Name: "Traccar Interval", Min: 5, Max: 3600},
+}
+
+// settingSwitches describes the HA switch entities for the pause and privacy
+// commands.
+var settingSwitches = []transmission.SettingSwitch{
+	{Key: "location_privacy", Name: "Location Privacy", Icon: "mdi:map-marker-off", CommandOn: "privacy_on", CommandOff: "privacy_off"},
+	{Key: "paused", Name: "Pause Telemetry", Icon: "mdi:pause-circle-outline", CommandOn: "pause", CommandOff: "resume"},
+}
+
+// preconditionSwitch turns the preconditioning schedule off without
+// removing it from the configuration.
+var preconditionSwitch = transmission.SettingSwitch{
+	Key: "precondition_schedule", Name: "Preconditioning Schedule", Icon: "mdi:calendar-clock", CommandOn: "precondition_on", CommandOff: "precondition_off",
+}
+
+// refreshButton forces an immediate poll and publish.
+var refreshButton = transmission.CommandButton{
+	Key: "force_refresh", Name: "Refresh Data", Icon: "mdi:refresh", Command: "force_update", Category: "diagnostic",
+}
+
+// carButtons trigger Diplus commands; each is only registered when its
+// command is mapped.
+var carButtons = map[api.Command]transmission.CommandButton{
+	api.CmdFlashLights: {Key: "flash_lights", Name: "Flash Lights", Icon: "mdi:car-light-high", Command: string(api.CmdFlashLights)},
+	api.CmdHonk:        {Key: "honk", Name: "Honk Horn", Icon: "mdi:bullhorn", Command: string(api.CmdHonk)},
+}
+
+// registerSettings exposes the intervals as number entities and the
+// pause/privacy switches, then publishes their current values.
+func (c *controller) registerSettings() {
+	switches := settingSwitches
+	if c.schedule {
+		switches = append(switches[:len(switches):len(switches)], preconditionSwitch)
+	}
+	for _, s := range switches {
+		if err := c.mqttTx.RegisterSettingSwitch(s); err != nil {
+			c.logger.WithError(err).WithField("switch", s.Key).Warn("Failed to register switch")
+		}
+	}
+	buttons := []transmission.CommandButton{refreshButton}
+	for _, cmd := range []api.Command{api.CmdFlashLights, api.CmdHonk} {
+		if c.control.Supports(cmd) {
+			buttons = append(buttons, carButtons[cmd])
+		}
+	}
+	for _, b := range buttons {
+		if err := c.mqttTx.RegisterCommandButton(b); err != nil {
+			c.logger.WithError(err).WithField("button", b.Key).Warn("Failed to register button")
+		}
+	}
+	for _, n := range intervalNumbers {
+		if _, ok := c.intervals[n.Target]; !ok {
+			continue
+		}
+		if err := c.mqttTx.RegisterIntervalNumber(n); err != nil {
+			c.logger.WithError(err).WithField("target", n.Target).Warn("Failed to register interval number")
+		}
+	}
+	c.publishSettings()
+}
+
+// publishSettings publishes the current interval values in seconds and the
+// switch states.
+func (c *controller) publishSettings() {
+	c.settingsMu.Lock()
+	settings := make(map[string]interface{}, len(c.intervals)+2)
+	for target, d := range c.intervals {
+		settings[target+"_interval"] = int(d.Seconds())
+	}
+	c.settingsMu.Unlock()
+	settings["paused"] = onOff(c.paused.Load())
+	settings["location_privacy"] = onOff(c.privacy.Load())
+	if c.schedule {
+		settings["precondition_schedule"] = onOff(!c.precondOff.Load())
+	}
+
+	if err := c.mqttTx.PublishSettings(settings); err != nil {
+		c.logger.WithError(err).Debug("Failed to publish settings")
+	}
+}
+
+func onOff(b bool) string {
+	if b {
+		return "ON"
+	}
+	return "OFF"
+}
+
+// Paused reports whether transmissions are suspended.
+func (c *controller) Paused() bool {
+	return c != nil && c.paused.Load()
+}
+
+// LocationPrivacy reports whether location publishing is suspended.
+func (c *controller) LocationPrivacy() bool {
+	return c != nil && c.privacy.Load()
+}
+
+// PreconditionEnabled reports whether the preconditioning schedule is
+// switched on.
+func (c *controller) PreconditionEnabled() bool {
+	return c == nil || !c.precondOff.Load()
+}
+
+// setSwitch updates a persisted switch and reports the new state.
+func (c *controller) setSwitch(sw *atomic.Bool, on bool) {
+	sw.Store(on)
+	c.saveState()
+	c.publishSettings()
+}
+
+// handle applies a remote command.
+func (c *controller) handle(cmd transmission.Command) {
+	log := c.logger.WithField("command", cmd.Command)
+	switch cmd.Command {
+	case "force_update":
+		select {
+		case c.forceCh <- struct{}{}:
+		default: // one pending force is enough
+		}
+	case "set_interval":
+		d, err := time.ParseDuration(cmd.Interval)
+		if err != nil || d < minRemoteInterval || d > maxRemoteInterval {
+			log.WithField("interval", cmd.Interval).Warnf("Interval must be a duration between %s and %s", minRemoteInterval, maxRemoteInterval)
+			return
+		}
+		c.setInterval(cmd.Target, d)
+	case "pause":
+		c.setSwitch(&c.paused, true)
+		log.Info("Transmissions paused")
+	case "resume":
+		c.setSwitch(&c.paused, false)
+		log.Info("Transmissions resumed")
+	case "privacy_on":
+		c.setSwitch(&c.privacy, true)
+		log.Info("Location privacy enabled; location is no longer published")
+	case "privacy_off":
+		c.setSwitch(&c.privacy, false)
+		log.Info("Location privacy disabled")
+	case "precondition_on":
+		c.setSwitch(&c.precondOff, false)
+		log.Info("Preconditioning schedule enabled")
+	case "precondition_off":
+		c.setSwitch(&c.precondOff, true)
+		log.Info("Preconditioning schedule disabled")
+	case string(api.CmdFlashLights), string(api.CmdHonk):
+		if !c.control.Supports(api.Command(cmd.Command)) {
+			log.Warn("Command needs a Diplus command template (-diplus-commands)")
+			return
+		}
+		// Don't block the MQTT callback on the HTTP round trip.
+		go c.sendCarCommand(api.Command(cmd.Command), log)
+	case "republish_discovery":
+		if err := c.mqttTx.RepublishDiscovery(); err != nil {
+			log.WithError(err).Warn("Republishing discovery failed")
+		}
+	case "restart":
+		log.Warn("Restart requested; exiting so the keep-alive script restarts byd-hass")
+		c.restart()
+	default:
+		log.Warn("Ignoring unknown MQTT command")
+	}
+}
+
+// sendCarCommand sends a one-shot Diplus command such as flash_lights.
+func (c *controller) sendCarCommand(cmd api.Command, log *logrus.Entry) {
+	ctx, cancel := context.WithTimeout(context.Background(), carCommandTimeout)
+	defer cancel()
+	var err error
+	switch cmd {
+	case api.CmdFlashLights:
+		err = c.control.FlashLights(ctx)
+	case api.CmdHonk:
+		err = c.control.Honk(ctx)
+	}
+	if err != nil {
+		log.WithError(err).Warn("Diplus command failed")
+		return
+	}
+	log.Info("Diplus command executed")
+}
+
+// setInterval routes an interval change to the collector or scheduler.
+func (c *controller) setInterval(target string, d time.Duration) {
+	log := c.logger.WithFields(logrus.Fields{"target": target, "interval": d})
+	c.settingsMu.Lock()
+	_, known := c.intervals[target]
+	c.settingsMu.Unlock()
+	if !known {
+		log.Warn("set_interval target must be one of poll, mqtt, abrp or traccar (and enabled)")
+		return
+	}
+
+	switch target {
+	case "poll":
+		select {
+		case c.pollCh <- d:
+		default:
+			// Replace the pending value with the newest one.
+			select {
+			case <-c.pollCh:
+			default:
+			}
+			c.pollCh <- d
+		}
+	case "mqtt", "abrp", "traccar":
+		names := map[string]string{"mqtt": "MQTT", "abrp": "ABRP", "traccar": "Traccar"}
+		select {
+		case c.intervalCh <- intervalChange{transmitter: names[target], interval: d}:
+		default:
+			log.Warn("Too many pending interval changes, ignoring")
+			return
+		}
+	}
+
+	c.settingsMu.Lock()
+	c.intervals[target] = d
+	c.settingsMu.Unlock()
+	c.publishSettings()
+	log.Info("Interval change requested")
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/api"
+	"github.com/sirupsen/logrus"
+)
+
+// Diplus outage handling: after diplusDownAfter consecutive failed polls
+// the link counts as down and polls back off exponentially, from the poll
+// interval up to diplusMaxBackoff, until one succeeds again. This rides out
+// Diplus restarts and OTA updates without hammering it or the log.
+const (
+	diplusDownAfter  = 3
+	diplusMaxBackoff = 5 * time.Minute
+)
+
+// diplusLink tracks consecutive poll failures. It is used by the collector
+// goroutine only.
+type diplusLink struct {
+	failures int
+}
+
+// down reports whether Diplus is currently considered unreachable.
+func (l *diplusLink) down() bool {
+	return l.failures >= diplusDownAfter
+}
+
+// failed records a failed poll. It returns the interval until the next
+// probe (0 while the link isn't down yet) and whether this failure took the
+// link down.
+func (l *diplusLink) failed(interval time.Duration) (next time.Duration, wentDown bool) {
+	l.failures++
+	if !l.down() {
+		return 0, false
+	}
+	next = interval
+	for i := diplusDownAfter; i < l.failures && next < diplusMaxBackoff; i++ {
+		next *= 2
+	}
+	if next > diplusMaxBackoff {
+		next = diplusMaxBackoff
+	}
+	return next, l.failures == diplusDownAfter
+}
+
+// succeeded records a successful poll and reports whether the link was down.
+func (l *diplusLink) succeeded() (wasDown bool) {
+	wasDown = l.down()
+	l.failures = 0
+	return wasDown
+}
+
+// resetBackoff restarts the backoff from the poll interval, e.g. after the
+// phone resumed from a suspend, which says nothing about Diplus. A link that
+// is down stays down until a poll succeeds.
+func (l *diplusLink) resetBackoff() {
+	if l.down() {
+		l.failures = diplusDownAfter
+	}
+}
+
+// logSecondarySourceErrors warns about every source other than Diplus that
+// failed in a poll; Diplus failures go through diplusLink instead.
+func logSecondarySourceErrors(logger *logrus.Logger, err error, tier string) {
+	var failed api.SourceErrors
+	if !errors.As(err, &failed) {
+		return
+	}
+	for name, err := range failed {
+		if name != api.DiplusSourceName {
+			logger.WithError(err).WithFields(logrus.Fields{"source": name, "tier": tier}).Warn("collector: data source poll failed")
+		}
+	}
+}
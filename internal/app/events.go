@@ -0,0 +1,643 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/charging"
+	"github.com/jkaberg/byd-hass/internal/config"
+	"github.com/jkaberg/byd-hass/internal/domain"
+	"github.com/jkaberg/byd-hass/internal/events"
+	"github.com/jkaberg/byd-hass/internal/geofence"
+	"github.com/jkaberg/byd-hass/internal/hook"
+	"github.com/jkaberg/byd-hass/internal/notify"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/jkaberg/byd-hass/internal/sentry"
+	"github.com/jkaberg/byd-hass/internal/stats"
+	"github.com/jkaberg/byd-hass/internal/transmission"
+	"github.com/jkaberg/byd-hass/internal/upload"
+	"github.com/jkaberg/byd-hass/internal/wifi"
+	"github.com/sirupsen/logrus"
+)
+
+// Event watchers -----------------------------------------------------------
+//
+// Each watcher owns its own bus subscription and publishes events as soon as
+// a condition is detected, independently of the MQTT state interval.
+
+// eventPublisher fires events on their MQTT event entities and runs the exec
+// hook for them. Either may be missing; without MQTT the hook still sees
+// every event.
+type eventPublisher struct {
+	mqtt   *transmission.MQTTTransmitter
+	hooks  *hook.Runner
+	paused func() bool // drops events while telemetry is paused
+}
+
+// register publishes the discovery config of an event entity, if MQTT is
+// configured.
+func (p *eventPublisher) register(ev transmission.EventEntity) error {
+	if p.mqtt == nil {
+		return nil
+	}
+	return p.mqtt.RegisterEventEntity(ev)
+}
+
+// publish hands the event to the hook and publishes it on MQTT.
+func (p *eventPublisher) publish(ctx context.Context, entityID, eventType string, attrs map[string]interface{}) error {
+	if p.paused != nil && p.paused() {
+		return nil
+	}
+	data, err := transmission.EventPayload(eventType, attrs)
+	if err != nil {
+		return err
+	}
+	p.hooks.Trigger(ctx, entityID, data)
+	if p.mqtt == nil {
+		return nil
+	}
+	return p.mqtt.PublishEventPayload(entityID, eventType, data)
+}
+
+// runGeofence watches location updates and fires zone enter/leave events as
+// soon as they happen rather than waiting for the next MQTT interval.
+func runGeofence(ctx context.Context, sub <-chan *sensors.SensorData, tracker *geofence.Tracker, pub *eventPublisher, logger *logrus.Logger) error {
+	zoneEvent := transmission.EventEntity{
+		EntityID:   "zone_event",
+		Name:       "Zone",
+		Icon:       "mdi:map-marker-radius",
+		EventTypes: []string{"enter", "leave"},
+	}
+	if err := pub.register(zoneEvent); err != nil {
+		logger.WithError(err).Warn("geofence: failed to register zone event entity")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			for _, tr := range tracker.Update(snap.Location) {
+				eventType := "leave"
+				if tr.Enter {
+					eventType = "enter"
+				}
+				logger.WithFields(logrus.Fields{"zone": tr.Zone, "event": eventType}).Info("geofence: zone transition")
+				if err := pub.publish(ctx, zoneEvent.EntityID, eventType, map[string]interface{}{"zone": tr.Zone}); err != nil {
+					logger.WithError(err).Warn("geofence: failed to publish zone event")
+				}
+			}
+		}
+	}
+}
+
+// runDeviceTriggers fires Home Assistant device triggers for door, charging
+// and sentry transitions.
+func runDeviceTriggers(ctx context.Context, sub <-chan *sensors.SensorData, detector *events.TriggerDetector, mqttTx *transmission.MQTTTransmitter, logger *logrus.Logger) error {
+	for _, tr := range events.DeviceTriggers {
+		if err := mqttTx.RegisterDeviceTrigger(tr.ID, tr.Type, tr.Subtype); err != nil {
+			logger.WithError(err).WithField("trigger", tr.ID).Warn("triggers: failed to register device trigger")
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			for _, id := range detector.Update(snap) {
+				if err := mqttTx.FireDeviceTrigger(id); err != nil {
+					logger.WithError(err).WithField("trigger", id).Warn("triggers: failed to fire device trigger")
+				}
+			}
+		}
+	}
+}
+
+// alerter delivers alert notifications: a Termux notification on the head
+// unit when enabled for the alert, and a push notification when -notify-url
+// is set. Push delivery runs in the background so a slow service doesn't
+// hold up the subscriber.
+type alerter struct {
+	push   *notify.Pusher
+	paused func() bool // drops alerts while telemetry is paused
+	logger *logrus.Logger
+}
+
+func (a *alerter) send(ctx context.Context, id, title, content, priority string, termux bool) {
+	if a.paused != nil && a.paused() {
+		a.logger.WithField("alert", id).Debug("notify: paused, alert dropped")
+		return
+	}
+	if termux {
+		if err := notify.Termux(ctx, id, title, content, priority); err != nil {
+			a.logger.WithError(err).Debug("notify: termux notification failed")
+		}
+	}
+	if a.push == nil {
+		return
+	}
+	go func() {
+		if err := a.push.Send(ctx, title, content, priority); err != nil {
+			a.logger.WithError(err).WithField("service", a.push.String()).Warn("notify: push notification failed")
+		}
+	}()
+}
+
+// runRules evaluates user-defined rules and publishes an event (plus an
+// optional Termux notification) whenever one starts matching.
+func runRules(ctx context.Context, sub <-chan *sensors.SensorData, engine *events.RuleEngine, pub *eventPublisher, alerts *alerter, notifyTermux bool, logger *logrus.Logger) error {
+	ruleEvent := transmission.EventEntity{
+		EntityID: "rule_alert",
+		Name:     "Rule Alert",
+		Icon:     "mdi:alert",
+	}
+	for _, r := range engine.Rules() {
+		ruleEvent.EventTypes = append(ruleEvent.EventTypes, r.Name)
+	}
+	if err := pub.register(ruleEvent); err != nil {
+		logger.WithError(err).Warn("rules: failed to register rule event entity")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			for _, hit := range engine.Update(snap) {
+				attrs := map[string]interface{}{
+					"rule":  hit.Rule.String(),
+					"field": hit.Rule.Field,
+					"value": hit.Value,
+				}
+				logger.WithFields(logrus.Fields(attrs)).Info("rules: rule matched")
+
+				if err := pub.publish(ctx, ruleEvent.EntityID, hit.Rule.Name, attrs); err != nil {
+					logger.WithError(err).Warn("rules: failed to publish rule event")
+				}
+				content := fmt.Sprintf("%s = %.1f (%s)", hit.Rule.Field, hit.Value, hit.Rule.String())
+				alerts.send(ctx, "byd-hass-rule-"+hit.Rule.Name, "BYD: "+hit.Rule.Name, content, notify.PriorityHigh, notifyTermux)
+			}
+		}
+	}
+}
+
+// runSpeedCheck compares GPS and wheel speed and publishes the verdict
+// whenever it changes.
+func runSpeedCheck(ctx context.Context, sub <-chan *sensors.SensorData, checker *events.SpeedChecker, mqttTx *transmission.MQTTTransmitter, logger *logrus.Logger) error {
+	if err := mqttTx.RegisterSpeedCheckSensor(); err != nil {
+		logger.WithError(err).Warn("speed check: failed to register speed_mismatch sensor")
+	}
+	// Publish the initial verdict so the entity isn't unknown until the
+	// first drive.
+	if err := mqttTx.PublishSpeedCheck(events.SpeedCheckResult{}); err != nil {
+		logger.WithError(err).Warn("speed check: failed to publish verdict")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			res, changed := checker.Update(snap)
+			if !changed {
+				continue
+			}
+			entry := logger.WithFields(logrus.Fields{"reason": res.Reason, "ratio": res.Ratio, "samples": res.Samples})
+			if res.Problem {
+				entry.Warn("speed check: GPS and wheel speed disagree")
+			} else {
+				entry.Info("speed check: GPS and wheel speed agree again")
+			}
+			if err := mqttTx.PublishSpeedCheck(res); err != nil {
+				logger.WithError(err).Warn("speed check: failed to publish verdict")
+			}
+		}
+	}
+}
+
+// runTheftAlert publishes a high-priority event and notification when the car
+// moves while locked with power off.
+func runTheftAlert(ctx context.Context, sub <-chan *sensors.SensorData, detector *events.TheftDetector, pub *eventPublisher, alerts *alerter, logger *logrus.Logger) error {
+	theftEvent := transmission.EventEntity{
+		EntityID:   "theft_alert",
+		Name:       "Theft Alert",
+		Icon:       "mdi:car-emergency",
+		EventTypes: []string{"movement_while_locked"},
+	}
+	if err := pub.register(theftEvent); err != nil {
+		logger.WithError(err).Warn("theft: failed to register theft event entity")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			alert := detector.Update(snap)
+			if alert == nil {
+				continue
+			}
+
+			attrs := map[string]interface{}{
+				"reason":       alert.Reason,
+				"speed":        alert.Speed,
+				"displacement": alert.Displacement,
+				"priority":     "high",
+			}
+			if alert.Latitude != 0 || alert.Longitude != 0 {
+				attrs["latitude"] = alert.Latitude
+				attrs["longitude"] = alert.Longitude
+			}
+			logger.WithFields(logrus.Fields(attrs)).Warn("theft: movement while locked")
+
+			if err := pub.publish(ctx, theftEvent.EntityID, "movement_while_locked", attrs); err != nil {
+				logger.WithError(err).Warn("theft: failed to publish theft event")
+			}
+			content := fmt.Sprintf("Car moved while locked (%s)", alert.Reason)
+			alerts.send(ctx, "byd-hass-theft", "BYD: movement while locked", content, notify.PriorityMax, true)
+		}
+	}
+}
+
+// runParkedReminders publishes an event (plus an optional Termux
+// notification) when doors or lights are left open or on after parking.
+func runParkedReminders(ctx context.Context, sub <-chan *sensors.SensorData, detector *events.ReminderDetector, pub *eventPublisher, alerts *alerter, notifyTermux bool, logger *logrus.Logger) error {
+	reminderEvent := transmission.EventEntity{
+		EntityID:   "parked_reminder",
+		Name:       "Parked Reminder",
+		Icon:       "mdi:car-door",
+		EventTypes: events.ReminderKinds,
+	}
+	if err := pub.register(reminderEvent); err != nil {
+		logger.WithError(err).Warn("reminder: failed to register parked reminder event entity")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			for _, r := range detector.Update(snap) {
+				logger.WithFields(logrus.Fields{"kind": r.Kind, "items": r.Items}).Info("reminder: left open after parking")
+
+				attrs := map[string]interface{}{"items": r.Items}
+				if err := pub.publish(ctx, reminderEvent.EntityID, r.Kind, attrs); err != nil {
+					logger.WithError(err).Warn("reminder: failed to publish parked reminder event")
+				}
+				title := "BYD: door left open"
+				if r.Kind == events.ReminderLightsOn {
+					title = "BYD: lights left on"
+				}
+				content := strings.ReplaceAll(strings.Join(r.Items, ", "), "_", " ")
+				alerts.send(ctx, "byd-hass-reminder-"+r.Kind, title, content, notify.PriorityHigh, notifyTermux)
+			}
+		}
+	}
+}
+
+// chargeReminderText holds the notification title and body per reminder kind.
+var chargeReminderText = map[string][2]string{
+	events.ChargeComplete:    {"BYD: charging complete", "Charged to %.0f%%"},
+	events.ChargeInterrupted: {"BYD: charging interrupted", "Charging stopped at %.0f%%"},
+	events.PlugInReminder:    {"BYD: plug in", "Parked at home with %.0f%% and not plugged in"},
+}
+
+// runChargeReminders publishes an event and a Termux notification for
+// finished or interrupted charging and for a car left unplugged at home with
+// a low SOC. Without home the plug-in reminder never fires.
+func runChargeReminders(ctx context.Context, sub <-chan *sensors.SensorData, detector *events.ChargeReminderDetector, home *geofence.Zone, pub *eventPublisher, alerts *alerter, logger *logrus.Logger) error {
+	reminderEvent := transmission.EventEntity{
+		EntityID:   "charge_reminder",
+		Name:       "Charge Reminder",
+		Icon:       "mdi:ev-station",
+		EventTypes: events.ChargeReminderKinds,
+	}
+	if err := pub.register(reminderEvent); err != nil {
+		logger.WithError(err).Warn("charging reminders: failed to register charge reminder event entity")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			loc := snap.Location
+			atHome := home != nil && loc != nil && loc.Provider != "default" && home.Contains(loc.Latitude, loc.Longitude)
+			for _, r := range detector.Update(snap, atHome) {
+				logger.WithFields(logrus.Fields{"kind": r.Kind, "soc": r.SOC}).Info("charging reminders: reminder due")
+
+				attrs := map[string]interface{}{"battery_percentage": r.SOC}
+				if err := pub.publish(ctx, reminderEvent.EntityID, r.Kind, attrs); err != nil {
+					logger.WithError(err).Warn("charging reminders: failed to publish charge reminder event")
+				}
+				text := chargeReminderText[r.Kind]
+				alerts.send(ctx, "byd-hass-"+r.Kind, text[0], fmt.Sprintf(text[1], r.SOC), notify.PriorityDefault, true)
+			}
+		}
+	}
+}
+
+// runSentry publishes an event and, with MQTT, the stored snapshot whenever
+// the head unit reports a new sentry trigger.
+func runSentry(ctx context.Context, sub <-chan *sensors.SensorData, watcher *sentry.Watcher, pub *eventPublisher, logger *logrus.Logger) error {
+	mqttTx := pub.mqtt
+	const imageEntity, imageName = "sentry_snapshot", "Sentry Snapshot"
+	sentryEvent := transmission.EventEntity{
+		EntityID:   "sentry_event",
+		Name:       "Sentry",
+		Icon:       "mdi:cctv",
+		EventTypes: []string{"triggered"},
+	}
+	if err := pub.register(sentryEvent); err != nil {
+		logger.WithError(err).Warn("sentry: failed to register sentry event entity")
+	}
+	// Most head units store JPEGs; the entity is re-registered below when a
+	// snapshot turns out to be another format.
+	if mqttTx != nil {
+		if err := mqttTx.RegisterImageEntity(imageEntity, imageName, "image/jpeg"); err != nil {
+			logger.WithError(err).Warn("sentry: failed to register snapshot image entity")
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			tr := watcher.Update(snap)
+			if tr == nil {
+				continue
+			}
+
+			attrs := map[string]interface{}{}
+			if !tr.Time.IsZero() {
+				attrs["trigger_time"] = tr.Time.Format(time.RFC3339)
+			}
+			if tr.ImagePath != "" {
+				attrs["image_path"] = tr.ImagePath
+			}
+			if tr.ImagePath != "" && mqttTx != nil {
+				if img, contentType, err := sentry.ReadImage(tr.ImagePath); err != nil {
+					logger.WithError(err).Warn("sentry: snapshot unavailable")
+				} else if err := mqttTx.RegisterImageEntity(imageEntity, imageName, contentType); err != nil {
+					logger.WithError(err).Warn("sentry: failed to register snapshot image entity")
+				} else if err := mqttTx.PublishImage(imageEntity, img); err != nil {
+					logger.WithError(err).Warn("sentry: failed to publish snapshot")
+				}
+			}
+
+			logger.WithFields(logrus.Fields(attrs)).Info("sentry: trigger detected")
+			if err := pub.publish(ctx, sentryEvent.EntityID, "triggered", attrs); err != nil {
+				logger.WithError(err).Warn("sentry: failed to publish sentry event")
+			}
+		}
+	}
+}
+
+// runHook runs the exec hook with the JSON snapshot whenever the data changed.
+func runHook(ctx context.Context, sub <-chan *sensors.SensorData, runner *hook.Runner, logger *logrus.Logger) error {
+	var last *sensors.SensorData
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			if !domain.Changed(last, snap) {
+				continue
+			}
+			last = snap
+			payload, err := json.Marshal(snap)
+			if err != nil {
+				logger.WithError(err).Warn("hook: failed to encode snapshot")
+				continue
+			}
+			runner.Trigger(ctx, hook.KindChange, payload)
+		}
+	}
+}
+
+// Accumulated totals (statistics, charging cost) are published at most this
+// often and persisted less often to spare the head unit's flash storage.
+const (
+	totalsPublishInterval = time.Minute
+	totalsSaveInterval    = 5 * time.Minute
+)
+
+// totalsSync throttles publishing and persisting of accumulated totals.
+type totalsSync struct {
+	publish, save         func() error
+	lastPublish, lastSave time.Time
+	unpublished, unsaved  bool
+	name                  string
+	logger                *logrus.Logger
+}
+
+// update records whether the totals changed and publishes / persists them
+// when their interval has elapsed.
+func (s *totalsSync) update(changed bool) {
+	if changed {
+		s.unpublished, s.unsaved = true, true
+	}
+	now := time.Now()
+	if s.unpublished && now.Sub(s.lastPublish) >= totalsPublishInterval {
+		if err := s.publish(); err != nil {
+			s.logger.WithError(err).Debugf("%s: failed to publish", s.name)
+		} else {
+			s.lastPublish, s.unpublished = now, false
+		}
+	}
+	if s.unsaved && now.Sub(s.lastSave) >= totalsSaveInterval {
+		s.flush()
+		s.lastSave = now
+	}
+}
+
+// flush persists unsaved totals, e.g. on shutdown.
+func (s *totalsSync) flush() {
+	if !s.unsaved {
+		return
+	}
+	if err := s.save(); err != nil {
+		s.logger.WithError(err).Warnf("%s: failed to persist", s.name)
+		return
+	}
+	s.unsaved = false
+}
+
+// runStatistics accumulates per-period distance and energy totals, publishes
+// them over MQTT and persists them to path.
+func runStatistics(ctx context.Context, sub <-chan *sensors.SensorData, tracker *stats.Tracker, path string, mqttTx *transmission.MQTTTransmitter, logger *logrus.Logger) error {
+	if err := mqttTx.RegisterStatisticsSensors(); err != nil {
+		logger.WithError(err).Warn("stats: failed to register statistics sensors")
+	}
+	totals := &totalsSync{
+		publish: func() error { return mqttTx.PublishStatistics(tracker) },
+		save:    func() error { return tracker.Save(path) },
+		name:    "stats",
+		logger:  logger,
+	}
+	totals.unpublished = true // publish restored totals right away
+
+	for {
+		select {
+		case <-ctx.Done():
+			totals.flush()
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				totals.flush()
+				return nil
+			}
+			totals.update(tracker.Observe(snap))
+		}
+	}
+}
+
+// runChargingCurves records DC charging sessions and, once the gun is
+// unplugged, stores the curve as CSV in dir and publishes it over MQTT.
+func runChargingCurves(ctx context.Context, sub <-chan *sensors.SensorData, recorder *charging.CurveRecorder, dir string, mqttTx *transmission.MQTTTransmitter, logger *logrus.Logger) error {
+	if mqttTx != nil {
+		if err := mqttTx.RegisterChargingCurveSensor(); err != nil {
+			logger.WithError(err).Warn("charging curves: failed to register last_dc_charge sensor")
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			curve := recorder.Observe(snap)
+			if curve == nil {
+				continue
+			}
+			from, to := curve.SOCRange()
+			entry := logger.WithFields(logrus.Fields{"soc_from": from, "soc_to": to, "peak_kw": curve.PeakPower(), "samples": len(curve.Samples)})
+			if path, err := curve.WriteCSV(dir); err != nil {
+				entry.WithError(err).Warn("charging curves: failed to save curve")
+			} else {
+				entry.WithField("path", path).Info("charging curves: DC session recorded")
+			}
+			if mqttTx != nil {
+				if err := mqttTx.PublishChargingCurve(curve); err != nil {
+					logger.WithError(err).Warn("charging curves: failed to publish curve")
+				}
+			}
+		}
+	}
+}
+
+// runChargingCost tracks charging sessions and their cost. When homeZone is
+// set, only sessions that start inside it count towards the monthly totals.
+func runChargingCost(ctx context.Context, sub <-chan *sensors.SensorData, tracker *charging.Tracker, path, currency string, homeZone *geofence.Zone, mqttTx *transmission.MQTTTransmitter, logger *logrus.Logger) error {
+	if err := mqttTx.RegisterChargingCostSensors(currency); err != nil {
+		logger.WithError(err).Warn("charging: failed to register cost sensors")
+	}
+	totals := &totalsSync{
+		publish: func() error { return mqttTx.PublishChargingCost(tracker) },
+		save:    func() error { return tracker.Save(path) },
+		name:    "charging",
+		logger:  logger,
+	}
+	totals.unpublished = true
+
+	for {
+		select {
+		case <-ctx.Done():
+			totals.flush()
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				totals.flush()
+				return nil
+			}
+			atHome := true
+			if homeZone != nil {
+				loc := snap.Location
+				atHome = loc != nil && loc.Provider != "default" && homeZone.Contains(loc.Latitude, loc.Longitude)
+			}
+			wasActive := tracker.Active
+			changed := tracker.Observe(snap, atHome)
+			if tracker.Active && !wasActive {
+				logger.WithField("at_home", tracker.Current.Counted).Info("charging: session started")
+			}
+			totals.update(changed)
+		}
+	}
+}
+
+// newVideoUploadManager builds the upload manager, gating uploads on WiFi
+// (the configured network, if any) and forwarding progress as events.
+func newVideoUploadManager(ctx context.Context, cfg *config.Config, uploader upload.Uploader, pub *eventPublisher, logger *logrus.Logger) *upload.Manager {
+	opts := upload.Options{
+		MaxAge:            cfg.UploadMaxAge,
+		DeleteAfterUpload: cfg.UploadDeleteLocal,
+	}
+
+	// Recordings run to hundreds of MB, so cellular needs an explicit opt-in.
+	if cfg.UploadWiFiSSID != "" || !cfg.UploadCellular {
+		wifiManager := wifi.NewWiFiManager(logger)
+		opts.CanUpload = func(ctx context.Context) bool {
+			ssid, err := wifiManager.CurrentSSID(ctx)
+			if err != nil {
+				logger.WithError(err).Debug("upload: cannot determine WiFi network")
+				return false
+			}
+			if cfg.UploadWiFiSSID != "" {
+				return ssid == cfg.UploadWiFiSSID
+			}
+			return ssid != ""
+		}
+	}
+
+	uploadEvent := transmission.EventEntity{
+		EntityID:   "video_upload",
+		Name:       "Video Upload",
+		Icon:       "mdi:cloud-upload",
+		EventTypes: []string{"started", "progress", "completed", "failed"},
+	}
+	if err := pub.register(uploadEvent); err != nil {
+		logger.WithError(err).Warn("upload: failed to register upload event entity")
+	}
+	opts.Notify = func(eventType string, attrs map[string]interface{}) {
+		if err := pub.publish(ctx, uploadEvent.EntityID, eventType, attrs); err != nil {
+			logger.WithError(err).Debug("upload: failed to publish upload event")
+		}
+	}
+
+	return upload.NewManager(uploader, opts, logger)
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/api"
+	"github.com/jkaberg/byd-hass/internal/precondition"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+// preconditionCheckInterval is how often the schedule is evaluated. Entries
+// fire once when their start time passes, so this only bounds the delay.
+const preconditionCheckInterval = 30 * time.Second
+
+// preconditionMaxDelay is how late a start may still fire, e.g. after the
+// phone resumes from a suspend that spanned the scheduled time.
+const preconditionMaxDelay = 10 * time.Minute
+
+// runPreconditioning switches the climate on at the scheduled times. With
+// requirePlug it only does so while the charge gun is connected, so the
+// battery isn't drained for a warm cabin. enabled reflects the Preconditioning
+// Schedule switch.
+func runPreconditioning(ctx context.Context, sub <-chan *sensors.SensorData, entries []precondition.Entry, control *api.DiplusControl, requirePlug bool, enabled func() bool, logger *logrus.Logger) error {
+	ticker := time.NewTicker(preconditionCheckInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	var latest *sensors.SensorData
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case snap, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			latest = snap
+		case now := <-ticker.C:
+			// Compare wall clocks: the monotonic clock stops during a
+			// suspend (see resumeDetector).
+			from := last
+			if now.Round(0).Sub(last.Round(0)) > preconditionMaxDelay {
+				from = now.Add(-preconditionMaxDelay)
+			}
+			for _, e := range entries {
+				if e.DueBetween(from, now) {
+					startPreconditioning(ctx, e, latest, control, requirePlug, enabled(), logger)
+				}
+			}
+			last = now
+		}
+	}
+}
+
+func startPreconditioning(ctx context.Context, e precondition.Entry, latest *sensors.SensorData, control *api.DiplusControl, requirePlug, enabled bool, logger *logrus.Logger) {
+	log := logger.WithField("entry", e.String())
+	switch {
+	case !enabled:
+		log.Info("precondition: schedule switched off, skipping")
+		return
+	case requirePlug && sensors.DeriveChargingStatus(latest) == "disconnected":
+		log.Info("precondition: car not plugged in, skipping")
+		return
+	}
+
+	if e.Temperature > 0 {
+		if !control.Supports(api.CmdACTemperature) {
+			log.Warn("precondition: no ac_temperature command, keeping the car's temperature")
+		} else if err := control.SetACTemperature(ctx, e.Temperature); err != nil {
+			log.WithError(err).Warn("precondition: failed to set temperature")
+		}
+	}
+	if err := control.SetClimate(ctx, true); err != nil {
+		log.WithError(err).Warn("precondition: failed to switch the climate on")
+		return
+	}
+	log.Info("precondition: climate switched on")
+}
@@ -0,0 +1,39 @@
+package app
+
+import "time"
+
+// Suspend detection: Android dozes or suspends the phone while the car is
+// off. Go's monotonic clock (used by tickers and time.Since) stops while the
+// phone is suspended, but the wall clock keeps running, so after a resume a
+// ticker fires "on time" although minutes or hours went by. Comparing both
+// clocks between ticks reveals the gap. A process frozen without a suspend
+// (the cached-app freezer) instead shows up as a monotonic gap far longer
+// than the tick interval.
+const resumeThreshold = 30 * time.Second
+
+// resumeDetector compares consecutive ticks. It is used by one goroutine
+// only.
+type resumeDetector struct {
+	last time.Time // with monotonic reading
+}
+
+// check records a tick at now and reports how long the process was away when
+// it was suspended or frozen since the previous tick, which was expected
+// interval ago. A wall clock set forward by more than resumeThreshold counts
+// as well; that is harmless, it only causes an early poll.
+func (r *resumeDetector) check(now time.Time, interval time.Duration) (gap time.Duration, resumed bool) {
+	last := r.last
+	r.last = now
+	if last.IsZero() {
+		return 0, false
+	}
+	mono := now.Sub(last)
+	wall := now.Round(0).Sub(last.Round(0))
+	switch {
+	case wall-mono > resumeThreshold:
+		return wall, true
+	case mono > interval+resumeThreshold:
+		return mono, true
+	}
+	return 0, false
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/crash"
+	"github.com/jkaberg/byd-hass/internal/status"
+	"github.com/sirupsen/logrus"
+)
+
+// restartPolicy controls how a supervised subsystem is restarted after it
+// returns an error or panics.
+type restartPolicy struct {
+	maxRestarts int           // give up after this many restarts in a row (0 = never)
+	minBackoff  time.Duration // first restart delay, doubled per failure
+	maxBackoff  time.Duration // cap; a run lasting longer resets the delay
+}
+
+var (
+	// policyCritical keeps the collector and scheduler alive indefinitely.
+	policyCritical = restartPolicy{minBackoff: time.Second, maxBackoff: time.Minute}
+	// policyDefault gives optional features a few chances before disabling
+	// them, so one broken feature can't take the bridge down.
+	policyDefault = restartPolicy{maxRestarts: 10, minBackoff: 5 * time.Second, maxBackoff: 5 * time.Minute}
+)
+
+// supervisor runs the app's subsystems. Unlike an errgroup, one failing
+// subsystem doesn't stop the others: it is restarted with backoff according
+// to its policy. A subsystem that returns nil or stops because ctx was
+// cancelled is finished.
+type supervisor struct {
+	ctx    context.Context
+	wg     sync.WaitGroup
+	health *status.Recorder
+	logger *logrus.Logger
+}
+
+func newSupervisor(ctx context.Context, health *status.Recorder, logger *logrus.Logger) *supervisor {
+	return &supervisor{ctx: ctx, health: health, logger: logger}
+}
+
+// Go runs fn as the named subsystem under policyDefault.
+func (s *supervisor) Go(name string, fn func() error) {
+	s.GoWithPolicy(name, policyDefault, fn)
+}
+
+// GoWithPolicy runs fn as the named subsystem under the given policy.
+func (s *supervisor) GoWithPolicy(name string, policy restartPolicy, fn func() error) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		backoff := policy.minBackoff
+		restarts := 0
+		for {
+			started := time.Now()
+			err := s.run(fn)
+			ran := time.Since(started)
+			s.health.Record(status.GroupSubsystem, name, ran, err)
+
+			if err == nil || s.ctx.Err() != nil || errors.Is(err, context.Canceled) {
+				return
+			}
+			if ran > policy.maxBackoff {
+				// It worked for a while; treat this as a fresh failure.
+				backoff, restarts = policy.minBackoff, 0
+			}
+			if policy.maxRestarts > 0 && restarts >= policy.maxRestarts {
+				s.logger.WithError(err).WithField("subsystem", name).Error("Subsystem keeps failing, giving up")
+				return
+			}
+			restarts++
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"subsystem": name,
+				"restart":   restarts,
+				"in":        backoff,
+			}).Warn("Subsystem failed, restarting")
+
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > policy.maxBackoff {
+				backoff = policy.maxBackoff
+			}
+		}
+	}()
+}
+
+// run calls fn, turning a panic into an error (reported to crash reporting)
+// so the subsystem can be restarted.
+func (s *supervisor) run(fn func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			crash.ReportPanic(v, debug.Stack())
+			err = fmt.Errorf("panic: %v", v)
+		}
+	}()
+	return fn()
+}
+
+// Wait blocks until every subsystem has finished.
+func (s *supervisor) Wait() {
+	s.wg.Wait()
+}
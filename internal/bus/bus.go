@@ -2,17 +2,57 @@ package bus
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/jkaberg/byd-hass/internal/sensors"
 )
 
+// DefaultBuffer is the subscriber channel size unless WithBuffer says
+// otherwise. A small buffer avoids blocking the publisher.
+const DefaultBuffer = 1
+
 // Bus provides fan-out pub/sub semantics for *sensors.SensorData* messages.
 // Each Subscribe call gets its own channel that receives every future
-// publication. Past messages are not replayed. The implementation is safe for
-// concurrent publishers and subscribers.
+// publication; WithReplay additionally delivers the latest snapshot right
+// away. The implementation is safe for concurrent publishers and subscribers.
 type Bus struct {
 	mu          sync.RWMutex
 	subscribers []chan *sensors.SensorData
+	latest      *sensors.SensorData
+
+	published uint64 // snapshots published
+	skipped   uint64 // deliveries skipped because a subscriber was busy
+}
+
+// Stats are the bus counters since start.
+type Stats struct {
+	Published   uint64 `json:"published"`
+	Skipped     uint64 `json:"skipped"`
+	Subscribers int    `json:"subscribers"`
+}
+
+// SubscribeOption customises a subscription.
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	buffer int
+	replay bool
+}
+
+// WithBuffer sets the subscriber's channel size. Slow consumers that must see
+// bursts of snapshots can use a larger buffer; n < 1 keeps the default.
+func WithBuffer(n int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		if n >= 1 {
+			o.buffer = n
+		}
+	}
+}
+
+// WithReplay delivers the most recent snapshot (if any) immediately, so a
+// late subscriber doesn't wait a full poll cycle for data.
+func WithReplay() SubscribeOption {
+	return func(o *subscribeOptions) { o.replay = true }
 }
 
 // New creates a ready-to-use Bus.
@@ -20,22 +60,31 @@ func New() *Bus { return &Bus{} }
 
 // Subscribe returns a read-only channel that will receive all future
 // SensorData snapshots.
-func (b *Bus) Subscribe() <-chan *sensors.SensorData {
-	ch := make(chan *sensors.SensorData, 1) // small buffer avoids blocking
+func (b *Bus) Subscribe(opts ...SubscribeOption) <-chan *sensors.SensorData {
+	o := subscribeOptions{buffer: DefaultBuffer}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ch := make(chan *sensors.SensorData, o.buffer)
 	b.mu.Lock()
 	b.subscribers = append(b.subscribers, ch)
+	if o.replay && b.latest != nil {
+		ch <- b.latest // the buffer is empty and at least 1
+	}
 	b.mu.Unlock()
 	return ch
 }
 
 // Publish delivers the snapshot to all subscribers in a best-effort, non-blocking
-// way. If a subscriber's buffer is full, the subscriber is dropped to keep the
-// producer quick and the overall system from stalling.
+// way. If a subscriber's buffer is full, the snapshot is skipped for that
+// subscriber to keep the producer quick and the overall system from stalling.
 func (b *Bus) Publish(s *sensors.SensorData) {
-	b.mu.RLock()
+	b.mu.Lock()
+	b.latest = s
 	subs := make([]chan *sensors.SensorData, len(b.subscribers))
 	copy(subs, b.subscribers)
-	b.mu.RUnlock()
+	b.mu.Unlock()
+	atomic.AddUint64(&b.published, 1)
 
 	for _, ch := range subs {
 		select {
@@ -44,7 +93,7 @@ func (b *Bus) Publish(s *sensors.SensorData) {
 			// Subscriber is currently busy; skip this snapshot instead of dropping the
 			// subscriber entirely. The consumer will receive the next snapshot once
 			// it has processed the current one.
-			continue
+			atomic.AddUint64(&b.skipped, 1)
 		}
 	}
 }
@@ -62,3 +111,33 @@ func (b *Bus) dropSubscriber(ch chan *sensors.SensorData) {
 	}
 	b.mu.Unlock()
 }
+
+// Latest returns the most recently published snapshot, or nil.
+func (b *Bus) Latest() *sensors.SensorData {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.latest
+}
+
+// Stats returns the bus counters.
+func (b *Bus) Stats() Stats {
+	b.mu.RLock()
+	n := len(b.subscribers)
+	b.mu.RUnlock()
+	return Stats{
+		Published:   atomic.LoadUint64(&b.published),
+		Skipped:     atomic.LoadUint64(&b.skipped),
+		Subscribers: n,
+	}
+}
+
+// Queued returns the number of snapshots waiting in subscriber buffers.
+func (b *Bus) Queued() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	n := 0
+	for _, ch := range b.subscribers {
+		n += len(ch)
+	}
+	return n
+}
@@ -0,0 +1,80 @@
+package charging
+
+import (
+	"math"
+	"time"
+)
+
+// Charge types reported by Classifier.
+const (
+	TypeNone    = "none"    // charge gun not connected
+	TypeUnknown = "unknown" // connected, not enough history yet
+	TypeAC      = "ac"
+	TypeDC      = "dc"
+)
+
+// TypeSensorIDs are the sensors the classifier uses: EnginePower,
+// ChargeGunState and MaxBatteryVoltage.
+var TypeSensorIDs = []int{10, 12, 17}
+
+// DCCurrentThreshold (A) is the battery current no on-board charger
+// reaches: 11 kW into even a small ~300 V pack stays below it, while DC
+// chargers exceed it long before 12 kW on such packs.
+const DCCurrentThreshold = 40.0
+
+// minPackVoltage (V) tells a traction pack reading from the 12 V battery,
+// which some cars report as MaxBatteryVoltage instead.
+const minPackVoltage = 100.0
+
+// acSettle is how long a session must charge without a DC sign before it
+// counts as AC. DC chargers ramp up over the first minute or two, so an
+// early low reading doesn't mean AC.
+const acSettle = 3 * time.Minute
+
+// Classifier tells AC from DC charging per session (plug-in to unplug). DC
+// is sticky: once a session exceeds DCPowerThreshold or DCCurrentThreshold
+// it stays DC through the taper at the end, when power drops to AC levels.
+// It is not safe for concurrent use.
+type Classifier struct {
+	kind     string
+	charging time.Duration // time spent charging this session
+	last     time.Time
+}
+
+// NewClassifier returns a classifier with no session.
+func NewClassifier() *Classifier { return &Classifier{kind: TypeNone} }
+
+// Observe folds in a snapshot: whether the gun is connected, the power into
+// the battery (kW, positive while charging) and the pack voltage if known;
+// readings below minPackVoltage are ignored.
+// It returns the session's charge type.
+func (c *Classifier) Observe(ts time.Time, connected bool, chargeKW float64, voltage *float64) string {
+	if !connected {
+		c.kind, c.charging, c.last = TypeNone, 0, time.Time{}
+		return c.kind
+	}
+	if c.kind == TypeNone {
+		c.kind = TypeUnknown
+	}
+	prev := c.last
+	c.last = ts
+	if c.kind == TypeDC || chargeKW <= 1 {
+		return c.kind
+	}
+
+	current := 0.0
+	if voltage != nil && *voltage >= minPackVoltage {
+		current = chargeKW * 1000 / *voltage
+	}
+	if chargeKW >= DCPowerThreshold || current >= DCCurrentThreshold {
+		c.kind = TypeDC
+		return c.kind
+	}
+	if !prev.IsZero() && ts.After(prev) {
+		c.charging += time.Duration(math.Min(float64(ts.Sub(prev)), float64(maxStep)))
+	}
+	if c.charging >= acSettle {
+		c.kind = TypeAC
+	}
+	return c.kind
+}
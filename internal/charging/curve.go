@@ -0,0 +1,164 @@
+package charging
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// CurveSensorIDs are the sensors the curve recorder samples on the fast
+// tier: EnginePower, ChargeGunState, BatteryPercentage and AvgBatteryTemp.
+var CurveSensorIDs = []int{10, 12, 33, 15}
+
+// CurveDir is the directory below the state directory that holds one CSV
+// file per recorded curve.
+const CurveDir = "charging_curves"
+
+// DCPowerThreshold (kW) separates DC fast charging from AC: BYD's on-board
+// chargers top out at 11 kW, so a session that ever draws more is DC.
+const DCPowerThreshold = 12.0
+
+// maxCurveSamples caps a curve's length; at a 15 s fast poll this covers
+// several hours.
+const maxCurveSamples = 2000
+
+// CurveSample is one point of a charging curve.
+type CurveSample struct {
+	Elapsed     time.Duration // since the session started
+	SOC         float64       // %
+	Power       float64       // kW into the battery
+	BatteryTemp *float64      // average cell temperature, °C
+}
+
+// Curve is a completed DC charging session.
+type Curve struct {
+	Start, End time.Time
+	Samples    []CurveSample
+}
+
+// PeakPower returns the highest power in the curve (kW).
+func (c *Curve) PeakPower() float64 {
+	var peak float64
+	for _, s := range c.Samples {
+		peak = math.Max(peak, s.Power)
+	}
+	return peak
+}
+
+// SOCRange returns the first and last SOC of the curve.
+func (c *Curve) SOCRange() (from, to float64) {
+	if len(c.Samples) == 0 {
+		return 0, 0
+	}
+	return c.Samples[0].SOC, c.Samples[len(c.Samples)-1].SOC
+}
+
+// PerPercent reduces the curve to one sample per whole SOC percent (the one
+// with the highest power), small enough for a Home Assistant attribute.
+func (c *Curve) PerPercent() []CurveSample {
+	var out []CurveSample
+	for _, s := range c.Samples {
+		if n := len(out); n > 0 && math.Floor(out[n-1].SOC) == math.Floor(s.SOC) {
+			if s.Power > out[n-1].Power {
+				out[n-1] = s
+			}
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// WriteCSV stores the full-resolution curve as dir/<start>.csv and returns
+// the file path.
+func (c *Curve) WriteCSV(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create curve directory: %w", err)
+	}
+	path := filepath.Join(dir, c.Start.Format("2006-01-02T150405")+".csv")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create curve file: %w", err)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	_ = w.Write([]string{"elapsed_s", "soc", "power_kw", "battery_temp"})
+	for _, s := range c.Samples {
+		temp := ""
+		if s.BatteryTemp != nil {
+			temp = strconv.FormatFloat(*s.BatteryTemp, 'f', -1, 64)
+		}
+		_ = w.Write([]string{
+			strconv.Itoa(int(s.Elapsed.Seconds())),
+			strconv.FormatFloat(s.SOC, 'f', -1, 64),
+			strconv.FormatFloat(math.Round(s.Power*10)/10, 'f', -1, 64),
+			temp,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write curve file: %w", err)
+	}
+	return path, nil
+}
+
+// CurveRecorder samples SOC and power while charging and hands out the curve
+// when the gun is unplugged, if the session was DC. It is not safe for
+// concurrent use.
+type CurveRecorder struct {
+	current *Curve
+	peak    float64
+}
+
+// NewCurveRecorder returns an idle recorder.
+func NewCurveRecorder() *CurveRecorder { return &CurveRecorder{} }
+
+// Observe folds a snapshot into the current session. It returns the curve
+// once a DC session ends; AC sessions are discarded.
+func (r *CurveRecorder) Observe(data *sensors.SensorData) *Curve {
+	if data == nil {
+		return nil
+	}
+	ts := data.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	status := sensors.DeriveChargingStatus(data)
+	if status == "disconnected" {
+		done, peak := r.current, r.peak
+		r.current, r.peak = nil, 0
+		if done == nil || peak < DCPowerThreshold || len(done.Samples) < 2 {
+			return nil
+		}
+		return done
+	}
+
+	if r.current == nil {
+		r.current = &Curve{Start: ts}
+	}
+	if status != "charging" || data.BatteryPercentage == nil || data.EnginePower == nil {
+		return nil
+	}
+	r.current.End = ts
+	if len(r.current.Samples) >= maxCurveSamples {
+		return nil
+	}
+	// Negative engine power is energy flowing into the battery.
+	power := -*data.EnginePower
+	r.peak = math.Max(r.peak, power)
+	r.current.Samples = append(r.current.Samples, CurveSample{
+		Elapsed:     ts.Sub(r.current.Start),
+		SOC:         *data.BatteryPercentage,
+		Power:       power,
+		BatteryTemp: data.AvgBatteryTemp,
+	})
+	return nil
+}
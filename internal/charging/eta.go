@@ -0,0 +1,74 @@
+package charging
+
+import (
+	"math"
+	"time"
+)
+
+// etaSmoothing is the time constant of the charging power average. It
+// rides out short dips (a charger rebalancing, the car heating the pack)
+// while still following the taper towards the end of a session.
+const etaSmoothing = 5 * time.Minute
+
+// ETA estimates the time left until the battery reaches a SOC while
+// charging. With a known usable capacity the remaining energy is divided by
+// the smoothed charging power; otherwise the SOC rate since charging
+// started is extrapolated. It is not safe for concurrent use.
+type ETA struct {
+	power float64 // smoothed kW into the battery
+	last  time.Time
+
+	startSOC float64 // SOC when charging started, for the SOC rate
+	start    time.Time
+}
+
+// NewETA returns an estimator with no charging history.
+func NewETA() *ETA { return &ETA{} }
+
+// Observe folds in a snapshot. chargeKW is the power into the battery,
+// charging whether the car is charging at all.
+func (e *ETA) Observe(ts time.Time, charging bool, chargeKW, soc float64) {
+	if !charging || chargeKW <= 0 {
+		*e = ETA{}
+		return
+	}
+	if e.last.IsZero() {
+		e.power, e.last = chargeKW, ts
+		e.startSOC, e.start = soc, ts
+		return
+	}
+	dt := ts.Sub(e.last)
+	if dt <= 0 {
+		return
+	}
+	if dt > maxStep {
+		dt = maxStep
+	}
+	alpha := 1 - math.Exp(-float64(dt)/float64(etaSmoothing))
+	e.power += alpha * (chargeKW - e.power)
+	e.last = ts
+}
+
+// Remaining returns the time until the battery reaches target (%), given the
+// current SOC and the usable capacity (kWh, 0 if unknown). ok is false while
+// not charging or before there is enough history for an estimate.
+func (e *ETA) Remaining(soc, target, usableKWh float64) (time.Duration, bool) {
+	if e.last.IsZero() {
+		return 0, false
+	}
+	if soc >= target {
+		return 0, true
+	}
+	if usableKWh > 0 && e.power > 0 {
+		hours := (target - soc) / 100 * usableKWh / e.power
+		return time.Duration(hours * float64(time.Hour)), true
+	}
+	// Without a capacity, wait for at least one percent of progress.
+	gained := soc - e.startSOC
+	elapsed := e.last.Sub(e.start)
+	if gained < 1 || elapsed <= 0 {
+		return 0, false
+	}
+	rate := gained / elapsed.Hours() // % per hour
+	return time.Duration((target - soc) / rate * float64(time.Hour)), true
+}
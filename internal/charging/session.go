@@ -0,0 +1,140 @@
+package charging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// SensorIDs are the sensors the cost tracker needs: EnginePower and
+// ChargeGunState.
+var SensorIDs = []int{10, 12}
+
+// FileName is the cost tracker state kept in the state directory.
+const FileName = "charging_cost.json"
+
+// maxStep caps the time credited between two snapshots so a polling gap
+// doesn't multiply a stale power reading.
+const maxStep = 2 * time.Minute
+
+// Session is one plug-in to unplug cycle.
+type Session struct {
+	Start   time.Time `json:"start"`
+	Energy  float64   `json:"energy_kwh"`
+	Cost    float64   `json:"cost"`
+	Counted bool      `json:"counted"` // false when charged away from home
+}
+
+// Tracker integrates charging power into per-session and monthly energy and
+// cost. It is not safe for concurrent use.
+type Tracker struct {
+	Current     *Session `json:"current,omitempty"` // latest (possibly finished) session
+	Active      bool     `json:"active"`
+	Month       string   `json:"month"` // "2006-01"
+	MonthEnergy float64  `json:"month_energy_kwh"`
+	MonthCost   float64  `json:"month_cost"`
+
+	tariff   *Tariff
+	loc      *time.Location
+	lastSeen time.Time
+}
+
+// NewTracker returns an empty tracker.
+func NewTracker(tariff *Tariff, loc *time.Location) *Tracker {
+	return &Tracker{tariff: tariff, loc: loc}
+}
+
+// Load reads a tracker from path. A missing file yields an empty tracker.
+func Load(path string, tariff *Tariff, loc *time.Location) (*Tracker, error) {
+	t := NewTracker(tariff, loc)
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return t, fmt.Errorf("failed to read charging cost state: %w", err)
+	}
+	if err := json.Unmarshal(raw, t); err != nil {
+		return NewTracker(tariff, loc), fmt.Errorf("failed to parse charging cost state %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// Save writes the tracker to path, creating its directory.
+func (t *Tracker) Save(path string) error {
+	raw, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode charging cost state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write charging cost state: %w", err)
+	}
+	return nil
+}
+
+// Observe folds a snapshot into the tracker. atHome tells whether a session
+// starting now should count towards the monthly totals. It reports whether
+// anything changed.
+func (t *Tracker) Observe(data *sensors.SensorData, atHome bool) bool {
+	if data == nil {
+		return false
+	}
+	ts := data.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	ts = ts.In(t.loc)
+
+	changed := false
+	if month := ts.Format("2006-01"); month != t.Month {
+		t.Month, t.MonthEnergy, t.MonthCost = month, 0, 0
+		changed = true
+	}
+
+	status := sensors.DeriveChargingStatus(data)
+	switch {
+	case status == "disconnected":
+		if t.Active {
+			t.Active = false
+			changed = true
+		}
+	case !t.Active:
+		t.Active = true
+		t.Current = &Session{Start: ts, Counted: atHome}
+		changed = true
+	}
+
+	prev := t.lastSeen
+	t.lastSeen = ts
+	if !t.Active || t.Current == nil || status != "charging" || prev.IsZero() || data.EnginePower == nil {
+		return changed
+	}
+
+	dt := ts.Sub(prev)
+	if dt <= 0 {
+		return changed
+	}
+	if dt > maxStep {
+		dt = maxStep
+	}
+	// Negative engine power is energy flowing into the battery.
+	energy := -*data.EnginePower * dt.Hours()
+	if energy <= 0 {
+		return changed
+	}
+	cost := energy * t.tariff.PriceAt(ts)
+	t.Current.Energy += energy
+	t.Current.Cost += cost
+	if t.Current.Counted {
+		t.MonthEnergy += energy
+		t.MonthCost += cost
+	}
+	return true
+}
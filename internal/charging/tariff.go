@@ -0,0 +1,101 @@
+// Package charging tracks charging sessions and what they cost under a
+// flat or time-of-use energy tariff.
+package charging
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tariff is an energy price per kWh, optionally varying by time of day.
+type Tariff struct {
+	Default float64
+	Windows []Window
+}
+
+// Window is a time-of-day range with its own price. End may be before Start
+// for ranges crossing midnight (e.g. 22:00-06:00).
+type Window struct {
+	Start, End int // minutes after midnight
+	Price      float64
+}
+
+func (w Window) contains(minute int) bool {
+	if w.Start <= w.End {
+		return minute >= w.Start && minute < w.End
+	}
+	return minute >= w.Start || minute < w.End
+}
+
+// ParseTariff parses "price" or "price;HH:MM-HH:MM=price;...", e.g.
+// "0.30;22:00-06:00=0.12". The first matching window wins; the leading price
+// applies outside every window.
+func ParseTariff(spec string) (*Tariff, error) {
+	parts := strings.Split(spec, ";")
+	def, err := parsePrice(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	t := &Tariff{Default: def}
+
+	for _, entry := range parts[1:] {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		rangePrice := strings.SplitN(entry, "=", 2)
+		if len(rangePrice) != 2 {
+			return nil, fmt.Errorf("tariff window %q: expected HH:MM-HH:MM=price", entry)
+		}
+		bounds := strings.SplitN(rangePrice[0], "-", 2)
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("tariff window %q: expected HH:MM-HH:MM=price", entry)
+		}
+		start, err := parseClock(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("tariff window %q: %w", entry, err)
+		}
+		end, err := parseClock(bounds[1])
+		if err != nil {
+			return nil, fmt.Errorf("tariff window %q: %w", entry, err)
+		}
+		if start == end {
+			return nil, fmt.Errorf("tariff window %q: start and end are equal", entry)
+		}
+		price, err := parsePrice(rangePrice[1])
+		if err != nil {
+			return nil, fmt.Errorf("tariff window %q: %w", entry, err)
+		}
+		t.Windows = append(t.Windows, Window{Start: start, End: end, Price: price})
+	}
+	return t, nil
+}
+
+func parsePrice(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid price %q", strings.TrimSpace(s))
+	}
+	return v, nil
+}
+
+func parseClock(s string) (int, error) {
+	ts, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", strings.TrimSpace(s))
+	}
+	return ts.Hour()*60 + ts.Minute(), nil
+}
+
+// PriceAt returns the price per kWh at local time ts.
+func (t *Tariff) PriceAt(ts time.Time) float64 {
+	minute := ts.Hour()*60 + ts.Minute()
+	for _, w := range t.Windows {
+		if w.contains(minute) {
+			return w.Price
+		}
+	}
+	return t.Default
+}
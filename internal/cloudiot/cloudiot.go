@@ -0,0 +1,240 @@
+// Package cloudiot connects to managed cloud IoT brokers (Azure IoT Hub and
+// AWS IoT Core). Both speak MQTT over TLS but differ in how devices
+// authenticate and which topics they may publish to, so the connection
+// details live here and callers only hand over the state to report.
+package cloudiot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/sirupsen/logrus"
+)
+
+// Provider names.
+const (
+	ProviderAzure = "azure"
+	ProviderAWS   = "aws"
+)
+
+// azureTokenTTL is the lifetime of generated SAS tokens. IoT Hub drops the
+// connection when the token expires; the reconnect then signs a new one.
+const azureTokenTTL = 24 * time.Hour
+
+// azureAPIVersion is the IoT Hub MQTT API version sent in the username.
+const azureAPIVersion = "2021-04-12"
+
+// publishTimeout bounds a single publish.
+const publishTimeout = 10 * time.Second
+
+// Client publishes device state to a cloud IoT broker.
+type Client struct {
+	provider string
+	client   mqtt.Client
+	topic    string
+	logger   *logrus.Logger
+}
+
+// NewAzure connects to Azure IoT Hub as the device described by an IoT Hub
+// device connection string:
+//
+//	HostName=<hub>.azure-devices.net;DeviceId=<id>;SharedAccessKey=<key>
+//
+// Devices using X.509 authentication set "x509=true" instead of a key and
+// pass their certificate and key files. State is sent as device-to-cloud
+// messages.
+func NewAzure(connectionString, certFile, keyFile, caFile string, logger *logrus.Logger) (*Client, error) {
+	cs, err := parseConnectionString(connectionString)
+	if err != nil {
+		return nil, err
+	}
+	host, deviceID := cs["hostname"], cs["deviceid"]
+	if host == "" || deviceID == "" {
+		return nil, fmt.Errorf("azure connection string needs HostName and DeviceId")
+	}
+
+	tlsConfig, err := tlsConfig(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := baseOptions("ssl://"+host+":8883", deviceID, tlsConfig, logger)
+	username := fmt.Sprintf("%s/%s/?api-version=%s", host, deviceID, azureAPIVersion)
+	switch {
+	case cs["sharedaccesskey"] != "":
+		key, err := base64.StdEncoding.DecodeString(cs["sharedaccesskey"])
+		if err != nil {
+			return nil, fmt.Errorf("azure SharedAccessKey is not valid base64: %w", err)
+		}
+		// Sign a fresh token on every (re)connect.
+		opts.SetCredentialsProvider(func() (string, string) {
+			return username, azureSASToken(host, deviceID, key, time.Now().Add(azureTokenTTL))
+		})
+	case strings.EqualFold(cs["x509"], "true"):
+		if len(tlsConfig.Certificates) == 0 {
+			return nil, fmt.Errorf("azure X.509 authentication needs a certificate and key")
+		}
+		opts.SetUsername(username)
+	default:
+		return nil, fmt.Errorf("azure connection string needs SharedAccessKey or x509=true")
+	}
+
+	// The property bag marks the body as UTF-8 JSON so IoT Hub message
+	// routing can query it.
+	topic := fmt.Sprintf("devices/%s/messages/events/$.ct=application%%2Fjson&$.ce=utf-8", deviceID)
+	return connect(ProviderAzure, opts, topic, logger)
+}
+
+// NewAWS connects to the AWS IoT Core data endpoint
+// (<prefix>-ats.iot.<region>.amazonaws.com) as thing using the thing's X.509
+// certificate. State is reported to the thing's classic device shadow.
+func NewAWS(endpoint, thing, certFile, keyFile, caFile string, logger *logrus.Logger) (*Client, error) {
+	if endpoint == "" || thing == "" {
+		return nil, fmt.Errorf("AWS IoT needs an endpoint and a thing name")
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("AWS IoT needs the thing's certificate and private key")
+	}
+	tlsConfig, err := tlsConfig(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	host := endpoint
+	if !strings.Contains(host, ":") {
+		host += ":8883"
+	}
+	opts := baseOptions("ssl://"+host, thing, tlsConfig, logger)
+	return connect(ProviderAWS, opts, fmt.Sprintf("$aws/things/%s/shadow/update", thing), logger)
+}
+
+// PublishState sends state (any JSON-encodable value) using the provider's
+// convention: a device-to-cloud message on Azure, a reported shadow state on
+// AWS.
+func (c *Client) PublishState(state interface{}) error {
+	var body interface{} = state
+	if c.provider == ProviderAWS {
+		body = map[string]interface{}{"state": map[string]interface{}{"reported": state}}
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s state: %w", c.provider, err)
+	}
+
+	token := c.client.Publish(c.topic, 1, false, payload)
+	if !token.WaitTimeout(publishTimeout) {
+		return fmt.Errorf("%s publish timed out after %s", c.provider, publishTimeout)
+	}
+	if err := token.Error(); err != nil {
+		return fmt.Errorf("%s publish failed: %w", c.provider, err)
+	}
+	c.logger.WithFields(logrus.Fields{"provider": c.provider, "size": len(payload)}).Debug("Published cloud IoT state")
+	return nil
+}
+
+// Provider returns ProviderAzure or ProviderAWS.
+func (c *Client) Provider() string { return c.provider }
+
+// IsConnected reports whether the broker connection is up.
+func (c *Client) IsConnected() bool { return c.client.IsConnected() }
+
+// Disconnect closes the connection.
+func (c *Client) Disconnect() { c.client.Disconnect(250) }
+
+func baseOptions(broker, clientID string, tlsConfig *tls.Config, logger *logrus.Logger) *mqtt.ClientOptions {
+	opts := mqtt.NewClientOptions()
+	opts.AddBroker(broker)
+	opts.SetClientID(clientID)
+	opts.SetTLSConfig(tlsConfig)
+	opts.SetProtocolVersion(4) // both brokers require MQTT 3.1.1
+	opts.SetCleanSession(true)
+	opts.SetAutoReconnect(true)
+	opts.SetKeepAlive(60 * time.Second)
+	opts.SetConnectTimeout(10 * time.Second)
+	opts.SetMaxReconnectInterval(time.Minute)
+	opts.SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+		logger.WithError(err).Warn("Cloud IoT connection lost")
+	})
+	return opts
+}
+
+func connect(provider string, opts *mqtt.ClientOptions, topic string, logger *logrus.Logger) (*Client, error) {
+	client := mqtt.NewClient(opts)
+	if token := client.Connect(); token.Wait() && token.Error() != nil {
+		return nil, fmt.Errorf("failed to connect to %s IoT broker: %w", provider, token.Error())
+	}
+	logger.WithFields(logrus.Fields{
+		"provider":  provider,
+		"broker":    opts.Servers[0].Host,
+		"client_id": opts.ClientID,
+	}).Info("Cloud IoT client connected")
+	return &Client{provider: provider, client: client, topic: topic, logger: logger}, nil
+}
+
+// tlsConfig verifies the broker against the system roots (or caFile) and
+// presents the client certificate when one is given.
+func tlsConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("client certificate and key must be given together")
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// parseConnectionString splits "Key=Value;Key=Value" into a map with
+// lower-cased keys. Values may contain '=' (base64 padding).
+func parseConnectionString(s string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid connection string entry %q", part)
+		}
+		out[strings.ToLower(strings.TrimSpace(kv[0]))] = strings.TrimSpace(kv[1])
+	}
+	return out, nil
+}
+
+// azureSASToken signs a device-scoped shared access signature valid until
+// expiry.
+func azureSASToken(host, deviceID string, key []byte, expiry time.Time) string {
+	resource := url.QueryEscape(host + "/devices/" + deviceID)
+	se := strconv.FormatInt(expiry.Unix(), 10)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(resource + "\n" + se))
+	sig := url.QueryEscape(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return fmt.Sprintf("SharedAccessSignature sr=%s&sig=%s&se=%s", resource, sig, se)
+}
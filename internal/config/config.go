@@ -1,9 +1,26 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"strings"
 	"time"
+
+	"github.com/jkaberg/byd-hass/internal/api"
+	"github.com/jkaberg/byd-hass/internal/charging"
+	"github.com/jkaberg/byd-hass/internal/domain"
+	"github.com/jkaberg/byd-hass/internal/geofence"
+	"github.com/jkaberg/byd-hass/internal/logging"
+	"github.com/jkaberg/byd-hass/internal/netutil"
+	"github.com/jkaberg/byd-hass/internal/notify"
+	"github.com/jkaberg/byd-hass/internal/obd"
+	"github.com/jkaberg/byd-hass/internal/payload"
+	"github.com/jkaberg/byd-hass/internal/precondition"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/jkaberg/byd-hass/internal/update"
+	"github.com/jkaberg/byd-hass/internal/vehicle"
 )
 
 // Config holds all configuration options for the BYD-HASS application
@@ -11,6 +28,12 @@ type Config struct {
 	// MQTT Configuration
 	MQTTUrl         string `json:"mqtt_url"`         // MQTT URL (supports both WebSocket and standard MQTT)
 	DiscoveryPrefix string `json:"discovery_prefix"` // Home Assistant discovery prefix
+	// MQTTClientID overrides the client ID; by default it is
+	// "byd-hass-<device ID>-<random suffix kept in StateDir>".
+	MQTTClientID string `json:"mqtt_client_id"`
+	// DiscoveryReconcile reads the retained discovery configs at startup and
+	// only republishes the ones that changed.
+	DiscoveryReconcile bool `json:"discovery_reconcile"`
 
 	// ABRP Configuration
 	ABRPAPIKey string `json:"abrp_api_key"` // ABRP API key
@@ -22,12 +45,19 @@ type Config struct {
 	// Application Configuration
 	Verbose bool `json:"verbose"` // Enable verbose logging
 
-	// ABRP Application Requirement
-	// When true, telemetry will only be transmitted to ABRP when the Android
-	// application "com.iternio.abrpapp" is detected to be running via ADB.
-	// This can be toggled at runtime through the "-require-abrp-app" CLI flag
-	// or the "BYD_HASS_REQUIRE_ABRP_APP" environment variable.
-	RequireABRPApp bool `json:"require_abrp_app"`
+	// ABRPAppMode decides how the official ABRP Android app
+	// ("com.iternio.abrpapp") on the head unit affects our ABRP telemetry:
+	// ABRPAppYield suspends it while the app runs so the two don't send
+	// duplicate/conflicting data, ABRPAppRequire only sends while the app
+	// runs, and ABRPAppIgnore doesn't check.
+	ABRPAppMode string `json:"abrp_app_mode"`
+
+	// ABRPPowerSign is the sign convention of EnginePower (a
+	// sensors.PowerSign* value) and ABRPPowerScale the factor to kW, for cars
+	// that report charging as positive power or use other units. MQTT's
+	// charging entities use the same calibration.
+	ABRPPowerSign  string  `json:"abrp_power_sign"`
+	ABRPPowerScale float64 `json:"abrp_power_scale"`
 
 	// WiFi Re-enable
 	// When true, the application will periodically check if WiFi is disabled
@@ -36,67 +66,802 @@ type Config struct {
 	// environment variable (default: false).
 	EnableWiFiReenable bool `json:"enable_wifi_reenable"`
 
+	// AutoUpdate installs new releases of UpdateChannel ("stable" or "beta")
+	// while parked on WiFi, checking every AutoUpdateInterval. A version
+	// that fails its self-test after installing is rolled back.
+	AutoUpdate         bool          `json:"auto_update"`
+	UpdateChannel      string        `json:"update_channel"`
+	AutoUpdateInterval time.Duration `json:"auto_update_interval"`
+
+	// Sensor selection
+	// DetectCapabilities probes the sensors at startup and stops polling the
+	// ones Diplus keeps leaving out; results are kept in StateDir.
+	DetectCapabilities bool   `json:"detect_capabilities"`
+	StateDir           string `json:"state_dir"` // Directory for persisted state
+
+	// SensorOverlay is a JSON file correcting AllSensors metadata (see
+	// sensors.LoadOverlay). Defaults to sensor_overlay.json in StateDir when
+	// that file exists.
+	SensorOverlay string `json:"sensor_overlay"`
+
+	// SensorProfile selects a preset sensor set ("minimal", "standard",
+	// "phev", "everything"); SensorIDs, when set, replaces it entirely using
+	// the "id:publish,id,..." format documented in sensors.MonitoredSensor.
+	SensorProfile string `json:"sensor_profile"`
+	SensorIDs     string `json:"sensor_ids"`
+
+	// FuelTankSize (litres) enables the fuel_level sensor on plug-in hybrids;
+	// 0 disables it.
+	FuelTankSize int `json:"fuel_tank_size"`
+
+	// UsableCapacity (kWh) is the usable battery capacity behind
+	// battery_energy, battery_health and ABRP's capacity/soe/soh; 0 takes it
+	// from the car model.
+	UsableCapacity float64 `json:"usable_capacity_kwh"`
+
+	// HVACPower publishes the estimated climate power draw as hvac_power and
+	// sends it to ABRP.
+	HVACPower bool `json:"hvac_power"`
+
 	// API Configuration
 	DiplusURL       string `json:"diplus_url"`       // Di-Plus API URL
 	ExtendedPolling bool   `json:"extended_polling"` // Use extended sensor polling for more data
 	APITimeout      int    `json:"api_timeout"`      // API request timeout in seconds (default: 10)
 
+	// DiplusCommands maps car commands to Diplus command templates
+	// (api.ParseCommands). When set, the control endpoint at
+	// DiplusControlPath is probed at startup and supported commands become
+	// available, e.g. arming sentry mode from the alarm panel.
+	DiplusCommands    string `json:"diplus_commands"`
+	DiplusControlPath string `json:"diplus_control_path"`
+
+	// Validation is the policy for implausible readings (sensors.PolicyWarn,
+	// PolicyDrop or PolicyClamp), applied before they are published.
+	Validation string `json:"validation"`
+	// ValidationRules overrides the per-sensor plausible ranges and policies
+	// (sensors.ParseValidationRules).
+	ValidationRules string `json:"validation_rules"`
+	// Deadband overrides the per-sensor change tolerances that decide
+	// whether a snapshot is worth transmitting (domain.ParseTolerances).
+	Deadband string `json:"deadband"`
+
+	// CellVoltages and CellTemperatures enable per-cell battery polling for
+	// Diplus builds that expose it, as "label{n}:count" (sensors.ParseCellSpec).
+	// CellAttributes also publishes the full cell arrays as attributes.
+	CellVoltages     string `json:"cell_voltages"`
+	CellTemperatures string `json:"cell_temperatures"`
+	CellAttributes   bool   `json:"cell_attributes"`
+
+	// ELM327 is the address of an ELM327 OBD-II adapter, an RFCOMM device
+	// (e.g. /dev/rfcomm0) or tcp://host:port. When set it is polled next to
+	// Diplus and fills in the fields Diplus doesn't report. ELM327PIDs lists
+	// what to read (obd.ParsePIDs).
+	ELM327     string `json:"elm327"`
+	ELM327PIDs string `json:"elm327_pids"`
+
 	// ABRP Configuration
 	ABRPEnhanced    bool   `json:"abrp_enhanced"`     // Use enhanced ABRP telemetry data
 	ABRPLocation    bool   `json:"abrp_location"`     // Include GPS location in ABRP data (if available)
-	ABRPVehicleType string `json:"abrp_vehicle_type"` // ABRP vehicle type for better range estimation
+	ABRPVehicleType string `json:"abrp_vehicle_type"` // ABRP car_model code; defaults to the one of CarModel
+
+	// CarModel is a vehicle.Models key; empty detects the model from the
+	// head unit's Android properties.
+	CarModel string `json:"car_model"`
+
+	// TeslaMateCarID mirrors snapshots to teslamate/cars/<id>/... on the MQTT
+	// broker for TeslaMate-based tooling; 0 disables it.
+	TeslaMateCarID int `json:"teslamate_car_id"`
+
+	// Traccar Configuration
+	TraccarURL      string `json:"traccar_url"`       // OsmAnd endpoint of a Traccar server (e.g. http://host:5055)
+	TraccarDeviceID string `json:"traccar_device_id"` // Traccar device identifier (defaults to DeviceID)
+
+	// Location precision
+	// LocationPrecision snaps published coordinates to a grid of this many
+	// metres (0 = full precision) for the transmitters listed in
+	// LocationPrecisionFor ("mqtt", "abrp", "traccar", "kafka", "rabbitmq", "cloudiot", "ha").
+	// Geofencing always
+	// uses the exact position.
+	LocationPrecision    int    `json:"location_precision"`
+	LocationPrecisionFor string `json:"location_precision_for"`
+
+	// Kafka Configuration
+	// KafkaURL is a Confluent Kafka REST Proxy (http(s)://[user:pass@]host:port),
+	// not a broker; snapshots are produced to KafkaTopic as "json" or "avro"
+	// (KafkaFormat).
+	KafkaURL    string `json:"kafka_url"`
+	KafkaTopic  string `json:"kafka_topic"`
+	KafkaFormat string `json:"kafka_format"`
+
+	// RabbitMQ Configuration
+	// RabbitMQURL is the broker's AMQP address
+	// (amqp(s)://[user:pass@]host[:port]); snapshots are published per sensor
+	// group to RabbitMQExchange in RabbitMQVHost.
+	RabbitMQURL      string `json:"rabbitmq_url"`
+	RabbitMQVHost    string `json:"rabbitmq_vhost"`
+	RabbitMQExchange string `json:"rabbitmq_exchange"`
+
+	// Cloud IoT Configuration
+	// Either AzureIoTConnectionString (Azure IoT Hub device connection string)
+	// or AWSIoTEndpoint (AWS IoT Core data endpoint) enables the cloud IoT
+	// transmitter. CloudIoTCert/Key are the device's X.509 certificate and
+	// key; CloudIoTCA overrides the system root CAs.
+	AzureIoTConnectionString string `json:"azure_iot_connection_string"`
+	AWSIoTEndpoint           string `json:"aws_iot_endpoint"`
+	AWSIoTThing              string `json:"aws_iot_thing"` // Defaults to DeviceID
+	CloudIoTCert             string `json:"cloud_iot_cert"`
+	CloudIoTKey              string `json:"cloud_iot_key"`
+	CloudIoTCA               string `json:"cloud_iot_ca"`
+
+	// Home Assistant REST Configuration
+	// HAURL and HAToken (a long-lived access token) post states straight to
+	// Home Assistant's REST API, for setups without an MQTT broker.
+	HAURL   string `json:"ha_url"`
+	HAToken string `json:"ha_token"`
+
+	// Geofencing
+	// Zones is a ";"-separated list of "name:lat,lon[,radius]" entries
+	// (radius in metres). When set, a current_zone sensor and zone
+	// enter/leave events are published over MQTT.
+	Zones string `json:"zones"`
+	// Home is the home location "lat,lon[,radius]". With Home or Zones set the
+	// device tracker reports home/not_home/<zone> itself instead of leaving
+	// it to Home Assistant's zones.
+	Home string `json:"home"`
+
+	// StateCompat also publishes renamed state keys under their previous names
+	// (sensors.LegacyKeys) so older consumers keep working.
+	StateCompat bool `json:"state_compat"`
+	// StateEncoding is "json" (default), "msgpack" or "cbor". Binary
+	// encodings go to byd_car/<id>/state/<encoding> for non-Home Assistant
+	// consumers on metered links.
+	StateEncoding string `json:"state_encoding"`
+	// MQTTRetain lists the topics published retained, from "state",
+	// "location" and "last_transmission" ("none" for none). MQTTExpireAfter
+	// makes state entities unavailable when no state arrives for that long
+	// (0 = never).
+	MQTTRetain      string        `json:"mqtt_retain"`
+	MQTTExpireAfter time.Duration `json:"mqtt_expire_after"`
+
+	// CrashDSN opts in to reporting panics and ERROR log lines to a
+	// Sentry-compatible service (sentry.io, self-hosted Sentry, GlitchTip).
+	CrashDSN string `json:"crash_dsn"`
+
+	// LogProfile is logging.ProfileNormal or logging.ProfileQuiet, which
+	// only logs warnings and errors. Verbose takes precedence.
+	LogProfile string `json:"log_profile"`
+
+	// LogDedupeWindow collapses repeated identical warnings into one
+	// summary line per window (0 disables it).
+	LogDedupeWindow time.Duration `json:"log_dedupe_window"`
+
+	// LogLevels overrides the log level per subsystem, e.g.
+	// "abrp=debug,mqtt=warn" (see logging.Modules).
+	LogLevels string `json:"log_levels"`
+
+	// LogStream mirrors WARN+ log lines to byd_car/<id>/log, at most
+	// LogStreamRate lines per minute.
+	LogStream     bool `json:"log_stream"`
+	LogStreamRate int  `json:"log_stream_rate"`
+
+	// DNS is the resolver chain, e.g. "system,1.1.1.1" (see netutil.ParseDNS).
+	// Private hostnames always use the system configuration.
+	DNS string `json:"dns"`
+
+	// Proxy routes outgoing HTTP traffic (ABRP and the other HTTP
+	// transmitters) through an http(s):// or socks5(h):// proxy; NoProxy
+	// lists destinations that bypass it (see netutil.SetProxy). Empty uses
+	// the HTTP(S)_PROXY environment variables.
+	Proxy   string `json:"proxy"`
+	NoProxy string `json:"no_proxy"`
+
+	// LANProbe and InternetProbe are the host:port targets dialled to tell
+	// whether the LAN (often via WireGuard/Tailscale) and the internet are
+	// reachable. LANProbe defaults to a private MQTT broker; an empty target
+	// is never probed. Transmitters are held back while their path is down.
+	LANProbe      string `json:"lan_probe"`
+	InternetProbe string `json:"internet_probe"`
+
+	// Device triggers
+	// When true, door/charging/sentry transitions are published as Home
+	// Assistant device triggers (device_automation).
+	DeviceTriggers bool `json:"device_triggers"`
+
+	// Speed check
+	// When true, GPS speed is compared with the car's speed sensor while
+	// driving and a speed_mismatch problem sensor flags systematic
+	// disagreement (stale GPS file, wrong scale factor).
+	SpeedCheck bool `json:"speed_check"`
+
+	// Rules
+	// Rules is a ";"-separated list of "name:expression" conditions (see
+	// events.ParseRules) that fire MQTT events when they start matching.
+	// RulesNotify additionally shows a Termux notification.
+	Rules       string `json:"rules"`
+	RulesNotify bool   `json:"rules_notify"`
+
+	// Remote control
+	// RemoteControl accepts commands on byd_car/<device_id>/cmd (see
+	// transmission.Command). Off by default: the topic is not authenticated,
+	// so anyone who can publish there can pause or restart the bridge or
+	// operate the car. Only enable it behind broker ACLs.
+	RemoteControl bool `json:"remote_control"`
+
+	// Time zones
+	// Timezone is the IANA zone (e.g. "Europe/Oslo") used for published
+	// local timestamps, statistics resets, tariffs and schedules instead of
+	// the phone's zone. CarTimezone is the zone the head-unit clock is set
+	// to, often "Asia/Shanghai" on imported cars; it defaults to Timezone.
+	Timezone    string `json:"timezone"`
+	CarTimezone string `json:"car_timezone"`
+
+	// Statistics
+	// Statistics publishes distance and energy per day, week and month,
+	// persisted in StateDir.
+	Statistics bool `json:"statistics"`
+
+	// Charging cost
+	// ChargingPrice enables charging cost tracking: a price per kWh, or a
+	// time-of-use schedule such as "0.30;22:00-06:00=0.12" (see
+	// charging.ParseTariff). With ChargingHomeZone set, only sessions that
+	// start inside that geofence zone count towards the monthly cost.
+	ChargingPrice    string `json:"charging_price"`
+	ChargingCurrency string `json:"charging_currency"` // ISO 4217 code
+	ChargingHomeZone string `json:"charging_home_zone"`
+
+	// evcc
+	// EVCCListen serves the vehicle state for evcc's custom vehicle template
+	// on this host:port (e.g. ":8989"); empty disables it. EVCCRangeSensor
+	// names a virtual sensor whose value is reported as the range.
+	EVCCListen      string `json:"evcc_listen"`
+	EVCCRangeSensor string `json:"evcc_range_sensor"`
+
+	// Exec hook
+	// HookCommand runs through "sh -c" with a JSON payload on stdin for each
+	// kind in HookOn ("change", event entity IDs such as "theft_alert", or
+	// "all").
+	HookCommand string        `json:"hook_command"`
+	HookOn      string        `json:"hook_on"`
+	HookTimeout time.Duration `json:"hook_timeout"`
+
+	// Virtual sensors
+	// VirtualSensors is a ";"-separated list of "key[unit]=expression"
+	// formulas (see formula.ParseVirtualSensors) published as extra sensors.
+	VirtualSensors string `json:"virtual_sensors"`
+
+	// Theft alert
+	// When true, movement (speed or GPS displacement) while the car is
+	// locked with power off raises a high-priority event and notification.
+	// Off by default: a phone GPS indoors can still drift far enough to
+	// raise a false alarm.
+	TheftAlert bool `json:"theft_alert"`
+
+	// Preconditioning
+	// Precondition is a schedule of cabin preconditioning starts such as
+	// "mon-fri@07:30=21;sat@09:00" (see package precondition). It needs the
+	// climate_on command in DiplusCommands. With PreconditionRequirePlug the
+	// climate is only switched on while the car is plugged in.
+	Precondition            string `json:"precondition"`
+	PreconditionRequirePlug bool   `json:"precondition_require_plug"`
+
+	// Drive modes
+	// DriveModes overrides the names of the VehicleOperatingMode (67) and
+	// VehicleRunningMode (68) codes as "68=1:Eco,2:Normal,3:Sport" (see
+	// sensors.ParseModeNames), for cars whose codes differ from the defaults.
+	DriveModes string `json:"drive_modes"`
+
+	// Charging curves
+	// When true, SOC and power are sampled on every fast poll while charging
+	// and each DC session is saved as a CSV file in StateDir/charging_curves
+	// and published to MQTT when the gun is unplugged.
+	ChargingCurves bool `json:"charging_curves"`
+
+	// Push notifications
+	// NotifyURL sends every alert (theft, rules, reminders) to ntfy, Gotify
+	// or a Telegram bot as well, e.g. "ntfy://ntfy.sh/my-byd" (see
+	// notify.Pusher).
+	NotifyURL string `json:"notify_url"`
+
+	// Parked reminders
+	// ParkedReminder is how long a door, trunk, hood or light may stay open
+	// or on after the car is locked with power off before a reminder event
+	// is published (0 = disabled). ParkedReminderNotify additionally shows a
+	// Termux notification.
+	ParkedReminder       time.Duration `json:"parked_reminder"`
+	ParkedReminderNotify bool          `json:"parked_reminder_notify"`
+
+	// Charging reminders
+	// When true, charge complete / interrupted and "parked at home with a
+	// low SOC but not plugged in" raise an MQTT event and a Termux
+	// notification. Charging that stops below ChargeCompleteSOC with the gun
+	// still connected counts as interrupted; the plug-in reminder needs -home
+	// (or a zone named home) and fires below ChargeLowSOC (0 = disabled).
+	ChargeReminders   bool `json:"charge_reminders"`
+	ChargeCompleteSOC int  `json:"charge_complete_soc"`
+	ChargeLowSOC      int  `json:"charge_low_soc"`
+
+	// ChargeTargetSOC is the SOC charging_time_to_target and
+	// charging_ready_at estimate towards.
+	ChargeTargetSOC int `json:"charge_target_soc"`
+
+	// Sentry alerts
+	// When true, new sentry triggers reported by the head unit are published
+	// as an MQTT event together with the stored snapshot image.
+	SentryEvents bool `json:"sentry_events"`
+
+	// Video upload
+	// UploadURL enables uploading new dashcam/sentry recordings
+	// (webdav://, webdavs:// or s3://). Uploads only happen on WiFi, on
+	// UploadWiFiSSID when set, unless UploadCellular allows any network.
+	UploadURL         string        `json:"upload_url"`
+	UploadWiFiSSID    string        `json:"upload_wifi_ssid"`
+	UploadCellular    bool          `json:"upload_cellular"`
+	UploadMaxAge      time.Duration `json:"upload_max_age"`      // Skip recordings older than this (0 = no limit)
+	UploadDeleteLocal bool          `json:"upload_delete_local"` // Delete recordings from the head unit once uploaded
 
 	// Timing intervals (overridable via CLI flags / env vars)
 	MQTTInterval        time.Duration `json:"mqtt_interval"`         // Interval between MQTT transmissions
 	ABRPInterval        time.Duration `json:"abrp_interval"`         // Interval between ABRP transmissions
+	TraccarInterval     time.Duration `json:"traccar_interval"`      // Interval between Traccar reports
+	KafkaInterval       time.Duration `json:"kafka_interval"`        // Interval between Kafka records
+	RabbitMQInterval    time.Duration `json:"rabbitmq_interval"`     // Interval between RabbitMQ publishes
+	CloudIoTInterval    time.Duration `json:"cloud_iot_interval"`    // Interval between cloud IoT messages
+	HAInterval          time.Duration `json:"ha_interval"`           // Interval between Home Assistant REST updates
 	ForceUpdateInterval time.Duration `json:"force_update_interval"` // Force update all sensors at this interval (0 = disabled)
+	StatusInterval      time.Duration `json:"status_interval"`       // Publish the bridge status report at this interval (0 = disabled)
+	ProbeInterval       time.Duration `json:"probe_interval"`        // Probe LAN/internet reachability at this interval (0 = disabled)
+
+	// TransmitJitter adds a random delay of up to this much to every
+	// transmit interval, so many bridges behind one broker don't publish in
+	// lockstep. MinSendSpacing is the minimum gap between two sends to any
+	// target (0 = disabled).
+	TransmitJitter time.Duration `json:"transmit_jitter"`
+	MinSendSpacing time.Duration `json:"min_send_spacing"`
 }
 
 // GetDefaultConfig returns a configuration with sensible defaults
 func GetDefaultConfig() *Config {
 	return &Config{
-		DiscoveryPrefix: "homeassistant",
-		DeviceID:        "", // Will be auto-generated
-		Verbose:         false,
-		DiplusURL:       "localhost:8988",
+		DiscoveryPrefix:    "homeassistant",
+		DiscoveryReconcile: true,
+		DeviceID:           "", // Will be auto-generated
+		Verbose:            false,
+		DiplusURL:          "localhost:8988",
+
+		ExtendedPolling:    true, // Enable extended polling by default
+		DetectCapabilities: true,
+		StateDir:           "/storage/emulated/0/bydhass",
+		UpdateChannel:      update.ChannelStable,
+		AutoUpdateInterval: 24 * time.Hour,
+		SensorProfile:      sensors.DefaultSensorProfile,
+		APITimeout:         10,   // 10 second API timeout
+		ABRPEnhanced:       true, // Use enhanced ABRP data by default
+		ABRPLocation:       true, // Location ENABLED by default
 
-		ExtendedPolling: true,    // Enable extended polling by default
-		APITimeout:      10,      // 10 second API timeout
-		ABRPEnhanced:    true,    // Use enhanced ABRP data by default
-		ABRPLocation:    true,    // Location ENABLED by default
-		ABRPVehicleType: "byd:*", // Generic BYD vehicle type
+		LocationPrecisionFor: "abrp,traccar",
 
 		// Default intervals (can be overridden)
-		MQTTInterval:       MQTTTransmitInterval,
-		ABRPInterval:       ABRPTransmitInterval,
-		RequireABRPApp:     true,
-		EnableWiFiReenable: false, // WiFi re-enable disabled by default
+		MQTTInterval:            MQTTTransmitInterval,
+		ABRPInterval:            ABRPTransmitInterval,
+		TraccarInterval:         TraccarTransmitInterval,
+		KafkaInterval:           KafkaTransmitInterval,
+		KafkaTopic:              "byd-hass",
+		KafkaFormat:             "json",
+		RabbitMQInterval:        RabbitMQTransmitInterval,
+		RabbitMQVHost:           "/",
+		RabbitMQExchange:        "byd-hass",
+		CloudIoTInterval:        CloudIoTTransmitInterval,
+		HAInterval:              HARESTTransmitInterval,
+		StatusInterval:          StatusPublishInterval,
+		ProbeInterval:           ConnectivityProbeInterval,
+		InternetProbe:           netutil.DefaultInternetProbe,
+		LogStreamRate:           10,
+		LogProfile:              logging.ProfileNormal,
+		LogDedupeWindow:         10 * time.Minute,
+		DNS:                     netutil.DefaultDNS,
+		ELM327PIDs:              obd.DefaultPIDs,
+		Validation:              sensors.PolicyDrop,
+		DiplusControlPath:       api.DefaultControlPath,
+		ABRPAppMode:             ABRPAppYield,
+		ABRPPowerSign:           sensors.PowerSignAuto,
+		ABRPPowerScale:          1,
+		HVACPower:               true,
+		EnableWiFiReenable:      false, // WiFi re-enable disabled by default
+		UploadMaxAge:            24 * time.Hour,
+		Statistics:              true,
+		ChargingCurrency:        "EUR",
+		StateEncoding:           payload.JSON,
+		MQTTRetain:              "state,last_transmission",
+		HookOn:                  "change",
+		HookTimeout:             30 * time.Second,
+		DeviceTriggers:          true,
+		SpeedCheck:              true,
+		ParkedReminder:          5 * time.Minute,
+		ChargeCompleteSOC:       80,
+		ChargeTargetSOC:         80,
+		PreconditionRequirePlug: true,
+		ChargeLowSOC:            30,
 	}
 }
 
-// Validate checks if the configuration is valid
+// Validate checks if the configuration is valid. All problems are reported
+// at once, each naming the flag (and environment variable) to fix, so the
+// user doesn't have to restart repeatedly to discover them one by one.
 func (c *Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
 	// Basic validation
+	if strings.ContainsAny(c.MQTTClientID, " ") {
+		add("MQTT client ID %q must not contain spaces (-mqtt-client-id / BYD_HASS_MQTT_CLIENT_ID)", c.MQTTClientID)
+	}
 	if c.DeviceID == "" {
-		return fmt.Errorf("device ID is required")
+		add("device ID is required (-device-id / BYD_HASS_DEVICE_ID)")
+	} else if strings.ContainsAny(c.DeviceID, "/+# ") {
+		add("device ID %q must not contain '/', '+', '#' or spaces since it is used in MQTT topics (-device-id / BYD_HASS_DEVICE_ID)", c.DeviceID)
+	}
+
+	// Diplus
+	if _, _, err := net.SplitHostPort(c.DiplusURL); err != nil {
+		add("Diplus address %q must be host:port, e.g. localhost:8988 (-diplus-url / BYD_HASS_DIPLUS_URL)", c.DiplusURL)
 	}
 
 	// MQTT validation - support both WebSocket and standard MQTT protocols
 	if c.MQTTUrl != "" {
-		if !strings.HasPrefix(c.MQTTUrl, "ws://") &&
-			!strings.HasPrefix(c.MQTTUrl, "wss://") &&
-			!strings.HasPrefix(c.MQTTUrl, "mqtt://") &&
-			!strings.HasPrefix(c.MQTTUrl, "mqtts://") {
-			return fmt.Errorf("MQTT URL must use supported protocol (ws://, wss://, mqtt://, or mqtts://)")
+		u, err := url.Parse(c.MQTTUrl)
+		switch {
+		case err != nil:
+			add("MQTT URL is not a valid URL: %v (-mqtt-url / BYD_HASS_MQTT_URL)", err)
+		case u.Scheme != "ws" && u.Scheme != "wss" && u.Scheme != "mqtt" && u.Scheme != "mqtts":
+			add("MQTT URL must use supported protocol (ws://, wss://, mqtt://, or mqtts://), got %q (-mqtt-url / BYD_HASS_MQTT_URL)", u.Scheme)
+		case u.Host == "":
+			add("MQTT URL has no broker host (-mqtt-url / BYD_HASS_MQTT_URL)")
 		}
 	}
+	if c.DiscoveryPrefix == "" {
+		add("discovery prefix must not be empty (-discovery-prefix / BYD_HASS_DISCOVERY_PREFIX)")
+	}
 
 	// ABRP validation
 	if c.ABRPAPIKey != "" && c.ABRPToken == "" {
-		return fmt.Errorf("ABRP token is required when API key is provided")
+		add("ABRP token is required when API key is provided (-abrp-token / BYD_HASS_ABRP_TOKEN)")
 	}
 	if c.ABRPToken != "" && c.ABRPAPIKey == "" {
-		return fmt.Errorf("ABRP API key is required when token is provided")
+		add("ABRP API key is required when token is provided (-abrp-api-key / BYD_HASS_ABRP_API_KEY)")
+	}
+	if c.CarModel != "" {
+		if _, ok := vehicle.ByKey(c.CarModel); !ok {
+			add("unknown car model %q, expected one of %s (-car-model / BYD_HASS_CAR_MODEL)", c.CarModel, strings.Join(vehicle.Keys(), ", "))
+		}
+	}
+	if strings.ContainsAny(c.ABRPVehicleType, " \"") {
+		add("ABRP car model %q must be an ABRP type code such as byd:atto3:22:60 (-abrp-car-model / BYD_HASS_ABRP_CAR_MODEL)", c.ABRPVehicleType)
+	}
+	if !sensors.ValidPowerSign(c.ABRPPowerSign) {
+		add("ABRP power sign must be %s, %s or %s (-abrp-power-sign / BYD_HASS_ABRP_POWER_SIGN)", sensors.PowerSignAuto, sensors.PowerSignNormal, sensors.PowerSignInverted)
+	}
+	if c.ABRPPowerScale <= 0 || c.ABRPPowerScale > 1000 {
+		add("ABRP power scale must be above 0 and at most 1000 (-abrp-power-scale / BYD_HASS_ABRP_POWER_SCALE)")
+	}
+	switch c.ABRPAppMode {
+	case ABRPAppYield, ABRPAppRequire, ABRPAppIgnore:
+	default:
+		add("ABRP app mode must be %s, %s or %s (-abrp-app / BYD_HASS_ABRP_APP)", ABRPAppYield, ABRPAppRequire, ABRPAppIgnore)
+	}
+
+	// Traccar validation
+	if c.TraccarURL != "" {
+		u, err := url.Parse(c.TraccarURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("Traccar URL must look like http://host:5055 (-traccar-url / BYD_HASS_TRACCAR_URL)")
+		}
+	}
+
+	// Upload validation (scheme specifics are checked by the uploader)
+	if c.UploadURL != "" {
+		if _, err := url.Parse(c.UploadURL); err != nil {
+			add("upload URL is not a valid URL: %v (-upload-url / BYD_HASS_UPLOAD_URL)", err)
+		}
+	}
+	if c.UploadMaxAge < 0 {
+		add("upload max age must not be negative (-upload-max-age / BYD_HASS_UPLOAD_MAX_AGE)")
+	}
+
+	// Kafka
+	if c.KafkaURL != "" {
+		if u, err := url.Parse(c.KafkaURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("Kafka REST proxy URL %q must be http(s)://host:port; brokers can't be used directly (-kafka-url / BYD_HASS_KAFKA_URL)", c.KafkaURL)
+		}
+		if c.KafkaTopic == "" {
+			add("Kafka topic must not be empty (-kafka-topic / BYD_HASS_KAFKA_TOPIC)")
+		}
+		if c.KafkaFormat != "json" && c.KafkaFormat != "avro" {
+			add("Kafka format must be json or avro (-kafka-format / BYD_HASS_KAFKA_FORMAT)")
+		}
+	}
+
+	// RabbitMQ
+	if c.RabbitMQURL != "" {
+		if u, err := url.Parse(c.RabbitMQURL); err != nil || (u.Scheme != "amqp" && u.Scheme != "amqps") || u.Host == "" {
+			add("RabbitMQ URL %q must be amqp(s)://host[:port], not the management API (-rabbitmq-url / BYD_HASS_RABBITMQ_URL)", c.RabbitMQURL)
+		} else if strings.Trim(u.Path, "/") != "" {
+			add("RabbitMQ URL %q must not contain a vhost, use -rabbitmq-vhost / BYD_HASS_RABBITMQ_VHOST (-rabbitmq-url / BYD_HASS_RABBITMQ_URL)", c.RabbitMQURL)
+		}
+		if c.RabbitMQVHost == "" {
+			add("RabbitMQ vhost must not be empty (-rabbitmq-vhost / BYD_HASS_RABBITMQ_VHOST)")
+		}
+		if c.RabbitMQExchange == "" {
+			add("RabbitMQ exchange must not be empty (-rabbitmq-exchange / BYD_HASS_RABBITMQ_EXCHANGE)")
+		}
+	}
+
+	// Cloud IoT
+	if c.AzureIoTConnectionString != "" && c.AWSIoTEndpoint != "" {
+		add("only one of Azure IoT Hub (-azure-iot-connection-string) and AWS IoT Core (-aws-iot-endpoint) can be used")
+	}
+	if c.AWSIoTEndpoint != "" && (c.CloudIoTCert == "" || c.CloudIoTKey == "") {
+		add("AWS IoT Core needs the thing certificate and key (-cloud-iot-cert / -cloud-iot-key)")
+	}
+	if (c.CloudIoTCert == "") != (c.CloudIoTKey == "") {
+		add("cloud IoT certificate and key must be set together (-cloud-iot-cert / -cloud-iot-key)")
+	}
+
+	if !payload.Valid(c.StateEncoding) {
+		add("state encoding must be json, msgpack or cbor (-state-encoding / BYD_HASS_STATE_ENCODING)")
+	}
+
+	if c.MQTTRetain != "none" {
+		for _, topic := range strings.Split(c.MQTTRetain, ",") {
+			switch strings.TrimSpace(topic) {
+			case "state", "location", "last_transmission", "":
+			default:
+				add("retained topic %q must be state, location or last_transmission (-mqtt-retain / BYD_HASS_MQTT_RETAIN)", topic)
+			}
+		}
+	}
+	if c.MQTTExpireAfter < 0 {
+		add("expire after must not be negative (-mqtt-expire-after / BYD_HASS_MQTT_EXPIRE_AFTER)")
+	} else if c.MQTTExpireAfter > 0 && c.MQTTExpireAfter <= c.MQTTInterval {
+		add("expire after (%s) must be longer than the MQTT interval (%s) (-mqtt-expire-after / BYD_HASS_MQTT_EXPIRE_AFTER)",
+			c.MQTTExpireAfter, c.MQTTInterval)
+	}
+
+	// Home Assistant REST
+	if c.HAURL != "" {
+		if u, err := url.Parse(c.HAURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("Home Assistant URL %q must be http(s)://host:port (-ha-url / BYD_HASS_HA_URL)", c.HAURL)
+		}
+		if c.HAToken == "" {
+			add("Home Assistant URL requires a long-lived access token (-ha-token / BYD_HASS_HA_TOKEN)")
+		}
+	}
+
+	// Intervals
+	if c.MQTTInterval <= 0 {
+		add("MQTT interval must be positive (-mqtt-interval / BYD_HASS_MQTT_INTERVAL)")
+	}
+	if c.ABRPInterval <= 0 {
+		add("ABRP interval must be positive (-abrp-interval / BYD_HASS_ABRP_INTERVAL)")
+	}
+	if c.TraccarInterval <= 0 {
+		add("Traccar interval must be positive (-traccar-interval / BYD_HASS_TRACCAR_INTERVAL)")
+	}
+	if c.KafkaInterval <= 0 {
+		add("Kafka interval must be positive (-kafka-interval / BYD_HASS_KAFKA_INTERVAL)")
+	}
+	if c.RabbitMQInterval <= 0 {
+		add("RabbitMQ interval must be positive (-rabbitmq-interval / BYD_HASS_RABBITMQ_INTERVAL)")
+	}
+	if c.CloudIoTInterval <= 0 {
+		add("cloud IoT interval must be positive (-cloud-iot-interval / BYD_HASS_CLOUD_IOT_INTERVAL)")
+	}
+	if c.HAInterval <= 0 {
+		add("Home Assistant interval must be positive (-ha-interval / BYD_HASS_HA_INTERVAL)")
+	}
+	if _, err := netutil.ParseDNS(c.DNS); err != nil {
+		add("%v (-dns / BYD_HASS_DNS)", err)
+	}
+	if c.Proxy != "" {
+		if _, err := netutil.ParseProxy(c.Proxy); err != nil {
+			add("%v (-proxy / BYD_HASS_PROXY)", err)
+		}
+	}
+	if err := netutil.ValidateBypass(c.NoProxy); err != nil {
+		add("%v (-no-proxy / BYD_HASS_NO_PROXY)", err)
+	}
+	if _, err := logging.ProfileLevel(c.LogProfile, false); err != nil {
+		add("%v (-log-profile / BYD_HASS_LOG_PROFILE)", err)
+	}
+	if c.LogDedupeWindow < 0 {
+		add("log dedupe window must not be negative (-log-dedupe-window / BYD_HASS_LOG_DEDUPE_WINDOW)")
+	}
+	if _, err := logging.ParseLevels(c.LogLevels); err != nil {
+		add("%v (-log-levels / BYD_HASS_LOG_LEVELS)", err)
+	}
+	if c.LogStream && c.MQTTUrl == "" {
+		add("log streaming requires MQTT (-log-stream / BYD_HASS_LOG_STREAM)")
+	}
+	if c.LogStreamRate <= 0 {
+		add("log stream rate must be positive (-log-stream-rate / BYD_HASS_LOG_STREAM_RATE)")
+	}
+	for _, probe := range []struct{ target, flag string }{
+		{c.LANProbe, "-lan-probe / BYD_HASS_LAN_PROBE"},
+		{c.InternetProbe, "-internet-probe / BYD_HASS_INTERNET_PROBE"},
+	} {
+		if probe.target == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(probe.target); err != nil {
+			add("probe target %q must be host:port (%s)", probe.target, probe.flag)
+		}
+	}
+	if c.ProbeInterval < 0 {
+		add("probe interval must not be negative (-probe-interval / BYD_HASS_PROBE_INTERVAL)")
+	}
+	if c.StatusInterval < 0 {
+		add("status interval must not be negative (-status-interval / BYD_HASS_STATUS_INTERVAL)")
+	}
+	if c.TransmitJitter < 0 {
+		add("transmit jitter must not be negative (-transmit-jitter / BYD_HASS_TRANSMIT_JITTER)")
+	}
+	if c.MinSendSpacing < 0 {
+		add("minimum send spacing must not be negative (-min-send-spacing / BYD_HASS_MIN_SEND_SPACING)")
+	}
+	if c.ForceUpdateInterval < 0 {
+		add("force update interval must not be negative (-force-update-interval / BYD_HASS_FORCE_UPDATE_INTERVAL)")
+	} else if c.ForceUpdateInterval > 0 && c.ForceUpdateInterval < c.MQTTInterval {
+		add("force update interval (%s) must not be shorter than the MQTT interval (%s) (-force-update-interval / BYD_HASS_FORCE_UPDATE_INTERVAL)",
+			c.ForceUpdateInterval, c.MQTTInterval)
+	}
+
+	// Location precision
+	if c.LocationPrecision < 0 {
+		add("location precision must not be negative (-location-precision / BYD_HASS_LOCATION_PRECISION)")
+	}
+	for _, name := range strings.Split(c.LocationPrecisionFor, ",") {
+		switch strings.TrimSpace(name) {
+		case "", "mqtt", "abrp", "traccar", "kafka", "rabbitmq", "cloudiot", "ha":
+		default:
+			add("unknown transmitter %q, expected mqtt, abrp, traccar, kafka, rabbitmq, cloudiot or ha (-location-precision-for / BYD_HASS_LOCATION_PRECISION_FOR)", strings.TrimSpace(name))
+		}
+	}
+
+	// Sensor selection
+	if c.DetectCapabilities && c.StateDir == "" {
+		add("a state directory is required for capability detection (-state-dir / BYD_HASS_STATE_DIR)")
+	}
+	if c.Statistics && c.StateDir == "" {
+		add("a state directory is required for statistics (-state-dir / BYD_HASS_STATE_DIR)")
+	}
+
+	// Updates
+	if c.UpdateChannel != update.ChannelStable && c.UpdateChannel != update.ChannelBeta {
+		add("update channel must be %s or %s (-update-channel / BYD_HASS_UPDATE_CHANNEL)", update.ChannelStable, update.ChannelBeta)
+	}
+	if c.AutoUpdate {
+		if c.StateDir == "" {
+			add("a state directory is required for automatic updates (-state-dir / BYD_HASS_STATE_DIR)")
+		}
+		if c.AutoUpdateInterval < time.Hour {
+			add("auto-update interval must be at least 1h (-auto-update-interval / BYD_HASS_AUTO_UPDATE_INTERVAL)")
+		}
+	}
+
+	// TeslaMate
+	if c.TeslaMateCarID < 0 {
+		add("TeslaMate car ID must not be negative (-teslamate-car-id / BYD_HASS_TESLAMATE_CAR_ID)")
+	} else if c.TeslaMateCarID > 0 && c.MQTTUrl == "" {
+		add("TeslaMate topics need MQTT (-mqtt-url / BYD_HASS_MQTT_URL)")
+	}
+
+	// evcc
+	if c.EVCCListen != "" {
+		if _, _, err := net.SplitHostPort(c.EVCCListen); err != nil {
+			add("evcc listen address %q must be host:port, e.g. :8989 (-evcc-listen / BYD_HASS_EVCC_LISTEN)", c.EVCCListen)
+		}
+	}
+
+	// Charging cost
+	if c.ChargingPrice != "" {
+		if _, err := charging.ParseTariff(c.ChargingPrice); err != nil {
+			add("invalid charging price: %v (-charging-price / BYD_HASS_CHARGING_PRICE)", err)
+		}
+		if len(c.ChargingCurrency) != 3 {
+			add("charging currency must be a 3-letter ISO 4217 code (-charging-currency / BYD_HASS_CHARGING_CURRENCY)")
+		}
+		if c.StateDir == "" {
+			add("a state directory is required for charging cost tracking (-state-dir / BYD_HASS_STATE_DIR)")
+		}
+		if c.ChargingHomeZone != "" && c.Zones == "" {
+			add("charging home zone %q needs -zones / BYD_HASS_ZONES (-charging-home-zone / BYD_HASS_CHARGING_HOME_ZONE)", c.ChargingHomeZone)
+		}
+	}
+	if c.DiplusCommands != "" {
+		if _, err := api.ParseCommands(c.DiplusCommands); err != nil {
+			add("%v (-diplus-commands / BYD_HASS_DIPLUS_COMMANDS)", err)
+		}
+		if !strings.HasPrefix(c.DiplusControlPath, "/") {
+			add("Diplus control path must start with / (-diplus-control-path / BYD_HASS_DIPLUS_CONTROL_PATH)")
+		}
+	}
+	if !sensors.ValidPolicy(c.Validation) {
+		add("validation policy must be %s, %s or %s (-validation / BYD_HASS_VALIDATION)", sensors.PolicyWarn, sensors.PolicyDrop, sensors.PolicyClamp)
+	}
+	if _, err := sensors.ParseValidationRules(c.ValidationRules); err != nil {
+		add("%v (-validation-rules / BYD_HASS_VALIDATION_RULES)", err)
+	}
+	if _, err := domain.ParseTolerances(c.Deadband); err != nil {
+		add("%v (-deadband / BYD_HASS_DEADBAND)", err)
+	}
+	if c.CellVoltages != "" {
+		if _, _, err := sensors.ParseCellSpec(c.CellVoltages); err != nil {
+			add("%v (-cell-voltages / BYD_HASS_CELL_VOLTAGES)", err)
+		}
+	}
+	if c.CellTemperatures != "" {
+		if _, _, err := sensors.ParseCellSpec(c.CellTemperatures); err != nil {
+			add("%v (-cell-temperatures / BYD_HASS_CELL_TEMPERATURES)", err)
+		}
+	}
+	if c.ELM327 != "" {
+		if _, err := obd.ParsePIDs(c.ELM327PIDs); err != nil {
+			add("invalid OBD PIDs: %v (-elm327-pids / BYD_HASS_ELM327_PIDS)", err)
+		}
+	}
+	if c.Home != "" {
+		if _, err := geofence.ParseHome(c.Home); err != nil {
+			add("invalid home location: %v (-home / BYD_HASS_HOME)", err)
+		}
+	}
+	if _, err := sensors.SensorProfile(c.SensorProfile); err != nil {
+		add("%v (-sensor-profile / BYD_HASS_SENSOR_PROFILE)", err)
+	}
+	if c.Precondition != "" {
+		if _, err := precondition.Parse(c.Precondition); err != nil {
+			add("invalid preconditioning schedule: %v (-precondition / BYD_HASS_PRECONDITION)", err)
+		}
+	}
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			add("unknown timezone %q (-timezone / BYD_HASS_TIMEZONE)", c.Timezone)
+		}
+	}
+	if c.CarTimezone != "" {
+		if _, err := time.LoadLocation(c.CarTimezone); err != nil {
+			add("unknown car timezone %q (-car-timezone / BYD_HASS_CAR_TIMEZONE)", c.CarTimezone)
+		}
+	}
+	if c.DriveModes != "" {
+		if _, err := sensors.ParseModeNames(c.DriveModes); err != nil {
+			add("%v (-drive-modes / BYD_HASS_DRIVE_MODES)", err)
+		}
+	}
+	if c.NotifyURL != "" {
+		if _, err := notify.NewPusher(c.NotifyURL); err != nil {
+			add("%v (-notify-url / BYD_HASS_NOTIFY_URL)", err)
+		}
+	}
+	if c.ChargeCompleteSOC < 0 || c.ChargeCompleteSOC > 100 {
+		add("charge complete SOC must be between 0 and 100 (-charge-complete-soc / BYD_HASS_CHARGE_COMPLETE_SOC)")
+	}
+	if c.ChargeTargetSOC < 1 || c.ChargeTargetSOC > 100 {
+		add("charge target SOC must be between 1 and 100 (-charge-target-soc / BYD_HASS_CHARGE_TARGET_SOC)")
+	}
+	if c.ChargeLowSOC < 0 || c.ChargeLowSOC > 100 {
+		add("low SOC must be between 0 and 100 (-charge-low-soc / BYD_HASS_CHARGE_LOW_SOC)")
+	}
+	if c.FuelTankSize < 0 {
+		add("fuel tank size must not be negative (-fuel-tank-size / BYD_HASS_FUEL_TANK_SIZE)")
+	}
+	if c.UsableCapacity < 0 || c.UsableCapacity > 250 {
+		add("usable capacity must be between 0 and 250 kWh (-usable-capacity / BYD_HASS_USABLE_CAPACITY)")
+	}
+	if c.SensorIDs != "" {
+		if _, err := sensors.ParseSensorIDSpec(c.SensorIDs); err != nil {
+			add("invalid sensor list: %v (-sensor-ids / BYD_HASS_SENSOR_IDS)", err)
+		}
 	}
 
 	// Set defaults for invalid values
@@ -104,7 +869,7 @@ func (c *Config) Validate() error {
 		c.APITimeout = 10 // Set default
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 // HasMQTT returns true if MQTT is configured
@@ -117,7 +882,29 @@ func (c *Config) HasABRP() bool {
 	return c.ABRPAPIKey != "" && c.ABRPToken != ""
 }
 
+// HasTraccar returns true if Traccar is configured
+func (c *Config) HasTraccar() bool {
+	return c.TraccarURL != ""
+}
+
 // GetAPITimeout returns the API timeout as a duration
 func (c *Config) GetAPITimeout() time.Duration {
+	if c.APITimeout <= 0 {
+		return 10 * time.Second
+	}
 	return time.Duration(c.APITimeout) * time.Second
 }
+
+// CoarseLocationFor reports whether coordinates sent to transmitter ("mqtt",
+// "abrp", "traccar", "kafka", "rabbitmq", "cloudiot" or "ha") are reduced to LocationPrecision.
+func (c *Config) CoarseLocationFor(transmitter string) bool {
+	if c.LocationPrecision <= 0 {
+		return false
+	}
+	for _, name := range strings.Split(c.LocationPrecisionFor, ",") {
+		if strings.EqualFold(strings.TrimSpace(name), transmitter) {
+			return true
+		}
+	}
+	return false
+}
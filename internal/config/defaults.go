@@ -4,9 +4,17 @@ import "time"
 
 const (
 	// Polling / transmission intervals
-	DiplusPollInterval   = 8 * time.Second  // Poll local DiPlus API
-	ABRPTransmitInterval = 10 * time.Second // Push data to ABRP (HTTP)
-	MQTTTransmitInterval = 60 * time.Second // Publish data to MQTT
+	DiplusPollInterval        = 8 * time.Second  // Poll local DiPlus API (fast tier)
+	DiplusSlowPollInterval    = 2 * time.Minute  // Poll slow-changing DiPlus sensors
+	ABRPTransmitInterval      = 10 * time.Second // Push data to ABRP (HTTP)
+	MQTTTransmitInterval      = 60 * time.Second // Publish data to MQTT
+	TraccarTransmitInterval   = 30 * time.Second // Report position to Traccar (HTTP)
+	KafkaTransmitInterval     = 30 * time.Second // Produce snapshots to Kafka (HTTP)
+	RabbitMQTransmitInterval  = 30 * time.Second // Publish snapshots to RabbitMQ (HTTP)
+	CloudIoTTransmitInterval  = 60 * time.Second // Send snapshots to Azure IoT Hub / AWS IoT Core
+	HARESTTransmitInterval    = 60 * time.Second // Post states to the Home Assistant REST API
+	StatusPublishInterval     = 5 * time.Minute  // Publish the bridge status report to MQTT
+	ConnectivityProbeInterval = 30 * time.Second // Probe LAN and internet reachability
 
 	// Operation time-outs (to avoid blocking goroutines)
 	DiplusTimeout = 3 * time.Second // DiPlus API call
@@ -14,3 +22,10 @@ const (
 	ABRPTimeout   = 4 * time.Second // ABRP HTTP call
 
 )
+
+// ABRP app modes, see Config.ABRPAppMode.
+const (
+	ABRPAppYield   = "yield"   // Suspend ABRP telemetry while the ABRP app runs
+	ABRPAppRequire = "require" // Only send ABRP telemetry while the ABRP app runs
+	ABRPAppIgnore  = "off"     // Don't check for the ABRP app
+)
@@ -0,0 +1,267 @@
+// Package crash reports panics and error log lines to a Sentry-compatible
+// service (sentry.io, or a self-hosted Sentry or GlitchTip) so problems on
+// head units whose logs are hard to get at still arrive with a stack trace.
+//
+// Reporting is opt-in: nothing is sent until Init is called with a DSN.
+// Events are posted to the project's store endpoint with plain HTTP, so no
+// SDK is needed; they carry the release, OS/architecture and the log fields,
+// but no sensor data or location.
+package crash
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Limits that keep a misbehaving install from flooding the project.
+const (
+	maxEventsPerHour = 20
+	dedupWindow      = time.Hour
+	sendTimeout      = 5 * time.Second
+)
+
+type reporter struct {
+	endpoint   string
+	auth       string
+	release    string
+	httpClient *http.Client
+	logger     *logrus.Logger
+
+	mu        sync.Mutex
+	windowEnd time.Time
+	sent      int
+	lastSeen  map[string]time.Time // message → last report
+}
+
+var (
+	activeMu sync.RWMutex
+	active   *reporter
+)
+
+// Init enables reporting to dsn ("https://<key>@<host>/<project-id>") and
+// installs a logrus hook on logger that reports ERROR and more severe lines.
+func Init(dsn, release string, logger *logrus.Logger) error {
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User == nil || u.Host == "" {
+		return fmt.Errorf("invalid crash reporting DSN (expected https://<key>@<host>/<project>)")
+	}
+	project := strings.TrimPrefix(u.Path, "/")
+	prefix := ""
+	if i := strings.LastIndex(project, "/"); i >= 0 {
+		prefix, project = "/"+project[:i], project[i+1:]
+	}
+	if project == "" {
+		return fmt.Errorf("crash reporting DSN has no project ID")
+	}
+
+	r := &reporter{
+		endpoint: fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, project),
+		auth: fmt.Sprintf("Sentry sentry_version=7, sentry_client=byd-hass/%s, sentry_key=%s",
+			release, u.User.Username()),
+		release:    "byd-hass@" + release,
+		httpClient: &http.Client{Timeout: sendTimeout},
+		logger:     logger,
+		lastSeen:   make(map[string]time.Time),
+	}
+	activeMu.Lock()
+	active = r
+	activeMu.Unlock()
+
+	logger.AddHook(hook{r})
+	return nil
+}
+
+// Recover reports a panic in progress and re-panics, so the process still
+// crashes as it would without reporting. Use it as "defer crash.Recover()" at
+// the top of goroutines. It is a no-op unless Init was called.
+func Recover() {
+	activeMu.RLock()
+	r := active
+	activeMu.RUnlock()
+	if r == nil {
+		return
+	}
+	if v := recover(); v != nil {
+		r.send(r.panicEvent(v, debug.Stack()), true)
+		panic(v)
+	}
+}
+
+// ReportPanic reports a panic that the caller recovered from, e.g. a
+// subsystem the supervisor restarts. It is a no-op unless Init was called.
+func ReportPanic(v interface{}, stack []byte) {
+	activeMu.RLock()
+	r := active
+	activeMu.RUnlock()
+	if r != nil {
+		r.send(r.panicEvent(v, stack), false)
+	}
+}
+
+// hook reports ERROR, FATAL and PANIC log lines.
+type hook struct{ r *reporter }
+
+func (h hook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+}
+
+func (h hook) Fire(e *logrus.Entry) error {
+	extra := make(map[string]interface{}, len(e.Data))
+	for k, v := range e.Data {
+		if err, ok := v.(error); ok {
+			extra[k] = err.Error()
+		} else {
+			extra[k] = fmt.Sprint(v)
+		}
+	}
+	ev := h.r.newEvent(levelName(e.Level))
+	ev["message"] = map[string]string{"formatted": e.Message}
+	ev["extra"] = extra
+	ev["logger"] = "logrus"
+	// Fatal exits right after the hooks run, so don't go async.
+	h.r.send(ev, e.Level <= logrus.FatalLevel)
+	return nil
+}
+
+func levelName(l logrus.Level) string {
+	if l <= logrus.FatalLevel {
+		return "fatal"
+	}
+	return "error"
+}
+
+func (r *reporter) newEvent(level string) map[string]interface{} {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return map[string]interface{}{
+		"event_id":  hex.EncodeToString(id),
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"level":     level,
+		"platform":  "go",
+		"release":   r.release,
+		"tags": map[string]string{
+			"os":   runtime.GOOS,
+			"arch": runtime.GOARCH,
+		},
+	}
+}
+
+func (r *reporter) panicEvent(v interface{}, stack []byte) map[string]interface{} {
+	ev := r.newEvent("fatal")
+	ev["exception"] = map[string]interface{}{
+		"values": []map[string]interface{}{{
+			"type":       "panic",
+			"value":      fmt.Sprint(v),
+			"stacktrace": map[string]interface{}{"frames": parseStack(stack)},
+		}},
+	}
+	return ev
+}
+
+// send posts ev unless the rate limit or deduplication suppresses it. Async
+// sends run in the background; sync sends wait up to sendTimeout.
+func (r *reporter) send(ev map[string]interface{}, sync bool) {
+	if !r.allow(dedupKey(ev)) {
+		return
+	}
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	post := func() {
+		req, err := http.NewRequest(http.MethodPost, r.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Sentry-Auth", r.auth)
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			// Debug only: an error here must not feed back into the hook.
+			r.logger.WithError(err).Debug("crash: report failed")
+			return
+		}
+		resp.Body.Close()
+	}
+	if sync {
+		post()
+	} else {
+		go post()
+	}
+}
+
+func (r *reporter) allow(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if now.After(r.windowEnd) {
+		r.windowEnd = now.Add(time.Hour)
+		r.sent = 0
+	}
+	if r.sent >= maxEventsPerHour {
+		return false
+	}
+	if last, ok := r.lastSeen[key]; ok && now.Sub(last) < dedupWindow {
+		return false
+	}
+	r.lastSeen[key] = now
+	r.sent++
+	return true
+}
+
+func dedupKey(ev map[string]interface{}) string {
+	if m, ok := ev["message"].(map[string]string); ok {
+		return "msg:" + m["formatted"]
+	}
+	if exc, ok := ev["exception"].(map[string]interface{}); ok {
+		if vals, ok := exc["values"].([]map[string]interface{}); ok && len(vals) > 0 {
+			return fmt.Sprint("panic:", vals[0]["value"])
+		}
+	}
+	return fmt.Sprint(ev["event_id"])
+}
+
+// parseStack turns a debug.Stack() dump into Sentry frames, outermost call
+// first as Sentry expects.
+func parseStack(stack []byte) []map[string]interface{} {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+	var frames []map[string]interface{}
+	// Line 0 is "goroutine N [running]:", then function/location pairs.
+	for i := 1; i+1 < len(lines); i += 2 {
+		fn := strings.TrimSpace(lines[i])
+		if p := strings.LastIndex(fn, "("); p > 0 {
+			fn = fn[:p]
+		}
+		loc := strings.TrimSpace(lines[i+1])
+		if p := strings.LastIndex(loc, " +0x"); p > 0 {
+			loc = loc[:p]
+		}
+		file, lineNo := loc, 0
+		if p := strings.LastIndex(loc, ":"); p > 0 {
+			file = loc[:p]
+			fmt.Sscanf(loc[p+1:], "%d", &lineNo)
+		}
+		frames = append(frames, map[string]interface{}{
+			"function": fn,
+			"abs_path": file,
+			"lineno":   lineNo,
+			"in_app":   strings.Contains(fn, "byd-hass"),
+		})
+	}
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames
+}
@@ -1,16 +1,97 @@
 package domain
 
 import (
+	"fmt"
 	"math"
-	"reflect"
-	"time"
+	"strconv"
+	"strings"
 
+	"github.com/jkaberg/byd-hass/internal/location"
 	"github.com/jkaberg/byd-hass/internal/sensors"
 )
 
+// ignoredKeys are wall-clock date/time fields that naturally change every
+// minute and must not trigger a transmit on their own.
+var ignoredKeys = map[string]bool{
+	"month": true, "day": true, "hour": true, "minute": true,
+}
+
+// defaultTolerances are the built-in deadbands by AllSensors ID, in the
+// sensor's unit, for readings that jitter while the car sits idle.
+var defaultTolerances = map[int]float64{
+	17:   0.01, // MaxBatteryVoltage, a cell voltage on most cars
+	18:   0.01, // MinBatteryVoltage
+	25:   0.5,  // CabinTemperature
+	26:   0.5,  // OutsideTemperature
+	39:   0.1,  // BatteryVoltage12V
+	1009: 5,    // BluetoothSignalStrength (dBm)
+}
+
+// Tolerances holds per-sensor deadbands keyed by state key (e.g.
+// "battery_voltage12_v": 0.1). A change no larger than the tolerance is not
+// reported by Changed. Sensors without an entry must match exactly. It starts
+// out with the built-in defaults; SetTolerances applies overrides at startup,
+// before the first poll.
+var Tolerances = tolerancesByKey(defaultTolerances)
+
+func tolerancesByKey(byID map[int]float64) map[string]float64 {
+	out := make(map[string]float64, len(byID))
+	for id, tol := range byID {
+		if def := sensors.GetSensorByID(id); def != nil && tol > 0 {
+			out[sensors.ToSnakeCase(def.FieldName)] = tol
+		}
+	}
+	return out
+}
+
+// ParseTolerances parses deadband overrides as "key=value,..." with state
+// keys, e.g. "battery_voltage12_v=0.2,speed=1". A value of 0 makes the
+// sensor match exactly again, disabling a built-in deadband.
+func ParseTolerances(spec string) (map[int]float64, error) {
+	out := make(map[int]float64)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok {
+			return nil, fmt.Errorf("deadband %q: expected key=value", entry)
+		}
+		def := sensors.GetSensorByKey(key)
+		if def == nil {
+			return nil, fmt.Errorf("deadband %q: unknown sensor", key)
+		}
+		if _, dup := out[def.ID]; dup {
+			return nil, fmt.Errorf("deadband %q: defined more than once", key)
+		}
+		tol, err := strconv.ParseFloat(value, 64)
+		if err != nil || tol < 0 || math.IsInf(tol, 0) || math.IsNaN(tol) {
+			return nil, fmt.Errorf("deadband %q: invalid value %q", key, value)
+		}
+		out[def.ID] = tol
+	}
+	return out, nil
+}
+
+// SetTolerances replaces Tolerances with the built-in deadbands overridden
+// by overrides (see ParseTolerances).
+func SetTolerances(overrides map[int]float64) {
+	merged := make(map[int]float64, len(defaultTolerances)+len(overrides))
+	for id, tol := range defaultTolerances {
+		merged[id] = tol
+	}
+	for id, tol := range overrides {
+		merged[id] = tol
+	}
+	Tolerances = tolerancesByKey(merged)
+}
+
 // Changed returns true if *cur* differs from *prev* beyond tolerated jitter.
-// It zeroes the Timestamp field and ignores small GPS noise so that minor
-// location updates don't trigger a transmit.
+// It ignores the Timestamp, the wall-clock fields, per-sensor deadbands and
+// small GPS noise so that minor updates don't trigger a transmit. Fields are
+// compared one by one without copying the snapshots.
 func Changed(prev, cur *sensors.SensorData) bool {
 	if prev == nil && cur == nil {
 		return false
@@ -19,44 +100,40 @@ func Changed(prev, cur *sensors.SensorData) bool {
 		return true
 	}
 
-	p, c := *prev, *cur // copy
-	p.Timestamp = time.Time{}
-	c.Timestamp = time.Time{}
-
-	// Ignore wall-clock date/time fields that naturally change every minute
-	p.Year, p.Month, p.Day, p.Hour, p.Minute = nil, nil, nil, nil, nil
-	c.Year, c.Month, c.Day, c.Hour, c.Minute = nil, nil, nil, nil, nil
-
-	if p.Location != nil && c.Location != nil {
-		const distThr = 10.0 // metres
-		const bearThr = 5.0  // degrees
-		dist := haversineMeters(p.Location.Latitude, p.Location.Longitude,
-			c.Location.Latitude, c.Location.Longitude)
-		bearingDiff := math.Abs(p.Location.Bearing - c.Location.Bearing)
-		if bearingDiff > 180 {
-			bearingDiff = 360 - bearingDiff
-		}
-		if dist < distThr && bearingDiff < bearThr {
-			p.Location = nil
-			c.Location = nil
-		}
+	if !sensors.ZipFloats(prev, cur, floatEqual) {
+		return true
 	}
-
-	return !reflect.DeepEqual(p, c)
+	if !sensors.ZipStrings(prev, cur, func(_ string, a, b *string) bool {
+		return (a == nil) == (b == nil) && (a == nil || *a == *b)
+	}) {
+		return true
+	}
+	return locationChanged(prev.Location, cur.Location)
 }
 
-func haversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
-	const r = 6371000.0 // Earth radius in metres
-	dLat := toRad(lat2 - lat1)
-	dLon := toRad(lon2 - lon1)
-	lat1Rad := toRad(lat1)
-	lat2Rad := toRad(lat2)
-
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Cos(lat1Rad)*math.Cos(lat2Rad)*
-			math.Sin(dLon/2)*math.Sin(dLon/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	return r * c
+func floatEqual(key string, a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b || ignoredKeys[key]
+	}
+	if *a == *b || ignoredKeys[key] {
+		return true
+	}
+	return math.Abs(*a-*b) <= Tolerances[key]
 }
 
-func toRad(deg float64) float64 { return deg * math.Pi / 180 }
+func locationChanged(p, c *location.LocationData) bool {
+	if p == nil || c == nil {
+		return p != c
+	}
+	const distThr = 10.0 // metres
+	const bearThr = 5.0  // degrees
+	dist := location.HaversineMeters(p.Latitude, p.Longitude, c.Latitude, c.Longitude)
+	bearingDiff := math.Abs(p.Bearing - c.Bearing)
+	if bearingDiff > 180 {
+		bearingDiff = 360 - bearingDiff
+	}
+	if dist < distThr && bearingDiff < bearThr {
+		return false
+	}
+	return *p != *c
+}
@@ -0,0 +1,147 @@
+// Package evcc serves the latest vehicle state over HTTP in a shape that
+// evcc's "custom" vehicle template can read with simple jq expressions, so
+// the car can take part in evcc charge management.
+package evcc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/formula"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+// SensorIDs are the sensors the endpoint reports: BatteryPercentage,
+// Mileage, EnginePower and ChargeGunState.
+var SensorIDs = []int{33, 3, 10, 12}
+
+// Path is the endpoint serving the vehicle state.
+const Path = "/api/vehicle"
+
+// Vehicle is the JSON document served at Path.
+type Vehicle struct {
+	SoC       float64   `json:"soc"`
+	Range     *float64  `json:"range,omitempty"` // km, only with a range sensor
+	Status    string    `json:"status"`          // IEC 61851: A, B or C
+	Odometer  *float64  `json:"odometer,omitempty"`
+	Connected bool      `json:"connected"`
+	Charging  bool      `json:"charging"`
+	Updated   time.Time `json:"updated"`
+}
+
+// Server keeps the latest snapshot and serves it as a Vehicle.
+type Server struct {
+	addr      string
+	rangeExpr []formula.VirtualSensor // virtual sensors up to the range sensor
+	rangeKey  string
+	logger    *logrus.Logger
+
+	mu     sync.RWMutex
+	latest *sensors.SensorData
+}
+
+// New creates a server listening on addr. A non-empty rangeKey must name one
+// of the virtual sensors; its value is reported as the range.
+func New(addr string, virtual []formula.VirtualSensor, rangeKey string, logger *logrus.Logger) (*Server, error) {
+	s := &Server{addr: addr, logger: logger}
+	if rangeKey == "" {
+		return s, nil
+	}
+	for i, v := range virtual {
+		if v.Key == rangeKey {
+			s.rangeExpr, s.rangeKey = virtual[:i+1], rangeKey
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("range sensor %q is not a virtual sensor", rangeKey)
+}
+
+// Observe records the latest snapshot.
+func (s *Server) Observe(snap *sensors.SensorData) {
+	s.mu.Lock()
+	s.latest = snap
+	s.mu.Unlock()
+}
+
+// vehicle converts the latest snapshot; ok is false before the first one.
+func (s *Server) vehicle() (Vehicle, bool) {
+	s.mu.RLock()
+	data := s.latest
+	s.mu.RUnlock()
+	if data == nil || data.BatteryPercentage == nil {
+		return Vehicle{}, false
+	}
+
+	v := Vehicle{
+		SoC:      *data.BatteryPercentage,
+		Odometer: data.Mileage,
+		Updated:  data.Timestamp.UTC(),
+	}
+	switch sensors.DeriveChargingStatus(data) {
+	case "charging":
+		v.Status, v.Connected, v.Charging = "C", true, true
+	case "connected":
+		v.Status, v.Connected = "B", true
+	default:
+		v.Status = "A"
+	}
+	if s.rangeKey != "" {
+		raw := sensors.GetNonNilFields(data)
+		lookup := func(name string) (float64, bool) {
+			f, ok := raw[name].(float64)
+			return f, ok
+		}
+		if r, ok := formula.EvaluateAll(s.rangeExpr, lookup)[s.rangeKey]; ok {
+			v.Range = &r
+		}
+	}
+	return v, true
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	v, ok := s.vehicle()
+	if !ok {
+		http.Error(w, "no vehicle data yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.WithError(err).Debug("evcc: failed to write response")
+	}
+}
+
+// Run serves HTTP until ctx is cancelled. A failure to listen is logged but
+// not returned, so it doesn't bring the rest of the bridge down.
+func (s *Server) Run(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(Path, s.handle)
+	srv := &http.Server{
+		Addr:              s.addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	s.logger.WithField("addr", s.addr).Info("evcc endpoint listening")
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.logger.WithError(err).Error("evcc endpoint stopped")
+		return nil
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,111 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// Charge reminder kinds, used as event types.
+const (
+	ChargeComplete    = "charge_complete"
+	ChargeInterrupted = "charge_interrupted"
+	PlugInReminder    = "plug_in_reminder"
+)
+
+// ChargeReminderKinds lists every kind a ChargeReminderDetector can report.
+var ChargeReminderKinds = []string{ChargeComplete, ChargeInterrupted, PlugInReminder}
+
+// ChargeReminderSensorIDs lists the Diplus sensors the detector relies on:
+// PowerStatus, EnginePower, ChargeGunState and BatteryPercentage.
+var ChargeReminderSensorIDs = []int{1, 10, 12, 33}
+
+// plugInDelay is how long the car must sit unplugged at home with a low SOC
+// before the plug-in reminder fires, so it doesn't fire while still parking.
+const plugInDelay = 10 * time.Minute
+
+// ChargeReminder is a due charging reminder.
+type ChargeReminder struct {
+	Kind string  // one of ChargeReminderKinds
+	SOC  float64 // battery percentage, 0 if unknown
+}
+
+// ChargeReminderDetector reports finished and interrupted charging sessions
+// and a car left unplugged at home with a low SOC. It is safe for concurrent
+// use.
+type ChargeReminderDetector struct {
+	mu          sync.Mutex
+	completeSOC float64 // stopping at or above this is "complete"
+	lowSOC      float64 // plug-in reminder below this
+	prevStatus  string
+	lowSince    time.Time
+	lowReported bool
+}
+
+// NewChargeReminderDetector returns a detector. Charging that stops with the
+// gun still connected below completeSOC counts as interrupted; lowSOC is the
+// plug-in reminder threshold (0 disables it).
+func NewChargeReminderDetector(completeSOC, lowSOC float64) *ChargeReminderDetector {
+	return &ChargeReminderDetector{completeSOC: completeSOC, lowSOC: lowSOC}
+}
+
+// Update feeds a snapshot and returns the reminders that became due. atHome
+// reports whether the car is inside the home zone; the plug-in reminder only
+// fires there.
+func (d *ChargeReminderDetector) Update(data *sensors.SensorData, atHome bool) []ChargeReminder {
+	if data == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var soc float64
+	if data.BatteryPercentage != nil {
+		soc = *data.BatteryPercentage
+	}
+
+	var out []ChargeReminder
+	status := sensors.DeriveChargingStatus(data)
+	if d.prevStatus == "charging" && status == "connected" {
+		// Unplugging mid-session is deliberate, so only a stop with the gun
+		// still connected is reported.
+		kind := ChargeComplete
+		if data.BatteryPercentage != nil && soc < d.completeSOC {
+			kind = ChargeInterrupted
+		}
+		out = append(out, ChargeReminder{Kind: kind, SOC: soc})
+	}
+	d.prevStatus = status
+
+	if r, ok := d.plugIn(data, status, atHome, soc); ok {
+		out = append(out, r)
+	}
+	return out
+}
+
+// plugIn tracks the "parked at home, low SOC, not plugged in" condition and
+// fires once per parked period after plugInDelay.
+func (d *ChargeReminderDetector) plugIn(data *sensors.SensorData, status string, atHome bool, soc float64) (ChargeReminder, bool) {
+	low := d.lowSOC > 0 && atHome && status == "disconnected" &&
+		data.PowerStatus != nil && *data.PowerStatus == 0 &&
+		data.BatteryPercentage != nil && soc < d.lowSOC
+	if !low {
+		d.lowSince, d.lowReported = time.Time{}, false
+		return ChargeReminder{}, false
+	}
+
+	now := data.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if d.lowSince.IsZero() {
+		d.lowSince = now
+	}
+	if d.lowReported || now.Sub(d.lowSince) < plugInDelay {
+		return ChargeReminder{}, false
+	}
+	d.lowReported = true
+	return ChargeReminder{Kind: PlugInReminder, SOC: soc}, true
+}
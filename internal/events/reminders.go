@@ -0,0 +1,118 @@
+package events
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// Reminder kinds, used as event types.
+const (
+	ReminderDoorOpen = "door_open"
+	ReminderLightsOn = "lights_on"
+)
+
+// ReminderKinds lists every kind a ReminderDetector can report.
+var ReminderKinds = []string{ReminderDoorOpen, ReminderLightsOn}
+
+// ReminderSensorIDs lists the Diplus sensors the reminder detector relies
+// on: PowerStatus, RemoteLockStatus, the doors, trunk and hood, and the
+// parking and low-beam lights.
+var ReminderSensorIDs = []int{1, 22, 81, 82, 83, 84, 85, 86, 99, 100}
+
+// reminderItems maps the watched sensors to their kind and item name.
+var reminderItems = []struct {
+	kind, item string
+	get        func(*sensors.SensorData) *float64
+}{
+	{ReminderDoorOpen, "driver_door", func(d *sensors.SensorData) *float64 { return d.DriverDoor }},
+	{ReminderDoorOpen, "passenger_door", func(d *sensors.SensorData) *float64 { return d.PassengerDoor }},
+	{ReminderDoorOpen, "left_rear_door", func(d *sensors.SensorData) *float64 { return d.LeftRearDoor }},
+	{ReminderDoorOpen, "right_rear_door", func(d *sensors.SensorData) *float64 { return d.RightRearDoor }},
+	{ReminderDoorOpen, "trunk", func(d *sensors.SensorData) *float64 { return d.TrunkDoor }},
+	{ReminderDoorOpen, "hood", func(d *sensors.SensorData) *float64 { return d.Hood }},
+	{ReminderLightsOn, "parking_lights", func(d *sensors.SensorData) *float64 { return d.ParkingLights }},
+	{ReminderLightsOn, "low_beam_lights", func(d *sensors.SensorData) *float64 { return d.LowBeamLights }},
+}
+
+// Reminder reports items left open or on after the car was parked and
+// locked.
+type Reminder struct {
+	Kind  string   // ReminderDoorOpen or ReminderLightsOn
+	Items []string // e.g. "trunk", "parking_lights"
+}
+
+// ReminderDetector raises a reminder once an item has stayed open or on for
+// the configured delay while the car is locked with power off. Each item is
+// reported at most once per parked period. It is safe for concurrent use.
+type ReminderDetector struct {
+	mu       sync.Mutex
+	delay    time.Duration
+	since    map[string]time.Time // item -> first seen open/on while parked
+	reported map[string]bool
+}
+
+// NewReminderDetector returns a detector that waits delay before reminding.
+func NewReminderDetector(delay time.Duration) *ReminderDetector {
+	return &ReminderDetector{
+		delay:    delay,
+		since:    make(map[string]time.Time),
+		reported: make(map[string]bool),
+	}
+}
+
+// Update feeds a snapshot and returns the reminders that became due, at most
+// one per kind.
+func (d *ReminderDetector) Update(data *sensors.SensorData) []Reminder {
+	if data == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !parkedAndLocked(data) {
+		d.since = make(map[string]time.Time)
+		d.reported = make(map[string]bool)
+		return nil
+	}
+
+	now := data.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	due := make(map[string][]string)
+	for _, it := range reminderItems {
+		v := it.get(data)
+		if v == nil {
+			// Unknown this round: keep the timer running.
+			continue
+		}
+		if *v == 0 {
+			delete(d.since, it.item)
+			delete(d.reported, it.item)
+			continue
+		}
+		start, ok := d.since[it.item]
+		if !ok {
+			d.since[it.item] = now
+			continue
+		}
+		if !d.reported[it.item] && now.Sub(start) >= d.delay {
+			d.reported[it.item] = true
+			due[it.kind] = append(due[it.kind], it.item)
+		}
+	}
+
+	var out []Reminder
+	for _, kind := range ReminderKinds {
+		if items := due[kind]; len(items) > 0 {
+			sort.Strings(items)
+			out = append(out, Reminder{Kind: kind, Items: items})
+		}
+	}
+	return out
+}
@@ -0,0 +1,213 @@
+package events
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// Rule is a user-defined condition evaluated against every snapshot.
+//
+// Rules are written as "name:expr" and separated by ";":
+//
+//	speeding:speed>120                     speed above 120 km/h
+//	hot_battery:max_battery_temp>45/5m     above 45 °C for at least 5 minutes
+//	soc_drop:drop(battery_percentage)>5/10m  SOC fell by more than 5 within 10 minutes
+//
+// Field names are the snake_case JSON keys of sensors.SensorData. The
+// optional "/duration" is the sustain time for threshold rules and the
+// look-back window for drop()/rise() rules.
+type Rule struct {
+	Name      string
+	Field     string
+	Op        string // ">", "<", ">=", "<="
+	Value     float64
+	Rate      string // "", "drop" or "rise"
+	Window    time.Duration
+	rawString string
+}
+
+func (r Rule) String() string { return r.rawString }
+
+var ruleExpr = regexp.MustCompile(`^(?:(drop|rise)\(([a-z0-9_]+)\)|([a-z0-9_]+))\s*(>=|<=|>|<)\s*(-?[0-9.]+)\s*(?:/\s*([0-9a-z.]+))?$`)
+
+// ParseRules parses a rule specification (see Rule).
+func ParseRules(spec string) ([]Rule, error) {
+	var rules []Rule
+	seen := make(map[string]bool)
+
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("rule %q: expected name:expression", entry)
+		}
+		name := strings.TrimSpace(parts[0])
+		if seen[name] {
+			return nil, fmt.Errorf("rule %q: defined more than once", name)
+		}
+
+		m := ruleExpr.FindStringSubmatch(strings.TrimSpace(parts[1]))
+		if m == nil {
+			return nil, fmt.Errorf("rule %q: cannot parse %q", name, parts[1])
+		}
+
+		r := Rule{Name: name, Rate: m[1], Field: m[2], Op: m[4], rawString: entry}
+		if r.Field == "" {
+			r.Field = m[3]
+		}
+		v, err := strconv.ParseFloat(m[5], 64)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: invalid value %q", name, m[5])
+		}
+		r.Value = v
+		if m[6] != "" {
+			d, err := time.ParseDuration(m[6])
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("rule %q: invalid duration %q", name, m[6])
+			}
+			r.Window = d
+		}
+		if r.Rate != "" && r.Window == 0 {
+			return nil, fmt.Errorf("rule %q: %s() needs a window, e.g. /10m", name, r.Rate)
+		}
+
+		seen[name] = true
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// RuleHit is reported when a rule starts matching.
+type RuleHit struct {
+	Rule  Rule
+	Value float64 // the observed value (or delta for drop/rise rules)
+}
+
+type sample struct {
+	at    time.Time
+	value float64
+}
+
+type ruleState struct {
+	since   time.Time // when the condition started to hold (threshold rules)
+	active  bool      // already reported; re-armed once the condition clears
+	history []sample  // recent values (rate rules)
+}
+
+// RuleEngine evaluates rules against incoming snapshots. Each rule fires once
+// when its condition becomes true and re-arms when it clears.
+type RuleEngine struct {
+	rules []Rule
+
+	mu    sync.Mutex
+	state map[string]*ruleState
+}
+
+// NewRuleEngine returns an engine for the given rules.
+func NewRuleEngine(rules []Rule) *RuleEngine {
+	e := &RuleEngine{rules: rules, state: make(map[string]*ruleState, len(rules))}
+	for _, r := range rules {
+		e.state[r.Name] = &ruleState{}
+	}
+	return e
+}
+
+// Rules returns the configured rules.
+func (e *RuleEngine) Rules() []Rule { return e.rules }
+
+// Update evaluates all rules against the snapshot and returns the ones that
+// started matching.
+func (e *RuleEngine) Update(data *sensors.SensorData) []RuleHit {
+	if data == nil {
+		return nil
+	}
+	values := sensors.GetNonNilFields(data)
+	now := data.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var hits []RuleHit
+	for _, r := range e.rules {
+		raw, ok := values[r.Field]
+		if !ok {
+			continue
+		}
+		v, ok := raw.(float64)
+		if !ok {
+			continue
+		}
+
+		st := e.state[r.Name]
+		observed, holds := e.evaluate(r, st, now, v)
+
+		if !holds {
+			st.since = time.Time{}
+			st.active = false
+			continue
+		}
+		if st.active {
+			continue
+		}
+		if r.Rate == "" && r.Window > 0 && now.Sub(st.since) < r.Window {
+			continue
+		}
+		st.active = true
+		hits = append(hits, RuleHit{Rule: r, Value: observed})
+	}
+
+	return hits
+}
+
+// evaluate returns the value compared against the rule and whether the
+// condition currently holds.
+func (e *RuleEngine) evaluate(r Rule, st *ruleState, now time.Time, v float64) (float64, bool) {
+	if r.Rate == "" {
+		holds := compare(v, r.Op, r.Value)
+		if holds && st.since.IsZero() {
+			st.since = now
+		}
+		return v, holds
+	}
+
+	// Keep samples inside the window and compare against the oldest one.
+	st.history = append(st.history, sample{at: now, value: v})
+	cutoff := now.Add(-r.Window)
+	for len(st.history) > 1 && st.history[0].at.Before(cutoff) {
+		st.history = st.history[1:]
+	}
+
+	delta := v - st.history[0].value
+	if r.Rate == "drop" {
+		delta = -delta
+	}
+	return delta, compare(delta, r.Op, r.Value)
+}
+
+func compare(v float64, op string, threshold float64) bool {
+	switch op {
+	case ">":
+		return v > threshold
+	case "<":
+		return v < threshold
+	case ">=":
+		return v >= threshold
+	case "<=":
+		return v <= threshold
+	}
+	return false
+}
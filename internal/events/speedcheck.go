@@ -0,0 +1,131 @@
+package events
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// Speed check tuning. Comparisons only happen while driving fast enough for
+// GPS speed to be meaningful; the verdict needs a full-ish window so a tunnel
+// or a single bad fix doesn't flip it.
+const (
+	speedCheckMinSpeed   = 15.0             // km/h wheel speed before samples count
+	speedCheckMaxFixAge  = 30 * time.Second // older fixes count as stale
+	speedCheckWindow     = 30               // samples kept
+	speedCheckMinSamples = 10               // samples needed for a verdict
+	speedCheckMismatch   = 0.15             // median relative error that raises
+	speedCheckRecover    = 0.10             // ... and that clears again
+)
+
+// Speed check reasons.
+const (
+	SpeedCheckOK       = ""
+	SpeedCheckStaleGPS = "stale_gps"
+	SpeedCheckMismatch = "speed_mismatch"
+)
+
+// SpeedCheckResult is the current verdict of a SpeedChecker.
+type SpeedCheckResult struct {
+	Problem bool
+	Reason  string  // SpeedCheckStaleGPS or SpeedCheckMismatch when Problem
+	Ratio   float64 // median GPS speed / wheel speed over the window, 0 if unknown
+	Samples int     // samples in the window
+}
+
+// speedSample is one moving snapshot: either a GPS/wheel ratio or a stale fix.
+type speedSample struct {
+	ratio float64
+	stale bool
+}
+
+// SpeedChecker compares GPS-derived speed with the car's speed sensor and
+// flags systematic disagreement, which usually means a stale GPS file or a
+// wrong speed scale factor. It is safe for concurrent use.
+type SpeedChecker struct {
+	mu      sync.Mutex
+	samples []speedSample
+	result  SpeedCheckResult
+}
+
+// NewSpeedChecker returns a ready-to-use checker.
+func NewSpeedChecker() *SpeedChecker { return &SpeedChecker{} }
+
+// Update feeds a snapshot and returns the verdict and whether it changed.
+// Snapshots without a wheel speed, below the minimum speed or without a GPS
+// fix leave the window untouched.
+func (c *SpeedChecker) Update(data *sensors.SensorData) (SpeedCheckResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sample, ok := speedSampleOf(data)
+	if !ok {
+		return c.result, false
+	}
+	c.samples = append(c.samples, sample)
+	if len(c.samples) > speedCheckWindow {
+		c.samples = c.samples[len(c.samples)-speedCheckWindow:]
+	}
+
+	prev := c.result
+	c.result = c.evaluate()
+	changed := c.result.Problem != prev.Problem || c.result.Reason != prev.Reason
+	return c.result, changed
+}
+
+// speedSampleOf extracts a sample from a moving snapshot with a real fix.
+func speedSampleOf(data *sensors.SensorData) (speedSample, bool) {
+	if data == nil || data.Speed == nil || *data.Speed < speedCheckMinSpeed {
+		return speedSample{}, false
+	}
+	loc := data.Location
+	if loc == nil || loc.Provider == "default" {
+		return speedSample{}, false
+	}
+	if !loc.Timestamp.IsZero() && data.Timestamp.Sub(loc.Timestamp) > speedCheckMaxFixAge {
+		return speedSample{stale: true}, true
+	}
+	return speedSample{ratio: loc.Speed * 3.6 / *data.Speed}, true
+}
+
+// evaluate derives the verdict from the window. The stale check wins over the
+// ratio since a frozen fix makes the ratio meaningless.
+func (c *SpeedChecker) evaluate() SpeedCheckResult {
+	res := SpeedCheckResult{Samples: len(c.samples)}
+
+	var ratios []float64
+	stale := 0
+	for _, s := range c.samples {
+		if s.stale {
+			stale++
+		} else {
+			ratios = append(ratios, s.ratio)
+		}
+	}
+	if len(ratios) > 0 {
+		sort.Float64s(ratios)
+		res.Ratio = math.Round(ratios[len(ratios)/2]*1000) / 1000
+	}
+	if len(c.samples) < speedCheckMinSamples {
+		// Not enough evidence yet: keep the previous verdict.
+		res.Problem, res.Reason = c.result.Problem, c.result.Reason
+		return res
+	}
+
+	switch {
+	case stale*2 > len(c.samples):
+		res.Problem, res.Reason = true, SpeedCheckStaleGPS
+	case len(ratios) >= speedCheckMinSamples:
+		threshold := speedCheckMismatch
+		if c.result.Reason == SpeedCheckMismatch {
+			threshold = speedCheckRecover
+		}
+		if math.Abs(res.Ratio-1) > threshold {
+			res.Problem, res.Reason = true, SpeedCheckMismatch
+		}
+	}
+	return res
+}
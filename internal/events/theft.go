@@ -0,0 +1,124 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/location"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// TheftSensorIDs lists the Diplus sensors the theft detector relies on:
+// PowerStatus, Speed and RemoteLockStatus.
+var TheftSensorIDs = []int{1, 2, 22}
+
+// Displacement tuning. A single fix can be off by far more than its
+// reported accuracy (multipath in garages, a cold start), so movement has to
+// show on several fresh fixes in a row before it counts.
+const (
+	theftDisplacement = 75.0             // metres beyond both fixes' accuracy
+	theftMaxFixAge    = 60 * time.Second // older fixes are ignored
+	theftConfirmFixes = 2                // consecutive displaced fixes needed
+)
+
+// TheftAlert describes movement detected while the car is locked and off.
+type TheftAlert struct {
+	Reason       string  // "speed" or "displacement"
+	Speed        float64 // km/h, when Reason == "speed"
+	Displacement float64 // metres from the locked position
+	Latitude     float64
+	Longitude    float64
+}
+
+// TheftDetector watches for movement while the car is locked with power off.
+// It fires at most once per locked period. It is safe for concurrent use.
+type TheftDetector struct {
+	mu      sync.Mutex
+	armed   bool
+	fired   bool
+	anchor  *location.LocationData
+	lastFix time.Time // last fix seen, so a repeated fix isn't counted twice
+	moved   int       // consecutive fresh fixes beyond the displacement limit
+}
+
+// NewTheftDetector returns a ready-to-use detector.
+func NewTheftDetector() *TheftDetector { return &TheftDetector{} }
+
+// Update feeds a snapshot and returns an alert when movement is detected.
+func (d *TheftDetector) Update(data *sensors.SensorData) *TheftAlert {
+	if data == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !parkedAndLocked(data) {
+		d.armed, d.fired, d.anchor, d.moved = false, false, nil, 0
+		return nil
+	}
+
+	fix := d.freshFix(data)
+	if !d.armed {
+		// Just locked: remember where, and don't alert on this snapshot.
+		d.armed = true
+		d.anchor = fix
+		return nil
+	}
+	if d.fired {
+		return nil
+	}
+	if d.anchor == nil {
+		d.anchor = fix
+		fix = nil
+	}
+
+	alert := &TheftAlert{}
+	if fix != nil {
+		alert.Latitude, alert.Longitude = fix.Latitude, fix.Longitude
+		alert.Displacement = location.HaversineMeters(d.anchor.Latitude, d.anchor.Longitude, fix.Latitude, fix.Longitude)
+		if alert.Displacement > theftDisplacement+d.anchor.Accuracy+fix.Accuracy {
+			d.moved++
+		} else {
+			d.moved = 0
+		}
+	}
+
+	switch {
+	case data.Speed != nil && *data.Speed > 0:
+		alert.Reason = "speed"
+		alert.Speed = *data.Speed
+	case d.moved >= theftConfirmFixes:
+		alert.Reason = "displacement"
+	default:
+		return nil
+	}
+
+	d.fired = true
+	return alert
+}
+
+// freshFix returns the snapshot's location when it is a real fix not older
+// than theftMaxFixAge and not the same fix as last time, nil otherwise.
+func (d *TheftDetector) freshFix(data *sensors.SensorData) *location.LocationData {
+	loc := data.Location
+	if loc == nil || loc.Provider == "default" {
+		return nil
+	}
+	if !loc.Timestamp.IsZero() {
+		if data.Timestamp.Sub(loc.Timestamp) > theftMaxFixAge || loc.Timestamp.Equal(d.lastFix) {
+			return nil
+		}
+		d.lastFix = loc.Timestamp
+	}
+	fix := *loc
+	return &fix
+}
+
+// parkedAndLocked reports RemoteLockStatus locked (> 0) with power off (0).
+// Missing values are treated as "not locked" so we never alert on partial
+// data.
+func parkedAndLocked(data *sensors.SensorData) bool {
+	return data.RemoteLockStatus != nil && *data.RemoteLockStatus > 0 &&
+		data.PowerStatus != nil && *data.PowerStatus == 0
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// DeviceTrigger identifies a Home Assistant device trigger (type/subtype
+// pair as shown on the HA device page).
+type DeviceTrigger struct {
+	ID      string // unique per device, used in topics
+	Type    string
+	Subtype string
+}
+
+// DeviceTriggers lists every trigger the detector can fire.
+var DeviceTriggers = []DeviceTrigger{
+	{ID: "driver_door_opened", Type: "door_opened", Subtype: "driver_door"},
+	{ID: "passenger_door_opened", Type: "door_opened", Subtype: "passenger_door"},
+	{ID: "left_rear_door_opened", Type: "door_opened", Subtype: "left_rear_door"},
+	{ID: "right_rear_door_opened", Type: "door_opened", Subtype: "right_rear_door"},
+	{ID: "trunk_opened", Type: "door_opened", Subtype: "trunk"},
+	{ID: "hood_opened", Type: "door_opened", Subtype: "hood"},
+	{ID: "charge_started", Type: "charge_started", Subtype: "charger"},
+	{ID: "charge_complete", Type: "charge_complete", Subtype: "charger"},
+	{ID: "sentry_triggered", Type: "sentry_triggered", Subtype: "sentry"},
+}
+
+// TriggerSensorIDs lists the Diplus sensors the detector relies on beyond
+// the default monitored set.
+var TriggerSensorIDs = []int{81, 82, 83, 84, 85, 86, 1003, 1006}
+
+// doorFields maps trigger IDs to the door sensor they watch.
+var doorFields = []struct {
+	id  string
+	get func(*sensors.SensorData) *float64
+}{
+	{"driver_door_opened", func(d *sensors.SensorData) *float64 { return d.DriverDoor }},
+	{"passenger_door_opened", func(d *sensors.SensorData) *float64 { return d.PassengerDoor }},
+	{"left_rear_door_opened", func(d *sensors.SensorData) *float64 { return d.LeftRearDoor }},
+	{"right_rear_door_opened", func(d *sensors.SensorData) *float64 { return d.RightRearDoor }},
+	{"trunk_opened", func(d *sensors.SensorData) *float64 { return d.TrunkDoor }},
+	{"hood_opened", func(d *sensors.SensorData) *float64 { return d.Hood }},
+}
+
+// TriggerDetector compares consecutive snapshots and reports which device
+// triggers fired. It is safe for concurrent use.
+type TriggerDetector struct {
+	mu   sync.Mutex
+	prev *sensors.SensorData
+}
+
+// NewTriggerDetector returns a ready-to-use detector.
+func NewTriggerDetector() *TriggerDetector { return &TriggerDetector{} }
+
+// Update feeds a snapshot and returns the IDs of triggers that fired. The
+// first snapshot only establishes the baseline.
+func (d *TriggerDetector) Update(cur *sensors.SensorData) []string {
+	if cur == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	prev := d.prev
+	d.prev = cur
+	d.mu.Unlock()
+
+	if prev == nil {
+		return nil
+	}
+
+	var fired []string
+	for _, door := range doorFields {
+		if opened(door.get(prev), door.get(cur)) {
+			fired = append(fired, door.id)
+		}
+	}
+
+	prevCharge := sensors.DeriveChargingStatus(prev)
+	curCharge := sensors.DeriveChargingStatus(cur)
+	if curCharge == "charging" && prevCharge != "charging" {
+		fired = append(fired, "charge_started")
+	}
+	if prevCharge == "charging" && curCharge == "connected" {
+		fired = append(fired, "charge_complete")
+	}
+
+	if sensors.DeriveSentryState(cur) == sensors.SentryTriggered &&
+		sensors.DeriveSentryState(prev) != sensors.SentryTriggered {
+		fired = append(fired, "sentry_triggered")
+	}
+
+	return fired
+}
+
+// opened reports a closed → open transition of a door sensor.
+func opened(prev, cur *float64) bool {
+	return prev != nil && cur != nil && *prev == 0 && *cur > 0
+}
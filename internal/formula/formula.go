@@ -0,0 +1,355 @@
+// Package formula implements the small arithmetic language used for
+// user-defined virtual sensors, e.g.
+//
+//	total_range[km]=battery_percentage/100*82.5/16*100
+//
+// Expressions support numbers, sensor keys, + - * /, unary minus,
+// parentheses and the functions min, max, abs and round.
+package formula
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a parsed expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Lookup resolves a variable; ok is false when no value is available.
+type Lookup func(name string) (value float64, ok bool)
+
+// Parse compiles an expression.
+func Parse(src string) (*Expr, error) {
+	p := &parser{src: src}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.tok.text, p.tok.pos)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source expression.
+func (e *Expr) String() string { return e.src }
+
+// Vars returns the distinct variable names referenced by the expression.
+func (e *Expr) Vars() []string {
+	seen := make(map[string]bool)
+	var out []string
+	e.root.vars(func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	})
+	return out
+}
+
+// Eval evaluates the expression. ok is false when a variable is missing or
+// the result is not a finite number (e.g. division by zero).
+func (e *Expr) Eval(lookup Lookup) (float64, bool) {
+	v, ok := e.root.eval(lookup)
+	if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+// -----------------------------------------------------------------------------
+// AST
+// -----------------------------------------------------------------------------
+
+type node interface {
+	eval(Lookup) (float64, bool)
+	vars(func(string))
+}
+
+type numNode float64
+
+func (n numNode) eval(Lookup) (float64, bool) { return float64(n), true }
+func (n numNode) vars(func(string))           {}
+
+type varNode string
+
+func (n varNode) eval(l Lookup) (float64, bool) { return l(string(n)) }
+func (n varNode) vars(f func(string))           { f(string(n)) }
+
+type negNode struct{ x node }
+
+func (n negNode) eval(l Lookup) (float64, bool) {
+	v, ok := n.x.eval(l)
+	return -v, ok
+}
+func (n negNode) vars(f func(string)) { n.x.vars(f) }
+
+type binNode struct {
+	op   byte
+	a, b node
+}
+
+func (n binNode) eval(l Lookup) (float64, bool) {
+	a, ok := n.a.eval(l)
+	if !ok {
+		return 0, false
+	}
+	b, ok := n.b.eval(l)
+	if !ok {
+		return 0, false
+	}
+	switch n.op {
+	case '+':
+		return a + b, true
+	case '-':
+		return a - b, true
+	case '*':
+		return a * b, true
+	default:
+		if b == 0 {
+			return 0, false
+		}
+		return a / b, true
+	}
+}
+func (n binNode) vars(f func(string)) { n.a.vars(f); n.b.vars(f) }
+
+type callNode struct {
+	fn   string
+	args []node
+}
+
+// functions maps names to implementations and their argument count
+// (-1 = at least one).
+var functions = map[string]struct {
+	arity int
+	fn    func([]float64) float64
+}{
+	"abs":   {1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"round": {1, func(a []float64) float64 { return math.Round(a[0]) }},
+	"min": {-1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Min(m, v)
+		}
+		return m
+	}},
+	"max": {-1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Max(m, v)
+		}
+		return m
+	}},
+}
+
+func (n callNode) eval(l Lookup) (float64, bool) {
+	vals := make([]float64, len(n.args))
+	for i, a := range n.args {
+		v, ok := a.eval(l)
+		if !ok {
+			return 0, false
+		}
+		vals[i] = v
+	}
+	return functions[n.fn].fn(vals), true
+}
+func (n callNode) vars(f func(string)) {
+	for _, a := range n.args {
+		a.vars(f)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Lexer & recursive-descent parser
+// -----------------------------------------------------------------------------
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNum
+	tokIdent
+	tokOp // + - * / ( ) ,
+)
+
+type token struct {
+	kind tokKind
+	text string
+	num  float64
+	pos  int
+}
+
+type parser struct {
+	src string
+	pos int
+	tok token
+}
+
+func (p *parser) next() error {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return nil
+	}
+	c := rune(p.src[p.pos])
+	switch {
+	case unicode.IsDigit(c) || c == '.':
+		for p.pos < len(p.src) && (unicode.IsDigit(rune(p.src[p.pos])) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		text := p.src[start:p.pos]
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q at position %d", text, start)
+		}
+		p.tok = token{kind: tokNum, text: text, num: v, pos: start}
+	case unicode.IsLetter(c) || c == '_':
+		for p.pos < len(p.src) && (unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos])) || p.src[p.pos] == '_') {
+			p.pos++
+		}
+		p.tok = token{kind: tokIdent, text: p.src[start:p.pos], pos: start}
+	case strings.ContainsRune("+-*/(),", c):
+		p.pos++
+		p.tok = token{kind: tokOp, text: string(c), pos: start}
+	default:
+		return fmt.Errorf("unexpected character %q at position %d", c, start)
+	}
+	return nil
+}
+
+func (p *parser) isOp(op string) bool { return p.tok.kind == tokOp && p.tok.text == op }
+
+func (p *parser) expect(op string) error {
+	if !p.isOp(op) {
+		return fmt.Errorf("expected %q at position %d", op, p.tok.pos)
+	}
+	return p.next()
+}
+
+// sum := product (('+' | '-') product)*
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+") || p.isOp("-") {
+		op := p.tok.text[0]
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binNode{op: op, a: left, b: right}
+	}
+	return left, nil
+}
+
+// product := unary (('*' | '/') unary)*
+func (p *parser) parseProduct() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*") || p.isOp("/") {
+		op := p.tok.text[0]
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binNode{op: op, a: left, b: right}
+	}
+	return left, nil
+}
+
+// unary := '-' unary | primary
+func (p *parser) parseUnary() (node, error) {
+	if p.isOp("-") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negNode{x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+// primary := number | ident | ident '(' args ')' | '(' sum ')'
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokNum:
+		return numNode(tok.num), p.next()
+	case tok.kind == tokIdent:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if !p.isOp("(") {
+			return varNode(tok.text), nil
+		}
+		return p.parseCall(tok)
+	case p.isOp("("):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+	case tok.kind == tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.pos)
+	}
+	if err := p.next(); err != nil { // consume '('
+		return nil, err
+	}
+	var args []node
+	for !p.isOp(")") {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if err := p.next(); err != nil { // consume ')'
+		return nil, err
+	}
+	if (fn.arity < 0 && len(args) == 0) || (fn.arity >= 0 && len(args) != fn.arity) {
+		return nil, fmt.Errorf("wrong number of arguments to %s()", name.text)
+	}
+	return callNode{fn: name.text, args: args}, nil
+}
@@ -0,0 +1,90 @@
+package formula
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// VirtualSensor is a user-defined sensor computed from other sensors.
+type VirtualSensor struct {
+	Key  string // snake_case entity/state key
+	Unit string // optional unit of measurement
+	Expr *Expr
+}
+
+// Name returns a human-readable name derived from the key.
+func (v VirtualSensor) Name() string {
+	words := strings.Split(v.Key, "_")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+var virtualHead = regexp.MustCompile(`^([a-z][a-z0-9_]*)\s*(?:\[([^\]]*)\])?$`)
+
+// ParseVirtualSensors parses "key[unit]=expression" entries separated by ';'.
+// Expressions may reference sensor keys accepted by isSensor and virtual
+// sensors defined earlier in the list.
+func ParseVirtualSensors(spec string, isSensor func(key string) bool) ([]VirtualSensor, error) {
+	var out []VirtualSensor
+	defined := make(map[string]bool)
+
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("virtual sensor %q: expected key[unit]=expression", entry)
+		}
+		m := virtualHead.FindStringSubmatch(strings.TrimSpace(parts[0]))
+		if m == nil {
+			return nil, fmt.Errorf("virtual sensor %q: key must be lower_snake_case, optionally followed by [unit]", parts[0])
+		}
+		key := m[1]
+		if defined[key] {
+			return nil, fmt.Errorf("virtual sensor %q: defined more than once", key)
+		}
+		if isSensor(key) {
+			return nil, fmt.Errorf("virtual sensor %q: clashes with a built-in sensor", key)
+		}
+
+		expr, err := Parse(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("virtual sensor %q: %w", key, err)
+		}
+		for _, name := range expr.Vars() {
+			if !defined[name] && !isSensor(name) {
+				return nil, fmt.Errorf("virtual sensor %q: unknown sensor %q", key, name)
+			}
+		}
+
+		defined[key] = true
+		out = append(out, VirtualSensor{Key: key, Unit: strings.TrimSpace(m[2]), Expr: expr})
+	}
+	return out, nil
+}
+
+// EvaluateAll computes every virtual sensor in order; later sensors can use
+// earlier results. Sensors whose inputs are missing are left out.
+func EvaluateAll(vs []VirtualSensor, lookup Lookup) map[string]float64 {
+	results := make(map[string]float64, len(vs))
+	chained := func(name string) (float64, bool) {
+		if v, ok := results[name]; ok {
+			return v, true
+		}
+		return lookup(name)
+	}
+	for _, v := range vs {
+		if val, ok := v.Expr.Eval(chained); ok {
+			results[v.Key] = val
+		}
+	}
+	return results
+}
@@ -0,0 +1,208 @@
+package geofence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jkaberg/byd-hass/internal/location"
+)
+
+// DefaultRadius is used when a zone definition omits the radius (metres).
+const DefaultRadius = 100.0
+
+// AwayZone is reported as the current zone when the car is outside every
+// configured zone.
+const AwayZone = "away"
+
+// leaveHysteresis widens a zone by this factor before we consider the car to
+// have left it, so GPS jitter around the border doesn't flap enter/leave.
+const leaveHysteresis = 1.1
+
+// Zone is a named circular area.
+type Zone struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+	Radius    float64 // metres
+}
+
+// Contains reports whether the coordinate lies within the zone radius.
+func (z Zone) Contains(lat, lon float64) bool {
+	return location.HaversineMeters(z.Latitude, z.Longitude, lat, lon) <= z.Radius
+}
+
+// ParseZones parses a zone specification of the form
+//
+//	name:lat,lon[,radius];name:lat,lon[,radius];...
+//
+// e.g. "home:59.91,10.75,150;work:59.95,10.70". Radius is in metres and
+// defaults to DefaultRadius.
+func ParseZones(spec string) ([]Zone, error) {
+	var zones []Zone
+	seen := make(map[string]bool)
+
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("zone %q: expected name:lat,lon[,radius]", entry)
+		}
+		name := strings.TrimSpace(parts[0])
+		if name == AwayZone {
+			return nil, fmt.Errorf("zone %q: name is reserved", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("zone %q: defined more than once", name)
+		}
+
+		coords := strings.Split(parts[1], ",")
+		if len(coords) < 2 || len(coords) > 3 {
+			return nil, fmt.Errorf("zone %q: expected lat,lon[,radius]", name)
+		}
+
+		values := make([]float64, len(coords))
+		for i, c := range coords {
+			v, err := strconv.ParseFloat(strings.TrimSpace(c), 64)
+			if err != nil {
+				return nil, fmt.Errorf("zone %q: invalid number %q", name, c)
+			}
+			values[i] = v
+		}
+
+		z := Zone{Name: name, Latitude: values[0], Longitude: values[1], Radius: DefaultRadius}
+		if len(values) == 3 {
+			z.Radius = values[2]
+		}
+		if z.Latitude < -90 || z.Latitude > 90 || z.Longitude < -180 || z.Longitude > 180 {
+			return nil, fmt.Errorf("zone %q: coordinates out of range", name)
+		}
+		if z.Radius <= 0 {
+			return nil, fmt.Errorf("zone %q: radius must be positive", name)
+		}
+
+		seen[name] = true
+		zones = append(zones, z)
+	}
+
+	return zones, nil
+}
+
+// HomeZone is the zone name Home Assistant treats as home.
+const HomeZone = "home"
+
+// Device tracker states besides zone names, matching Home Assistant's.
+const (
+	TrackerHome    = "home"
+	TrackerNotHome = "not_home"
+)
+
+// ParseHome parses the home location "lat,lon[,radius]" into a zone named
+// HomeZone.
+func ParseHome(spec string) (Zone, error) {
+	zones, err := ParseZones(HomeZone + ":" + spec)
+	if err != nil {
+		return Zone{}, err
+	}
+	if len(zones) != 1 {
+		return Zone{}, fmt.Errorf("home: expected lat,lon[,radius]")
+	}
+	return zones[0], nil
+}
+
+// TrackerState returns the device tracker state for a coordinate: "home"
+// inside home (or a zone named home), the name of the first other zone
+// containing it, or "not_home".
+func TrackerState(home *Zone, zones []Zone, lat, lon float64) string {
+	if home != nil && home.Contains(lat, lon) {
+		return TrackerHome
+	}
+	for _, z := range zones {
+		if z.Contains(lat, lon) {
+			if z.Name == HomeZone {
+				return TrackerHome
+			}
+			return z.Name
+		}
+	}
+	return TrackerNotHome
+}
+
+// Transition describes the car entering or leaving a zone.
+type Transition struct {
+	Zone  string
+	Enter bool // true = entered, false = left
+}
+
+// Tracker remembers which zones the car is in and reports transitions as new
+// fixes arrive. It is safe for concurrent use.
+type Tracker struct {
+	zones []Zone
+
+	mu      sync.Mutex
+	inside  map[string]bool
+	started bool
+}
+
+// NewTracker returns a tracker for the given zones.
+func NewTracker(zones []Zone) *Tracker {
+	return &Tracker{zones: zones, inside: make(map[string]bool)}
+}
+
+// Zones returns the configured zones.
+func (t *Tracker) Zones() []Zone { return t.zones }
+
+// Current returns the first configured zone the tracker considers the car
+// to be in, or AwayZone. It follows the same hysteresis as the transitions
+// Update reports; ok is false until the first fix has been seen.
+func (t *Tracker) Current() (zone string, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.started {
+		return "", false
+	}
+	for _, z := range t.zones {
+		if t.inside[z.Name] {
+			return z.Name, true
+		}
+	}
+	return AwayZone, true
+}
+
+// Update feeds a new fix into the tracker and returns the resulting
+// transitions. The very first fix only establishes the baseline and never
+// yields transitions, so a restart inside a zone doesn't fire "enter".
+func (t *Tracker) Update(loc *location.LocationData) []Transition {
+	if loc == nil || loc.Provider == "default" {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var out []Transition
+	for _, z := range t.zones {
+		dist := location.HaversineMeters(z.Latitude, z.Longitude, loc.Latitude, loc.Longitude)
+		was := t.inside[z.Name]
+
+		now := was
+		if !was && dist <= z.Radius {
+			now = true
+		} else if was && dist > z.Radius*leaveHysteresis {
+			now = false
+		}
+
+		if now != was && t.started {
+			out = append(out, Transition{Zone: z.Name, Enter: now})
+		}
+		t.inside[z.Name] = now
+	}
+	t.started = true
+
+	return out
+}
@@ -0,0 +1,216 @@
+// Package golden replays recorded Diplus responses of several models
+// through the parse → derive → render pipeline and compares the resulting
+// MQTT state and ABRP telemetry with the payloads checked in under
+// testdata/expected. Changes to parsing, scale factors or derived sensors
+// that alter a model's payloads show up as a failing test before a release;
+// when the change is intended, run the test with -update and review the
+// diff.
+//
+// Fixtures live in testdata/fixtures/<name>.json: the car model, a start
+// time and frames, each the "val" string of a Diplus response and its offset
+// from the start. Frames of one fixture share the transmitters, so session
+// state such as the AC/DC classification carries over like on a car.
+package golden
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/jkaberg/byd-hass/internal/transmission"
+	"github.com/jkaberg/byd-hass/internal/vehicle"
+	"github.com/sirupsen/logrus"
+)
+
+var update = flag.Bool("update", false, "rewrite the expected payloads in testdata/expected")
+
+// fixture is a recorded sequence of Diplus responses.
+type fixture struct {
+	Name   string    `json:"-"`
+	Model  string    `json:"model"` // vehicle.Models key
+	Start  time.Time `json:"start"`
+	Frames []frame   `json:"frames"`
+}
+
+// frame is one Diplus response.
+type frame struct {
+	Offset string `json:"offset"` // since Start, e.g. "90s"
+	Val    string `json:"val"`    // the response's pipe-separated values
+}
+
+// output is what the pipeline produced for one frame.
+type output struct {
+	Offset string                     `json:"offset"`
+	MQTT   map[string]interface{}     `json:"mqtt"`
+	ABRP   transmission.ABRPTelemetry `json:"abrp"`
+}
+
+func TestPayloads(t *testing.T) {
+	// The payloads contain local times; pin the zone so they don't depend on
+	// the machine running the test.
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = local })
+
+	fixtures, err := loadFixtures()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no fixtures in testdata/fixtures")
+	}
+	for _, f := range fixtures {
+		t.Run(f.Name, func(t *testing.T) {
+			got, err := render(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join("testdata", "expected", f.Name+".json")
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("no expected payloads, run with -update: %v", err)
+			}
+			diffs, err := compare(got, want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, d := range diffs {
+				t.Error(d)
+			}
+			if len(diffs) > 0 {
+				t.Logf("%d payload value(s) differ; if intended, run \"go test ./internal/golden -update\" and review the diff", len(diffs))
+			}
+		})
+	}
+}
+
+// loadFixtures reads testdata/fixtures sorted by name.
+func loadFixtures() ([]fixture, error) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "fixtures", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var out []fixture
+	for _, p := range paths {
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var f fixture
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", p, err)
+		}
+		f.Name = strings.TrimSuffix(filepath.Base(p), ".json")
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// render runs the fixture through the pipeline and returns the indented
+// JSON of its outputs, the format of the expected files.
+func render(f fixture) ([]byte, error) {
+	model, ok := vehicle.ByKey(f.Model)
+	if !ok {
+		return nil, fmt.Errorf("unknown model %q", f.Model)
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	// Shared like in main, so both transmitters see calibrated power.
+	charge := transmission.NewChargeTracker(sensors.NewPowerCalibration(sensors.PowerSignAuto, 1))
+	mqttTx := transmission.NewMQTTTransmitter(nil, "golden", "homeassistant", logger)
+	mqttTx.SetChargeTracker(charge)
+	mqttTx.SetUsableCapacity(model.UsableKWh)
+	mqttTx.SetHVACPower(true)
+	mqttTx.SetChargeTarget(80)
+	abrpTx := transmission.NewABRPTransmitter("", "", logger)
+	abrpTx.SetCarModel(model.ABRPModel)
+	abrpTx.SetUsableCapacity(model.UsableKWh)
+	abrpTx.SetChargeTracker(charge)
+
+	outputs := make([]output, 0, len(f.Frames))
+	for i, fr := range f.Frames {
+		offset, err := time.ParseDuration(fr.Offset)
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, err)
+		}
+		body, _ := json.Marshal(sensors.APIResponse{Success: true, Val: fr.Val})
+		data, err := sensors.ParseAPIResponse(body)
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, err)
+		}
+		data.Timestamp = f.Start.Add(offset)
+		outputs = append(outputs, output{
+			Offset: fr.Offset,
+			MQTT:   mqttTx.RenderState(data),
+			ABRP:   abrpTx.RenderTelemetry(data),
+		})
+	}
+	raw, err := json.MarshalIndent(outputs, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(raw, '\n'), nil
+}
+
+// compare lists the paths whose values differ between two renderings.
+func compare(got, want []byte) ([]string, error) {
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(want, &w); err != nil {
+		return nil, fmt.Errorf("invalid expected payloads: %w", err)
+	}
+	gf, wf := map[string]interface{}{}, map[string]interface{}{}
+	flatten("", g, gf)
+	flatten("", w, wf)
+
+	var diffs []string
+	for key, gv := range gf {
+		if wv, ok := wf[key]; !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: new value %v", key, gv))
+		} else if !reflect.DeepEqual(gv, wv) {
+			diffs = append(diffs, fmt.Sprintf("%s: got %v, want %v", key, gv, wv))
+		}
+	}
+	for key, wv := range wf {
+		if _, ok := gf[key]; !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: missing, want %v", key, wv))
+		}
+	}
+	sort.Strings(diffs)
+	return diffs, nil
+}
+
+// flatten maps every leaf of v to its path, e.g. "[2].mqtt.speed".
+func flatten(prefix string, v interface{}, out map[string]interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flatten(strings.TrimPrefix(prefix+"."+key, "."), child, out)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), child, out)
+		}
+	default:
+		out[prefix] = v
+	}
+}
@@ -0,0 +1,115 @@
+// Package hook runs a user-supplied command on new data or events so local
+// integrations (displays, scripts, …) can be built without Go code.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// KindChange is the hook kind used for changed snapshots. Event hooks use the
+// event entity ID (e.g. "theft_alert") as their kind.
+const KindChange = "change"
+
+// Runner executes the hook command through "sh -c" with the JSON payload on
+// stdin and the kind in $BYD_HASS_HOOK_EVENT. One invocation per kind runs
+// at a time; triggers arriving while that kind's previous run is busy are
+// dropped so a slow script can't pile up processes, while a slow "change"
+// run never holds back an event such as "theft_alert".
+type Runner struct {
+	command string
+	kinds   map[string]bool // nil = every kind
+	timeout time.Duration
+	logger  *logrus.Logger
+
+	mu   sync.Mutex
+	busy map[string]bool // kinds with a run in progress
+}
+
+// New creates a Runner. on is a comma-separated list of kinds to run for
+// ("change", event entity IDs, or "all").
+func New(command, on string, timeout time.Duration, logger *logrus.Logger) (*Runner, error) {
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("hook command is empty")
+	}
+	r := &Runner{
+		command: command,
+		timeout: timeout,
+		logger:  logger,
+		busy:    make(map[string]bool),
+	}
+	for _, k := range strings.Split(on, ",") {
+		k = strings.TrimSpace(k)
+		switch k {
+		case "":
+		case "all":
+			r.kinds = nil
+			return r, nil
+		default:
+			if r.kinds == nil {
+				r.kinds = make(map[string]bool)
+			}
+			r.kinds[k] = true
+		}
+	}
+	if r.kinds == nil {
+		return nil, fmt.Errorf("hook has no kinds to run for")
+	}
+	return r, nil
+}
+
+// Wants reports whether the hook runs for the given kind.
+func (r *Runner) Wants(kind string) bool {
+	return r != nil && (r.kinds == nil || r.kinds[kind])
+}
+
+// Trigger starts the hook in the background unless it doesn't want kind or a
+// previous run for kind is still in progress.
+func (r *Runner) Trigger(ctx context.Context, kind string, payload []byte) {
+	if !r.Wants(kind) {
+		return
+	}
+	r.mu.Lock()
+	if r.busy[kind] {
+		r.mu.Unlock()
+		r.logger.WithField("kind", kind).Debug("hook: previous run still busy, skipping")
+		return
+	}
+	r.busy[kind] = true
+	r.mu.Unlock()
+	go func() {
+		defer func() {
+			r.mu.Lock()
+			delete(r.busy, kind)
+			r.mu.Unlock()
+		}()
+		if err := r.run(ctx, kind, payload); err != nil {
+			r.logger.WithError(err).WithField("kind", kind).Warn("hook: command failed")
+		}
+	}()
+}
+
+func (r *Runner) run(ctx context.Context, kind string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", r.command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), "BYD_HASS_HOOK_EVENT="+kind)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w (%s)", err, strings.TrimSpace(string(out)))
+	}
+	if len(out) > 0 {
+		r.logger.WithField("kind", kind).Debugf("hook: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
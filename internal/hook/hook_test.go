@@ -0,0 +1,49 @@
+package hook
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestTriggerKeepsOneRunPerKind(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	out := filepath.Join(t.TempDir(), "runs")
+	r, err := New(`echo "$BYD_HASS_HOOK_EVENT" >> "`+out+`"; sleep 0.5`, "all", 5*time.Second, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	r.Trigger(ctx, KindChange, nil)
+	r.Trigger(ctx, KindChange, nil) // dropped: change is still running
+	r.Trigger(ctx, "theft_alert", nil)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		r.mu.Lock()
+		idle := len(r.busy) == 0
+		r.mu.Unlock()
+		if idle || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs := strings.Fields(string(raw))
+	sort.Strings(runs)
+	if strings.Join(runs, ",") != "change,theft_alert" {
+		t.Errorf("runs = %v, want one change and one theft_alert", runs)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"sync"
 	"time"
@@ -70,12 +71,16 @@ func (p *TermuxLocationProvider) fetchFromFile() (*LocationData, time.Time, erro
 	}
 
 	var raw struct {
-		Latitude  float64 `json:"latitude"`
-		Longitude float64 `json:"longitude"`
-		Speed     float64 `json:"speed"`
-		Accuracy  float64 `json:"accuracy"`
-		Battery   float64 `json:"battery"`
-		Timestamp *int64  `json:"timestamp,omitempty"` // Optional timestamp from GPS script
+		Latitude         float64 `json:"latitude"`
+		Longitude        float64 `json:"longitude"`
+		Altitude         float64 `json:"altitude"`
+		Bearing          float64 `json:"bearing"`
+		Speed            float64 `json:"speed"`
+		Accuracy         float64 `json:"accuracy"`
+		VerticalAccuracy float64 `json:"vertical_accuracy"`
+		Provider         string  `json:"provider"`
+		Battery          float64 `json:"battery"`
+		Timestamp        *int64  `json:"timestamp,omitempty"` // Optional timestamp from GPS script
 	}
 
 	if err := json.Unmarshal(data, &raw); err != nil {
@@ -91,13 +96,23 @@ func (p *TermuxLocationProvider) fetchFromFile() (*LocationData, time.Time, erro
 		timestamp = fileModTime
 	}
 
+	// Keep the provider reported by termux-location (gps/network) when the GPS
+	// script passes it through, so consumers can judge fix quality.
+	provider := "termux-file"
+	if raw.Provider != "" {
+		provider = raw.Provider
+	}
+
 	return &LocationData{
-		Latitude:  raw.Latitude,
-		Longitude: raw.Longitude,
-		Speed:     raw.Speed,
-		Accuracy:  raw.Accuracy,
-		Provider:  "termux-file",
-		Timestamp: timestamp,
+		Latitude:         raw.Latitude,
+		Longitude:        raw.Longitude,
+		Altitude:         raw.Altitude,
+		Bearing:          raw.Bearing,
+		Speed:            raw.Speed,
+		Accuracy:         raw.Accuracy,
+		VerticalAccuracy: raw.VerticalAccuracy,
+		Provider:         provider,
+		Timestamp:        timestamp,
 	}, fileModTime, nil
 }
 
@@ -215,3 +230,40 @@ func (p *TermuxLocationProvider) SetFetchTimeout(timeout time.Duration) {
 	defer p.mu.Unlock()
 	p.fetchTimeout = timeout
 }
+
+// HaversineMeters returns the great-circle distance between two coordinates
+// in metres.
+func HaversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	const r = 6371000.0 // Earth radius in metres
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	lat1Rad := toRad(lat1)
+	lat2Rad := toRad(lat2)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1Rad)*math.Cos(lat2Rad)*
+			math.Sin(dLon/2)*math.Sin(dLon/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	return r * c
+}
+
+func toRad(deg float64) float64 { return deg * math.Pi / 180 }
+
+// Coarsened returns a copy of l with the coordinates snapped to a grid of
+// roughly meters × meters, so consumers only learn the area the car is in.
+// Accuracy is widened accordingly; l is returned unchanged when meters <= 0.
+func (l *LocationData) Coarsened(meters float64) *LocationData {
+	if l == nil || meters <= 0 {
+		return l
+	}
+	c := *l
+	const metersPerDegree = 111320.0
+	latStep := meters / metersPerDegree
+	c.Latitude = math.Round(l.Latitude/latStep) * latStep
+	if cos := math.Cos(toRad(c.Latitude)); cos > 1e-6 {
+		lonStep := meters / (metersPerDegree * cos)
+		c.Longitude = math.Round(l.Longitude/lonStep) * lonStep
+	}
+	c.Accuracy = math.Max(l.Accuracy, meters)
+	return &c
+}
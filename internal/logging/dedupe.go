@@ -0,0 +1,122 @@
+package logging
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// repeatedField marks the summary lines a Deduper writes itself.
+const repeatedField = "repeated"
+
+// Deduper is a logrus formatter that collapses repeated warnings. The first
+// WARN or ERROR line with a given message is written; identical messages
+// within the following window are counted instead, and Run writes one
+// summary per message with the count ("repeated"), e.g. when Diplus is
+// unreachable all night. If the message recurs before that, the new line
+// carries the count. Lines differing only in their fields (such as the
+// error text) count as identical. INFO and DEBUG lines pass through.
+//
+// Hooks still see every line; the MQTT log stream has its own rate limit.
+type Deduper struct {
+	logrus.Formatter
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[dedupeKey]*dedupeState
+}
+
+type dedupeKey struct {
+	level   logrus.Level
+	message string
+}
+
+type dedupeState struct {
+	since      time.Time     // when the current window started
+	suppressed int           // lines dropped in the current window
+	last       logrus.Fields // fields of the last dropped line
+}
+
+// NewDeduper wraps base, collapsing repeats within window.
+func NewDeduper(base logrus.Formatter, window time.Duration) *Deduper {
+	return &Deduper{Formatter: base, window: window, seen: make(map[dedupeKey]*dedupeState)}
+}
+
+// Format implements logrus.Formatter. Dropped lines format to nothing.
+func (d *Deduper) Format(entry *logrus.Entry) ([]byte, error) {
+	if entry.Level > logrus.WarnLevel {
+		return d.Formatter.Format(entry)
+	}
+	if _, summary := entry.Data[repeatedField]; summary {
+		return d.Formatter.Format(entry)
+	}
+
+	key := dedupeKey{entry.Level, entry.Message}
+	d.mu.Lock()
+	st, ok := d.seen[key]
+	if ok && entry.Time.Sub(st.since) < d.window {
+		st.suppressed++
+		st.last = entry.Data
+		d.mu.Unlock()
+		return nil, nil
+	}
+	d.seen[key] = &dedupeState{since: entry.Time}
+	d.mu.Unlock()
+	if ok && st.suppressed > 0 {
+		// The window ended before Run summarized it; report the count here.
+		dup := *entry
+		dup.Data = make(logrus.Fields, len(entry.Data)+1)
+		for k, v := range entry.Data {
+			dup.Data[k] = v
+		}
+		dup.Data[repeatedField] = st.suppressed
+		return d.Formatter.Format(&dup)
+	}
+	return d.Formatter.Format(entry)
+}
+
+// Run writes the summaries of expired windows through logger until ctx is
+// done. logger must use d as its formatter.
+func (d *Deduper) Run(ctx context.Context, logger *logrus.Logger) {
+	ticker := time.NewTicker(d.window / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			d.flush(logger, time.Time{})
+			return
+		case now := <-ticker.C:
+			d.flush(logger, now)
+		}
+	}
+}
+
+// flush logs the summaries of windows that ended before now (all of them if
+// now is zero) and forgets them.
+func (d *Deduper) flush(logger *logrus.Logger, now time.Time) {
+	type summary struct {
+		key   dedupeKey
+		state dedupeState
+	}
+	var due []summary
+	d.mu.Lock()
+	for key, st := range d.seen {
+		if !now.IsZero() && now.Sub(st.since) < d.window {
+			continue
+		}
+		if st.suppressed > 0 {
+			due = append(due, summary{key, *st})
+		}
+		delete(d.seen, key)
+	}
+	d.mu.Unlock()
+
+	for _, s := range due {
+		logger.WithFields(s.state.last).
+			WithField(repeatedField, s.state.suppressed).
+			WithField("window", d.window.String()).
+			Log(s.key.level, s.key.message)
+	}
+}
@@ -0,0 +1,83 @@
+// Package logging derives per-subsystem loggers so one subsystem can log at
+// a different level than the rest, e.g. "abrp=debug,mqtt=warn".
+package logging
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Log profiles select the base level when -verbose is off.
+const (
+	ProfileNormal = "normal" // INFO: one summary line per publish
+	ProfileQuiet  = "quiet"  // WARN: problems only, for small head-unit storage
+)
+
+// ProfileLevel returns the base level of a profile; verbose wins over it.
+func ProfileLevel(profile string, verbose bool) (logrus.Level, error) {
+	switch {
+	case verbose:
+		return logrus.DebugLevel, nil
+	case profile == ProfileQuiet:
+		return logrus.WarnLevel, nil
+	case profile == ProfileNormal || profile == "":
+		return logrus.InfoLevel, nil
+	}
+	return logrus.InfoLevel, fmt.Errorf("unknown log profile %q (expected %s or %s)", profile, ProfileNormal, ProfileQuiet)
+}
+
+// Modules lists the subsystems that accept their own log level.
+var Modules = []string{"collector", "mqtt", "abrp", "location", "wifi"}
+
+// ParseLevels parses a comma-separated list of module=level pairs.
+func ParseLevels(spec string) (map[string]logrus.Level, error) {
+	levels := make(map[string]logrus.Level)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("log level %q: expected module=level", part)
+		}
+		module := strings.TrimSpace(kv[0])
+		if !isModule(module) {
+			return nil, fmt.Errorf("log level %q: unknown module %q (expected one of %s)", part, module, strings.Join(Modules, ", "))
+		}
+		level, err := logrus.ParseLevel(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("log level %q: %w", part, err)
+		}
+		levels[module] = level
+	}
+	return levels, nil
+}
+
+// Module returns the logger for module. Without an override in levels this
+// is base itself; otherwise a logger sharing base's output, formatter and
+// hooks but with its own level.
+func Module(base *logrus.Logger, levels map[string]logrus.Level, module string) *logrus.Logger {
+	level, ok := levels[module]
+	if !ok {
+		return base
+	}
+	l := logrus.New()
+	l.Out = base.Out
+	l.Formatter = base.Formatter
+	l.Hooks = base.Hooks // shared, so hooks added to base later apply too
+	l.ReportCaller = base.ReportCaller
+	l.SetLevel(level)
+	return l
+}
+
+func isModule(name string) bool {
+	for _, m := range Modules {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package mqtt
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+// ErrNotAuthorized is returned when the broker rejects a connection or
+// subscription, typically because of its ACLs.
+var ErrNotAuthorized = errors.New("not authorized by the broker")
+
+// ErrTopicSuspended is returned for publishes to a topic that kept failing
+// while the connection was up; see topicGuard.
+var ErrTopicSuspended = errors.New("publishing suspended")
+
+// MQTT 3.1.1 has no publish error codes: a broker that denies a publish by
+// ACL either drops it silently or never acknowledges it, which looks like a
+// timeout. A topic whose publishes fail aclFailures times in a row while the
+// connection stays up is therefore suspended, starting at aclMinBackoff and
+// doubling up to aclMaxBackoff, instead of being retried every interval.
+const (
+	aclFailures   = 3
+	aclMinBackoff = time.Minute
+	aclMaxBackoff = 30 * time.Minute
+)
+
+// connectError explains a refused connection.
+func connectError(token mqtt.Token) error {
+	if ct, ok := token.(*mqtt.ConnectToken); ok {
+		switch ct.ReturnCode() {
+		case packets.ErrRefusedBadUsernameOrPassword:
+			return fmt.Errorf("broker rejected the username or password: %w", ErrNotAuthorized)
+		case packets.ErrRefusedNotAuthorised:
+			return fmt.Errorf("broker refused the connection, check its ACLs for this user and client ID: %w", ErrNotAuthorized)
+		}
+	}
+	return token.Error()
+}
+
+// subscribeError reports topics the broker refused in its SUBACK (0x80).
+func subscribeError(token mqtt.Token) error {
+	st, ok := token.(*mqtt.SubscribeToken)
+	if !ok {
+		return nil
+	}
+	for topic, code := range st.Result() {
+		if code == 0x80 {
+			return fmt.Errorf("broker refused the subscription to %s, check its ACLs: %w", topic, ErrNotAuthorized)
+		}
+	}
+	return nil
+}
+
+type topicState struct {
+	failures int
+	backoff  time.Duration
+	until    time.Time
+	lastErr  string
+}
+
+// topicGuard tracks consecutive publish failures per topic and suspends
+// topics that look denied. It is safe for concurrent use.
+type topicGuard struct {
+	mu     sync.Mutex
+	topics map[string]*topicState
+	denied map[string]string // subscriptions refused by the broker
+}
+
+func newTopicGuard() *topicGuard {
+	return &topicGuard{topics: make(map[string]*topicState), denied: make(map[string]string)}
+}
+
+// check returns ErrTopicSuspended while topic is suspended.
+func (g *topicGuard) check(topic string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	st := g.topics[topic]
+	if st == nil || time.Now().After(st.until) {
+		return nil
+	}
+	return fmt.Errorf("%w for %s until %s after repeated failures (broker ACL?): %s",
+		ErrTopicSuspended, topic, st.until.Format(time.TimeOnly), st.lastErr)
+}
+
+// failed records a failed publish. connected tells whether the connection
+// survived it; failures during an outage say nothing about the topic. It
+// returns the suspension when this failure started one.
+func (g *topicGuard) failed(topic string, err error, connected bool) (suspended time.Duration) {
+	if !connected {
+		return 0
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	st := g.topics[topic]
+	if st == nil {
+		st = &topicState{}
+		g.topics[topic] = st
+	}
+	st.failures++
+	st.lastErr = err.Error()
+	if st.failures < aclFailures {
+		return 0
+	}
+	switch {
+	case st.backoff == 0:
+		st.backoff = aclMinBackoff
+	case st.backoff < aclMaxBackoff:
+		st.backoff = min(2*st.backoff, aclMaxBackoff)
+	}
+	st.until = time.Now().Add(st.backoff)
+	st.failures = 0
+	return st.backoff
+}
+
+// succeeded clears the topic's failure history.
+func (g *topicGuard) succeeded(topic string) {
+	g.mu.Lock()
+	delete(g.topics, topic)
+	g.mu.Unlock()
+}
+
+// deny records a refused subscription.
+func (g *topicGuard) deny(topic string, err error) {
+	g.mu.Lock()
+	g.denied[topic] = err.Error()
+	g.mu.Unlock()
+}
+
+// problems describes the suspended topics and refused subscriptions.
+func (g *topicGuard) problems() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var out []string
+	now := time.Now()
+	for topic, st := range g.topics {
+		if now.Before(st.until) {
+			out = append(out, fmt.Sprintf("publish to %s suspended until %s: %s", topic, st.until.UTC().Format(time.RFC3339), st.lastErr))
+		}
+	}
+	for topic, msg := range g.denied {
+		out = append(out, fmt.Sprintf("subscribe to %s: %s", topic, msg))
+	}
+	sort.Strings(out)
+	return out
+}
@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 	"github.com/sirupsen/logrus"
+
+	"github.com/jkaberg/byd-hass/internal/netutil"
 )
 
 // Client wraps the MQTT client with additional functionality
@@ -16,18 +19,27 @@ type Client struct {
 	client   mqtt.Client
 	deviceID string
 	logger   *logrus.Logger
+
+	// Subscriptions are replayed on reconnect because we use clean sessions.
+	subMu sync.Mutex
+	subs  map[string]mqtt.MessageHandler
+
+	guard   *topicGuard // ACL diagnostics, see acl.go
+	metrics metrics
 }
 
-// NewClient creates a new MQTT client with support for both WebSocket and standard MQTT protocols
-func NewClient(mqttURL, deviceID string, logger *logrus.Logger) (*Client, error) {
+// NewClient creates a new MQTT client with support for both WebSocket and standard MQTT protocols.
+// An empty clientID defaults to "byd-hass-<deviceID>" (see DefaultClientID).
+func NewClient(mqttURL, deviceID, clientID string, logger *logrus.Logger) (*Client, error) {
 	// Parse the MQTT URL
 	parsedURL, err := url.Parse(mqttURL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid MQTT URL: %w", err)
 	}
 
-	// Generate client ID
-	clientID := fmt.Sprintf("byd-hass-%s", deviceID)
+	if clientID == "" {
+		clientID = fmt.Sprintf("byd-hass-%s", deviceID)
+	}
 
 	// Configure MQTT client options
 	opts := mqtt.NewClientOptions()
@@ -58,6 +70,9 @@ func NewClient(mqttURL, deviceID string, logger *logrus.Logger) (*Client, error)
 	}
 
 	opts.AddBroker(brokerURL)
+	// A LAN broker (.local, private IP) resolves through the system
+	// configuration rather than the public DNS chain.
+	opts.SetDialer(netutil.Dialer(parsedURL.Hostname(), 30*time.Second))
 	opts.SetClientID(clientID)
 	opts.SetCleanSession(true)
 	opts.SetAutoReconnect(true)
@@ -87,6 +102,13 @@ func NewClient(mqttURL, deviceID string, logger *logrus.Logger) (*Client, error)
 		logger.Debug("MQTT reconnecting...")
 	})
 
+	c := &Client{
+		deviceID: deviceID,
+		logger:   logger,
+		subs:     make(map[string]mqtt.MessageHandler),
+		guard:    newTopicGuard(),
+	}
+
 	firstConnect := true
 	opts.SetOnConnectHandler(func(client mqtt.Client) {
 		if firstConnect {
@@ -94,15 +116,18 @@ func NewClient(mqttURL, deviceID string, logger *logrus.Logger) (*Client, error)
 			firstConnect = false
 		} else {
 			logger.Info("MQTT reconnected")
+			c.metrics.reconnected()
+			go c.resubscribe()
 		}
 	})
 
 	// Create client
 	client := mqtt.NewClient(opts)
+	c.client = client
 
 	// Connect to broker
 	if token := client.Connect(); token.Wait() && token.Error() != nil {
-		return nil, fmt.Errorf("failed to connect to MQTT broker: %w", token.Error())
+		return nil, fmt.Errorf("failed to connect to MQTT broker: %w", connectError(token))
 	}
 
 	logger.WithFields(logrus.Fields{
@@ -111,15 +136,29 @@ func NewClient(mqttURL, deviceID string, logger *logrus.Logger) (*Client, error)
 		"client_id": clientID,
 	}).Info("MQTT client connected")
 
-	return &Client{
-		client:   client,
-		deviceID: deviceID,
-		logger:   logger,
-	}, nil
+	return c, nil
 }
 
 // Publish publishes a message to the specified topic
 func (c *Client) Publish(topic string, payload []byte, retained bool) error {
+	if err := c.guard.check(topic); err != nil {
+		return err
+	}
+	started := time.Now()
+	err := c.publish(topic, payload, retained)
+	c.metrics.publish(time.Since(started), err)
+	if err != nil {
+		if d := c.guard.failed(topic, err, c.client.IsConnected()); d > 0 {
+			c.logger.WithError(err).WithFields(logrus.Fields{"topic": topic, "retry_in": d}).
+				Error("MQTT publishes keep failing while connected; the broker ACL probably denies this topic. Suspending it")
+		}
+		return err
+	}
+	c.guard.succeeded(topic)
+	return nil
+}
+
+func (c *Client) publish(topic string, payload []byte, retained bool) error {
 	qos := byte(1) // At least once delivery
 	token := c.client.Publish(topic, qos, retained, payload)
 
@@ -154,11 +193,87 @@ func (c *Client) Subscribe(topic string, handler mqtt.MessageHandler) error {
 	if token.Error() != nil {
 		return fmt.Errorf("failed to subscribe to topic %s: %w", topic, token.Error())
 	}
+	if err := subscribeError(token); err != nil {
+		c.guard.deny(topic, err)
+		return err
+	}
+
+	c.subMu.Lock()
+	c.subs[topic] = handler
+	c.subMu.Unlock()
 
 	c.logger.WithField("topic", topic).Debug("Subscribed to MQTT topic")
 	return nil
 }
 
+// OnMessage subscribes to topic and calls fn with each payload. It hides the
+// paho types from callers that only care about the message body.
+func (c *Client) OnMessage(topic string, fn func(payload []byte)) error {
+	return c.Subscribe(topic, func(_ mqtt.Client, msg mqtt.Message) {
+		fn(msg.Payload())
+	})
+}
+
+// Retained returns the retained messages on filter by topic. It subscribes
+// for wait and unsubscribes again; the broker sends retained messages right
+// after subscribing, so a second or two is plenty.
+func (c *Client) Retained(filter string, wait time.Duration) (map[string][]byte, error) {
+	var mu sync.Mutex
+	retained := make(map[string][]byte)
+	token := c.client.Subscribe(filter, 1, func(_ mqtt.Client, msg mqtt.Message) {
+		if !msg.Retained() {
+			return
+		}
+		mu.Lock()
+		retained[msg.Topic()] = append([]byte(nil), msg.Payload()...)
+		mu.Unlock()
+	})
+	const subTimeout = 5 * time.Second
+	if !token.WaitTimeout(subTimeout) {
+		return nil, fmt.Errorf("subscribe to topic %s timed out after %s", filter, subTimeout)
+	}
+	if token.Error() != nil {
+		return nil, fmt.Errorf("failed to subscribe to topic %s: %w", filter, token.Error())
+	}
+
+	time.Sleep(wait)
+	if token := c.client.Unsubscribe(filter); !token.WaitTimeout(subTimeout) || token.Error() != nil {
+		c.logger.WithField("topic", filter).Debug("MQTT unsubscribe failed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return retained, nil
+}
+
+// resubscribe restores all subscriptions after a reconnect.
+func (c *Client) resubscribe() {
+	c.subMu.Lock()
+	subs := make(map[string]mqtt.MessageHandler, len(c.subs))
+	for topic, handler := range c.subs {
+		subs[topic] = handler
+	}
+	c.subMu.Unlock()
+
+	for topic, handler := range subs {
+		if err := c.Subscribe(topic, handler); err != nil {
+			c.logger.WithError(err).WithField("topic", topic).Warn("MQTT resubscribe failed")
+		}
+	}
+}
+
+// Metrics returns the connection health counters. Publishes skipped for a
+// suspended topic aren't counted.
+func (c *Client) Metrics() Metrics {
+	return c.metrics.snapshot()
+}
+
+// Problems describes topics suspended after repeated publish failures and
+// subscriptions the broker refused, for the status report.
+func (c *Client) Problems() []string {
+	return c.guard.problems()
+}
+
 // IsConnected returns true if the client is connected
 func (c *Client) IsConnected() bool {
 	return c.client.IsConnected()
@@ -0,0 +1,52 @@
+package mqtt
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ClientIDFileName is the file below the state directory holding the random
+// client ID suffix, so it survives restarts.
+const ClientIDFileName = "mqtt_client_id"
+
+// DefaultClientID returns "byd-hass-<deviceID>-<suffix>" with a random
+// suffix persisted in stateDir. Two cars left on the same device ID thus
+// don't kick each other off the broker, while one car keeps its client ID
+// (and its broker session) across restarts. Without a state directory the
+// suffix changes on every start.
+func DefaultClientID(deviceID, stateDir string) (string, error) {
+	suffix, err := clientIDSuffix(stateDir)
+	return fmt.Sprintf("byd-hass-%s-%s", deviceID, suffix), err
+}
+
+func clientIDSuffix(stateDir string) (string, error) {
+	var path string
+	if stateDir != "" {
+		path = filepath.Join(stateDir, ClientIDFileName)
+		if raw, err := os.ReadFile(path); err == nil {
+			if suffix := strings.TrimSpace(string(raw)); suffix != "" {
+				return suffix, nil
+			}
+		}
+	}
+
+	buf := make([]byte, 3)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate client ID suffix: %w", err)
+	}
+	suffix := hex.EncodeToString(buf)
+	if path == "" {
+		return suffix, nil
+	}
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		return suffix, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(suffix+"\n"), 0o644); err != nil {
+		return suffix, fmt.Errorf("failed to save client ID suffix: %w", err)
+	}
+	return suffix, nil
+}
@@ -0,0 +1,120 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogHook is a logrus hook that mirrors WARN and more severe log lines to
+// byd_car/<device_id>/log, so an installation can be debugged from the
+// broker. Lines are rate-limited to perMinute; the number of lines dropped
+// since the last published one is reported in its "dropped" field.
+type LogHook struct {
+	client *Client
+	topic  string
+	lines  chan logLine
+
+	mu        sync.Mutex
+	perMinute float64
+	tokens    float64
+	refilled  time.Time
+	dropped   int
+}
+
+type logLine struct {
+	Time    string                 `json:"time"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"msg"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Dropped int                    `json:"dropped,omitempty"`
+}
+
+// NewLogHook creates a hook publishing through client. Call Run to start
+// publishing.
+func NewLogHook(client *Client, perMinute int) *LogHook {
+	return &LogHook{
+		client:    client,
+		topic:     fmt.Sprintf("%s/log", client.GetBaseTopic()),
+		lines:     make(chan logLine, 32),
+		perMinute: float64(perMinute),
+		tokens:    float64(perMinute),
+		refilled:  time.Now(),
+	}
+}
+
+// Levels implements logrus.Hook.
+func (h *LogHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}
+}
+
+// Fire implements logrus.Hook. It never blocks; lines over the rate limit or
+// arriving while the queue is full are counted as dropped.
+func (h *LogHook) Fire(e *logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	h.tokens += now.Sub(h.refilled).Minutes() * h.perMinute
+	if h.tokens > h.perMinute {
+		h.tokens = h.perMinute
+	}
+	h.refilled = now
+	if h.tokens < 1 {
+		h.dropped++
+		return nil
+	}
+
+	line := logLine{
+		Time:    e.Time.UTC().Format(time.RFC3339),
+		Level:   e.Level.String(),
+		Message: e.Message,
+		Dropped: h.dropped,
+	}
+	if len(e.Data) > 0 {
+		line.Fields = make(map[string]interface{}, len(e.Data))
+		for k, v := range e.Data {
+			switch x := v.(type) {
+			case error:
+				line.Fields[k] = x.Error()
+			case string, bool, int, int64, float64:
+				line.Fields[k] = x
+			default:
+				line.Fields[k] = fmt.Sprint(x)
+			}
+		}
+	}
+
+	select {
+	case h.lines <- line:
+		h.tokens--
+		h.dropped = 0
+	default:
+		h.dropped++
+	}
+	return nil
+}
+
+// Run publishes queued lines until ctx is cancelled. Failures are ignored:
+// logging them would feed back into the hook.
+func (h *LogHook) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case line := <-h.lines:
+			if !h.client.IsConnected() {
+				continue
+			}
+			payload, err := json.Marshal(line)
+			if err != nil {
+				continue
+			}
+			_ = h.client.Publish(h.topic, payload, false)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package mqtt
+
+import (
+	"sync"
+	"time"
+)
+
+// qualityWindow is the number of recent publishes LinkQuality looks at.
+const qualityWindow = 20
+
+// Metrics describes the health of the broker connection.
+type Metrics struct {
+	Reconnects          uint64        // since start
+	ConsecutiveFailures int           // failed publishes since the last success
+	LastLatency         time.Duration // of the last successful publish
+	AvgLatency          time.Duration // exponential moving average
+	LinkQuality         int           // % of the last qualityWindow publishes that succeeded
+}
+
+// metrics accumulates Metrics. It is safe for concurrent use.
+type metrics struct {
+	mu       sync.Mutex
+	m        Metrics
+	outcomes [qualityWindow]bool
+	n        int // publishes recorded, capped at qualityWindow
+	next     int // ring position
+}
+
+func (m *metrics) publish(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outcomes[m.next] = err == nil
+	m.next = (m.next + 1) % qualityWindow
+	if m.n < qualityWindow {
+		m.n++
+	}
+	if err != nil {
+		m.m.ConsecutiveFailures++
+		return
+	}
+	m.m.ConsecutiveFailures = 0
+	m.m.LastLatency = d
+	if m.m.AvgLatency == 0 {
+		m.m.AvgLatency = d
+	} else {
+		m.m.AvgLatency = (m.m.AvgLatency*7 + d) / 8
+	}
+}
+
+func (m *metrics) reconnected() {
+	m.mu.Lock()
+	m.m.Reconnects++
+	m.mu.Unlock()
+}
+
+func (m *metrics) snapshot() Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := m.m
+	out.LinkQuality = 100
+	if m.n > 0 {
+		ok := 0
+		for i := 0; i < m.n; i++ {
+			if m.outcomes[i] {
+				ok++
+			}
+		}
+		out.LinkQuality = ok * 100 / m.n
+	}
+	return out
+}
@@ -0,0 +1,218 @@
+// Package netutil configures name resolution. Termux has no usable
+// /etc/resolv.conf, so Go's built-in resolver needs explicit DNS servers,
+// while LAN names (the MQTT broker, Home Assistant) must keep using the
+// system configuration.
+package netutil
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// System is the DNS chain entry for the servers of the system configuration.
+const System = "system"
+
+// DefaultDNS is the resolver chain used when none is configured: the system
+// servers first, then two public resolvers for phones without a resolv.conf.
+const DefaultDNS = "system,1.1.1.1,8.8.8.8"
+
+// ParseDNS parses a comma-separated resolver chain of "system" and
+// host[:port] entries (port 53 by default).
+func ParseDNS(spec string) ([]string, error) {
+	var servers []string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+			continue
+		case entry == System:
+			servers = append(servers, System)
+			continue
+		}
+		host, port := entry, "53"
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			host, port = h, p
+		}
+		if net.ParseIP(host) == nil {
+			return nil, fmt.Errorf("DNS server %q must be an IP address", entry)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return nil, fmt.Errorf("DNS server %q has an invalid port", entry)
+		}
+		servers = append(servers, net.JoinHostPort(host, port))
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("DNS resolver chain is empty")
+	}
+	return servers, nil
+}
+
+// systemResolver ignores the custom chain, so it uses the system
+// configuration even after InstallResolver.
+var systemResolver = &net.Resolver{}
+
+// cgnat is 100.64.0.0/10, the range Tailscale assigns to its nodes.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0).To4(), Mask: net.CIDRMask(10, 32)}
+
+// IsPrivateHost reports whether host is a LAN or VPN address or a name that
+// public resolvers can't answer: private, loopback and Tailscale (CGNAT)
+// IPs, single-label names and names under .local, .lan, .home, .home.arpa,
+// .internal, .localdomain or .ts.net (Tailscale MagicDNS).
+func IsPrivateHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || cgnat.Contains(ip)
+	}
+	if !strings.Contains(host, ".") {
+		return true
+	}
+	for _, suffix := range []string{".local", ".lan", ".home", ".home.arpa", ".internal", ".localdomain", ".ts.net"} {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Dialer returns a dialer for connections to host: private hosts resolve
+// through the system configuration, everything else through the default
+// resolver (the installed chain).
+func Dialer(host string, timeout time.Duration) *net.Dialer {
+	d := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	if IsPrivateHost(host) {
+		d.Resolver = systemResolver
+	}
+	return d
+}
+
+// DialContext dials addr like net.Dialer.DialContext, picking the resolver
+// per host as Dialer does.
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return Dialer(host, 30*time.Second).DialContext(ctx, network, addr)
+}
+
+// InstallResolver makes the resolver chain the process-wide default. Lookups
+// go to one server at a time; when it fails or times out, later lookups move
+// on to the next server in the chain (wrapping around). Successful answers
+// are cached and served when a lookup fails, so a brief DNS outage doesn't
+// break connections to hosts that were resolved before.
+func InstallResolver(servers []string, logger *logrus.Logger) {
+	c := &chain{servers: servers, cache: newDNSCache(), logger: logger}
+	net.DefaultResolver = &net.Resolver{PreferGo: true, Dial: c.dial}
+	if tr, ok := http.DefaultTransport.(*http.Transport); ok {
+		tr.DialContext = DialContext
+	}
+	logger.WithField("servers", strings.Join(servers, ",")).Debug("Custom DNS resolver installed")
+}
+
+type chain struct {
+	servers []string
+	current uint32 // index into servers
+	cache   *dnsCache
+	mu      sync.Mutex
+	logger  *logrus.Logger
+}
+
+func (c *chain) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	i := atomic.LoadUint32(&c.current)
+	server := c.servers[i]
+	if server == System {
+		server = address // chosen by Go from the system configuration
+	}
+	d := net.Dialer{Timeout: time.Second}
+	conn, err := d.DialContext(ctx, network, server)
+	if err != nil {
+		c.fail(i, err)
+		return nil, err
+	}
+	onErr := func(err error) { c.fail(i, err) }
+	if udp, ok := conn.(*net.UDPConn); ok {
+		// Keep the net.PacketConn methods so Go uses datagram framing.
+		return &packetConn{UDPConn: udp, cache: c.cache, onErr: onErr}, nil
+	}
+	return &streamConn{Conn: conn, onErr: onErr}, nil
+}
+
+// fail moves the chain past server i unless another lookup already did.
+func (c *chain) fail(i uint32, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if atomic.LoadUint32(&c.current) != i {
+		return
+	}
+	next := (i + 1) % uint32(len(c.servers))
+	atomic.StoreUint32(&c.current, next)
+	if len(c.servers) > 1 {
+		c.logger.WithError(err).WithFields(logrus.Fields{
+			"failed": c.servers[i],
+			"next":   c.servers[next],
+		}).Debug("DNS server failed, switching to the next one")
+	}
+}
+
+// DNS response codes that mean the server failed rather than that the name
+// doesn't exist.
+const (
+	rcodeServFail = 2
+	rcodeRefused  = 5
+)
+
+// packetConn carries one UDP query/response exchange. It records the query
+// so the response can be cached, and answers from the cache when the server
+// doesn't.
+type packetConn struct {
+	*net.UDPConn
+	cache *dnsCache
+	query []byte
+	onErr func(error)
+}
+
+func (c *packetConn) Write(b []byte) (int, error) {
+	c.query = append(c.query[:0], b...)
+	return c.UDPConn.Write(b)
+}
+
+func (c *packetConn) Read(b []byte) (int, error) {
+	n, err := c.UDPConn.Read(b)
+	if err != nil {
+		c.onErr(err)
+		if msg, ok := c.cache.lookup(c.query); ok && len(msg) <= len(b) {
+			return copy(b, msg), nil
+		}
+		return n, err
+	}
+	if n >= 4 && (b[3]&0x0f == rcodeServFail || b[3]&0x0f == rcodeRefused) {
+		// The server answered but couldn't resolve; a stale answer beats none.
+		if msg, ok := c.cache.lookup(c.query); ok && len(msg) <= len(b) {
+			return copy(b, msg), nil
+		}
+	}
+	c.cache.store(c.query, b[:n])
+	return n, nil
+}
+
+type streamConn struct {
+	net.Conn
+	onErr func(error)
+}
+
+func (c *streamConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if err != nil {
+		c.onErr(err)
+	}
+	return n, err
+}
@@ -0,0 +1,81 @@
+package netutil
+
+import (
+	"sync"
+	"time"
+)
+
+// dnsCacheMaxAge bounds how stale a cached answer may be when it is reused.
+// It is deliberately generous: an old address for the ABRP or broker host is
+// far more useful than a failed transmit while cellular DNS is down.
+const dnsCacheMaxAge = 24 * time.Hour
+
+// dnsCacheMaxEntries caps the cache; the app only talks to a handful of hosts.
+const dnsCacheMaxEntries = 256
+
+type cachedAnswer struct {
+	msg    []byte
+	stored time.Time
+}
+
+// dnsCache keeps the last successful answer per DNS question. Answers are only
+// served when a fresh lookup fails, so normal lookups always see current
+// records.
+type dnsCache struct {
+	mu      sync.Mutex
+	answers map[string]cachedAnswer
+}
+
+func newDNSCache() *dnsCache {
+	return &dnsCache{answers: make(map[string]cachedAnswer)}
+}
+
+// questionKey identifies a query by everything but its 2-byte message ID.
+func questionKey(query []byte) (string, bool) {
+	if len(query) < 12 {
+		return "", false
+	}
+	return string(query[2:]), true
+}
+
+// store remembers a successful (NOERROR) response to query.
+func (c *dnsCache) store(query, resp []byte) {
+	key, ok := questionKey(query)
+	if !ok || len(resp) < 12 || resp[3]&0x0f != 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.answers[key]; !exists && len(c.answers) >= dnsCacheMaxEntries {
+		c.evictOldest()
+	}
+	c.answers[key] = cachedAnswer{msg: append([]byte(nil), resp...), stored: time.Now()}
+}
+
+// lookup returns the cached response to query with its ID rewritten to match.
+func (c *dnsCache) lookup(query []byte) ([]byte, bool) {
+	key, ok := questionKey(query)
+	if !ok {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a, ok := c.answers[key]
+	if !ok || time.Since(a.stored) > dnsCacheMaxAge {
+		return nil, false
+	}
+	msg := append([]byte(nil), a.msg...)
+	copy(msg[:2], query[:2])
+	return msg, true
+}
+
+func (c *dnsCache) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for k, a := range c.answers {
+		if oldest.IsZero() || a.stored.Before(oldest) {
+			oldestKey, oldest = k, a.stored
+		}
+	}
+	delete(c.answers, oldestKey)
+}
@@ -0,0 +1,133 @@
+package netutil
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultInternetProbe is dialled to decide whether the internet is
+// reachable. It is an IP address so the check doesn't depend on DNS.
+const DefaultInternetProbe = "1.1.1.1:443"
+
+const probeTimeout = 5 * time.Second
+
+// Prober tracks two independent paths: the LAN (home network, often reached
+// through WireGuard or Tailscale) and the internet. Either may be up while
+// the other is down, e.g. MQTT over Tailscale keeps working while ABRP is
+// unreachable. A path without a probe target counts as up.
+type Prober struct {
+	lanTarget      string
+	internetTarget string
+	lan            uint32 // 1 = reachable
+	internet       uint32
+	onChange       func(lan, internet bool)
+	logger         *logrus.Logger
+}
+
+// NewProber creates a Prober dialling the given host:port targets. onChange,
+// if set, is called after the first probe and whenever a path changes.
+func NewProber(lanTarget, internetTarget string, onChange func(lan, internet bool), logger *logrus.Logger) *Prober {
+	return &Prober{
+		lanTarget:      lanTarget,
+		internetTarget: internetTarget,
+		lan:            1,
+		internet:       1,
+		onChange:       onChange,
+		logger:         logger,
+	}
+}
+
+// LANUp reports whether the LAN target answered the last probe.
+func (p *Prober) LANUp() bool { return p == nil || atomic.LoadUint32(&p.lan) == 1 }
+
+// InternetUp reports whether the internet target answered the last probe.
+func (p *Prober) InternetUp() bool { return p == nil || atomic.LoadUint32(&p.internet) == 1 }
+
+// Reachable reports whether the path to host is up: private hosts (see
+// IsPrivateHost) need the LAN, everything else the internet.
+func (p *Prober) Reachable(host string) bool {
+	if IsPrivateHost(host) {
+		return p.LANUp()
+	}
+	return p.InternetUp()
+}
+
+// Run probes both paths immediately and then every interval until ctx is
+// cancelled.
+func (p *Prober) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	first := true
+	for {
+		lanChanged := p.probe(ctx, p.lanTarget, &p.lan, "LAN")
+		internetChanged := p.probe(ctx, p.internetTarget, &p.internet, "internet")
+		if p.onChange != nil && (first || lanChanged || internetChanged) {
+			p.onChange(p.LANUp(), p.InternetUp())
+		}
+		first = false
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe dials target and stores the result in state, reporting whether it
+// changed.
+func (p *Prober) probe(ctx context.Context, target string, state *uint32, path string) bool {
+	if target == "" {
+		return false
+	}
+	host, _, _ := net.SplitHostPort(target)
+	dctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	conn, err := Dialer(host, probeTimeout).DialContext(dctx, "tcp", target)
+	cancel()
+	up := uint32(0)
+	if err == nil {
+		conn.Close()
+		up = 1
+	}
+	if atomic.SwapUint32(state, up) == up {
+		return false
+	}
+	entry := p.logger.WithFields(logrus.Fields{"path": path, "target": target})
+	if up == 1 {
+		entry.Info("Connectivity restored")
+	} else {
+		entry.WithError(err).Warn("Connectivity lost")
+	}
+	return true
+}
+
+// HostOf returns the host of a URL, or "" if it has none.
+func HostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// ProbeTargetFor returns host:port for a URL, filling in the scheme's default
+// port, or "" when the URL has no host.
+func ProbeTargetFor(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	if u.Port() != "" {
+		return u.Host
+	}
+	port := map[string]string{"http": "80", "ws": "80", "https": "443", "wss": "443", "mqtt": "1883", "mqtts": "8883"}[u.Scheme]
+	if port == "" {
+		return ""
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
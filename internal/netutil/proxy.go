@@ -0,0 +1,139 @@
+package netutil
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// proxyFunc picks the proxy for outgoing HTTP requests. Until SetProxy is
+// called the standard HTTP(S)_PROXY / NO_PROXY environment variables apply.
+var proxyFunc = http.ProxyFromEnvironment
+
+// Proxy is an http.Transport Proxy function honoring the configured proxy.
+// Transports built by transmitters should use it instead of
+// http.ProxyFromEnvironment.
+func Proxy(req *http.Request) (*url.URL, error) {
+	return proxyFunc(req)
+}
+
+// SetProxy routes HTTP requests through proxyURL (http, https, socks5 or
+// socks5h) except for destinations matching noProxy, a comma-separated list
+// of host names (matching subdomains too), ".suffix" patterns, IPs, CIDRs or
+// "*". An empty proxyURL keeps the environment settings.
+func SetProxy(proxyURL, noProxy string) error {
+	if proxyURL == "" {
+		return nil
+	}
+	u, err := ParseProxy(proxyURL)
+	if err != nil {
+		return err
+	}
+	bypass, err := parseBypass(noProxy)
+	if err != nil {
+		return err
+	}
+	proxyFunc = func(req *http.Request) (*url.URL, error) {
+		// Local services such as Diplus never go through the proxy.
+		if host := req.URL.Hostname(); isLoopback(host) || bypass.matches(host) {
+			return nil, nil
+		}
+		return u, nil
+	}
+	if tr, ok := http.DefaultTransport.(*http.Transport); ok {
+		tr.Proxy = Proxy
+	}
+	return nil
+}
+
+// ParseProxy validates a proxy URL.
+func ParseProxy(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (supported: http, https, socks5, socks5h)", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", raw)
+	}
+	return u, nil
+}
+
+// ValidateBypass checks a no-proxy list without applying it.
+func ValidateBypass(noProxy string) error {
+	_, err := parseBypass(noProxy)
+	return err
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+type bypassRules struct {
+	all      bool
+	suffixes []string // ".example.com" or "example.com" (also matches subdomains)
+	nets     []*net.IPNet
+}
+
+func parseBypass(spec string) (*bypassRules, error) {
+	rules := &bypassRules{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+		case entry == "*":
+			rules.all = true
+		case strings.Contains(entry, "/"):
+			_, ipnet, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid no-proxy CIDR %q", entry)
+			}
+			rules.nets = append(rules.nets, ipnet)
+		case net.ParseIP(entry) != nil:
+			ip := net.ParseIP(entry)
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			rules.nets = append(rules.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		default:
+			rules.suffixes = append(rules.suffixes, entry)
+		}
+	}
+	return rules, nil
+}
+
+func (r *bypassRules) matches(host string) bool {
+	if r.all {
+		return true
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if ip := net.ParseIP(host); ip != nil {
+		for _, n := range r.nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, s := range r.suffixes {
+		if strings.HasPrefix(s, ".") {
+			if strings.HasSuffix(host, s) {
+				return true
+			}
+		} else if host == s || strings.HasSuffix(host, "."+s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Pusher sends notifications to a push service so alerts reach users who
+// don't run Home Assistant. A nil Pusher sends nothing.
+//
+// Supported URLs:
+//
+//	ntfy://[token@]host/topic           ntfy (ntfy+http:// for plain HTTP)
+//	gotify://host/?token=APP_TOKEN      Gotify (gotify+http:// for plain HTTP)
+//	telegram://BOT_TOKEN@CHAT_ID        Telegram bot API
+type Pusher struct {
+	service string // "ntfy", "gotify" or "telegram"
+	url     string // request URL
+	token   string // ntfy access token or Telegram chat ID
+	client  *http.Client
+}
+
+// NewPusher parses a push service URL (see Pusher).
+func NewPusher(rawURL string) (*Pusher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notify URL: %w", err)
+	}
+	p := &Pusher{client: &http.Client{Timeout: 10 * time.Second}}
+
+	service, plain := strings.CutSuffix(u.Scheme, "+http")
+	scheme := "https"
+	if plain {
+		scheme = "http"
+	}
+
+	switch service {
+	case "ntfy":
+		topic := strings.Trim(u.Path, "/")
+		if u.Host == "" || topic == "" {
+			return nil, fmt.Errorf("ntfy URL needs a host and topic (ntfy://host/topic)")
+		}
+		p.url = fmt.Sprintf("%s://%s/%s", scheme, u.Host, topic)
+		if u.User != nil {
+			p.token = u.User.Username()
+		}
+	case "gotify":
+		token := u.Query().Get("token")
+		if u.Host == "" || token == "" {
+			return nil, fmt.Errorf("gotify URL needs a host and app token (gotify://host/?token=...)")
+		}
+		p.url = fmt.Sprintf("%s://%s%s/message?token=%s", scheme, u.Host, strings.TrimSuffix(u.Path, "/"), url.QueryEscape(token))
+	case "telegram":
+		if plain {
+			return nil, fmt.Errorf("telegram notifications only support HTTPS")
+		}
+		if u.User == nil || u.Host == "" {
+			return nil, fmt.Errorf("telegram URL needs a bot token and chat ID (telegram://BOT_TOKEN@CHAT_ID)")
+		}
+		p.url = fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", u.User.String())
+		p.token = u.Host
+	default:
+		return nil, fmt.Errorf("unsupported notify scheme %q (supported: ntfy, gotify, telegram)", u.Scheme)
+	}
+	p.service = service
+	return p, nil
+}
+
+// String names the service without credentials.
+func (p *Pusher) String() string {
+	if p == nil {
+		return "none"
+	}
+	return p.service
+}
+
+// Send delivers a notification. priority is one of the Priority* levels.
+func (p *Pusher) Send(ctx context.Context, title, content, priority string) error {
+	if p == nil {
+		return nil
+	}
+
+	var req *http.Request
+	var err error
+	switch p.service {
+	case "ntfy":
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, p.url, strings.NewReader(content))
+		if err == nil {
+			req.Header.Set("Title", title)
+			if prio, ok := map[string]string{PriorityHigh: "4", PriorityMax: "5"}[priority]; ok {
+				req.Header.Set("Priority", prio)
+			}
+			if p.token != "" {
+				req.Header.Set("Authorization", "Bearer "+p.token)
+			}
+		}
+	case "gotify":
+		prio := map[string]int{PriorityDefault: 5, PriorityHigh: 8, PriorityMax: 10}[priority]
+		req, err = jsonRequest(ctx, p.url, map[string]interface{}{"title": title, "message": content, "priority": prio})
+	case "telegram":
+		req, err = jsonRequest(ctx, p.url, map[string]interface{}{"chat_id": p.token, "text": title + "\n" + content})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to build %s request: %w", p.service, err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		// Drop the URL from the error; it carries the token.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%s request failed: %w", p.service, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("%s returned %s: %s", p.service, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func jsonRequest(ctx context.Context, target string, body interface{}) (*http.Request, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// Priority levels understood by termux-notification.
+const (
+	PriorityDefault = "default"
+	PriorityHigh    = "high"
+	PriorityMax     = "max"
+)
+
+// Termux shows an Android notification via Termux:API's termux-notification.
+// id groups notifications so repeated alerts replace each other.
+func Termux(ctx context.Context, id, title, content, priority string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	args := []string{
+		"--id", id,
+		"--title", title,
+		"--content", content,
+		"--priority", priority,
+	}
+	if priority == PriorityHigh || priority == PriorityMax {
+		args = append(args, "--sound", "--vibrate", "500,200,500")
+	}
+
+	if out, err := exec.CommandContext(ctx, "termux-notification", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("termux-notification failed: %w (%s)", err, out)
+	}
+	return nil
+}
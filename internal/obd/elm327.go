@@ -0,0 +1,167 @@
+// Package obd reads vehicle data through an ELM327 OBD-II adapter, as a
+// fallback for head units without a working Diplus install.
+package obd
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// commandTimeout bounds a single ELM327 command, including the CAN round
+// trip to the ECU.
+const commandTimeout = 3 * time.Second
+
+// initCommands reset the adapter and switch to terse CAN 11-bit/500 kbit
+// output: no echo, no linefeeds, no spaces, no headers.
+var initCommands = []string{"ATZ", "ATE0", "ATL0", "ATS0", "ATH0", "ATSP6"}
+
+// elm327 is a connection to an ELM327 adapter. Bluetooth dongles are used
+// through their RFCOMM serial device (e.g. /dev/rfcomm0), WiFi dongles
+// through "tcp://host:port".
+type elm327 struct {
+	addr string
+
+	mu     sync.Mutex
+	conn   io.ReadWriteCloser
+	reader *bufio.Reader
+	header string // current ATSH header
+}
+
+func newELM327(addr string) *elm327 {
+	return &elm327{addr: addr}
+}
+
+// ensureOpen connects and initialises the adapter if needed. Callers hold mu.
+func (e *elm327) ensureOpen() error {
+	if e.conn != nil {
+		return nil
+	}
+
+	var conn io.ReadWriteCloser
+	var err error
+	if host, ok := strings.CutPrefix(e.addr, "tcp://"); ok {
+		conn, err = net.DialTimeout("tcp", host, commandTimeout)
+	} else {
+		conn, err = os.OpenFile(e.addr, os.O_RDWR, 0)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open ELM327 at %s: %w", e.addr, err)
+	}
+	e.conn, e.reader, e.header = conn, bufio.NewReader(conn), ""
+
+	for _, cmd := range initCommands {
+		if _, err := e.command(cmd); err != nil {
+			e.closeLocked()
+			return fmt.Errorf("failed to initialise ELM327 (%s): %w", cmd, err)
+		}
+	}
+	return nil
+}
+
+// query sends an OBD request (hex, e.g. "010D") to header and returns the
+// response payload after the service and PID echo.
+func (e *elm327) query(header, request string) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.ensureOpen(); err != nil {
+		return nil, err
+	}
+	if header != e.header {
+		if _, err := e.command("ATSH" + header); err != nil {
+			e.closeLocked()
+			return nil, err
+		}
+		e.header = header
+	}
+	resp, err := e.command(request)
+	if err != nil {
+		e.closeLocked()
+		return nil, err
+	}
+	return parseResponse(request, resp)
+}
+
+// command writes cmd and reads up to the ">" prompt. Callers hold mu.
+func (e *elm327) command(cmd string) (string, error) {
+	if d, ok := e.conn.(interface{ SetDeadline(time.Time) error }); ok {
+		// Character devices without poll support return ErrNoDeadline;
+		// the adapter's own timeout still ends the read there.
+		if err := d.SetDeadline(time.Now().Add(commandTimeout)); err != nil && !errors.Is(err, os.ErrNoDeadline) {
+			return "", err
+		}
+	}
+	if _, err := io.WriteString(e.conn, cmd+"\r"); err != nil {
+		return "", fmt.Errorf("ELM327 write failed: %w", err)
+	}
+	resp, err := e.reader.ReadString('>')
+	if err != nil {
+		return "", fmt.Errorf("ELM327 read failed: %w", err)
+	}
+	return strings.TrimSpace(strings.TrimSuffix(resp, ">")), nil
+}
+
+// Close closes the adapter connection; the next query reconnects.
+func (e *elm327) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.closeLocked()
+}
+
+func (e *elm327) closeLocked() error {
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn, e.reader = nil, nil
+	return err
+}
+
+// parseResponse extracts the data bytes of the positive response to request
+// from the adapter output. Multi-frame (ISO-TP) responses, printed as
+// "0:…", "1:…" lines after a length line, are joined first.
+func parseResponse(request, resp string) ([]byte, error) {
+	var hexData strings.Builder
+	for _, line := range strings.FieldsFunc(resp, func(r rune) bool { return r == '\r' || r == '\n' }) {
+		line = strings.ReplaceAll(strings.TrimSpace(line), " ", "")
+		switch upper := strings.ToUpper(line); {
+		case upper == "" || upper == "SEARCHING...":
+			continue
+		case strings.Contains(upper, "NODATA"), strings.Contains(upper, "ERROR"),
+			strings.Contains(upper, "UNABLE"), upper == "?", upper == "STOPPED":
+			return nil, fmt.Errorf("ELM327: %s", line)
+		}
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			line = line[i+1:]
+		} else if len(line) == 3 {
+			continue // ISO-TP length line
+		}
+		hexData.WriteString(line)
+	}
+
+	data, err := hex.DecodeString(hexData.String())
+	if err != nil {
+		return nil, fmt.Errorf("ELM327: invalid response %q: %w", resp, err)
+	}
+	req, err := hex.DecodeString(request)
+	if err != nil || len(req) == 0 {
+		return nil, fmt.Errorf("ELM327: invalid request %q", request)
+	}
+
+	// A positive response echoes the service + 0x40 and the PID bytes.
+	echo := append([]byte{req[0] + 0x40}, req[1:]...)
+	for i := 0; i+len(echo) <= len(data); i++ {
+		if string(data[i:i+len(echo)]) == string(echo) {
+			return data[i+len(echo):], nil
+		}
+	}
+	return nil, fmt.Errorf("ELM327: no response to %s in %q", request, resp)
+}
@@ -0,0 +1,96 @@
+package obd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jkaberg/byd-hass/internal/formula"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// PID maps one OBD request to a sensor. The formula sees the response data
+// bytes as A, B, C, … and yields the value in the sensor's published unit.
+type PID struct {
+	Key     string // sensor state key, e.g. "battery_percentage"
+	Header  string // CAN request header, e.g. "7DF" (broadcast) or "7E7"
+	Request string // service + PID in hex, e.g. "010D"
+	Expr    *formula.Expr
+	id      int
+}
+
+// DefaultPIDs reads SOC, speed and odometer through standard OBD-II PIDs
+// (hybrid/EV battery pack remaining life, vehicle speed, odometer). BYD
+// models differ in which of them they answer; manufacturer PIDs (service 22
+// on the battery ECU) can be configured instead with -elm327-pids.
+const DefaultPIDs = "battery_percentage=7DF:015B:A*100/255;" +
+	"speed=7DF:010D:A;" +
+	"mileage=7DF:01A6:(A*16777216+B*65536+C*256+D)/10"
+
+var (
+	hexPattern = regexp.MustCompile(`^([0-9A-F]{2})+$`)
+	headerRe   = regexp.MustCompile(`^[0-9A-F]{3}([0-9A-F]{5})?$`)
+	byteVar    = regexp.MustCompile(`^[A-Z]$`)
+)
+
+// ParsePIDs parses "key=header:request:formula" entries separated by ';',
+// e.g. "speed=7DF:010D:A". Keys are sensor state keys.
+func ParsePIDs(spec string) ([]PID, error) {
+	var pids []PID
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, rest, ok := strings.Cut(entry, "=")
+		parts := strings.SplitN(rest, ":", 3)
+		if !ok || len(parts) != 3 {
+			return nil, fmt.Errorf("OBD PID %q: expected key=header:request:formula", entry)
+		}
+		key = strings.TrimSpace(key)
+		def := sensors.GetSensorByKey(key)
+		if def == nil {
+			return nil, fmt.Errorf("OBD PID %q: unknown sensor", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("OBD PID %q: defined more than once", key)
+		}
+
+		header := strings.ToUpper(strings.TrimSpace(parts[0]))
+		request := strings.ToUpper(strings.TrimSpace(parts[1]))
+		if !headerRe.MatchString(header) {
+			return nil, fmt.Errorf("OBD PID %q: header %q must be 3 or 8 hex digits", key, parts[0])
+		}
+		if !hexPattern.MatchString(request) {
+			return nil, fmt.Errorf("OBD PID %q: request %q must be hex bytes", key, parts[1])
+		}
+		expr, err := formula.Parse(strings.TrimSpace(parts[2]))
+		if err != nil {
+			return nil, fmt.Errorf("OBD PID %q: %w", key, err)
+		}
+		for _, v := range expr.Vars() {
+			if !byteVar.MatchString(v) {
+				return nil, fmt.Errorf("OBD PID %q: %q is not a response byte (A, B, C, …)", key, v)
+			}
+		}
+
+		seen[key] = true
+		pids = append(pids, PID{Key: key, Header: header, Request: request, Expr: expr, id: def.ID})
+	}
+	if len(pids) == 0 {
+		return nil, fmt.Errorf("no OBD PIDs configured")
+	}
+	return pids, nil
+}
+
+// decode evaluates the PID formula over the response bytes.
+func (p PID) decode(data []byte) (float64, bool) {
+	return p.Expr.Eval(func(name string) (float64, bool) {
+		i := int(name[0] - 'A')
+		if i >= len(data) {
+			return 0, false
+		}
+		return float64(data[i]), true
+	})
+}
@@ -0,0 +1,68 @@
+package obd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/api"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+// Source polls the configured PIDs through an ELM327 adapter. It is a
+// fast-tier only api.SensorSource, normally combined after Diplus so it
+// fills in whatever Diplus doesn't report.
+type Source struct {
+	elm    *elm327
+	pids   []PID
+	logger *logrus.Logger
+}
+
+var _ api.SensorSource = (*Source)(nil)
+
+// NewSource creates a Source for the adapter at addr (an RFCOMM device such
+// as /dev/rfcomm0, or tcp://host:port) reading pids. The adapter is opened
+// on the first poll and reopened after errors.
+func NewSource(addr string, pids []PID, logger *logrus.Logger) *Source {
+	return &Source{elm: newELM327(addr), pids: pids, logger: logger}
+}
+
+// PollFast reads every PID. It fails only when none could be read.
+func (s *Source) PollFast(ctx context.Context) (*sensors.SensorData, error) {
+	data := &sensors.SensorData{Timestamp: time.Now()}
+	var lastErr error
+	read := 0
+	for _, p := range s.pids {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		resp, err := s.elm.query(p.Header, p.Request)
+		if err != nil {
+			lastErr = err
+			s.logger.WithError(err).WithField("pid", p.Key).Debug("OBD read failed")
+			continue
+		}
+		v, ok := p.decode(resp)
+		if !ok {
+			lastErr = fmt.Errorf("OBD %s: response too short (%d bytes)", p.Key, len(resp))
+			continue
+		}
+		data.SetByID(p.id, v)
+		read++
+	}
+	if read == 0 {
+		return nil, fmt.Errorf("no OBD PIDs could be read: %w", lastErr)
+	}
+	return data, nil
+}
+
+// PollSlow returns nil; all PIDs are on the fast tier.
+func (s *Source) PollSlow(ctx context.Context) (*sensors.SensorData, error) {
+	return nil, nil
+}
+
+// Close closes the adapter connection.
+func (s *Source) Close() error {
+	return s.elm.Close()
+}
@@ -0,0 +1,275 @@
+// Package payload encodes state payloads as JSON or, for metered links, as
+// MessagePack or CBOR. Both binary formats carry the same structure as the
+// JSON document; integral numbers are sent as integers, which together with
+// the binary framing roughly halves the size of a typical state payload.
+package payload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Supported encodings.
+const (
+	JSON    = "json"
+	MsgPack = "msgpack"
+	CBOR    = "cbor"
+)
+
+// Valid reports whether format is a supported encoding.
+func Valid(format string) bool {
+	return format == JSON || format == MsgPack || format == CBOR
+}
+
+// Encode serialises v in format. v may be anything encoding/json accepts;
+// for the binary formats it is first normalised through JSON so struct tags
+// and omitempty behave the same in every encoding. Map keys are sorted, so
+// equal values always encode to equal bytes.
+func Encode(format string, v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if format == JSON {
+		return raw, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case MsgPack:
+		err = encodeMsgPack(&buf, generic)
+	case CBOR:
+		err = encodeCBOR(&buf, generic)
+	default:
+		return nil, fmt.Errorf("unknown payload encoding %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// number splits a JSON number into an integer when it has no fractional
+// part and fits exactly, or a float otherwise.
+func number(n json.Number) (i int64, f float64, isInt bool, err error) {
+	if i, err := n.Int64(); err == nil {
+		return i, 0, true, nil
+	}
+	f, err = n.Float64()
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("invalid number %q: %w", n, err)
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f), 0, true, nil
+	}
+	return 0, f, false, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// -----------------------------------------------------------------------------
+// MessagePack (https://github.com/msgpack/msgpack/blob/master/spec.md)
+// -----------------------------------------------------------------------------
+
+func encodeMsgPack(buf *bytes.Buffer, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if x {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		i, f, isInt, err := number(x)
+		if err != nil {
+			return err
+		}
+		if isInt {
+			msgPackInt(buf, i)
+		} else {
+			buf.WriteByte(0xcb)
+			_ = binary.Write(buf, binary.BigEndian, f)
+		}
+	case string:
+		n := len(x)
+		switch {
+		case n < 32:
+			buf.WriteByte(0xa0 | byte(n))
+		case n <= math.MaxUint8:
+			buf.WriteByte(0xd9)
+			buf.WriteByte(byte(n))
+		case n <= math.MaxUint16:
+			buf.WriteByte(0xda)
+			_ = binary.Write(buf, binary.BigEndian, uint16(n))
+		default:
+			buf.WriteByte(0xdb)
+			_ = binary.Write(buf, binary.BigEndian, uint32(n))
+		}
+		buf.WriteString(x)
+	case []interface{}:
+		msgPackLen(buf, len(x), 0x90, 0xdc, 0xdd)
+		for _, e := range x {
+			if err := encodeMsgPack(buf, e); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		msgPackLen(buf, len(x), 0x80, 0xde, 0xdf)
+		for _, k := range sortedKeys(x) {
+			if err := encodeMsgPack(buf, k); err != nil {
+				return err
+			}
+			if err := encodeMsgPack(buf, x[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+	return nil
+}
+
+func msgPackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= 127:
+		buf.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= 0 && i <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		_ = binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= 0 && i <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		_ = binary.Write(buf, binary.BigEndian, uint16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		_ = binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		_ = binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+// msgPackLen writes an array/map header: the fix form for fewer than 16
+// entries, otherwise the 16- or 32-bit form.
+func msgPackLen(buf *bytes.Buffer, n int, fix, b16, b32 byte) {
+	switch {
+	case n < 16:
+		buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(b16)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(b32)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+// -----------------------------------------------------------------------------
+// CBOR (RFC 8949)
+// -----------------------------------------------------------------------------
+
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+)
+
+func encodeCBOR(buf *bytes.Buffer, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteByte(0xf6)
+	case bool:
+		if x {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+	case json.Number:
+		i, f, isInt, err := number(x)
+		if err != nil {
+			return err
+		}
+		switch {
+		case isInt && i >= 0:
+			cborHead(buf, cborUint, uint64(i))
+		case isInt:
+			cborHead(buf, cborNegInt, uint64(-1-i))
+		default:
+			buf.WriteByte(0xfb)
+			_ = binary.Write(buf, binary.BigEndian, f)
+		}
+	case string:
+		cborHead(buf, cborText, uint64(len(x)))
+		buf.WriteString(x)
+	case []interface{}:
+		cborHead(buf, cborArray, uint64(len(x)))
+		for _, e := range x {
+			if err := encodeCBOR(buf, e); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		cborHead(buf, cborMap, uint64(len(x)))
+		for _, k := range sortedKeys(x) {
+			cborHead(buf, cborText, uint64(len(k)))
+			buf.WriteString(k)
+			if err := encodeCBOR(buf, x[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cbor: unsupported type %T", v)
+	}
+	return nil
+}
+
+// cborHead writes the initial byte(s) of a data item with the shortest
+// argument encoding.
+func cborHead(buf *bytes.Buffer, major byte, n uint64) {
+	m := major << 5
+	switch {
+	case n < 24:
+		buf.WriteByte(m | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(m | 24)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(m | 25)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= math.MaxUint32:
+		buf.WriteByte(m | 26)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(m | 27)
+		_ = binary.Write(buf, binary.BigEndian, n)
+	}
+}
@@ -0,0 +1,92 @@
+// Package pidfile keeps a second byd-hass from running next to the first,
+// e.g. one started by the boot script and one by hand, which would fight
+// over the MQTT client ID and double the ABRP telemetry.
+//
+// A PID file is used rather than flock because the state directory usually
+// lives on Android's shared storage, which doesn't reliably support locks.
+package pidfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// FileName is the PID file below the state directory.
+const FileName = "byd-hass.pid"
+
+// RunningError reports the instance holding the PID file.
+type RunningError struct {
+	PID int
+}
+
+func (e *RunningError) Error() string {
+	return fmt.Sprintf("another byd-hass instance is running (pid %d)", e.PID)
+}
+
+// Acquire writes this process's PID to path. It fails with a *RunningError
+// while the PID in an existing file belongs to a live byd-hass process; a
+// stale file is replaced. Call the returned function on shutdown.
+func Acquire(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write PID file: %w", err)
+			}
+			return func() { release(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create PID file: %w", err)
+		}
+
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PID file: %w", err)
+		}
+		pid, _ := strconv.Atoi(strings.TrimSpace(string(raw)))
+		// The same PID is this process after an exec (e.g. an update rollback).
+		if pid > 0 && pid != os.Getpid() && running(pid) {
+			return nil, &RunningError{PID: pid}
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove stale PID file: %w", err)
+		}
+	}
+	return nil, fmt.Errorf("failed to create PID file %s: created concurrently", path)
+}
+
+// release removes the PID file if it still names this process.
+func release(path string) {
+	raw, err := os.ReadFile(path)
+	if err == nil && strings.TrimSpace(string(raw)) == strconv.Itoa(os.Getpid()) {
+		os.Remove(path)
+	}
+}
+
+// running reports whether pid is a live byd-hass process. Processes of
+// another Android user can't be signalled (EPERM) but still count.
+func running(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil && !errors.Is(err, syscall.EPERM) {
+		return false
+	}
+	// Guard against PID reuse where the command line is readable.
+	cmdline, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return true
+	}
+	return bytes.Contains(cmdline, []byte("byd-hass"))
+}
@@ -0,0 +1,128 @@
+// Package precondition parses cabin preconditioning schedules, e.g.
+//
+//	mon-fri@07:30=21;sat,sun@09:00
+//
+// Each entry is "days@HH:MM" with an optional "=temperature" (°C). Days are
+// three-letter names, ranges such as "mon-fri", comma-separated lists, or
+// "daily".
+package precondition
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is one scheduled preconditioning start.
+type Entry struct {
+	Days        [7]bool // indexed by time.Weekday
+	Hour        int
+	Minute      int
+	Temperature float64 // °C, 0 = keep the car's setting
+	raw         string
+}
+
+func (e Entry) String() string { return e.raw }
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Parse parses a schedule (see the package documentation).
+func Parse(spec string) ([]Entry, error) {
+	var entries []Entry
+	for _, raw := range strings.Split(spec, ";") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		e, err := parseEntry(raw)
+		if err != nil {
+			return nil, fmt.Errorf("schedule entry %q: %w", raw, err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("schedule has no entries")
+	}
+	return entries, nil
+}
+
+func parseEntry(raw string) (Entry, error) {
+	e := Entry{raw: raw}
+	days, rest, ok := strings.Cut(raw, "@")
+	if !ok {
+		return e, fmt.Errorf("expected days@HH:MM")
+	}
+	clock, temp, hasTemp := strings.Cut(rest, "=")
+
+	if err := parseDays(strings.ToLower(strings.TrimSpace(days)), &e.Days); err != nil {
+		return e, err
+	}
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return e, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	e.Hour, e.Minute = t.Hour(), t.Minute()
+
+	if hasTemp {
+		e.Temperature, err = strconv.ParseFloat(strings.TrimSpace(temp), 64)
+		if err != nil || e.Temperature < 16 || e.Temperature > 32 {
+			return e, fmt.Errorf("temperature must be between 16 and 32 °C")
+		}
+	}
+	return e, nil
+}
+
+func parseDays(spec string, days *[7]bool) error {
+	if spec == "daily" {
+		for i := range days {
+			days[i] = true
+		}
+		return nil
+	}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		start, ok := weekdays[from]
+		if !ok {
+			return fmt.Errorf("unknown day %q", from)
+		}
+		end := start
+		if isRange {
+			if end, ok = weekdays[to]; !ok {
+				return fmt.Errorf("unknown day %q", to)
+			}
+		}
+		// Ranges may wrap around the week, e.g. "sat-mon".
+		for d := start; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == end {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// DueBetween reports whether the entry's start time falls in (from, to],
+// evaluated in to's location. Checking consecutive intervals therefore fires
+// each start exactly once, however coarse the check interval.
+func (e Entry) DueBetween(from, to time.Time) bool {
+	if !to.After(from) {
+		return false
+	}
+	from = from.In(to.Location())
+	// Look at every day the interval touches, capped at a week.
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, to.Location())
+	for i := 0; i <= 7 && !day.After(to); i++ {
+		start := time.Date(day.Year(), day.Month(), day.Day(), e.Hour, e.Minute, 0, 0, to.Location())
+		if e.Days[start.Weekday()] && start.After(from) && !start.After(to) {
+			return true
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return false
+}
@@ -0,0 +1,1353 @@
+// Code generated by accessorgen from types.go; DO NOT EDIT.
+
+package sensors
+
+// floatField returns the address of the *float64 field called name, or nil.
+func (d *SensorData) floatField(name string) **float64 {
+	switch name {
+	case "Speed":
+		return &d.Speed
+	case "Mileage":
+		return &d.Mileage
+	case "GearPosition":
+		return &d.GearPosition
+	case "PowerStatus":
+		return &d.PowerStatus
+	case "SteeringAngle":
+		return &d.SteeringAngle
+	case "AcceleratorDepth":
+		return &d.AcceleratorDepth
+	case "BrakeDepth":
+		return &d.BrakeDepth
+	case "EnginePower":
+		return &d.EnginePower
+	case "EngineRPM":
+		return &d.EngineRPM
+	case "FrontMotorRPM":
+		return &d.FrontMotorRPM
+	case "FrontMotorTorque":
+		return &d.FrontMotorTorque
+	case "RearMotorRPM":
+		return &d.RearMotorRPM
+	case "FuelPercentage":
+		return &d.FuelPercentage
+	case "BatteryPercentage":
+		return &d.BatteryPercentage
+	case "BatteryCapacity":
+		return &d.BatteryCapacity
+	case "ChargingStatus":
+		return &d.ChargingStatus
+	case "ChargeGunState":
+		return &d.ChargeGunState
+	case "MaxBatteryVoltage":
+		return &d.MaxBatteryVoltage
+	case "MinBatteryVoltage":
+		return &d.MinBatteryVoltage
+	case "TotalPowerConsumption":
+		return &d.TotalPowerConsumption
+	case "PowerConsumption100km":
+		return &d.PowerConsumption100km
+	case "BatteryVoltage12V":
+		return &d.BatteryVoltage12V
+	case "TotalFuelConsumption":
+		return &d.TotalFuelConsumption
+	case "AvgBatteryTemp":
+		return &d.AvgBatteryTemp
+	case "MinBatteryTemp":
+		return &d.MinBatteryTemp
+	case "MaxBatteryTemp":
+		return &d.MaxBatteryTemp
+	case "CabinTemperature":
+		return &d.CabinTemperature
+	case "OutsideTemperature":
+		return &d.OutsideTemperature
+	case "TemperatureUnit":
+		return &d.TemperatureUnit
+	case "EngineWaterTemperature":
+		return &d.EngineWaterTemperature
+	case "DriverDoor":
+		return &d.DriverDoor
+	case "PassengerDoor":
+		return &d.PassengerDoor
+	case "LeftRearDoor":
+		return &d.LeftRearDoor
+	case "RightRearDoor":
+		return &d.RightRearDoor
+	case "TrunkDoor":
+		return &d.TrunkDoor
+	case "Hood":
+		return &d.Hood
+	case "FuelTankCap":
+		return &d.FuelTankCap
+	case "DriverDoorLock":
+		return &d.DriverDoorLock
+	case "PassengerDoorLock":
+		return &d.PassengerDoorLock
+	case "LeftRearDoorLock":
+		return &d.LeftRearDoorLock
+	case "RightRearDoorLock":
+		return &d.RightRearDoorLock
+	case "TrunkLock":
+		return &d.TrunkLock
+	case "RemoteLockStatus":
+		return &d.RemoteLockStatus
+	case "LeftRearChildLock":
+		return &d.LeftRearChildLock
+	case "RightRearChildLock":
+		return &d.RightRearChildLock
+	case "DriverWindowOpenPercent":
+		return &d.DriverWindowOpenPercent
+	case "PassengerWindowOpenPercent":
+		return &d.PassengerWindowOpenPercent
+	case "LeftRearWindowOpenPercent":
+		return &d.LeftRearWindowOpenPercent
+	case "RightRearWindowOpenPercent":
+		return &d.RightRearWindowOpenPercent
+	case "SunroofOpenPercent":
+		return &d.SunroofOpenPercent
+	case "SunshadeOpenPercent":
+		return &d.SunshadeOpenPercent
+	case "LeftFrontTirePressure":
+		return &d.LeftFrontTirePressure
+	case "RightFrontTirePressure":
+		return &d.RightFrontTirePressure
+	case "LeftRearTirePressure":
+		return &d.LeftRearTirePressure
+	case "RightRearTirePressure":
+		return &d.RightRearTirePressure
+	case "LowBeamLights":
+		return &d.LowBeamLights
+	case "HighBeamLights":
+		return &d.HighBeamLights
+	case "FrontFogLights":
+		return &d.FrontFogLights
+	case "RearFogLights":
+		return &d.RearFogLights
+	case "ParkingLights":
+		return &d.ParkingLights
+	case "DaytimeRunningLights":
+		return &d.DaytimeRunningLights
+	case "LeftTurnSignal":
+		return &d.LeftTurnSignal
+	case "RightTurnSignal":
+		return &d.RightTurnSignal
+	case "HazardLights":
+		return &d.HazardLights
+	case "WiperGear":
+		return &d.WiperGear
+	case "FrontWiperSpeed":
+		return &d.FrontWiperSpeed
+	case "LastWiperTime":
+		return &d.LastWiperTime
+	case "ACStatus":
+		return &d.ACStatus
+	case "DriverACTemperature":
+		return &d.DriverACTemperature
+	case "FanSpeedLevel":
+		return &d.FanSpeedLevel
+	case "ACBlowingMode":
+		return &d.ACBlowingMode
+	case "ACCirculationMode":
+		return &d.ACCirculationMode
+	case "Weather":
+		return &d.Weather
+	case "FootwellLights":
+		return &d.FootwellLights
+	case "ACCCruiseStatus":
+		return &d.ACCCruiseStatus
+	case "LaneKeepAssistStatus":
+		return &d.LaneKeepAssistStatus
+	case "DriverSeatbelt":
+		return &d.DriverSeatbelt
+	case "PassengerSeatbeltWarn":
+		return &d.PassengerSeatbeltWarn
+	case "Row2LeftSeatbelt":
+		return &d.Row2LeftSeatbelt
+	case "Row2RightSeatbelt":
+		return &d.Row2RightSeatbelt
+	case "Row2CenterSeatbelt":
+		return &d.Row2CenterSeatbelt
+	case "DistanceToCarAhead":
+		return &d.DistanceToCarAhead
+	case "LaneCurvature":
+		return &d.LaneCurvature
+	case "RightLineDistance":
+		return &d.RightLineDistance
+	case "LeftLineDistance":
+		return &d.LeftLineDistance
+	case "CruiseSwitch":
+		return &d.CruiseSwitch
+	case "AutoParking":
+		return &d.AutoParking
+	case "RadarFrontLeft":
+		return &d.RadarFrontLeft
+	case "RadarFrontRight":
+		return &d.RadarFrontRight
+	case "RadarRearLeft":
+		return &d.RadarRearLeft
+	case "RadarRearRight":
+		return &d.RadarRearRight
+	case "RadarLeft":
+		return &d.RadarLeft
+	case "RadarFrontMidLeft":
+		return &d.RadarFrontMidLeft
+	case "RadarFrontMidRight":
+		return &d.RadarFrontMidRight
+	case "RadarRearCenter":
+		return &d.RadarRearCenter
+	case "RearLeftProximityAlert":
+		return &d.RearLeftProximityAlert
+	case "RearRightProximityAlert":
+		return &d.RearRightProximityAlert
+	case "VehicleOperatingMode":
+		return &d.VehicleOperatingMode
+	case "VehicleRunningMode":
+		return &d.VehicleRunningMode
+	case "SentryModeStatus":
+		return &d.SentryModeStatus
+	case "PowerOffRecordingConfig":
+		return &d.PowerOffRecordingConfig
+	case "PowerOffSentryAlarm":
+		return &d.PowerOffSentryAlarm
+	case "WiFiStatus":
+		return &d.WiFiStatus
+	case "BluetoothStatus":
+		return &d.BluetoothStatus
+	case "BluetoothSignalStrength":
+		return &d.BluetoothSignalStrength
+	case "WirelessADBSwitch":
+		return &d.WirelessADBSwitch
+	case "SteeringRotationSpeed":
+		return &d.SteeringRotationSpeed
+	case "AIPersonConfidence":
+		return &d.AIPersonConfidence
+	case "AIVehicleConfidence":
+		return &d.AIVehicleConfidence
+	case "LastSentryTriggerTime":
+		return &d.LastSentryTriggerTime
+	case "LastVideoStartTime":
+		return &d.LastVideoStartTime
+	case "LastVideoEndTime":
+		return &d.LastVideoEndTime
+	case "Month":
+		return &d.Month
+	case "Day":
+		return &d.Day
+	case "Hour":
+		return &d.Hour
+	case "Minute":
+		return &d.Minute
+	}
+	return nil
+}
+
+// stringField returns the address of the *string field called name, or nil.
+func (d *SensorData) stringField(name string) **string {
+	switch name {
+	case "LastSentryTriggerImage":
+		return &d.LastSentryTriggerImage
+	case "LastVideoPath":
+		return &d.LastVideoPath
+	}
+	return nil
+}
+
+// ForEachValue calls fn with the JSON key and value of every set numeric
+// and string field, in struct order.
+func (d *SensorData) ForEachValue(fn func(key string, value interface{})) {
+	if d.Speed != nil {
+		fn("speed", *d.Speed)
+	}
+	if d.Mileage != nil {
+		fn("mileage", *d.Mileage)
+	}
+	if d.GearPosition != nil {
+		fn("gear_position", *d.GearPosition)
+	}
+	if d.PowerStatus != nil {
+		fn("power_status", *d.PowerStatus)
+	}
+	if d.SteeringAngle != nil {
+		fn("steering_angle", *d.SteeringAngle)
+	}
+	if d.AcceleratorDepth != nil {
+		fn("accelerator_depth", *d.AcceleratorDepth)
+	}
+	if d.BrakeDepth != nil {
+		fn("brake_depth", *d.BrakeDepth)
+	}
+	if d.EnginePower != nil {
+		fn("engine_power", *d.EnginePower)
+	}
+	if d.EngineRPM != nil {
+		fn("engine_rpm", *d.EngineRPM)
+	}
+	if d.FrontMotorRPM != nil {
+		fn("front_motor_rpm", *d.FrontMotorRPM)
+	}
+	if d.FrontMotorTorque != nil {
+		fn("front_motor_torque", *d.FrontMotorTorque)
+	}
+	if d.RearMotorRPM != nil {
+		fn("rear_motor_rpm", *d.RearMotorRPM)
+	}
+	if d.FuelPercentage != nil {
+		fn("fuel_percentage", *d.FuelPercentage)
+	}
+	if d.BatteryPercentage != nil {
+		fn("battery_percentage", *d.BatteryPercentage)
+	}
+	if d.BatteryCapacity != nil {
+		fn("battery_capacity", *d.BatteryCapacity)
+	}
+	if d.ChargingStatus != nil {
+		fn("charging_status", *d.ChargingStatus)
+	}
+	if d.ChargeGunState != nil {
+		fn("charge_gun_state", *d.ChargeGunState)
+	}
+	if d.MaxBatteryVoltage != nil {
+		fn("max_battery_voltage", *d.MaxBatteryVoltage)
+	}
+	if d.MinBatteryVoltage != nil {
+		fn("min_battery_voltage", *d.MinBatteryVoltage)
+	}
+	if d.TotalPowerConsumption != nil {
+		fn("total_power_consumption", *d.TotalPowerConsumption)
+	}
+	if d.PowerConsumption100km != nil {
+		fn("power_consumption100km", *d.PowerConsumption100km)
+	}
+	if d.BatteryVoltage12V != nil {
+		fn("battery_voltage12_v", *d.BatteryVoltage12V)
+	}
+	if d.TotalFuelConsumption != nil {
+		fn("total_fuel_consumption", *d.TotalFuelConsumption)
+	}
+	if d.AvgBatteryTemp != nil {
+		fn("avg_battery_temp", *d.AvgBatteryTemp)
+	}
+	if d.MinBatteryTemp != nil {
+		fn("min_battery_temp", *d.MinBatteryTemp)
+	}
+	if d.MaxBatteryTemp != nil {
+		fn("max_battery_temp", *d.MaxBatteryTemp)
+	}
+	if d.CabinTemperature != nil {
+		fn("cabin_temperature", *d.CabinTemperature)
+	}
+	if d.OutsideTemperature != nil {
+		fn("outside_temperature", *d.OutsideTemperature)
+	}
+	if d.TemperatureUnit != nil {
+		fn("temperature_unit", *d.TemperatureUnit)
+	}
+	if d.EngineWaterTemperature != nil {
+		fn("engine_water_temperature", *d.EngineWaterTemperature)
+	}
+	if d.DriverDoor != nil {
+		fn("driver_door", *d.DriverDoor)
+	}
+	if d.PassengerDoor != nil {
+		fn("passenger_door", *d.PassengerDoor)
+	}
+	if d.LeftRearDoor != nil {
+		fn("left_rear_door", *d.LeftRearDoor)
+	}
+	if d.RightRearDoor != nil {
+		fn("right_rear_door", *d.RightRearDoor)
+	}
+	if d.TrunkDoor != nil {
+		fn("trunk_door", *d.TrunkDoor)
+	}
+	if d.Hood != nil {
+		fn("hood", *d.Hood)
+	}
+	if d.FuelTankCap != nil {
+		fn("fuel_tank_cap", *d.FuelTankCap)
+	}
+	if d.DriverDoorLock != nil {
+		fn("driver_door_lock", *d.DriverDoorLock)
+	}
+	if d.PassengerDoorLock != nil {
+		fn("passenger_door_lock", *d.PassengerDoorLock)
+	}
+	if d.LeftRearDoorLock != nil {
+		fn("left_rear_door_lock", *d.LeftRearDoorLock)
+	}
+	if d.RightRearDoorLock != nil {
+		fn("right_rear_door_lock", *d.RightRearDoorLock)
+	}
+	if d.TrunkLock != nil {
+		fn("trunk_lock", *d.TrunkLock)
+	}
+	if d.RemoteLockStatus != nil {
+		fn("remote_lock_status", *d.RemoteLockStatus)
+	}
+	if d.LeftRearChildLock != nil {
+		fn("left_rear_child_lock", *d.LeftRearChildLock)
+	}
+	if d.RightRearChildLock != nil {
+		fn("right_rear_child_lock", *d.RightRearChildLock)
+	}
+	if d.DriverWindowOpenPercent != nil {
+		fn("driver_window_open_percent", *d.DriverWindowOpenPercent)
+	}
+	if d.PassengerWindowOpenPercent != nil {
+		fn("passenger_window_open_percent", *d.PassengerWindowOpenPercent)
+	}
+	if d.LeftRearWindowOpenPercent != nil {
+		fn("left_rear_window_open_percent", *d.LeftRearWindowOpenPercent)
+	}
+	if d.RightRearWindowOpenPercent != nil {
+		fn("right_rear_window_open_percent", *d.RightRearWindowOpenPercent)
+	}
+	if d.SunroofOpenPercent != nil {
+		fn("sunroof_open_percent", *d.SunroofOpenPercent)
+	}
+	if d.SunshadeOpenPercent != nil {
+		fn("sunshade_open_percent", *d.SunshadeOpenPercent)
+	}
+	if d.LeftFrontTirePressure != nil {
+		fn("left_front_tire_pressure", *d.LeftFrontTirePressure)
+	}
+	if d.RightFrontTirePressure != nil {
+		fn("right_front_tire_pressure", *d.RightFrontTirePressure)
+	}
+	if d.LeftRearTirePressure != nil {
+		fn("left_rear_tire_pressure", *d.LeftRearTirePressure)
+	}
+	if d.RightRearTirePressure != nil {
+		fn("right_rear_tire_pressure", *d.RightRearTirePressure)
+	}
+	if d.LowBeamLights != nil {
+		fn("low_beam_lights", *d.LowBeamLights)
+	}
+	if d.HighBeamLights != nil {
+		fn("high_beam_lights", *d.HighBeamLights)
+	}
+	if d.FrontFogLights != nil {
+		fn("front_fog_lights", *d.FrontFogLights)
+	}
+	if d.RearFogLights != nil {
+		fn("rear_fog_lights", *d.RearFogLights)
+	}
+	if d.ParkingLights != nil {
+		fn("parking_lights", *d.ParkingLights)
+	}
+	if d.DaytimeRunningLights != nil {
+		fn("daytime_running_lights", *d.DaytimeRunningLights)
+	}
+	if d.LeftTurnSignal != nil {
+		fn("left_turn_signal", *d.LeftTurnSignal)
+	}
+	if d.RightTurnSignal != nil {
+		fn("right_turn_signal", *d.RightTurnSignal)
+	}
+	if d.HazardLights != nil {
+		fn("hazard_lights", *d.HazardLights)
+	}
+	if d.WiperGear != nil {
+		fn("wiper_gear", *d.WiperGear)
+	}
+	if d.FrontWiperSpeed != nil {
+		fn("front_wiper_speed", *d.FrontWiperSpeed)
+	}
+	if d.LastWiperTime != nil {
+		fn("last_wiper_time", *d.LastWiperTime)
+	}
+	if d.ACStatus != nil {
+		fn("ac_status", *d.ACStatus)
+	}
+	if d.DriverACTemperature != nil {
+		fn("driver_ac_temperature", *d.DriverACTemperature)
+	}
+	if d.FanSpeedLevel != nil {
+		fn("fan_speed_level", *d.FanSpeedLevel)
+	}
+	if d.ACBlowingMode != nil {
+		fn("ac_blowing_mode", *d.ACBlowingMode)
+	}
+	if d.ACCirculationMode != nil {
+		fn("ac_circulation_mode", *d.ACCirculationMode)
+	}
+	if d.Weather != nil {
+		fn("weather", *d.Weather)
+	}
+	if d.FootwellLights != nil {
+		fn("footwell_lights", *d.FootwellLights)
+	}
+	if d.ACCCruiseStatus != nil {
+		fn("acc_cruise_status", *d.ACCCruiseStatus)
+	}
+	if d.LaneKeepAssistStatus != nil {
+		fn("lane_keep_assist_status", *d.LaneKeepAssistStatus)
+	}
+	if d.DriverSeatbelt != nil {
+		fn("driver_seatbelt", *d.DriverSeatbelt)
+	}
+	if d.PassengerSeatbeltWarn != nil {
+		fn("passenger_seatbelt_warn", *d.PassengerSeatbeltWarn)
+	}
+	if d.Row2LeftSeatbelt != nil {
+		fn("row2_left_seatbelt", *d.Row2LeftSeatbelt)
+	}
+	if d.Row2RightSeatbelt != nil {
+		fn("row2_right_seatbelt", *d.Row2RightSeatbelt)
+	}
+	if d.Row2CenterSeatbelt != nil {
+		fn("row2_center_seatbelt", *d.Row2CenterSeatbelt)
+	}
+	if d.DistanceToCarAhead != nil {
+		fn("distance_to_car_ahead", *d.DistanceToCarAhead)
+	}
+	if d.LaneCurvature != nil {
+		fn("lane_curvature", *d.LaneCurvature)
+	}
+	if d.RightLineDistance != nil {
+		fn("right_line_distance", *d.RightLineDistance)
+	}
+	if d.LeftLineDistance != nil {
+		fn("left_line_distance", *d.LeftLineDistance)
+	}
+	if d.CruiseSwitch != nil {
+		fn("cruise_switch", *d.CruiseSwitch)
+	}
+	if d.AutoParking != nil {
+		fn("auto_parking", *d.AutoParking)
+	}
+	if d.RadarFrontLeft != nil {
+		fn("radar_front_left", *d.RadarFrontLeft)
+	}
+	if d.RadarFrontRight != nil {
+		fn("radar_front_right", *d.RadarFrontRight)
+	}
+	if d.RadarRearLeft != nil {
+		fn("radar_rear_left", *d.RadarRearLeft)
+	}
+	if d.RadarRearRight != nil {
+		fn("radar_rear_right", *d.RadarRearRight)
+	}
+	if d.RadarLeft != nil {
+		fn("radar_left", *d.RadarLeft)
+	}
+	if d.RadarFrontMidLeft != nil {
+		fn("radar_front_mid_left", *d.RadarFrontMidLeft)
+	}
+	if d.RadarFrontMidRight != nil {
+		fn("radar_front_mid_right", *d.RadarFrontMidRight)
+	}
+	if d.RadarRearCenter != nil {
+		fn("radar_rear_center", *d.RadarRearCenter)
+	}
+	if d.RearLeftProximityAlert != nil {
+		fn("rear_left_proximity_alert", *d.RearLeftProximityAlert)
+	}
+	if d.RearRightProximityAlert != nil {
+		fn("rear_right_proximity_alert", *d.RearRightProximityAlert)
+	}
+	if d.VehicleOperatingMode != nil {
+		fn("vehicle_operating_mode", *d.VehicleOperatingMode)
+	}
+	if d.VehicleRunningMode != nil {
+		fn("vehicle_running_mode", *d.VehicleRunningMode)
+	}
+	if d.SentryModeStatus != nil {
+		fn("sentry_mode_status", *d.SentryModeStatus)
+	}
+	if d.PowerOffRecordingConfig != nil {
+		fn("power_off_recording_config", *d.PowerOffRecordingConfig)
+	}
+	if d.PowerOffSentryAlarm != nil {
+		fn("power_off_sentry_alarm", *d.PowerOffSentryAlarm)
+	}
+	if d.WiFiStatus != nil {
+		fn("wi_fi_status", *d.WiFiStatus)
+	}
+	if d.BluetoothStatus != nil {
+		fn("bluetooth_status", *d.BluetoothStatus)
+	}
+	if d.BluetoothSignalStrength != nil {
+		fn("bluetooth_signal_strength", *d.BluetoothSignalStrength)
+	}
+	if d.WirelessADBSwitch != nil {
+		fn("wireless_adb_switch", *d.WirelessADBSwitch)
+	}
+	if d.SteeringRotationSpeed != nil {
+		fn("steering_rotation_speed", *d.SteeringRotationSpeed)
+	}
+	if d.AIPersonConfidence != nil {
+		fn("ai_person_confidence", *d.AIPersonConfidence)
+	}
+	if d.AIVehicleConfidence != nil {
+		fn("ai_vehicle_confidence", *d.AIVehicleConfidence)
+	}
+	if d.LastSentryTriggerTime != nil {
+		fn("last_sentry_trigger_time", *d.LastSentryTriggerTime)
+	}
+	if d.LastSentryTriggerImage != nil {
+		fn("last_sentry_trigger_image", *d.LastSentryTriggerImage)
+	}
+	if d.LastVideoStartTime != nil {
+		fn("last_video_start_time", *d.LastVideoStartTime)
+	}
+	if d.LastVideoEndTime != nil {
+		fn("last_video_end_time", *d.LastVideoEndTime)
+	}
+	if d.LastVideoPath != nil {
+		fn("last_video_path", *d.LastVideoPath)
+	}
+	if d.Month != nil {
+		fn("month", *d.Month)
+	}
+	if d.Day != nil {
+		fn("day", *d.Day)
+	}
+	if d.Hour != nil {
+		fn("hour", *d.Hour)
+	}
+	if d.Minute != nil {
+		fn("minute", *d.Minute)
+	}
+	d.forEachExtra(fn)
+}
+
+// ZipFloats calls fn with the JSON key and both values (nil when unset) of
+// every *float64 sensor until fn returns false. It reports whether every
+// call returned true.
+func ZipFloats(a, b *SensorData, fn func(key string, a, b *float64) bool) bool {
+	return fn("speed", a.Speed, b.Speed) &&
+		fn("mileage", a.Mileage, b.Mileage) &&
+		fn("gear_position", a.GearPosition, b.GearPosition) &&
+		fn("power_status", a.PowerStatus, b.PowerStatus) &&
+		fn("steering_angle", a.SteeringAngle, b.SteeringAngle) &&
+		fn("accelerator_depth", a.AcceleratorDepth, b.AcceleratorDepth) &&
+		fn("brake_depth", a.BrakeDepth, b.BrakeDepth) &&
+		fn("engine_power", a.EnginePower, b.EnginePower) &&
+		fn("engine_rpm", a.EngineRPM, b.EngineRPM) &&
+		fn("front_motor_rpm", a.FrontMotorRPM, b.FrontMotorRPM) &&
+		fn("front_motor_torque", a.FrontMotorTorque, b.FrontMotorTorque) &&
+		fn("rear_motor_rpm", a.RearMotorRPM, b.RearMotorRPM) &&
+		fn("fuel_percentage", a.FuelPercentage, b.FuelPercentage) &&
+		fn("battery_percentage", a.BatteryPercentage, b.BatteryPercentage) &&
+		fn("battery_capacity", a.BatteryCapacity, b.BatteryCapacity) &&
+		fn("charging_status", a.ChargingStatus, b.ChargingStatus) &&
+		fn("charge_gun_state", a.ChargeGunState, b.ChargeGunState) &&
+		fn("max_battery_voltage", a.MaxBatteryVoltage, b.MaxBatteryVoltage) &&
+		fn("min_battery_voltage", a.MinBatteryVoltage, b.MinBatteryVoltage) &&
+		fn("total_power_consumption", a.TotalPowerConsumption, b.TotalPowerConsumption) &&
+		fn("power_consumption100km", a.PowerConsumption100km, b.PowerConsumption100km) &&
+		fn("battery_voltage12_v", a.BatteryVoltage12V, b.BatteryVoltage12V) &&
+		fn("total_fuel_consumption", a.TotalFuelConsumption, b.TotalFuelConsumption) &&
+		fn("avg_battery_temp", a.AvgBatteryTemp, b.AvgBatteryTemp) &&
+		fn("min_battery_temp", a.MinBatteryTemp, b.MinBatteryTemp) &&
+		fn("max_battery_temp", a.MaxBatteryTemp, b.MaxBatteryTemp) &&
+		fn("cabin_temperature", a.CabinTemperature, b.CabinTemperature) &&
+		fn("outside_temperature", a.OutsideTemperature, b.OutsideTemperature) &&
+		fn("temperature_unit", a.TemperatureUnit, b.TemperatureUnit) &&
+		fn("engine_water_temperature", a.EngineWaterTemperature, b.EngineWaterTemperature) &&
+		fn("driver_door", a.DriverDoor, b.DriverDoor) &&
+		fn("passenger_door", a.PassengerDoor, b.PassengerDoor) &&
+		fn("left_rear_door", a.LeftRearDoor, b.LeftRearDoor) &&
+		fn("right_rear_door", a.RightRearDoor, b.RightRearDoor) &&
+		fn("trunk_door", a.TrunkDoor, b.TrunkDoor) &&
+		fn("hood", a.Hood, b.Hood) &&
+		fn("fuel_tank_cap", a.FuelTankCap, b.FuelTankCap) &&
+		fn("driver_door_lock", a.DriverDoorLock, b.DriverDoorLock) &&
+		fn("passenger_door_lock", a.PassengerDoorLock, b.PassengerDoorLock) &&
+		fn("left_rear_door_lock", a.LeftRearDoorLock, b.LeftRearDoorLock) &&
+		fn("right_rear_door_lock", a.RightRearDoorLock, b.RightRearDoorLock) &&
+		fn("trunk_lock", a.TrunkLock, b.TrunkLock) &&
+		fn("remote_lock_status", a.RemoteLockStatus, b.RemoteLockStatus) &&
+		fn("left_rear_child_lock", a.LeftRearChildLock, b.LeftRearChildLock) &&
+		fn("right_rear_child_lock", a.RightRearChildLock, b.RightRearChildLock) &&
+		fn("driver_window_open_percent", a.DriverWindowOpenPercent, b.DriverWindowOpenPercent) &&
+		fn("passenger_window_open_percent", a.PassengerWindowOpenPercent, b.PassengerWindowOpenPercent) &&
+		fn("left_rear_window_open_percent", a.LeftRearWindowOpenPercent, b.LeftRearWindowOpenPercent) &&
+		fn("right_rear_window_open_percent", a.RightRearWindowOpenPercent, b.RightRearWindowOpenPercent) &&
+		fn("sunroof_open_percent", a.SunroofOpenPercent, b.SunroofOpenPercent) &&
+		fn("sunshade_open_percent", a.SunshadeOpenPercent, b.SunshadeOpenPercent) &&
+		fn("left_front_tire_pressure", a.LeftFrontTirePressure, b.LeftFrontTirePressure) &&
+		fn("right_front_tire_pressure", a.RightFrontTirePressure, b.RightFrontTirePressure) &&
+		fn("left_rear_tire_pressure", a.LeftRearTirePressure, b.LeftRearTirePressure) &&
+		fn("right_rear_tire_pressure", a.RightRearTirePressure, b.RightRearTirePressure) &&
+		fn("low_beam_lights", a.LowBeamLights, b.LowBeamLights) &&
+		fn("high_beam_lights", a.HighBeamLights, b.HighBeamLights) &&
+		fn("front_fog_lights", a.FrontFogLights, b.FrontFogLights) &&
+		fn("rear_fog_lights", a.RearFogLights, b.RearFogLights) &&
+		fn("parking_lights", a.ParkingLights, b.ParkingLights) &&
+		fn("daytime_running_lights", a.DaytimeRunningLights, b.DaytimeRunningLights) &&
+		fn("left_turn_signal", a.LeftTurnSignal, b.LeftTurnSignal) &&
+		fn("right_turn_signal", a.RightTurnSignal, b.RightTurnSignal) &&
+		fn("hazard_lights", a.HazardLights, b.HazardLights) &&
+		fn("wiper_gear", a.WiperGear, b.WiperGear) &&
+		fn("front_wiper_speed", a.FrontWiperSpeed, b.FrontWiperSpeed) &&
+		fn("last_wiper_time", a.LastWiperTime, b.LastWiperTime) &&
+		fn("ac_status", a.ACStatus, b.ACStatus) &&
+		fn("driver_ac_temperature", a.DriverACTemperature, b.DriverACTemperature) &&
+		fn("fan_speed_level", a.FanSpeedLevel, b.FanSpeedLevel) &&
+		fn("ac_blowing_mode", a.ACBlowingMode, b.ACBlowingMode) &&
+		fn("ac_circulation_mode", a.ACCirculationMode, b.ACCirculationMode) &&
+		fn("weather", a.Weather, b.Weather) &&
+		fn("footwell_lights", a.FootwellLights, b.FootwellLights) &&
+		fn("acc_cruise_status", a.ACCCruiseStatus, b.ACCCruiseStatus) &&
+		fn("lane_keep_assist_status", a.LaneKeepAssistStatus, b.LaneKeepAssistStatus) &&
+		fn("driver_seatbelt", a.DriverSeatbelt, b.DriverSeatbelt) &&
+		fn("passenger_seatbelt_warn", a.PassengerSeatbeltWarn, b.PassengerSeatbeltWarn) &&
+		fn("row2_left_seatbelt", a.Row2LeftSeatbelt, b.Row2LeftSeatbelt) &&
+		fn("row2_right_seatbelt", a.Row2RightSeatbelt, b.Row2RightSeatbelt) &&
+		fn("row2_center_seatbelt", a.Row2CenterSeatbelt, b.Row2CenterSeatbelt) &&
+		fn("distance_to_car_ahead", a.DistanceToCarAhead, b.DistanceToCarAhead) &&
+		fn("lane_curvature", a.LaneCurvature, b.LaneCurvature) &&
+		fn("right_line_distance", a.RightLineDistance, b.RightLineDistance) &&
+		fn("left_line_distance", a.LeftLineDistance, b.LeftLineDistance) &&
+		fn("cruise_switch", a.CruiseSwitch, b.CruiseSwitch) &&
+		fn("auto_parking", a.AutoParking, b.AutoParking) &&
+		fn("radar_front_left", a.RadarFrontLeft, b.RadarFrontLeft) &&
+		fn("radar_front_right", a.RadarFrontRight, b.RadarFrontRight) &&
+		fn("radar_rear_left", a.RadarRearLeft, b.RadarRearLeft) &&
+		fn("radar_rear_right", a.RadarRearRight, b.RadarRearRight) &&
+		fn("radar_left", a.RadarLeft, b.RadarLeft) &&
+		fn("radar_front_mid_left", a.RadarFrontMidLeft, b.RadarFrontMidLeft) &&
+		fn("radar_front_mid_right", a.RadarFrontMidRight, b.RadarFrontMidRight) &&
+		fn("radar_rear_center", a.RadarRearCenter, b.RadarRearCenter) &&
+		fn("rear_left_proximity_alert", a.RearLeftProximityAlert, b.RearLeftProximityAlert) &&
+		fn("rear_right_proximity_alert", a.RearRightProximityAlert, b.RearRightProximityAlert) &&
+		fn("vehicle_operating_mode", a.VehicleOperatingMode, b.VehicleOperatingMode) &&
+		fn("vehicle_running_mode", a.VehicleRunningMode, b.VehicleRunningMode) &&
+		fn("sentry_mode_status", a.SentryModeStatus, b.SentryModeStatus) &&
+		fn("power_off_recording_config", a.PowerOffRecordingConfig, b.PowerOffRecordingConfig) &&
+		fn("power_off_sentry_alarm", a.PowerOffSentryAlarm, b.PowerOffSentryAlarm) &&
+		fn("wi_fi_status", a.WiFiStatus, b.WiFiStatus) &&
+		fn("bluetooth_status", a.BluetoothStatus, b.BluetoothStatus) &&
+		fn("bluetooth_signal_strength", a.BluetoothSignalStrength, b.BluetoothSignalStrength) &&
+		fn("wireless_adb_switch", a.WirelessADBSwitch, b.WirelessADBSwitch) &&
+		fn("steering_rotation_speed", a.SteeringRotationSpeed, b.SteeringRotationSpeed) &&
+		fn("ai_person_confidence", a.AIPersonConfidence, b.AIPersonConfidence) &&
+		fn("ai_vehicle_confidence", a.AIVehicleConfidence, b.AIVehicleConfidence) &&
+		fn("last_sentry_trigger_time", a.LastSentryTriggerTime, b.LastSentryTriggerTime) &&
+		fn("last_video_start_time", a.LastVideoStartTime, b.LastVideoStartTime) &&
+		fn("last_video_end_time", a.LastVideoEndTime, b.LastVideoEndTime) &&
+		fn("month", a.Month, b.Month) &&
+		fn("day", a.Day, b.Day) &&
+		fn("hour", a.Hour, b.Hour) &&
+		fn("minute", a.Minute, b.Minute) &&
+		zipExtra(a, b, fn)
+}
+
+// ZipStrings calls fn with the JSON key and both values (nil when unset) of
+// every *string sensor until fn returns false. It reports whether every
+// call returned true.
+func ZipStrings(a, b *SensorData, fn func(key string, a, b *string) bool) bool {
+	return fn("last_sentry_trigger_image", a.LastSentryTriggerImage, b.LastSentryTriggerImage) &&
+		fn("last_video_path", a.LastVideoPath, b.LastVideoPath)
+}
+
+// mergeMissing copies the fields set in src into the unset fields of d.
+func (d *SensorData) mergeMissing(src *SensorData) {
+	if d.Speed == nil {
+		d.Speed = src.Speed
+	}
+	if d.Mileage == nil {
+		d.Mileage = src.Mileage
+	}
+	if d.GearPosition == nil {
+		d.GearPosition = src.GearPosition
+	}
+	if d.PowerStatus == nil {
+		d.PowerStatus = src.PowerStatus
+	}
+	if d.SteeringAngle == nil {
+		d.SteeringAngle = src.SteeringAngle
+	}
+	if d.AcceleratorDepth == nil {
+		d.AcceleratorDepth = src.AcceleratorDepth
+	}
+	if d.BrakeDepth == nil {
+		d.BrakeDepth = src.BrakeDepth
+	}
+	if d.EnginePower == nil {
+		d.EnginePower = src.EnginePower
+	}
+	if d.EngineRPM == nil {
+		d.EngineRPM = src.EngineRPM
+	}
+	if d.FrontMotorRPM == nil {
+		d.FrontMotorRPM = src.FrontMotorRPM
+	}
+	if d.FrontMotorTorque == nil {
+		d.FrontMotorTorque = src.FrontMotorTorque
+	}
+	if d.RearMotorRPM == nil {
+		d.RearMotorRPM = src.RearMotorRPM
+	}
+	if d.FuelPercentage == nil {
+		d.FuelPercentage = src.FuelPercentage
+	}
+	if d.BatteryPercentage == nil {
+		d.BatteryPercentage = src.BatteryPercentage
+	}
+	if d.BatteryCapacity == nil {
+		d.BatteryCapacity = src.BatteryCapacity
+	}
+	if d.ChargingStatus == nil {
+		d.ChargingStatus = src.ChargingStatus
+	}
+	if d.ChargeGunState == nil {
+		d.ChargeGunState = src.ChargeGunState
+	}
+	if d.MaxBatteryVoltage == nil {
+		d.MaxBatteryVoltage = src.MaxBatteryVoltage
+	}
+	if d.MinBatteryVoltage == nil {
+		d.MinBatteryVoltage = src.MinBatteryVoltage
+	}
+	if d.TotalPowerConsumption == nil {
+		d.TotalPowerConsumption = src.TotalPowerConsumption
+	}
+	if d.PowerConsumption100km == nil {
+		d.PowerConsumption100km = src.PowerConsumption100km
+	}
+	if d.BatteryVoltage12V == nil {
+		d.BatteryVoltage12V = src.BatteryVoltage12V
+	}
+	if d.TotalFuelConsumption == nil {
+		d.TotalFuelConsumption = src.TotalFuelConsumption
+	}
+	if d.AvgBatteryTemp == nil {
+		d.AvgBatteryTemp = src.AvgBatteryTemp
+	}
+	if d.MinBatteryTemp == nil {
+		d.MinBatteryTemp = src.MinBatteryTemp
+	}
+	if d.MaxBatteryTemp == nil {
+		d.MaxBatteryTemp = src.MaxBatteryTemp
+	}
+	if d.CabinTemperature == nil {
+		d.CabinTemperature = src.CabinTemperature
+	}
+	if d.OutsideTemperature == nil {
+		d.OutsideTemperature = src.OutsideTemperature
+	}
+	if d.TemperatureUnit == nil {
+		d.TemperatureUnit = src.TemperatureUnit
+	}
+	if d.EngineWaterTemperature == nil {
+		d.EngineWaterTemperature = src.EngineWaterTemperature
+	}
+	if d.DriverDoor == nil {
+		d.DriverDoor = src.DriverDoor
+	}
+	if d.PassengerDoor == nil {
+		d.PassengerDoor = src.PassengerDoor
+	}
+	if d.LeftRearDoor == nil {
+		d.LeftRearDoor = src.LeftRearDoor
+	}
+	if d.RightRearDoor == nil {
+		d.RightRearDoor = src.RightRearDoor
+	}
+	if d.TrunkDoor == nil {
+		d.TrunkDoor = src.TrunkDoor
+	}
+	if d.Hood == nil {
+		d.Hood = src.Hood
+	}
+	if d.FuelTankCap == nil {
+		d.FuelTankCap = src.FuelTankCap
+	}
+	if d.DriverDoorLock == nil {
+		d.DriverDoorLock = src.DriverDoorLock
+	}
+	if d.PassengerDoorLock == nil {
+		d.PassengerDoorLock = src.PassengerDoorLock
+	}
+	if d.LeftRearDoorLock == nil {
+		d.LeftRearDoorLock = src.LeftRearDoorLock
+	}
+	if d.RightRearDoorLock == nil {
+		d.RightRearDoorLock = src.RightRearDoorLock
+	}
+	if d.TrunkLock == nil {
+		d.TrunkLock = src.TrunkLock
+	}
+	if d.RemoteLockStatus == nil {
+		d.RemoteLockStatus = src.RemoteLockStatus
+	}
+	if d.LeftRearChildLock == nil {
+		d.LeftRearChildLock = src.LeftRearChildLock
+	}
+	if d.RightRearChildLock == nil {
+		d.RightRearChildLock = src.RightRearChildLock
+	}
+	if d.DriverWindowOpenPercent == nil {
+		d.DriverWindowOpenPercent = src.DriverWindowOpenPercent
+	}
+	if d.PassengerWindowOpenPercent == nil {
+		d.PassengerWindowOpenPercent = src.PassengerWindowOpenPercent
+	}
+	if d.LeftRearWindowOpenPercent == nil {
+		d.LeftRearWindowOpenPercent = src.LeftRearWindowOpenPercent
+	}
+	if d.RightRearWindowOpenPercent == nil {
+		d.RightRearWindowOpenPercent = src.RightRearWindowOpenPercent
+	}
+	if d.SunroofOpenPercent == nil {
+		d.SunroofOpenPercent = src.SunroofOpenPercent
+	}
+	if d.SunshadeOpenPercent == nil {
+		d.SunshadeOpenPercent = src.SunshadeOpenPercent
+	}
+	if d.LeftFrontTirePressure == nil {
+		d.LeftFrontTirePressure = src.LeftFrontTirePressure
+	}
+	if d.RightFrontTirePressure == nil {
+		d.RightFrontTirePressure = src.RightFrontTirePressure
+	}
+	if d.LeftRearTirePressure == nil {
+		d.LeftRearTirePressure = src.LeftRearTirePressure
+	}
+	if d.RightRearTirePressure == nil {
+		d.RightRearTirePressure = src.RightRearTirePressure
+	}
+	if d.LowBeamLights == nil {
+		d.LowBeamLights = src.LowBeamLights
+	}
+	if d.HighBeamLights == nil {
+		d.HighBeamLights = src.HighBeamLights
+	}
+	if d.FrontFogLights == nil {
+		d.FrontFogLights = src.FrontFogLights
+	}
+	if d.RearFogLights == nil {
+		d.RearFogLights = src.RearFogLights
+	}
+	if d.ParkingLights == nil {
+		d.ParkingLights = src.ParkingLights
+	}
+	if d.DaytimeRunningLights == nil {
+		d.DaytimeRunningLights = src.DaytimeRunningLights
+	}
+	if d.LeftTurnSignal == nil {
+		d.LeftTurnSignal = src.LeftTurnSignal
+	}
+	if d.RightTurnSignal == nil {
+		d.RightTurnSignal = src.RightTurnSignal
+	}
+	if d.HazardLights == nil {
+		d.HazardLights = src.HazardLights
+	}
+	if d.WiperGear == nil {
+		d.WiperGear = src.WiperGear
+	}
+	if d.FrontWiperSpeed == nil {
+		d.FrontWiperSpeed = src.FrontWiperSpeed
+	}
+	if d.LastWiperTime == nil {
+		d.LastWiperTime = src.LastWiperTime
+	}
+	if d.ACStatus == nil {
+		d.ACStatus = src.ACStatus
+	}
+	if d.DriverACTemperature == nil {
+		d.DriverACTemperature = src.DriverACTemperature
+	}
+	if d.FanSpeedLevel == nil {
+		d.FanSpeedLevel = src.FanSpeedLevel
+	}
+	if d.ACBlowingMode == nil {
+		d.ACBlowingMode = src.ACBlowingMode
+	}
+	if d.ACCirculationMode == nil {
+		d.ACCirculationMode = src.ACCirculationMode
+	}
+	if d.Weather == nil {
+		d.Weather = src.Weather
+	}
+	if d.FootwellLights == nil {
+		d.FootwellLights = src.FootwellLights
+	}
+	if d.ACCCruiseStatus == nil {
+		d.ACCCruiseStatus = src.ACCCruiseStatus
+	}
+	if d.LaneKeepAssistStatus == nil {
+		d.LaneKeepAssistStatus = src.LaneKeepAssistStatus
+	}
+	if d.DriverSeatbelt == nil {
+		d.DriverSeatbelt = src.DriverSeatbelt
+	}
+	if d.PassengerSeatbeltWarn == nil {
+		d.PassengerSeatbeltWarn = src.PassengerSeatbeltWarn
+	}
+	if d.Row2LeftSeatbelt == nil {
+		d.Row2LeftSeatbelt = src.Row2LeftSeatbelt
+	}
+	if d.Row2RightSeatbelt == nil {
+		d.Row2RightSeatbelt = src.Row2RightSeatbelt
+	}
+	if d.Row2CenterSeatbelt == nil {
+		d.Row2CenterSeatbelt = src.Row2CenterSeatbelt
+	}
+	if d.DistanceToCarAhead == nil {
+		d.DistanceToCarAhead = src.DistanceToCarAhead
+	}
+	if d.LaneCurvature == nil {
+		d.LaneCurvature = src.LaneCurvature
+	}
+	if d.RightLineDistance == nil {
+		d.RightLineDistance = src.RightLineDistance
+	}
+	if d.LeftLineDistance == nil {
+		d.LeftLineDistance = src.LeftLineDistance
+	}
+	if d.CruiseSwitch == nil {
+		d.CruiseSwitch = src.CruiseSwitch
+	}
+	if d.AutoParking == nil {
+		d.AutoParking = src.AutoParking
+	}
+	if d.RadarFrontLeft == nil {
+		d.RadarFrontLeft = src.RadarFrontLeft
+	}
+	if d.RadarFrontRight == nil {
+		d.RadarFrontRight = src.RadarFrontRight
+	}
+	if d.RadarRearLeft == nil {
+		d.RadarRearLeft = src.RadarRearLeft
+	}
+	if d.RadarRearRight == nil {
+		d.RadarRearRight = src.RadarRearRight
+	}
+	if d.RadarLeft == nil {
+		d.RadarLeft = src.RadarLeft
+	}
+	if d.RadarFrontMidLeft == nil {
+		d.RadarFrontMidLeft = src.RadarFrontMidLeft
+	}
+	if d.RadarFrontMidRight == nil {
+		d.RadarFrontMidRight = src.RadarFrontMidRight
+	}
+	if d.RadarRearCenter == nil {
+		d.RadarRearCenter = src.RadarRearCenter
+	}
+	if d.RearLeftProximityAlert == nil {
+		d.RearLeftProximityAlert = src.RearLeftProximityAlert
+	}
+	if d.RearRightProximityAlert == nil {
+		d.RearRightProximityAlert = src.RearRightProximityAlert
+	}
+	if d.VehicleOperatingMode == nil {
+		d.VehicleOperatingMode = src.VehicleOperatingMode
+	}
+	if d.VehicleRunningMode == nil {
+		d.VehicleRunningMode = src.VehicleRunningMode
+	}
+	if d.SentryModeStatus == nil {
+		d.SentryModeStatus = src.SentryModeStatus
+	}
+	if d.PowerOffRecordingConfig == nil {
+		d.PowerOffRecordingConfig = src.PowerOffRecordingConfig
+	}
+	if d.PowerOffSentryAlarm == nil {
+		d.PowerOffSentryAlarm = src.PowerOffSentryAlarm
+	}
+	if d.WiFiStatus == nil {
+		d.WiFiStatus = src.WiFiStatus
+	}
+	if d.BluetoothStatus == nil {
+		d.BluetoothStatus = src.BluetoothStatus
+	}
+	if d.BluetoothSignalStrength == nil {
+		d.BluetoothSignalStrength = src.BluetoothSignalStrength
+	}
+	if d.WirelessADBSwitch == nil {
+		d.WirelessADBSwitch = src.WirelessADBSwitch
+	}
+	if d.SteeringRotationSpeed == nil {
+		d.SteeringRotationSpeed = src.SteeringRotationSpeed
+	}
+	if d.AIPersonConfidence == nil {
+		d.AIPersonConfidence = src.AIPersonConfidence
+	}
+	if d.AIVehicleConfidence == nil {
+		d.AIVehicleConfidence = src.AIVehicleConfidence
+	}
+	if d.LastSentryTriggerTime == nil {
+		d.LastSentryTriggerTime = src.LastSentryTriggerTime
+	}
+	if d.LastSentryTriggerImage == nil {
+		d.LastSentryTriggerImage = src.LastSentryTriggerImage
+	}
+	if d.LastVideoStartTime == nil {
+		d.LastVideoStartTime = src.LastVideoStartTime
+	}
+	if d.LastVideoEndTime == nil {
+		d.LastVideoEndTime = src.LastVideoEndTime
+	}
+	if d.LastVideoPath == nil {
+		d.LastVideoPath = src.LastVideoPath
+	}
+	if d.Month == nil {
+		d.Month = src.Month
+	}
+	if d.Day == nil {
+		d.Day = src.Day
+	}
+	if d.Hour == nil {
+		d.Hour = src.Hour
+	}
+	if d.Minute == nil {
+		d.Minute = src.Minute
+	}
+	if d.Location == nil {
+		d.Location = src.Location
+	}
+}
+
+// floatFieldByID returns the address of the *float64 field backing the sensor
+// with the given AllSensors ID, or nil.
+func (d *SensorData) floatFieldByID(id int) **float64 {
+	switch id {
+	case 1:
+		return &d.PowerStatus
+	case 2:
+		return &d.Speed
+	case 3:
+		return &d.Mileage
+	case 4:
+		return &d.GearPosition
+	case 5:
+		return &d.EngineRPM
+	case 6:
+		return &d.BrakeDepth
+	case 7:
+		return &d.AcceleratorDepth
+	case 8:
+		return &d.FrontMotorRPM
+	case 9:
+		return &d.RearMotorRPM
+	case 10:
+		return &d.EnginePower
+	case 11:
+		return &d.FrontMotorTorque
+	case 12:
+		return &d.ChargeGunState
+	case 13:
+		return &d.PowerConsumption100km
+	case 14:
+		return &d.MaxBatteryTemp
+	case 15:
+		return &d.AvgBatteryTemp
+	case 16:
+		return &d.MinBatteryTemp
+	case 17:
+		return &d.MaxBatteryVoltage
+	case 18:
+		return &d.MinBatteryVoltage
+	case 19:
+		return &d.LastWiperTime
+	case 20:
+		return &d.Weather
+	case 21:
+		return &d.DriverSeatbelt
+	case 22:
+		return &d.RemoteLockStatus
+	case 25:
+		return &d.CabinTemperature
+	case 26:
+		return &d.OutsideTemperature
+	case 27:
+		return &d.DriverACTemperature
+	case 28:
+		return &d.TemperatureUnit
+	case 29:
+		return &d.BatteryCapacity
+	case 30:
+		return &d.SteeringAngle
+	case 31:
+		return &d.SteeringRotationSpeed
+	case 32:
+		return &d.TotalPowerConsumption
+	case 33:
+		return &d.BatteryPercentage
+	case 34:
+		return &d.FuelPercentage
+	case 35:
+		return &d.TotalFuelConsumption
+	case 36:
+		return &d.LaneCurvature
+	case 37:
+		return &d.RightLineDistance
+	case 38:
+		return &d.LeftLineDistance
+	case 39:
+		return &d.BatteryVoltage12V
+	case 40:
+		return &d.RadarFrontLeft
+	case 41:
+		return &d.RadarFrontRight
+	case 42:
+		return &d.RadarRearLeft
+	case 43:
+		return &d.RadarRearRight
+	case 44:
+		return &d.RadarLeft
+	case 45:
+		return &d.RadarFrontMidLeft
+	case 46:
+		return &d.RadarFrontMidRight
+	case 47:
+		return &d.RadarRearCenter
+	case 48:
+		return &d.FrontWiperSpeed
+	case 49:
+		return &d.WiperGear
+	case 50:
+		return &d.CruiseSwitch
+	case 51:
+		return &d.DistanceToCarAhead
+	case 52:
+		return &d.ChargingStatus
+	case 53:
+		return &d.LeftFrontTirePressure
+	case 54:
+		return &d.RightFrontTirePressure
+	case 55:
+		return &d.LeftRearTirePressure
+	case 56:
+		return &d.RightRearTirePressure
+	case 57:
+		return &d.LeftTurnSignal
+	case 58:
+		return &d.RightTurnSignal
+	case 59:
+		return &d.DriverDoorLock
+	case 61:
+		return &d.DriverWindowOpenPercent
+	case 62:
+		return &d.PassengerWindowOpenPercent
+	case 63:
+		return &d.LeftRearWindowOpenPercent
+	case 64:
+		return &d.RightRearWindowOpenPercent
+	case 65:
+		return &d.SunroofOpenPercent
+	case 66:
+		return &d.SunshadeOpenPercent
+	case 67:
+		return &d.VehicleOperatingMode
+	case 68:
+		return &d.VehicleRunningMode
+	case 69:
+		return &d.Month
+	case 70:
+		return &d.Day
+	case 71:
+		return &d.Hour
+	case 72:
+		return &d.Minute
+	case 73:
+		return &d.PassengerSeatbeltWarn
+	case 74:
+		return &d.Row2LeftSeatbelt
+	case 75:
+		return &d.Row2RightSeatbelt
+	case 76:
+		return &d.Row2CenterSeatbelt
+	case 77:
+		return &d.ACStatus
+	case 78:
+		return &d.FanSpeedLevel
+	case 79:
+		return &d.ACCirculationMode
+	case 80:
+		return &d.ACBlowingMode
+	case 81:
+		return &d.DriverDoor
+	case 82:
+		return &d.PassengerDoor
+	case 83:
+		return &d.LeftRearDoor
+	case 84:
+		return &d.RightRearDoor
+	case 85:
+		return &d.Hood
+	case 86:
+		return &d.TrunkDoor
+	case 87:
+		return &d.FuelTankCap
+	case 88:
+		return &d.AutoParking
+	case 89:
+		return &d.ACCCruiseStatus
+	case 90:
+		return &d.RearLeftProximityAlert
+	case 91:
+		return &d.RearRightProximityAlert
+	case 92:
+		return &d.LaneKeepAssistStatus
+	case 93:
+		return &d.LeftRearDoorLock
+	case 94:
+		return &d.PassengerDoorLock
+	case 95:
+		return &d.RightRearDoorLock
+	case 96:
+		return &d.TrunkLock
+	case 97:
+		return &d.LeftRearChildLock
+	case 98:
+		return &d.RightRearChildLock
+	case 99:
+		return &d.ParkingLights
+	case 100:
+		return &d.LowBeamLights
+	case 101:
+		return &d.HighBeamLights
+	case 104:
+		return &d.FrontFogLights
+	case 105:
+		return &d.RearFogLights
+	case 106:
+		return &d.FootwellLights
+	case 107:
+		return &d.DaytimeRunningLights
+	case 108:
+		return &d.EngineWaterTemperature
+	case 109:
+		return &d.HazardLights
+	case 1003:
+		return &d.SentryModeStatus
+	case 1004:
+		return &d.PowerOffRecordingConfig
+	case 1006:
+		return &d.PowerOffSentryAlarm
+	case 1007:
+		return &d.WiFiStatus
+	case 1008:
+		return &d.BluetoothStatus
+	case 1009:
+		return &d.BluetoothSignalStrength
+	case 1010:
+		return &d.LastSentryTriggerTime
+	case 1012:
+		return &d.LastVideoStartTime
+	case 1013:
+		return &d.LastVideoEndTime
+	case 1101:
+		return &d.WirelessADBSwitch
+	}
+	return nil
+}
+
+// stringFieldByID returns the address of the *string field backing the sensor
+// with the given AllSensors ID, or nil.
+func (d *SensorData) stringFieldByID(id int) **string {
+	switch id {
+	case 1011:
+		return &d.LastSentryTriggerImage
+	case 1014:
+		return &d.LastVideoPath
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package sensors
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// plausibleRanges bounds the real values per unit. A scaled value outside
+// its unit's range usually means a wrong scale factor for that car.
+var plausibleRanges = map[string][2]float64{
+	"%":         {0, 100},
+	"°C":        {-50, 100},
+	"bar":       {1, 5},
+	"km/h":      {0, 300},
+	"km":        {0, 2000000},
+	"kW":        {-500, 800},
+	"kWh":       {0, 250},
+	"kWh/100km": {3, 60},
+	"V":         {2, 1000}, // see voltageNote
+	"rpm":       {-20000, 20000},
+	"m":         {0, 300},
+	"L":         {0, 100000},
+	"Nm":        {-1000, 1000},
+	"°":         {-900, 900},
+	"°/s":       {-2000, 2000},
+	"dBm":       {-140, 0},
+}
+
+// auditFactors are the corrections tried on implausible values, most
+// common first: tenths and hundredths are the usual culprits.
+var auditFactors = []float64{0.1, 0.01, 10, 100, 0.001, 1000}
+
+// AuditEntry is one sensor of a raw snapshot with its scaled value.
+type AuditEntry struct {
+	Def     SensorDefinition
+	Raw     string
+	Scale   float64
+	Value   float64 // scaled, valid when Numeric
+	Numeric bool
+	Note    string // why the value looks wrong, "" if it doesn't
+}
+
+// Audit scales raw values (field name → value as Diplus sent it) with the
+// sensor table and flags magnitudes that don't fit the target unit,
+// suggesting a scale factor that would. Entries are sorted by sensor ID.
+func Audit(raw map[string]string) []AuditEntry {
+	var out []AuditEntry
+	for _, def := range AllSensors {
+		value, ok := raw[def.FieldName]
+		if !ok || value == "" {
+			continue
+		}
+		e := AuditEntry{Def: def, Raw: value, Scale: scaleFactorFor(def.FieldName)}
+		f, err := strconv.ParseFloat(normalizeNumericValue(value), 64)
+		if err == nil {
+			e.Numeric = true
+			e.Value = f * e.Scale
+			e.Note = auditNote(def, e.Value)
+		}
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Def.ID < out[j].Def.ID })
+	return out
+}
+
+// auditNote explains why value looks wrong for the sensor's unit.
+func auditNote(def SensorDefinition, value float64) string {
+	unit := def.UnitOfMeasurement
+	r, ok := plausibleRanges[unit]
+	if !ok || value == 0 {
+		// 0 is what most cars send for sensors they don't have.
+		return ""
+	}
+	if unit == "V" {
+		return voltageNote(value)
+	}
+	if value >= r[0] && value <= r[1] {
+		return ""
+	}
+	scale := scaleFactorFor(def.FieldName)
+	if unit == "bar" && value >= 100 && value <= 500 {
+		return fmt.Sprintf("looks like kPa; scale %g instead of %g gives bar", scale*0.01, scale)
+	}
+	for _, f := range auditFactors {
+		if v := value * f; v >= r[0] && v <= r[1] {
+			return fmt.Sprintf("outside %g..%g %s; scale %g instead of %g gives %s", r[0], r[1], unit, scale*f, scale, strconv.FormatFloat(round3(v), 'f', -1, 64))
+		}
+	}
+	return fmt.Sprintf("outside %g..%g %s", r[0], r[1], unit)
+}
+
+// voltageNote checks a voltage against the three levels the car reports:
+// cells, the 12 V battery and the traction pack.
+func voltageNote(v float64) string {
+	switch {
+	case v >= 2 && v <= 5, v >= 9 && v <= 16, v >= 100 && v <= 1000:
+		return ""
+	default:
+		return "neither a cell, 12 V battery nor traction pack voltage"
+	}
+}
+
+func round3(v float64) float64 { return math.Round(v*1000) / 1000 }
@@ -0,0 +1,148 @@
+package sensors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Capabilities records which sensors this car actually reports. Not every BYD
+// model has every sensor (no sunroof, no fuel tank…); Diplus leaves those out
+// of its answer. A key it returns with an empty value is situational (no
+// recording yet, not charging) and still counts as supported.
+type Capabilities struct {
+	DetectedAt time.Time       `json:"detected_at"`
+	Sensors    map[string]bool `json:"sensors"`          // sensor ID → supported, once decided
+	Misses     map[string]int  `json:"misses,omitempty"` // undecided sensor ID → probes that left it out
+}
+
+// CapabilitiesFileName is the file name used inside the state directory.
+const CapabilitiesFileName = "capabilities.json"
+
+// UnsupportedAfterProbes is how many probes in a row must leave a sensor out
+// before it counts as unsupported, so one answer from a Diplus that is still
+// starting up doesn't drop it for good.
+const UnsupportedAfterProbes = 3
+
+// NewCapabilities returns a capability map with nothing probed yet.
+func NewCapabilities() *Capabilities {
+	return &Capabilities{Sensors: make(map[string]bool), Misses: make(map[string]int)}
+}
+
+// Record folds in one probe of ids: raw is the answer as returned by
+// RawValues, keyed by field name. A sensor is supported as soon as Diplus
+// returns its key, and unsupported once UnsupportedAfterProbes probes left
+// it out.
+func (c *Capabilities) Record(raw map[string]string, ids []int) {
+	c.DetectedAt = time.Now()
+	for _, id := range ids {
+		def := GetSensorByID(id)
+		if def == nil {
+			continue
+		}
+		key := strconv.Itoa(id)
+		if _, ok := raw[def.FieldName]; ok {
+			c.Sensors[key] = true
+			delete(c.Misses, key)
+			continue
+		}
+		if c.Misses[key]++; c.Misses[key] >= UnsupportedAfterProbes {
+			c.Sensors[key] = false
+			delete(c.Misses, key)
+		}
+	}
+}
+
+// UnsupportedCount returns how many sensors are known to be unsupported.
+func (c *Capabilities) UnsupportedCount() int {
+	n := 0
+	for _, supported := range c.Sensors {
+		if !supported {
+			n++
+		}
+	}
+	return n
+}
+
+// Known reports whether the sensor's support has been decided.
+func (c *Capabilities) Known(id int) bool {
+	_, ok := c.Sensors[strconv.Itoa(id)]
+	return ok
+}
+
+// Supported reports whether the sensor is available on this car. Sensors that
+// are still undecided are assumed to be supported.
+func (c *Capabilities) Supported(id int) bool {
+	supported, ok := c.Sensors[strconv.Itoa(id)]
+	return !ok || supported
+}
+
+// Unknown returns the IDs in ids whose support is still undecided: never
+// probed (e.g. sensors added to AllSensors by a newer release) or left out
+// by fewer than UnsupportedAfterProbes probes.
+func (c *Capabilities) Unknown(ids []int) []int {
+	var out []int
+	for _, id := range ids {
+		if !c.Known(id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// LoadCapabilities reads a capability file. A missing file yields (nil, nil).
+func LoadCapabilities(path string) (*Capabilities, error) {
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read capabilities: %w", err)
+	}
+	var caps Capabilities
+	if err := json.Unmarshal(raw, &caps); err != nil {
+		return nil, fmt.Errorf("failed to parse capabilities %s: %w", path, err)
+	}
+	if caps.Sensors == nil {
+		caps.Sensors = make(map[string]bool)
+	}
+	if caps.Misses == nil {
+		caps.Misses = make(map[string]int)
+	}
+	return &caps, nil
+}
+
+// SaveCapabilities writes the capability file, creating its directory.
+func SaveCapabilities(path string, caps *Capabilities) error {
+	raw, err := json.MarshalIndent(caps, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode capabilities: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write capabilities: %w", err)
+	}
+	return nil
+}
+
+// ApplyCapabilities drops unsupported sensors from MonitoredSensors so they
+// are neither polled nor published. Sensors a feature registered through
+// EnsureMonitored stay. It returns the removed IDs.
+func ApplyCapabilities(caps *Capabilities) []int {
+	var removed []int
+	kept := make([]MonitoredSensor, 0, len(MonitoredSensors))
+	for _, s := range MonitoredSensors {
+		if caps.Supported(s.ID) || featureSensorIDs[s.ID] {
+			kept = append(kept, s)
+		} else {
+			removed = append(removed, s.ID)
+		}
+	}
+	MonitoredSensors = kept
+	return removed
+}
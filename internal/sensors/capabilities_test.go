@@ -0,0 +1,47 @@
+package sensors
+
+import "testing"
+
+func TestCapabilitiesRecord(t *testing.T) {
+	soc, sentry := GetSensorByID(33), GetSensorByID(1014)
+	caps := NewCapabilities()
+	ids := []int{33, 1014, 34}
+
+	// The fuel level (34) is left out, the last video path is returned empty.
+	raw := map[string]string{soc.FieldName: "80", sentry.FieldName: ""}
+	for i := 1; i < UnsupportedAfterProbes; i++ {
+		caps.Record(raw, ids)
+		if !caps.Supported(34) || caps.Known(34) {
+			t.Fatalf("after %d probe(s) sensor 34 is decided, want it undecided", i)
+		}
+	}
+	caps.Record(raw, ids)
+	if caps.Supported(34) {
+		t.Errorf("sensor 34 left out of %d probes is still supported", UnsupportedAfterProbes)
+	}
+	if !caps.Known(33) || !caps.Supported(33) || !caps.Supported(1014) {
+		t.Errorf("returned sensors must be supported, empty values included: %v", caps.Sensors)
+	}
+	if got := caps.Unknown(ids); len(got) != 0 {
+		t.Errorf("Unknown = %v, want every sensor decided", got)
+	}
+}
+
+func TestApplyCapabilitiesKeepsFeatureSensors(t *testing.T) {
+	saved, savedFeatures := MonitoredSensors, featureSensorIDs
+	defer func() { MonitoredSensors, featureSensorIDs = saved, savedFeatures }()
+	MonitoredSensors, featureSensorIDs = []MonitoredSensor{{ID: 33, Publish: true}}, map[int]bool{}
+	EnsureMonitored(1014)
+
+	caps := NewCapabilities()
+	for i := 0; i < UnsupportedAfterProbes; i++ {
+		caps.Record(map[string]string{}, []int{33, 1014})
+	}
+	removed := ApplyCapabilities(caps)
+	if len(removed) != 1 || removed[0] != 33 {
+		t.Errorf("removed %v, want only 33", removed)
+	}
+	if len(MonitoredSensors) != 1 || MonitoredSensors[0].ID != 1014 {
+		t.Errorf("monitored %v, want the feature's sensor 1014 kept", MonitoredSensors)
+	}
+}
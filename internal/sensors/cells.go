@@ -0,0 +1,163 @@
+package sensors
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CellData holds per-cell battery readings, indexed from cell 1. Cells that
+// weren't reported are nil.
+type CellData struct {
+	Voltages     []*float64 `json:"voltages,omitempty"`     // V
+	Temperatures []*float64 `json:"temperatures,omitempty"` // °C
+}
+
+// CellLayout describes how Diplus exposes per-cell values, if at all: a
+// label with an {n} placeholder for the 1-based cell index and the number
+// of cells. A zero count disables that kind.
+type CellLayout struct {
+	VoltageLabel string
+	VoltageCount int
+	TempLabel    string
+	TempCount    int
+}
+
+// Keys echoed back by Diplus for cell values, followed by the cell number.
+const (
+	cellVoltageKey = "CellVoltage"
+	cellTempKey    = "CellTemperature"
+)
+
+// maxCells bounds the cell count accepted from configuration.
+const maxCells = 512
+
+var cellLayout CellLayout
+
+// SetCellLayout enables per-cell polling. It must run before polling starts.
+func SetCellLayout(l CellLayout) {
+	cellLayout = l
+}
+
+// ParseCellSpec parses "label{n}:count", e.g. "单体电压{n}:126".
+func ParseCellSpec(spec string) (label string, count int, err error) {
+	i := strings.LastIndex(spec, ":")
+	if i < 0 {
+		return "", 0, fmt.Errorf("cell spec %q: expected label{n}:count", spec)
+	}
+	label = strings.TrimSpace(spec[:i])
+	count, err = strconv.Atoi(strings.TrimSpace(spec[i+1:]))
+	if err != nil || count <= 0 || count > maxCells {
+		return "", 0, fmt.Errorf("cell spec %q: count must be 1-%d", spec, maxCells)
+	}
+	if !strings.Contains(label, "{n}") {
+		return "", 0, fmt.Errorf("cell spec %q: label needs an {n} placeholder", spec)
+	}
+	return label, count, nil
+}
+
+// CellTemplateParts returns the Diplus template parts for the configured
+// cells, in the same key:{label} form as regular sensors.
+func CellTemplateParts() []string {
+	var parts []string
+	add := func(key, label string, count int) {
+		for n := 1; n <= count; n++ {
+			idx := strconv.Itoa(n)
+			parts = append(parts, fmt.Sprintf("%s%s:{%s}", key, idx, strings.ReplaceAll(label, "{n}", idx)))
+		}
+	}
+	add(cellVoltageKey, cellLayout.VoltageLabel, cellLayout.VoltageCount)
+	add(cellTempKey, cellLayout.TempLabel, cellLayout.TempCount)
+	return parts
+}
+
+// setCell stores a cell value parsed from a "CellVoltage<n>" or
+// "CellTemperature<n>" key. It reports false for any other key.
+func (d *SensorData) setCell(name string, v float64) bool {
+	var cells *[]*float64
+	var count int
+	var rest string
+	switch {
+	case strings.HasPrefix(name, cellVoltageKey):
+		rest, count = name[len(cellVoltageKey):], cellLayout.VoltageCount
+		if d.Cells == nil {
+			d.Cells = &CellData{}
+		}
+		cells = &d.Cells.Voltages
+	case strings.HasPrefix(name, cellTempKey):
+		rest, count = name[len(cellTempKey):], cellLayout.TempCount
+		if d.Cells == nil {
+			d.Cells = &CellData{}
+		}
+		cells = &d.Cells.Temperatures
+	default:
+		return false
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < 1 || n > count {
+		return false
+	}
+	if len(*cells) < count {
+		grown := make([]*float64, count)
+		copy(grown, *cells)
+		*cells = grown
+	}
+	(*cells)[n-1] = &v
+	return true
+}
+
+// mergeCells fills the cells missing in dst from src.
+func mergeCells(dst, src *SensorData) {
+	if src.Cells == nil {
+		return
+	}
+	if dst.Cells == nil {
+		dst.Cells = &CellData{}
+	}
+	dst.Cells.Voltages = mergeCellSlice(dst.Cells.Voltages, src.Cells.Voltages)
+	dst.Cells.Temperatures = mergeCellSlice(dst.Cells.Temperatures, src.Cells.Temperatures)
+}
+
+func mergeCellSlice(dst, src []*float64) []*float64 {
+	if len(dst) < len(src) {
+		grown := make([]*float64, len(src))
+		copy(grown, dst)
+		dst = grown
+	}
+	for i, v := range src {
+		if dst[i] == nil {
+			dst[i] = v
+		}
+	}
+	return dst
+}
+
+// CellSpread summarises one kind of cell reading.
+type CellSpread struct {
+	Min, Max float64
+	MinCell  int // 1-based
+	MaxCell  int
+}
+
+// Delta returns Max - Min.
+func (s CellSpread) Delta() float64 { return s.Max - s.Min }
+
+// Spread returns the lowest and highest reported value of cells; ok is
+// false when no cell was reported.
+func Spread(cells []*float64) (s CellSpread, ok bool) {
+	s.Min, s.Max = math.Inf(1), math.Inf(-1)
+	for i, v := range cells {
+		if v == nil {
+			continue
+		}
+		ok = true
+		if *v < s.Min {
+			s.Min, s.MinCell = *v, i+1
+		}
+		if *v > s.Max {
+			s.Max, s.MaxCell = *v, i+1
+		}
+	}
+	return s, ok
+}
@@ -0,0 +1,75 @@
+package sensors
+
+import (
+	"time"
+)
+
+// clockSensorIDs are the head-unit clock fields (Month, Day, Hour, Minute).
+// They are fused into a single car_clock value instead of being published as
+// separate entities.
+var clockSensorIDs = map[int]bool{69: true, 70: true, 71: true, 72: true}
+
+// carLocation is the zone the head-unit clock is set to; nil means
+// time.Local.
+var carLocation *time.Location
+
+// SetCarLocation sets the zone the head-unit clock runs in, for cars whose
+// clock isn't set to the local zone.
+func SetCarLocation(loc *time.Location) {
+	carLocation = loc
+}
+
+func carZone() *time.Location {
+	if carLocation != nil {
+		return carLocation
+	}
+	return time.Local
+}
+
+// IsClockSensor reports whether id is one of the car clock fields.
+func IsClockSensor(id int) bool {
+	return clockSensorIDs[id]
+}
+
+// CarClock assembles the head-unit's wall clock (in the car's zone, see
+// SetCarLocation, at minute resolution). Diplus doesn't report the year, so
+// the year that puts the clock closest to ref is used, which keeps New
+// Year's Eve right. ok is false while any field is missing or out of range.
+func CarClock(data *SensorData, ref time.Time) (clock time.Time, ok bool) {
+	if data == nil || data.Month == nil || data.Day == nil || data.Hour == nil || data.Minute == nil {
+		return time.Time{}, false
+	}
+	month, day, hour, minute := int(*data.Month), int(*data.Day), int(*data.Hour), int(*data.Minute)
+
+	zone := carZone()
+	ref = ref.In(zone)
+	var best time.Duration
+	for _, year := range []int{ref.Year() - 1, ref.Year(), ref.Year() + 1} {
+		t := time.Date(year, time.Month(month), day, hour, minute, 0, 0, zone)
+		// time.Date normalises out-of-range values (e.g. day 32); reject them.
+		if t.Month() != time.Month(month) || t.Day() != day || t.Hour() != hour || t.Minute() != minute {
+			continue
+		}
+		if d := absDuration(t.Sub(ref)); !ok || d < best {
+			clock, best, ok = t, d, true
+		}
+	}
+	return clock, ok
+}
+
+// ClockDrift returns how far the car clock is ahead of ref (negative when
+// behind). ref is truncated to the minute first so an accurate clock reads 0.
+func ClockDrift(data *SensorData, ref time.Time) (time.Duration, bool) {
+	clock, ok := CarClock(data, ref)
+	if !ok {
+		return 0, false
+	}
+	return clock.Sub(ref.Truncate(time.Minute)), true
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -1,5 +1,7 @@
 package sensors
 
+import "math"
+
 // DeriveChargingStatus derives a human-readable charging state from the raw
 // Diplus metrics. The logic is as follows:
 //  1. If ChargeGunState is nil or not equal to 2 → "disconnected".
@@ -22,3 +24,191 @@ func DeriveChargingStatus(data *SensorData) string {
 
 	return "connected"
 }
+
+// DeriveChargerPower returns the power flowing into the battery from the
+// charger in kW: the negated EnginePower while charging, 0 otherwise. ok is
+// false when EnginePower is unknown.
+func DeriveChargerPower(data *SensorData) (kw float64, ok bool) {
+	if data == nil || data.EnginePower == nil {
+		return 0, false
+	}
+	if DeriveChargingStatus(data) != "charging" {
+		return 0, true
+	}
+	return -*data.EnginePower, true
+}
+
+// Alarm panel states used by DeriveSentryState. They match the states of Home
+// Assistant's alarm_control_panel platform.
+const (
+	SentryDisarmed  = "disarmed"
+	SentryArmedAway = "armed_away"
+	SentryTriggered = "triggered"
+)
+
+// DeriveSentryState maps the sentry sensors to an alarm panel state:
+//  1. SentryModeStatus nil → "" (unknown).
+//  2. SentryModeStatus == 0 → "disarmed".
+//  3. Sentry on and PowerOffSentryAlarm > 0 → "triggered".
+//  4. Otherwise → "armed_away".
+func DeriveSentryState(data *SensorData) string {
+	if data == nil || data.SentryModeStatus == nil {
+		return ""
+	}
+	if *data.SentryModeStatus == 0 {
+		return SentryDisarmed
+	}
+	if data.PowerOffSentryAlarm != nil && *data.PowerOffSentryAlarm > 0 {
+		return SentryTriggered
+	}
+	return SentryArmedAway
+}
+
+// DeriveEngineRunning reports whether the combustion engine of a hybrid is
+// running (EngineRPM > 0). ok is false when the car doesn't report the RPM.
+func DeriveEngineRunning(data *SensorData) (running, ok bool) {
+	if data == nil || data.EngineRPM == nil {
+		return false, false
+	}
+	return *data.EngineRPM > 0, true
+}
+
+// DeriveFuelLevel converts FuelPercentage to litres for a tank of the given
+// size. ok is false when the percentage or tank size is unknown.
+func DeriveFuelLevel(data *SensorData, tankLiters float64) (liters float64, ok bool) {
+	if data == nil || data.FuelPercentage == nil || tankLiters <= 0 {
+		return 0, false
+	}
+	return *data.FuelPercentage / 100 * tankLiters, true
+}
+
+// DeriveBatteryEnergy returns the energy left in the battery (state of
+// energy, kWh) from the SoC and the usable capacity. ok is false when either
+// is unknown.
+func DeriveBatteryEnergy(data *SensorData, usableKWh float64) (kwh float64, ok bool) {
+	if data == nil || data.BatteryPercentage == nil || usableKWh <= 0 {
+		return 0, false
+	}
+	return *data.BatteryPercentage / 100 * usableKWh, true
+}
+
+// DeriveBatteryHealth compares the capacity the car reports with the usable
+// capacity of a new battery (percent). Most cars report 0, in which case ok
+// is false.
+func DeriveBatteryHealth(data *SensorData, usableKWh float64) (percent float64, ok bool) {
+	if data == nil || data.BatteryCapacity == nil || *data.BatteryCapacity <= 0 || usableKWh <= 0 {
+		return 0, false
+	}
+	return math.Min(*data.BatteryCapacity/usableKWh*100, 100), true
+}
+
+// WindowSensorIDs lists the sensors DeriveWindowsOpenWhileParked relies on:
+// PowerStatus, the four window opening percentages and the sunroof.
+var WindowSensorIDs = []int{1, 61, 62, 63, 64, 65}
+
+// DeriveOpenWindows returns the snake_case keys of the windows and sunroof
+// that are open (> 0%). ok is false when the car reports none of them.
+func DeriveOpenWindows(data *SensorData) (open []string, ok bool) {
+	if data == nil {
+		return nil, false
+	}
+	open = []string{}
+	for _, w := range []struct {
+		key string
+		v   *float64
+	}{
+		{"driver_window", data.DriverWindowOpenPercent},
+		{"passenger_window", data.PassengerWindowOpenPercent},
+		{"left_rear_window", data.LeftRearWindowOpenPercent},
+		{"right_rear_window", data.RightRearWindowOpenPercent},
+		{"sunroof", data.SunroofOpenPercent},
+	} {
+		if w.v == nil {
+			continue
+		}
+		ok = true
+		if *w.v > 0 {
+			open = append(open, w.key)
+		}
+	}
+	return open, ok
+}
+
+// DoorSensorIDs lists the sensors DeriveOpenDoors relies on: the four
+// doors, the hood and the trunk.
+var DoorSensorIDs = []int{81, 82, 83, 84, 85, 86}
+
+// DeriveOpenDoors returns the snake_case keys of the doors, trunk and hood
+// that are open (> 0). ok is false when the car reports none of them.
+func DeriveOpenDoors(data *SensorData) (open []string, ok bool) {
+	if data == nil {
+		return nil, false
+	}
+	open = []string{}
+	for _, d := range []struct {
+		key string
+		v   *float64
+	}{
+		{"driver_door", data.DriverDoor},
+		{"passenger_door", data.PassengerDoor},
+		{"left_rear_door", data.LeftRearDoor},
+		{"right_rear_door", data.RightRearDoor},
+		{"hood", data.Hood},
+		{"trunk", data.TrunkDoor},
+	} {
+		if d.v == nil {
+			continue
+		}
+		ok = true
+		if *d.v > 0 {
+			open = append(open, d.key)
+		}
+	}
+	return open, ok
+}
+
+// DeriveWindowsOpenWhileParked reports whether any window or the sunroof is
+// open while the car is switched off (PowerStatus == 0). ok is false when
+// the power state or every window is unknown.
+func DeriveWindowsOpenWhileParked(data *SensorData) (open, ok bool) {
+	windows, ok := DeriveOpenWindows(data)
+	if !ok || data.PowerStatus == nil {
+		return false, false
+	}
+	return *data.PowerStatus == 0 && len(windows) > 0, true
+}
+
+// OccupancySensorIDs lists the sensors DeriveOccupants relies on:
+// PowerStatus, the seat belts and the passenger seat belt warning.
+var OccupancySensorIDs = []int{1, 21, 73, 74, 75, 76}
+
+// DeriveOccupants returns the seats that look occupied while the car is
+// switched on: a fastened belt (> 0), or for the front passenger the
+// unfastened-belt warning, which the car only raises for an occupied seat.
+// The driver's seat always counts while the car is on. ok is false when the
+// car is off or reports no belt at all.
+func DeriveOccupants(data *SensorData) (seats []string, ok bool) {
+	if data == nil || data.PowerStatus == nil || *data.PowerStatus == 0 {
+		return nil, false
+	}
+	ok = data.DriverSeatbelt != nil
+	seats = []string{"driver"}
+	for _, s := range []struct {
+		seat string
+		v    *float64
+	}{
+		{"passenger", data.PassengerSeatbeltWarn},
+		{"row2_left", data.Row2LeftSeatbelt},
+		{"row2_right", data.Row2RightSeatbelt},
+		{"row2_center", data.Row2CenterSeatbelt},
+	} {
+		if s.v == nil {
+			continue
+		}
+		ok = true
+		if *s.v > 0 {
+			seats = append(seats, s.seat)
+		}
+	}
+	return seats, ok
+}
@@ -0,0 +1,74 @@
+package sensors
+
+import "math"
+
+// HVACSensorIDs lists the sensors EstimateHVACPower relies on: cabin and
+// outside temperature, the driver's set temperature, AC status and fan
+// level.
+var HVACSensorIDs = []int{25, 26, 27, 77, 78}
+
+// HVAC model constants. The car doesn't report climate power, so these are
+// rough figures for a compact EV cabin with a heat pump.
+const (
+	hvacSetpointDefault = 22.0 // °C when the set temperature is unknown
+	hvacCabinLoss       = 0.1  // kW of heat lost or gained per °C outside/setpoint difference
+	hvacSolarLoad       = 0.4  // kW extra cooling load (sun, occupants)
+	hvacCatchUp         = 0.25 // kW per °C the cabin is still off the setpoint
+	hvacCatchUpMax      = 3.0  // kW
+	hvacFanPerLevel     = 0.04 // kW per blower level
+	hvacFanLevelMax     = 7.0  // highest blower level
+	hvacHeatPumpMinTemp = -10  // °C below which heating is resistive (COP 1)
+	hvacCOPHeating      = 2.0
+	hvacCOPCooling      = 2.5
+	hvacMaxPower        = 7.0 // kW, about what a PTC heater plus blower can draw
+)
+
+// EstimateHVACPower estimates the electrical power (kW) the climate system
+// draws. It is 0 while the AC is off; ok is false when the AC status is
+// unknown.
+//
+// The steady-state load grows with the difference between the outside and
+// the set temperature; while the cabin is still far from the set
+// temperature a catch-up load scaled by the fan level is added. Both are
+// divided by the heat pump's efficiency, which drops to that of a resistive
+// heater in the cold, and the blower's own power is added on top.
+func EstimateHVACPower(data *SensorData) (kw float64, ok bool) {
+	if data == nil || data.ACStatus == nil {
+		return 0, false
+	}
+	if *data.ACStatus <= 0 {
+		return 0, true
+	}
+
+	setpoint := hvacSetpointDefault
+	if data.DriverACTemperature != nil && *data.DriverACTemperature > 0 {
+		setpoint = *data.DriverACTemperature
+	}
+	fan := hvacFanLevelMax / 2
+	if data.FanSpeedLevel != nil {
+		fan = math.Max(0, math.Min(*data.FanSpeedLevel, hvacFanLevelMax))
+	}
+
+	// Positive: heating, negative: cooling.
+	var demand float64
+	if data.OutsideTemperature != nil {
+		demand = (setpoint - *data.OutsideTemperature) * hvacCabinLoss
+		if demand < 0 {
+			demand -= hvacSolarLoad
+		}
+	}
+	if data.CabinTemperature != nil {
+		offset := setpoint - *data.CabinTemperature
+		demand += math.Max(-hvacCatchUpMax, math.Min(offset*hvacCatchUp, hvacCatchUpMax)) * fan / hvacFanLevelMax
+	}
+
+	cop := hvacCOPCooling
+	if demand > 0 {
+		cop = hvacCOPHeating
+		if data.OutsideTemperature != nil && *data.OutsideTemperature < hvacHeatPumpMinTemp {
+			cop = 1
+		}
+	}
+	kw = math.Abs(demand)/cop + fan*hvacFanPerLevel
+	return math.Min(kw, hvacMaxPower), true
+}
@@ -0,0 +1,209 @@
+// Command accessorgen generates reflection-free accessors for
+// sensors.SensorData from the struct definition and the AllSensors table in
+// types.go. Run it through "go generate ./internal/sensors".
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	input  = "types.go"
+	output = "accessors_gen.go"
+)
+
+type field struct {
+	Name string
+	Key  string // JSON key
+	Type string // "float64" or "string"
+}
+
+type sensorRow struct {
+	ID    int
+	Field string
+}
+
+func main() {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, input, nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fields := structFields(file, "SensorData")
+	rows := tableRows(file, "AllSensors")
+	if len(fields) == 0 || len(rows) == 0 {
+		log.Fatalf("%s: SensorData or AllSensors not found", input)
+	}
+
+	src, err := format.Source(render(fields, rows))
+	if err != nil {
+		log.Fatalf("generated code does not compile: %v", err)
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// structFields returns the *float64 and *string fields of the named struct.
+func structFields(file *ast.File, name string) []field {
+	var out []field
+	ast.Inspect(file, func(n ast.Node) bool {
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok || ts.Name.Name != name {
+			return true
+		}
+		st := ts.Type.(*ast.StructType)
+		for _, f := range st.Fields.List {
+			star, ok := f.Type.(*ast.StarExpr)
+			if !ok {
+				continue
+			}
+			ident, ok := star.X.(*ast.Ident)
+			if !ok || (ident.Name != "float64" && ident.Name != "string") {
+				continue
+			}
+			tag := ""
+			if f.Tag != nil {
+				raw, _ := strconv.Unquote(f.Tag.Value)
+				tag = strings.Split(reflect.StructTag(raw).Get("json"), ",")[0]
+			}
+			for _, n := range f.Names {
+				out = append(out, field{Name: n.Name, Key: tag, Type: ident.Name})
+			}
+		}
+		return false
+	})
+	return out
+}
+
+// tableRows reads the ID and FieldName columns of the named
+// []SensorDefinition literal.
+func tableRows(file *ast.File, name string) []sensorRow {
+	var out []sensorRow
+	ast.Inspect(file, func(n ast.Node) bool {
+		vs, ok := n.(*ast.ValueSpec)
+		if !ok || len(vs.Names) != 1 || vs.Names[0].Name != name || len(vs.Values) != 1 {
+			return true
+		}
+		for _, elt := range vs.Values[0].(*ast.CompositeLit).Elts {
+			row := elt.(*ast.CompositeLit)
+			id, err := strconv.Atoi(row.Elts[0].(*ast.BasicLit).Value)
+			if err != nil {
+				log.Fatalf("%s: bad sensor ID: %v", input, err)
+			}
+			fieldName, _ := strconv.Unquote(row.Elts[1].(*ast.BasicLit).Value)
+			out = append(out, sensorRow{ID: id, Field: fieldName})
+		}
+		return false
+	})
+	return out
+}
+
+func render(fields []field, rows []sensorRow) []byte {
+	var b bytes.Buffer
+	p := func(format string, args ...interface{}) { fmt.Fprintf(&b, format+"\n", args...) }
+
+	types := make(map[string]string, len(fields))
+	for _, f := range fields {
+		types[f.Name] = f.Type
+	}
+
+	p("// Code generated by accessorgen from %s; DO NOT EDIT.", input)
+	p("")
+	p("package sensors")
+	p("")
+
+	p("// floatField returns the address of the *float64 field called name, or nil.")
+	p("func (d *SensorData) floatField(name string) **float64 {")
+	p("switch name {")
+	for _, f := range fields {
+		if f.Type == "float64" {
+			p("case %q: return &d.%s", f.Name, f.Name)
+		}
+	}
+	p("}")
+	p("return nil")
+	p("}")
+	p("")
+
+	p("// stringField returns the address of the *string field called name, or nil.")
+	p("func (d *SensorData) stringField(name string) **string {")
+	p("switch name {")
+	for _, f := range fields {
+		if f.Type == "string" {
+			p("case %q: return &d.%s", f.Name, f.Name)
+		}
+	}
+	p("}")
+	p("return nil")
+	p("}")
+	p("")
+
+	p("// ForEachValue calls fn with the JSON key and value of every set numeric")
+	p("// and string field, in struct order.")
+	p("func (d *SensorData) ForEachValue(fn func(key string, value interface{})) {")
+	for _, f := range fields {
+		p("if d.%s != nil { fn(%q, *d.%s) }", f.Name, f.Key, f.Name)
+	}
+	p("d.forEachExtra(fn)")
+	p("}")
+	p("")
+
+	for _, typ := range []string{"float64", "string"} {
+		name := strings.ToUpper(typ[:1]) + strings.TrimSuffix(typ[1:], "64") + "s"
+		p("// Zip%s calls fn with the JSON key and both values (nil when unset) of", name)
+		p("// every *%s sensor until fn returns false. It reports whether every", typ)
+		p("// call returned true.")
+		p("func Zip%s(a, b *SensorData, fn func(key string, a, b *%s) bool) bool {", name, typ)
+		var calls []string
+		for _, f := range fields {
+			if f.Type == typ {
+				calls = append(calls, fmt.Sprintf("fn(%q, a.%s, b.%s)", f.Key, f.Name, f.Name))
+			}
+		}
+		if typ == "float64" {
+			calls = append(calls, "zipExtra(a, b, fn)")
+		}
+		p("return %s", strings.Join(calls, " &&\n"))
+		p("}")
+		p("")
+	}
+
+	p("// mergeMissing copies the fields set in src into the unset fields of d.")
+	p("func (d *SensorData) mergeMissing(src *SensorData) {")
+	for _, f := range fields {
+		p("if d.%s == nil { d.%s = src.%s }", f.Name, f.Name, f.Name)
+	}
+	p("if d.Location == nil { d.Location = src.Location }")
+	p("}")
+	p("")
+
+	for _, typ := range []string{"float64", "string"} {
+		prefix := strings.TrimSuffix(typ, "64")
+		p("// %sFieldByID returns the address of the *%s field backing the sensor", prefix, typ)
+		p("// with the given AllSensors ID, or nil.")
+		p("func (d *SensorData) %sFieldByID(id int) **%s {", prefix, typ)
+		p("switch id {")
+		for _, r := range rows {
+			if types[r.Field] == typ {
+				p("case %d: return &d.%s", r.ID, r.Field)
+			}
+		}
+		p("}")
+		p("return nil")
+		p("}")
+		p("")
+	}
+	return b.Bytes()
+}
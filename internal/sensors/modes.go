@@ -0,0 +1,99 @@
+package sensors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Mode sensor IDs: VehicleOperatingMode (EV / HEV on plug-in hybrids) and
+// VehicleRunningMode (the selected drive mode).
+const (
+	OperatingModeSensorID = 67
+	DriveModeSensorID     = 68
+)
+
+// ModeUnknown is reported for codes without a name.
+const ModeUnknown = "unknown"
+
+// modeNames maps the numeric codes of the mode sensors to names. The
+// defaults follow the codes seen on DM-i models; cars that report others
+// can override them with SetModeNames.
+var modeNames = map[int]map[int]string{
+	OperatingModeSensorID: {1: "EV", 2: "HEV"},
+	DriveModeSensorID:     {1: "Eco", 2: "Normal", 3: "Sport", 4: "Snow"},
+}
+
+// ModeNames returns the code → name table of a mode sensor; ok is false for
+// other sensors.
+func ModeNames(id int) (map[int]string, bool) {
+	names, ok := modeNames[id]
+	return names, ok
+}
+
+// ModeOptions returns the names of a mode sensor in code order, followed by
+// ModeUnknown.
+func ModeOptions(id int) []string {
+	names := modeNames[id]
+	codes := make([]int, 0, len(names))
+	for code := range names {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	out := make([]string, 0, len(codes)+1)
+	for _, code := range codes {
+		out = append(out, names[code])
+	}
+	return append(out, ModeUnknown)
+}
+
+// ModeCode returns the code of a mode name (case-insensitive).
+func ModeCode(id int, name string) (int, bool) {
+	for code, n := range modeNames[id] {
+		if strings.EqualFold(n, name) {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// ParseModeNames parses overrides as "id=code:name,code:name;id=…", e.g.
+// "68=1:Eco,2:Sport,3:Normal".
+func ParseModeNames(spec string) (map[int]map[int]string, error) {
+	out := make(map[int]map[int]string)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		rawID, list, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("mode names %q: expected id=code:name,…", entry)
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(rawID))
+		if err != nil || modeNames[id] == nil {
+			return nil, fmt.Errorf("mode names %q: sensor must be %d or %d", entry, OperatingModeSensorID, DriveModeSensorID)
+		}
+		names := make(map[int]string)
+		for _, pair := range strings.Split(list, ",") {
+			rawCode, name, ok := strings.Cut(pair, ":")
+			code, err := strconv.Atoi(strings.TrimSpace(rawCode))
+			name = strings.TrimSpace(name)
+			if !ok || err != nil || name == "" || strings.ContainsAny(name, `'"\`) {
+				return nil, fmt.Errorf("mode names %q: invalid code:name pair %q", entry, pair)
+			}
+			names[code] = name
+		}
+		out[id] = names
+	}
+	return out, nil
+}
+
+// SetModeNames replaces the code tables of the given sensors. It must run
+// before discovery is published.
+func SetModeNames(overrides map[int]map[int]string) {
+	for id, names := range overrides {
+		modeNames[id] = names
+	}
+}
@@ -0,0 +1,89 @@
+package sensors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// SensorOverride corrects the discovery metadata of one AllSensors row. Only
+// the fields present in the overlay file are changed; an empty string clears
+// the value (e.g. "device_class": "").
+type SensorOverride struct {
+	Name        *string  `json:"name,omitempty"`
+	Category    *string  `json:"category,omitempty"`
+	DeviceClass *string  `json:"device_class,omitempty"`
+	Unit        *string  `json:"unit,omitempty"`
+	ScaleFactor *float64 `json:"scale_factor,omitempty"`
+}
+
+// OverlayFileName is the overlay looked up in the state directory when no
+// explicit path is configured.
+const OverlayFileName = "sensor_overlay.json"
+
+// LoadOverlay reads an overlay file keyed by sensor ID, e.g.
+//
+//	{"20": {"device_class": ""}, "53": {"unit": "kPa", "scale_factor": 1}}
+func LoadOverlay(path string) (map[int]SensorOverride, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sensor overlay: %w", err)
+	}
+	var byKey map[string]SensorOverride
+	if err := json.Unmarshal(raw, &byKey); err != nil {
+		return nil, fmt.Errorf("failed to parse sensor overlay %s: %w", path, err)
+	}
+	overlay := make(map[int]SensorOverride, len(byKey))
+	for key, o := range byKey {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("sensor overlay: key %q is not a sensor ID", key)
+		}
+		overlay[id] = o
+	}
+	return overlay, nil
+}
+
+// ApplyOverlay merges the overrides into AllSensors. It must run before
+// discovery is published.
+func ApplyOverlay(overlay map[int]SensorOverride) error {
+	index := make(map[int]int, len(AllSensors))
+	for i, s := range AllSensors {
+		index[s.ID] = i
+	}
+
+	// Validate everything first so a bad file leaves the table untouched.
+	for id, o := range overlay {
+		if _, ok := index[id]; !ok {
+			return fmt.Errorf("sensor overlay: unknown sensor ID %d", id)
+		}
+		if o.Category != nil && *o.Category != "sensor" && *o.Category != "binary_sensor" {
+			return fmt.Errorf("sensor overlay: sensor %d: category must be sensor or binary_sensor", id)
+		}
+		if o.ScaleFactor != nil && *o.ScaleFactor == 0 {
+			return fmt.Errorf("sensor overlay: sensor %d: scale_factor must not be 0", id)
+		}
+	}
+
+	for id, o := range overlay {
+		def := &AllSensors[index[id]]
+		if o.Name != nil {
+			def.EnglishName = *o.Name
+		}
+		if o.Category != nil {
+			def.Category = *o.Category
+		}
+		if o.DeviceClass != nil {
+			def.DeviceClass = *o.DeviceClass
+		}
+		if o.Unit != nil {
+			def.UnitOfMeasurement = *o.Unit
+		}
+		if o.ScaleFactor != nil {
+			def.ScaleFactor = *o.ScaleFactor
+		}
+	}
+	sensorIndex = buildFieldIndex()
+	return nil
+}
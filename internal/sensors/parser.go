@@ -38,6 +38,18 @@ func ParseAPIResponse(responseBody []byte) (*SensorData, error) {
 	return sensorData, nil
 }
 
+// RawValues returns the unparsed key → value pairs of an API response.
+func RawValues(responseBody []byte) (map[string]string, error) {
+	var apiResp APIResponse
+	if err := json.Unmarshal(responseBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal API response: %w", err)
+	}
+	if !apiResp.Success {
+		return nil, fmt.Errorf("API request failed: success=false")
+	}
+	return parseRawValues(apiResp.Val), nil
+}
+
 // parseValueString parses the pipe-separated key:value string from the API
 func parseValueString(valString string, sensorData *SensorData) error {
 	if valString == "" {
@@ -47,9 +59,6 @@ func parseValueString(valString string, sensorData *SensorData) error {
 	// Split by pipe separator
 	pairs := strings.Split(valString, "|")
 
-	// Use reflection to set struct fields
-	v := reflect.ValueOf(sensorData).Elem()
-
 	for _, pair := range pairs {
 		// Split key:value
 		parts := strings.SplitN(pair, ":", 2)
@@ -60,66 +69,38 @@ func parseValueString(valString string, sensorData *SensorData) error {
 		key := strings.TrimSpace(parts[0])
 		valueStr := strings.TrimSpace(parts[1])
 
-		// Lookup the struct field by the authoritative key directly; no fallback
-		// conversion is needed because Diplus now echoes back exactly what we
-		// requested.
-		field := v.FieldByName(key)
-		if !field.IsValid() || !field.CanSet() {
-			// Field not found or not settable; skip.
-			continue
-		}
-
-		// Determine scaling factor based on sensor metadata (defaults to 1)
-		scaleFactor := GetScaleFactor(ToSnakeCase(key))
-
-		// Parse the value and set the field with scaling applied where necessary
-		if err := setFieldValue(field, valueStr, scaleFactor); err != nil {
-			// Log error but continue with other fields
-			continue
-		}
+		// Diplus echoes back exactly the field names we requested, so the key
+		// selects the struct field directly. Unknown keys and unparsable values
+		// are skipped so one bad pair doesn't drop the whole poll.
+		setFieldValue(sensorData, key, normalizeNumericValue(valueStr))
 	}
 
 	return nil
 }
 
-// setFieldValue sets a reflect.Value field with the parsed string value
-func setFieldValue(field reflect.Value, valueStr string, scaleFactor float64) error {
-	// Normalize the value string for European formats
-	normalizedValue := normalizeNumericValue(valueStr)
-
-	// If the normalized value is empty, treat it as null/not present
-	if normalizedValue == "" {
-		return nil // Leave the pointer nil
+// setFieldValue stores value in the field called name, applying the sensor's
+// scale factor to numeric fields. An empty value leaves the field nil.
+func setFieldValue(data *SensorData, name, value string) {
+	if value == "" {
+		return
 	}
-	if field.Kind() != reflect.Ptr {
-		return fmt.Errorf("field is not a pointer")
+	if p := data.stringField(name); p != nil {
+		*p = &value
+		return
 	}
-
-	// Get the type of the pointer's element
-	elemType := field.Type().Elem()
-
-	// Create a new pointer to the element type
-	newVal := reflect.New(elemType)
-
-	switch elemType.Kind() {
-	case reflect.Float32, reflect.Float64:
-		floatVal, err := strconv.ParseFloat(normalizedValue, 64)
-		if err != nil {
-			return fmt.Errorf("failed to parse float value '%s': %w", normalizedValue, err)
-		}
-		newVal.Elem().SetFloat(floatVal * scaleFactor)
-	case reflect.String:
-		newVal.Elem().SetString(normalizedValue)
-	default:
-		// We currently only expect *float64 and *string fields in SensorData.
-		// Unknown types are ignored rather than treated as errors to keep the
-		// parser resilient to future struct changes.
-		return nil
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
 	}
-
-	field.Set(newVal)
-
-	return nil
+	f *= scaleFactorFor(name)
+	if p := data.floatField(name); p != nil {
+		*p = &f
+		return
+	}
+	if data.setCell(name, f) {
+		return
+	}
+	data.setExtra(name, f)
 }
 
 // normalizeNumericValue converts European number formats to standard formats
@@ -156,69 +137,34 @@ func GetAllSensorIDs() []int {
 	return ids
 }
 
-// ValidateSensorData performs basic validation on sensor data
-func ValidateSensorData(data *SensorData) []string {
-	var warnings []string
-
-	// Check for reasonable battery percentage
-	if data.BatteryPercentage != nil {
-		if *data.BatteryPercentage < 0 || *data.BatteryPercentage > 100 {
-			warnings = append(warnings, fmt.Sprintf("Battery percentage out of range: %.1f%%", *data.BatteryPercentage))
-		}
-	}
-
-	// Check for reasonable speed
-	if data.Speed != nil {
-		if *data.Speed < 0 || *data.Speed > 300 { // 300 km/h max reasonable speed
-			warnings = append(warnings, fmt.Sprintf("Speed out of reasonable range: %.1f km/h", *data.Speed))
-		}
-	}
-
-	// Check for reasonable temperatures
-	if data.CabinTemperature != nil {
-		if *data.CabinTemperature < -40 || *data.CabinTemperature > 80 {
-			warnings = append(warnings, fmt.Sprintf("Cabin temperature out of reasonable range: %.1f°C", *data.CabinTemperature))
-		}
-	}
-
-	if data.OutsideTemperature != nil {
-		if *data.OutsideTemperature < -50 || *data.OutsideTemperature > 60 {
-			warnings = append(warnings, fmt.Sprintf("Outside temperature out of reasonable range: %.1f°C", *data.OutsideTemperature))
-		}
-	}
-
-	return warnings
-}
-
 // GetNonNilFields returns a map of field names to values for all non-nil fields
 func GetNonNilFields(data *SensorData) map[string]interface{} {
 	result := make(map[string]interface{})
+	data.ForEachValue(func(key string, value interface{}) {
+		result[key] = value
+	})
+	if data.Location != nil {
+		result["location"] = *data.Location
+	}
+	return result
+}
 
-	v := reflect.ValueOf(data).Elem()
-	t := reflect.TypeOf(data).Elem()
-
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Field(i)
-		fieldType := t.Field(i)
-
-		// Skip timestamp field
-		if fieldType.Name == "Timestamp" {
-			continue
-		}
-
-		// Check if pointer field is not nil
-		if field.Kind() == reflect.Ptr && !field.IsNil() {
-			jsonTag := fieldType.Tag.Get("json")
-			if jsonTag != "" {
-				// Extract field name from json tag
-				tagParts := strings.Split(jsonTag, ",")
-				fieldName := tagParts[0]
-				result[fieldName] = field.Elem().Interface()
+// MergeSensorData copies every non-nil field of src into dst where dst has no
+// value yet, e.g. to complete a fast-tier poll with the latest slow-tier one.
+func MergeSensorData(dst, src *SensorData) {
+	if dst == nil || src == nil {
+		return
+	}
+	dst.mergeMissing(src)
+	mergeCells(dst, src)
+	for id, v := range src.Extra {
+		if _, ok := dst.Extra[id]; !ok {
+			if dst.Extra == nil {
+				dst.Extra = make(map[int]float64, len(src.Extra))
 			}
+			dst.Extra[id] = v
 		}
 	}
-
-	return result
 }
 
 // CompareRawVsParsed compares the raw API response map with the parsed SensorData struct.
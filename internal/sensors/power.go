@@ -0,0 +1,95 @@
+package sensors
+
+import "sync"
+
+// Power sign conventions for EnginePower. Most cars report power flowing
+// into the battery as negative; some report charging as positive.
+const (
+	PowerSignAuto     = "auto"     // learn the sign from the first charge
+	PowerSignNormal   = "normal"   // negative while charging
+	PowerSignInverted = "inverted" // positive while charging
+)
+
+// ValidPowerSign reports whether s is a known power sign convention.
+func ValidPowerSign(s string) bool {
+	return s == PowerSignAuto || s == PowerSignNormal || s == PowerSignInverted
+}
+
+// PowerCalibration turns EnginePower into the normal convention: kW,
+// negative while charging.
+//
+// In auto mode the sign is learned while the charge gun is connected and
+// the car is parked: once the SoC has risen, power that was mostly positive
+// until then means the car reports charging as positive. Until that happens
+// the normal convention is assumed. The SoC is the judge because a parked
+// car on the cable may also draw power for climate or a full battery.
+type PowerCalibration struct {
+	mu       sync.Mutex
+	mode     string
+	scale    float64
+	inverted bool
+	learned  bool
+
+	baseSoC *float64 // SoC when the gun was connected
+	sum     float64  // raw power summed since then
+}
+
+// NewPowerCalibration returns a calibration for the sign convention mode
+// (one of the PowerSign constants) that multiplies power by scale first.
+func NewPowerCalibration(mode string, scale float64) *PowerCalibration {
+	if scale == 0 {
+		scale = 1
+	}
+	return &PowerCalibration{mode: mode, scale: scale, inverted: mode == PowerSignInverted}
+}
+
+// Power returns the calibrated EnginePower of data, nil when unknown. learned
+// is true for the call that settles the sign in auto mode.
+func (c *PowerCalibration) Power(data *SensorData) (kw *float64, learned bool) {
+	if data == nil || data.EnginePower == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := *data.EnginePower * c.scale
+	if c.mode == PowerSignAuto && !c.learned {
+		learned = c.learn(data, p)
+	}
+	if c.inverted {
+		p = -p
+	}
+	return &p, learned
+}
+
+// Inverted reports whether the car reports charging as positive power.
+func (c *PowerCalibration) Inverted() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inverted
+}
+
+func (c *PowerCalibration) learn(data *SensorData, p float64) bool {
+	connected := data.ChargeGunState != nil && *data.ChargeGunState == 2
+	parked := data.Speed == nil || *data.Speed == 0
+	if !connected || !parked || data.BatteryPercentage == nil {
+		c.baseSoC, c.sum = nil, 0
+		return false
+	}
+	soc := *data.BatteryPercentage
+	if c.baseSoC == nil {
+		c.baseSoC = &soc
+		return false
+	}
+	if soc < *c.baseSoC {
+		// Draining on the cable; start over from here.
+		c.baseSoC, c.sum = &soc, 0
+		return false
+	}
+	c.sum += p
+	if soc < *c.baseSoC+1 || c.sum == 0 {
+		return false
+	}
+	c.inverted = c.sum > 0
+	c.learned = true
+	return true
+}
@@ -0,0 +1,77 @@
+package sensors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultSensorProfile is used when neither a profile nor explicit sensor IDs
+// are configured.
+const DefaultSensorProfile = "standard"
+
+// sensorProfiles are curated MonitoredSensors presets so new users get useful
+// data without learning the numeric ID scheme. "everything" is built from
+// AllSensors on demand.
+var sensorProfiles = map[string][]MonitoredSensor{
+	// Just enough for state of charge, driving and charging state.
+	"minimal": {
+		{ID: 33, Publish: true}, // BatteryPercentage
+		{ID: 2, Publish: true},  // Speed
+		{ID: 3, Publish: true},  // Mileage
+		{ID: 10, Publish: true}, // EnginePower
+		{ID: 12, Publish: false},
+	},
+
+	// The long-standing default set.
+	"standard": defaultMonitoredSensors,
+
+	// standard plus the combustion side of DM-i / DM-p hybrids.
+	"phev": {
+		{ID: 33, Publish: true},  // BatteryPercentage
+		{ID: 34, Publish: true},  // FuelPercentage
+		{ID: 2, Publish: true},   // Speed
+		{ID: 3, Publish: true},   // Mileage
+		{ID: 53, Publish: true},  // LF tire
+		{ID: 54, Publish: true},  // RF tire
+		{ID: 55, Publish: true},  // LR tire
+		{ID: 56, Publish: true},  // RR tire
+		{ID: 10, Publish: true},  // EnginePower
+		{ID: 26, Publish: true},  // OutsideTemp
+		{ID: 25, Publish: true},  // CabinTemp
+		{ID: 5, Publish: true},   // EngineRPM
+		{ID: 35, Publish: true},  // TotalFuelConsumption
+		{ID: 87, Publish: true},  // FuelTankCap
+		{ID: 108, Publish: true}, // EngineWaterTemperature
+
+		// Internal-only
+		{ID: 12, Publish: false},
+	},
+}
+
+// SensorProfileNames returns the available profile names, sorted.
+func SensorProfileNames() []string {
+	names := []string{"everything"}
+	for name := range sensorProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SensorProfile returns a copy of the named profile's sensor list.
+func SensorProfile(name string) ([]MonitoredSensor, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "everything" {
+		list := make([]MonitoredSensor, 0, len(AllSensors))
+		for _, def := range AllSensors {
+			list = append(list, MonitoredSensor{ID: def.ID, Publish: true})
+		}
+		return list, nil
+	}
+	profile, ok := sensorProfiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown sensor profile %q (available: %s)", name, strings.Join(SensorProfileNames(), ", "))
+	}
+	return append([]MonitoredSensor(nil), profile...), nil
+}
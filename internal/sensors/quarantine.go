@@ -0,0 +1,220 @@
+package sensors
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Validation policies for out-of-range readings. Sentinel values are always
+// dropped unless the policy is PolicyWarn.
+const (
+	PolicyWarn  = "warn"  // log only, keep the value
+	PolicyDrop  = "drop"  // remove the value from the snapshot
+	PolicyClamp = "clamp" // pull the value back into range
+)
+
+// ValidPolicy reports whether p is a known validation policy.
+func ValidPolicy(p string) bool {
+	return p == PolicyWarn || p == PolicyDrop || p == PolicyClamp
+}
+
+// ValidationRule is the plausible range of a sensor and what to do with
+// readings outside it. An empty Policy uses the global validation policy.
+type ValidationRule struct {
+	Min, Max float64 // ±Inf for an open bound
+	Policy   string
+}
+
+// defaultRules bounds the sensors whose bogus readings are easy to spot.
+var defaultRules = map[int]ValidationRule{
+	33: {Min: 0, Max: 100},  // BatteryPercentage
+	34: {Min: 0, Max: 100},  // FuelPercentage
+	2:  {Min: 0, Max: 300},  // Speed (km/h)
+	25: {Min: -40, Max: 80}, // CabinTemperature
+	26: {Min: -50, Max: 60}, // OutsideTemperature
+	14: {Min: -40, Max: 90}, // MaxBatteryTemp
+	15: {Min: -40, Max: 90}, // AvgBatteryTemp
+	16: {Min: -40, Max: 90}, // MinBatteryTemp
+	53: {Min: 0, Max: 6},    // LeftFrontTirePressure (bar)
+	54: {Min: 0, Max: 6},    // RightFrontTirePressure
+	55: {Min: 0, Max: 6},    // LeftRearTirePressure
+	56: {Min: 0, Max: 6},    // RightRearTirePressure
+}
+
+// validationRules are the rules in effect: defaultRules with the user's
+// overrides applied (SetValidationRules).
+var validationRules = defaultRules
+
+// ParseValidationRules parses "key=min..max[:policy]" entries separated by
+// ';', e.g. "speed=0..250;cabin_temperature=-30..70:clamp". Either bound may
+// be left out ("..250"); "key=off" disables the rule for that sensor.
+func ParseValidationRules(spec string) (map[int]*ValidationRule, error) {
+	rules := make(map[int]*ValidationRule)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, rest, ok := strings.Cut(entry, "=")
+		key, rest = strings.TrimSpace(key), strings.TrimSpace(rest)
+		if !ok {
+			return nil, fmt.Errorf("validation rule %q: expected key=min..max[:policy]", entry)
+		}
+		def := GetSensorByKey(key)
+		if def == nil {
+			return nil, fmt.Errorf("validation rule %q: unknown sensor", key)
+		}
+		if _, dup := rules[def.ID]; dup {
+			return nil, fmt.Errorf("validation rule %q: defined more than once", key)
+		}
+		if rest == "off" {
+			rules[def.ID] = nil
+			continue
+		}
+
+		bounds, policy, _ := strings.Cut(rest, ":")
+		lo, hi, ok := strings.Cut(bounds, "..")
+		if !ok {
+			return nil, fmt.Errorf("validation rule %q: expected min..max", key)
+		}
+		rule := &ValidationRule{Min: math.Inf(-1), Max: math.Inf(1), Policy: strings.TrimSpace(policy)}
+		for _, b := range []struct {
+			text string
+			dst  *float64
+		}{{lo, &rule.Min}, {hi, &rule.Max}} {
+			if t := strings.TrimSpace(b.text); t != "" {
+				v, err := strconv.ParseFloat(t, 64)
+				if err != nil {
+					return nil, fmt.Errorf("validation rule %q: invalid bound %q", key, t)
+				}
+				*b.dst = v
+			}
+		}
+		if rule.Min > rule.Max {
+			return nil, fmt.Errorf("validation rule %q: min is above max", key)
+		}
+		if rule.Policy != "" && !ValidPolicy(rule.Policy) {
+			return nil, fmt.Errorf("validation rule %q: policy must be %s, %s or %s", key, PolicyWarn, PolicyDrop, PolicyClamp)
+		}
+		rules[def.ID] = rule
+	}
+	return rules, nil
+}
+
+// SetValidationRules applies overrides from ParseValidationRules on top of
+// the default rules; a nil rule removes the default.
+func SetValidationRules(overrides map[int]*ValidationRule) {
+	rules := make(map[int]ValidationRule, len(defaultRules)+len(overrides))
+	for id, r := range defaultRules {
+		rules[id] = r
+	}
+	for id, r := range overrides {
+		if r == nil {
+			delete(rules, id)
+		} else {
+			rules[id] = *r
+		}
+	}
+	validationRules = rules
+}
+
+// sentinels are what the car reports for unavailable 16-bit values once
+// scaled (0xFFFF and 0x8000 at the usual scale factors), e.g. a tire
+// pressure of 655.35 or a temperature of -3276.8.
+var sentinels = []float64{65535, 6553.5, 655.35, 65.535, -32768, -3276.8, -327.68}
+
+// counterUnits mark running totals (odometer, energy and fuel counters),
+// which legitimately pass through the sentinel values.
+var counterUnits = map[string]bool{"km": true, "kWh": true, "L": true}
+
+var (
+	validationPolicy = PolicyDrop
+	rejectedTotal    atomic.Uint64
+)
+
+// SetValidationPolicy sets the policy applied by Quarantine.
+func SetValidationPolicy(p string) {
+	validationPolicy = p
+}
+
+// RejectedValues returns how many readings Quarantine dropped or clamped
+// since start.
+func RejectedValues() uint64 {
+	return rejectedTotal.Load()
+}
+
+// Rejection describes one reading Quarantine acted on.
+type Rejection struct {
+	Key    string
+	Value  float64
+	Reason string
+	Action string // the policy applied
+}
+
+func (r Rejection) String() string {
+	return fmt.Sprintf("%s=%g %s (%s)", r.Key, r.Value, r.Reason, r.Action)
+}
+
+// Quarantine checks every numeric reading for sentinel values and the
+// validation rules, and applies the rule's (or the global) policy so bogus values never
+// reach Home Assistant history or ABRP. It returns what it found.
+func Quarantine(data *SensorData) []Rejection {
+	if data == nil {
+		return nil
+	}
+	var found []Rejection
+	for _, def := range AllSensors {
+		v, ok := data.ValueByID(def.ID)
+		if !ok {
+			continue
+		}
+		key := sensorIndex.keyByID[def.ID]
+
+		if isSentinel(v) && !counterUnits[def.UnitOfMeasurement] {
+			r := Rejection{Key: key, Value: v, Reason: "is an unavailable-value marker", Action: PolicyDrop}
+			if validationPolicy == PolicyWarn {
+				r.Action = PolicyWarn
+			} else {
+				data.ClearByID(def.ID)
+			}
+			found = append(found, r)
+			continue
+		}
+
+		rule, ok := validationRules[def.ID]
+		if !ok || (v >= rule.Min && v <= rule.Max) {
+			continue
+		}
+		policy := rule.Policy
+		if policy == "" {
+			policy = validationPolicy
+		}
+		r := Rejection{Key: key, Value: v, Reason: fmt.Sprintf("outside %g..%g", rule.Min, rule.Max), Action: policy}
+		switch policy {
+		case PolicyDrop:
+			data.ClearByID(def.ID)
+		case PolicyClamp:
+			data.SetByID(def.ID, math.Max(rule.Min, math.Min(rule.Max, v)))
+		}
+		found = append(found, r)
+	}
+
+	for _, r := range found {
+		if r.Action != PolicyWarn {
+			rejectedTotal.Add(1)
+		}
+	}
+	return found
+}
+
+func isSentinel(v float64) bool {
+	for _, s := range sentinels {
+		if math.Abs(v-s) < 1e-9*math.Max(1, math.Abs(s)) {
+			return true
+		}
+	}
+	return false
+}
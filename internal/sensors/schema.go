@@ -0,0 +1,28 @@
+package sensors
+
+// SchemaVersion identifies the layout of the MQTT state and event payloads.
+// It is published in the SchemaField of every payload and only changes when
+// a key is renamed or removed or a value changes meaning; new keys are added
+// without a bump. See docs/payload-schema.md.
+const SchemaVersion = 1
+
+// SchemaField is the payload key carrying SchemaVersion.
+const SchemaField = "schema"
+
+// LegacyKeys maps current state keys to the names they were published under
+// before being renamed. In compatibility mode the value is published under
+// both names so existing templates and consumers keep working. Only list
+// keys that were actually published under the old name; no state key has
+// been renamed since schema 1.
+var LegacyKeys = map[string]string{}
+
+// AddLegacyKeys copies every renamed value in state to its former key.
+func AddLegacyKeys(state map[string]interface{}) {
+	for key, legacy := range LegacyKeys {
+		if v, ok := state[key]; ok {
+			if _, taken := state[legacy]; !taken {
+				state[legacy] = v
+			}
+		}
+	}
+}
@@ -1,15 +1,17 @@
 package sensors
 
 import (
-    "os"
-    "strings"
-    "strconv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
 )
 
 // MonitoredSensor represents a sensor that we (a) poll from Diplus and (b)
 // may expose to downstream integrations such as MQTT / ABRP / REST.
 //
-// • Every entry is included in each Diplus request (see PollSensorIDs).
+// • Every entry is polled from Diplus, either on the fast or the slow tier
+//   (see FastPollSensorIDs / SlowPollSensorIDs).
 // • If Publish == true the raw value is allowed to leave the application –
 //   currently that means it will appear in MQTT discovery/state payloads.
 //   When we add other outputs (Prometheus, REST, etc.) they will consult the
@@ -21,7 +23,7 @@ import (
 //   1. Make sure it exists in sensors.AllSensors with a unique ID.
 //   2. Append its ID to "BYD_HASS_SENSOR_IDS" env, choosing Publish=true/false
 //      in such manner: "ID:publish" for example "33:0,34:1", this will publish
-//      id 34, and read but not publish id 33, you can omit ":1" as publish is 
+//      id 34, and read but not publish id 33, you can omit ":1" as publish is
 //      the default, so you can write use "33,34:1" with the same effect
 //   3. No other lists need editing.
 
@@ -49,8 +51,17 @@ var defaultMonitoredSensors = []MonitoredSensor{
 	{ID: 26, Publish: true}, // OutsideTemp
 	{ID: 25, Publish: true}, // CabinTemp
 
+	// Plug-in hybrids; capability detection drops them on pure EVs
+	{ID: 35, Publish: true},  // TotalFuelConsumption
+	{ID: 108, Publish: true}, // EngineWaterTemperature
+
 	// Internal-only
 	{ID: 12, Publish: false},
+	{ID: 5, Publish: false},  // EngineRPM → engine_running
+	{ID: 69, Publish: false}, // Month  ┐
+	{ID: 70, Publish: false}, // Day    │ fused into car_clock
+	{ID: 71, Publish: false}, // Hour   │
+	{ID: 72, Publish: false}, // Minute ┘
 }
 
 // Global value initialized at startup
@@ -64,8 +75,22 @@ func loadMonitoredSensorsFromEnv() []MonitoredSensor {
 		return defaultMonitoredSensors
 	}
 
+	// Invalid specs are rejected by config validation at startup; fall back to
+	// the defaults here so package initialisation never fails.
+	sensorsList, err := ParseSensorIDSpec(raw)
+	if err != nil || len(sensorsList) == 0 {
+		return defaultMonitoredSensors
+	}
+
+	return sensorsList
+}
+
+// ParseSensorIDSpec parses the "id:publish,id,..." format. Every ID must exist
+// in AllSensors and the publish flag, when present, must be 0 or 1.
+func ParseSensorIDSpec(raw string) ([]MonitoredSensor, error) {
 	parts := strings.Split(raw, ",")
 	sensorsList := make([]MonitoredSensor, 0, len(parts))
+	seen := make(map[int]bool)
 
 	for _, p := range parts {
 		p = strings.TrimSpace(p)
@@ -80,27 +105,71 @@ func loadMonitoredSensorsFromEnv() []MonitoredSensor {
 		if strings.Contains(p, ":") {
 			pieces := strings.SplitN(p, ":", 2)
 			idStr = pieces[0]
-			if pieces[1] == "0" {
+			switch pieces[1] {
+			case "0":
 				publish = false
+			case "1":
+			default:
+				return nil, fmt.Errorf("entry %q: publish flag must be 0 or 1", p)
 			}
 		}
 
-		id, err := strconv.Atoi(idStr)
+		id, err := strconv.Atoi(strings.TrimSpace(idStr))
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("entry %q: %q is not a number", p, idStr)
+		}
+		if GetSensorByID(id) == nil {
+			return nil, fmt.Errorf("entry %q: unknown sensor ID %d (see AllSensors in internal/sensors/types.go)", p, id)
 		}
+		if seen[id] {
+			return nil, fmt.Errorf("entry %q: sensor ID %d listed more than once", p, id)
+		}
+		seen[id] = true
 
 		sensorsList = append(sensorsList, MonitoredSensor{
-			ID:	  id,
+			ID:      id,
 			Publish: publish,
 		})
 	}
 
 	if len(sensorsList) == 0 {
-		return defaultMonitoredSensors
+		return nil, fmt.Errorf("no sensor IDs given")
 	}
 
-	return sensorsList
+	return sensorsList, nil
+}
+
+// featureSensorIDs are the sensors registered through EnsureMonitored, which
+// capability detection never drops.
+var featureSensorIDs = map[int]bool{}
+
+// EnsureMonitored adds the given IDs as internal-only (Publish=false) entries
+// unless they are already monitored. Features that depend on specific sensors
+// call this at startup so users don't have to edit BYD_HASS_SENSOR_IDS.
+func EnsureMonitored(ids ...int) {
+	for _, id := range ids {
+		featureSensorIDs[id] = true
+		found := false
+		for _, s := range MonitoredSensors {
+			if s.ID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			MonitoredSensors = append(MonitoredSensors, MonitoredSensor{ID: id, Publish: false})
+		}
+	}
+}
+
+// IsPublished reports whether the sensor ID is monitored with Publish=true.
+func IsPublished(id int) bool {
+	for _, s := range MonitoredSensors {
+		if s.ID == id && s.Publish {
+			return true
+		}
+	}
+	return false
 }
 
 // PollSensorIDs returns every sensor ID we must include in the Diplus API
@@ -113,6 +182,54 @@ func PollSensorIDs() []int {
 	return ids
 }
 
+// fastSensorIDs are polled every DiplusPollInterval: the sensors ABRP and the
+// charge/drive state detection can't work without, plus anything registered
+// through EnsureFastPolled. Every other monitored sensor changes slowly (tire
+// pressure, windows, radar…) and is polled on the slow tier only.
+var fastSensorIDs = map[int]bool{
+	2:  true, // Speed
+	3:  true, // Mileage
+	10: true, // EnginePower
+	12: true, // ChargeGunState
+	33: true, // BatteryPercentage
+
+	// The car clock is compared with the snapshot time; a slow-tier value
+	// would be stale by up to the slow interval.
+	69: true, 70: true, 71: true, 72: true,
+}
+
+// EnsureFastPolled is EnsureMonitored for sensors that must also be on the
+// fast polling tier, e.g. those driving real-time alerts.
+func EnsureFastPolled(ids ...int) {
+	EnsureMonitored(ids...)
+	for _, id := range ids {
+		fastSensorIDs[id] = true
+	}
+}
+
+// FastPollSensorIDs returns the monitored IDs on the fast polling tier. With
+// extended polling disabled these are the only sensors requested.
+func FastPollSensorIDs() []int {
+	ids := make([]int, 0, len(fastSensorIDs))
+	for _, s := range MonitoredSensors {
+		if fastSensorIDs[s.ID] {
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids
+}
+
+// SlowPollSensorIDs returns the monitored IDs on the slow polling tier.
+func SlowPollSensorIDs() []int {
+	ids := make([]int, 0, len(MonitoredSensors))
+	for _, s := range MonitoredSensors {
+		if !fastSensorIDs[s.ID] {
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids
+}
+
 // PublishedSensorIDs returns only the IDs whose Publish flag is true.
 func PublishedSensorIDs() []int {
 	ids := make([]int, 0, len(MonitoredSensors))
@@ -138,11 +255,12 @@ func PublishedSensorIDs() []int {
 //   10  EnginePower         (power, is_charging, is_dcfc)
 //   12  ChargeGunState      (is_charging, is_dcfc)
 //   15  AvgBatteryTemp      (batt_temp)
-//   17  MaxBatteryVoltage   (voltage / current)
+//   17  MaxBatteryVoltage   (voltage / current, is_dcfc)
 //   25  CabinTemperature    (cabin_temp)
 //   26  OutsideTemperature  (ext_temp)
 //   29  BatteryCapacity     (capacity, soe)
 //   53-56 TirePressures LF/RF/LR/RR (tire_pressure_* – converted to kPa)
+//   27  DriverACTemperature (hvac_power)
 //   77  ACStatus            (hvac_power)
 //   78  FanSpeedLevel       (hvac_power)
 // -----------------------------------------------------------------------------
@@ -0,0 +1,163 @@
+package sensors
+
+import "sort"
+
+// fieldIndex caches per-sensor lookups for the parse and publish hot paths.
+// It is rebuilt whenever AllSensors changes (see ApplyOverlay).
+type fieldIndex struct {
+	scale    map[string]float64 // FieldName → scale factor, only if not 1
+	extraIDs map[string]int     // FieldName → ID, sensors without a struct field
+	keyByID  map[int]string     // ID → snake_case state key
+}
+
+var sensorIndex = buildFieldIndex()
+
+func buildFieldIndex() fieldIndex {
+	idx := fieldIndex{
+		scale:    make(map[string]float64, len(AllSensors)),
+		extraIDs: make(map[string]int),
+		keyByID:  make(map[int]string, len(AllSensors)),
+	}
+	var probe SensorData
+	for _, s := range AllSensors {
+		if s.ScaleFactor != 0 && s.ScaleFactor != 1 {
+			idx.scale[s.FieldName] = s.ScaleFactor // last match wins
+		}
+		if probe.floatField(s.FieldName) == nil && probe.stringField(s.FieldName) == nil {
+			idx.extraIDs[s.FieldName] = s.ID
+		}
+		idx.keyByID[s.ID] = ToSnakeCase(s.FieldName)
+	}
+	return idx
+}
+
+// scaleFactorFor returns the scaling factor for a sensor field name.
+func scaleFactorFor(fieldName string) float64 {
+	if f, ok := sensorIndex.scale[fieldName]; ok {
+		return f
+	}
+	return 1
+}
+
+// ValueByID returns the numeric value of the sensor with the given ID.
+func (d *SensorData) ValueByID(id int) (float64, bool) {
+	if p := d.floatFieldByID(id); p != nil {
+		if *p == nil {
+			return 0, false
+		}
+		return **p, true
+	}
+	v, ok := d.Extra[id]
+	return v, ok
+}
+
+// BoolByID returns a binary sensor's value; any non-zero value is true.
+func (d *SensorData) BoolByID(id int) (value, ok bool) {
+	v, ok := d.ValueByID(id)
+	return v != 0, ok
+}
+
+// StringByID returns the value of a text sensor with the given ID.
+func (d *SensorData) StringByID(id int) (string, bool) {
+	if p := d.stringFieldByID(id); p != nil && *p != nil {
+		return **p, true
+	}
+	return "", false
+}
+
+// IsTextSensor reports whether the sensor with the given ID holds a string
+// rather than a number.
+func IsTextSensor(id int) bool {
+	var probe SensorData
+	return probe.stringFieldByID(id) != nil
+}
+
+// HasID reports whether the sensor with the given ID has a value.
+func (d *SensorData) HasID(id int) bool {
+	if _, ok := d.ValueByID(id); ok {
+		return true
+	}
+	_, ok := d.StringByID(id)
+	return ok
+}
+
+// SetByID stores a numeric value for the sensor with the given ID. It
+// reports false for unknown IDs and text sensors.
+func (d *SensorData) SetByID(id int, v float64) bool {
+	if p := d.floatFieldByID(id); p != nil {
+		*p = &v
+		return true
+	}
+	if d.stringFieldByID(id) != nil {
+		return false
+	}
+	if _, known := sensorIndex.keyByID[id]; !known {
+		return false
+	}
+	if d.Extra == nil {
+		d.Extra = make(map[int]float64)
+	}
+	d.Extra[id] = v
+	return true
+}
+
+// ClearByID removes the numeric value of the sensor with the given ID.
+func (d *SensorData) ClearByID(id int) {
+	if p := d.floatFieldByID(id); p != nil {
+		*p = nil
+		return
+	}
+	delete(d.Extra, id)
+}
+
+// setExtra stores a parsed value for a table sensor without a struct field.
+func (d *SensorData) setExtra(fieldName string, v float64) bool {
+	id, ok := sensorIndex.extraIDs[fieldName]
+	if !ok {
+		return false
+	}
+	if d.Extra == nil {
+		d.Extra = make(map[int]float64)
+	}
+	d.Extra[id] = v
+	return true
+}
+
+// forEachExtra calls fn for the Extra values in ID order so payloads stay
+// deterministic.
+func (d *SensorData) forEachExtra(fn func(key string, value interface{})) {
+	if len(d.Extra) == 0 {
+		return
+	}
+	ids := make([]int, 0, len(d.Extra))
+	for id := range d.Extra {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		if key, ok := sensorIndex.keyByID[id]; ok {
+			fn(key, d.Extra[id])
+		}
+	}
+}
+
+// zipExtra is the Extra part of ZipFloats.
+func zipExtra(a, b *SensorData, fn func(key string, a, b *float64) bool) bool {
+	lookup := func(m map[int]float64, id int) *float64 {
+		if v, ok := m[id]; ok {
+			return &v
+		}
+		return nil
+	}
+	for id := range a.Extra {
+		if !fn(sensorIndex.keyByID[id], lookup(a.Extra, id), lookup(b.Extra, id)) {
+			return false
+		}
+	}
+	for id := range b.Extra {
+		if _, seen := a.Extra[id]; !seen && !fn(sensorIndex.keyByID[id], nil, lookup(b.Extra, id)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package sensors
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// validDeviceClasses lists the Home Assistant device classes accepted per
+// platform. An empty device class is always allowed.
+var validDeviceClasses = map[string]map[string]bool{
+	"sensor": setOf("apparent_power", "aqi", "atmospheric_pressure", "battery",
+		"carbon_dioxide", "carbon_monoxide", "current", "data_rate", "data_size",
+		"date", "distance", "duration", "energy", "energy_storage", "enum",
+		"frequency", "gas", "humidity", "illuminance", "irradiance", "moisture",
+		"monetary", "nitrogen_dioxide", "nitrogen_monoxide", "nitrous_oxide",
+		"ozone", "ph", "pm1", "pm10", "pm25", "power", "power_factor",
+		"precipitation", "precipitation_intensity", "pressure", "reactive_power",
+		"signal_strength", "sound_pressure", "speed", "sulphur_dioxide",
+		"temperature", "timestamp", "volatile_organic_compounds",
+		"volatile_organic_compounds_parts", "voltage", "volume",
+		"volume_flow_rate", "volume_storage", "water", "weight", "wind_speed"),
+	"binary_sensor": setOf("battery", "battery_charging", "carbon_monoxide",
+		"cold", "connectivity", "door", "garage_door", "gas", "heat", "light",
+		"lock", "moisture", "motion", "moving", "occupancy", "opening", "plug",
+		"power", "presence", "problem", "running", "safety", "smoke", "sound",
+		"tamper", "update", "vibration", "window"),
+}
+
+func setOf(items ...string) map[string]bool {
+	m := make(map[string]bool, len(items))
+	for _, it := range items {
+		m[it] = true
+	}
+	return m
+}
+
+// VerifyTable cross-checks AllSensors against SensorData and Home Assistant's
+// rules. It returns one message per problem (the table is inconsistent unless
+// problems is empty) and informational warnings that don't break anything.
+func VerifyTable() (problems, warnings []string) {
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	dataType := reflect.TypeOf(SensorData{})
+	ids := make(map[int]string)
+	fields := make(map[string]int)
+	labels := make(map[string]int)
+
+	for _, def := range AllSensors {
+		if other, dup := ids[def.ID]; dup {
+			report("ID %d: used by both %s and %s", def.ID, other, def.FieldName)
+		}
+		ids[def.ID] = def.FieldName
+
+		if other, dup := fields[def.FieldName]; dup {
+			report("ID %d: field %s already used by ID %d", def.ID, def.FieldName, other)
+		}
+		fields[def.FieldName] = def.ID
+
+		if other, dup := labels[def.ChineseName]; dup {
+			report("ID %d: Chinese label %q already used by ID %d", def.ID, def.ChineseName, other)
+		}
+		labels[def.ChineseName] = def.ID
+
+		// Rows without a SensorData field are stored in SensorData.Extra.
+		field, ok := dataType.FieldByName(def.FieldName)
+		switch {
+		case !ok:
+		case field.Type.Kind() != reflect.Ptr:
+			report("ID %d: SensorData.%s must be a pointer", def.ID, def.FieldName)
+		default:
+			// The state payload is keyed by JSON tag while discovery and the
+			// publish filter use ToSnakeCase(FieldName); they must agree.
+			tag := strings.Split(field.Tag.Get("json"), ",")[0]
+			if want := ToSnakeCase(def.FieldName); tag != want {
+				report("ID %d: SensorData.%s has JSON key %q, expected %q", def.ID, def.FieldName, tag, want)
+			}
+		}
+
+		classes, ok := validDeviceClasses[def.Category]
+		if !ok {
+			report("ID %d: invalid category %q", def.ID, def.Category)
+		} else if def.DeviceClass != "" && !classes[def.DeviceClass] {
+			report("ID %d: %q is not a valid %s device class", def.ID, def.DeviceClass, def.Category)
+		}
+
+		if def.ScaleFactor == 0 {
+			report("ID %d: scale factor must not be 0", def.ID)
+		}
+	}
+
+	// Fields without a table row can never be polled; that is allowed for
+	// fields filled by other means, so only warn.
+	var orphans []string
+	for i := 0; i < dataType.NumField(); i++ {
+		f := dataType.Field(i)
+		if f.Name == "Timestamp" || f.Name == "Location" || f.Name == "Extra" || f.Name == "Cells" {
+			continue
+		}
+		if _, ok := fields[f.Name]; !ok {
+			orphans = append(orphans, f.Name)
+		}
+	}
+	sort.Strings(orphans)
+	for _, name := range orphans {
+		warnings = append(warnings, fmt.Sprintf("SensorData.%s has no AllSensors entry", name))
+	}
+
+	return problems, warnings
+}
@@ -0,0 +1,103 @@
+package sentry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// SensorIDs lists the Diplus sensors the watcher needs to see.
+var SensorIDs = []int{1010, 1011}
+
+// MaxImageSize caps how much of a snapshot we are willing to push over MQTT.
+const MaxImageSize = 5 * 1024 * 1024
+
+// Trigger describes a new sentry alert reported by the head unit.
+type Trigger struct {
+	Time      time.Time // zero if the head unit reported an unparseable value
+	ImagePath string    // may be empty if no snapshot was stored
+}
+
+// Watcher detects changes to LastSentryTriggerTime between snapshots. It is
+// safe for concurrent use.
+type Watcher struct {
+	mu      sync.Mutex
+	last    float64
+	started bool
+}
+
+// NewWatcher returns a ready-to-use Watcher.
+func NewWatcher() *Watcher { return &Watcher{} }
+
+// Update inspects a snapshot and returns a Trigger when the trigger time has
+// moved since the previous snapshot. The first value seen only establishes
+// the baseline so restarts don't replay the last alert.
+func (w *Watcher) Update(data *sensors.SensorData) *Trigger {
+	if data == nil || data.LastSentryTriggerTime == nil {
+		return nil
+	}
+	cur := *data.LastSentryTriggerTime
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.started {
+		w.started = true
+		w.last = cur
+		return nil
+	}
+	if cur == w.last || cur <= 0 {
+		return nil
+	}
+	w.last = cur
+
+	tr := &Trigger{Time: ParseEpoch(cur)}
+	if data.LastSentryTriggerImage != nil {
+		tr.ImagePath = *data.LastSentryTriggerImage
+	}
+	return tr
+}
+
+// ParseEpoch converts a Diplus epoch value (seconds or milliseconds) to a
+// time. Non-positive values yield the zero time.
+func ParseEpoch(v float64) time.Time {
+	switch {
+	case v <= 0:
+		return time.Time{}
+	case v > 1e12:
+		return time.UnixMilli(int64(v))
+	default:
+		return time.Unix(int64(v), 0)
+	}
+}
+
+// ReadImage loads a sentry snapshot from storage and returns its bytes and
+// MIME type.
+func ReadImage(path string) ([]byte, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot stat sentry image: %w", err)
+	}
+	if info.Size() > MaxImageSize {
+		return nil, "", fmt.Errorf("sentry image too large (%d bytes)", info.Size())
+	}
+
+	img, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot read sentry image: %w", err)
+	}
+
+	contentType := "image/jpeg"
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		contentType = "image/png"
+	case ".webp":
+		contentType = "image/webp"
+	}
+	return img, contentType, nil
+}
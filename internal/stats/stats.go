@@ -0,0 +1,169 @@
+// Package stats accumulates distance driven and energy used per calendar
+// day, week and month from the odometer and total energy counters, and
+// persists the running totals so restarts don't lose them.
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// SensorIDs are the sensors the tracker needs: Mileage and
+// TotalPowerConsumption.
+var SensorIDs = []int{3, 32}
+
+// FileName is the statistics file kept in the state directory.
+const FileName = "statistics.json"
+
+// Implausible jumps between two snapshots (e.g. a glitched odometer read) are
+// ignored rather than counted.
+const (
+	maxDistanceStep = 1000.0 // km
+	maxEnergyStep   = 200.0  // kWh
+)
+
+// Period identifies the reporting periods.
+type Period string
+
+const (
+	Day   Period = "day"
+	Week  Period = "week"
+	Month Period = "month"
+)
+
+// Periods lists every period in display order.
+var Periods = []Period{Day, Week, Month}
+
+// Totals is the accumulated usage of one period.
+type Totals struct {
+	Key      string  `json:"key"` // e.g. "2025-07-14", "2025-W29", "2025-07"
+	Distance float64 `json:"distance_km"`
+	Energy   float64 `json:"energy_kwh"`
+}
+
+// Tracker turns cumulative counters into per-period totals. It is not safe
+// for concurrent use.
+type Tracker struct {
+	Periods     map[Period]*Totals `json:"periods"`
+	LastMileage *float64           `json:"last_mileage,omitempty"`
+	LastEnergy  *float64           `json:"last_energy,omitempty"`
+
+	loc *time.Location
+}
+
+// New returns an empty tracker using loc for period boundaries.
+func New(loc *time.Location) *Tracker {
+	return &Tracker{Periods: make(map[Period]*Totals), loc: loc}
+}
+
+// Load reads a tracker from path. A missing file yields an empty tracker.
+func Load(path string, loc *time.Location) (*Tracker, error) {
+	t := New(loc)
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return t, fmt.Errorf("failed to read statistics: %w", err)
+	}
+	if err := json.Unmarshal(raw, t); err != nil {
+		return New(loc), fmt.Errorf("failed to parse statistics %s: %w", path, err)
+	}
+	if t.Periods == nil {
+		t.Periods = make(map[Period]*Totals)
+	}
+	return t, nil
+}
+
+// Save writes the tracker to path, creating its directory.
+func (t *Tracker) Save(path string) error {
+	raw, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode statistics: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write statistics: %w", err)
+	}
+	return nil
+}
+
+// periodKey names the period containing ts.
+func periodKey(p Period, ts time.Time) string {
+	switch p {
+	case Week:
+		year, week := ts.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case Month:
+		return ts.Format("2006-01")
+	default:
+		return ts.Format("2006-01-02")
+	}
+}
+
+// Observe folds a snapshot into the totals and reports whether any value
+// changed (including a period rollover).
+func (t *Tracker) Observe(data *sensors.SensorData) bool {
+	if data == nil {
+		return false
+	}
+	ts := data.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	ts = ts.In(t.loc)
+
+	changed := false
+	for _, p := range Periods {
+		key := periodKey(p, ts)
+		if cur := t.Periods[p]; cur == nil || cur.Key != key {
+			t.Periods[p] = &Totals{Key: key}
+			changed = true
+		}
+	}
+
+	distance := step(&t.LastMileage, data.Mileage, maxDistanceStep)
+	energy := step(&t.LastEnergy, data.TotalPowerConsumption, maxEnergyStep)
+	if distance > 0 || energy > 0 {
+		for _, p := range Periods {
+			t.Periods[p].Distance += distance
+			t.Periods[p].Energy += energy
+		}
+		changed = true
+	}
+	return changed
+}
+
+// step returns the increase of a cumulative counter since *last and records
+// the new reading. Decreases and implausible jumps count as zero.
+func step(last **float64, current *float64, max float64) float64 {
+	if current == nil {
+		return 0
+	}
+	prev := *last
+	v := *current
+	*last = &v
+	if prev == nil {
+		return 0
+	}
+	d := v - *prev
+	if d <= 0 || d > max {
+		return 0
+	}
+	return d
+}
+
+// Get returns the totals of period p (zero before the first snapshot).
+func (t *Tracker) Get(p Period) Totals {
+	if cur := t.Periods[p]; cur != nil {
+		return *cur
+	}
+	return Totals{}
+}
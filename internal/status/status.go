@@ -0,0 +1,165 @@
+// Package status collects health counters of the bridge itself (polls,
+// transmissions, queues) for the periodic byd_car/<id>/status report, so
+// problems can be diagnosed from the broker without access to the logs.
+package status
+
+import (
+	"sync"
+	"time"
+)
+
+// Operation groups.
+const (
+	GroupPoll      = "polls"
+	GroupTransmit  = "transmitters"
+	GroupSubsystem = "subsystems"
+)
+
+// OpStats summarises one recurring operation, e.g. the fast poll or the
+// ABRP transmitter.
+type OpStats struct {
+	Count         uint64     `json:"count"`
+	Failures      uint64     `json:"failures"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastLatencyMs int64      `json:"last_latency_ms"`
+	AvgLatencyMs  int64      `json:"avg_latency_ms"` // exponential moving average
+}
+
+// Report is the published status document.
+type Report struct {
+	StartedAt    time.Time          `json:"started_at"`
+	UptimeS      int64              `json:"uptime_s"`
+	Polls        map[string]OpStats `json:"polls"`
+	Transmitters map[string]OpStats `json:"transmitters"`
+	Subsystems   map[string]OpStats `json:"subsystems,omitempty"` // supervised goroutine exits
+	Queues       map[string]int     `json:"queues,omitempty"`
+	Counters     map[string]uint64  `json:"counters,omitempty"`
+	Problems     []string           `json:"problems,omitempty"` // e.g. topics denied by the broker
+}
+
+// Recorder accumulates OpStats. A nil *Recorder ignores all calls.
+type Recorder struct {
+	mu      sync.Mutex
+	started time.Time
+	ops     map[string]map[string]*OpStats
+	queues  map[string]func() int
+	counts  map[string]func() uint64
+	probs   []func() []string
+}
+
+// New creates a Recorder; uptime counts from now.
+func New() *Recorder {
+	return &Recorder{
+		started: time.Now(),
+		ops:     map[string]map[string]*OpStats{GroupPoll: {}, GroupTransmit: {}, GroupSubsystem: {}},
+		queues:  make(map[string]func() int),
+		counts:  make(map[string]func() uint64),
+	}
+}
+
+// Record adds one run of the named operation that took d and failed with err
+// (nil = success).
+func (r *Recorder) Record(group, name string, d time.Duration, err error) {
+	if r == nil {
+		return
+	}
+	now := time.Now()
+	ms := d.Milliseconds()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ops := r.ops[group]
+	if ops == nil {
+		ops = make(map[string]*OpStats)
+		r.ops[group] = ops
+	}
+	op := ops[name]
+	if op == nil {
+		op = &OpStats{AvgLatencyMs: ms}
+		ops[name] = op
+	}
+	op.Count++
+	op.LastLatencyMs = ms
+	op.AvgLatencyMs = (op.AvgLatencyMs*7 + ms) / 8
+	if err != nil {
+		op.Failures++
+		op.LastError = err.Error()
+		op.LastErrorAt = &now
+	} else {
+		op.LastSuccessAt = &now
+	}
+}
+
+// TrackQueue registers a function reporting the current depth of a queue.
+func (r *Recorder) TrackQueue(name string, depth func() int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.queues[name] = depth
+	r.mu.Unlock()
+}
+
+// TrackCounter includes a monotonic counter, e.g. skipped bus deliveries, in
+// every report.
+func (r *Recorder) TrackCounter(name string, value func() uint64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.counts[name] = value
+	r.mu.Unlock()
+}
+
+// TrackProblems includes the descriptions returned by fn, e.g. MQTT topics
+// denied by the broker, in every report.
+func (r *Recorder) TrackProblems(fn func() []string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.probs = append(r.probs, fn)
+	r.mu.Unlock()
+}
+
+// Report returns a copy of the current counters.
+func (r *Recorder) Report() Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep := Report{
+		StartedAt:    r.started.UTC(),
+		UptimeS:      int64(time.Since(r.started).Seconds()),
+		Polls:        copyOps(r.ops[GroupPoll]),
+		Transmitters: copyOps(r.ops[GroupTransmit]),
+	}
+	if subs := r.ops[GroupSubsystem]; len(subs) > 0 {
+		rep.Subsystems = copyOps(subs)
+	}
+	if len(r.queues) > 0 {
+		rep.Queues = make(map[string]int, len(r.queues))
+		for name, depth := range r.queues {
+			rep.Queues[name] = depth()
+		}
+	}
+	if len(r.counts) > 0 {
+		rep.Counters = make(map[string]uint64, len(r.counts))
+		for name, value := range r.counts {
+			rep.Counters[name] = value()
+		}
+	}
+	for _, fn := range r.probs {
+		rep.Problems = append(rep.Problems, fn()...)
+	}
+	return rep
+}
+
+func copyOps(ops map[string]*OpStats) map[string]OpStats {
+	out := make(map[string]OpStats, len(ops))
+	for name, op := range ops {
+		out[name] = *op
+	}
+	return out
+}
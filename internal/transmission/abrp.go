@@ -12,6 +12,8 @@ import (
 
 	"sync/atomic"
 
+	"github.com/jkaberg/byd-hass/internal/charging"
+	"github.com/jkaberg/byd-hass/internal/netutil"
 	"github.com/jkaberg/byd-hass/internal/sensors"
 	"github.com/sirupsen/logrus"
 )
@@ -41,6 +43,7 @@ import (
 //   - hvac_power/hvac_setpoint: Climate control data
 //   - tire_pressure_*: Tire pressure monitoring
 //   - heading/elevation: Navigation enhancement data
+//   - fuel_percent: Fuel tank level of plug-in hybrids
 
 // ABRPTransmitter transmits telemetry data to A Better Route Planner
 type ABRPTransmitter struct {
@@ -48,7 +51,11 @@ type ABRPTransmitter struct {
 	token      string
 	httpClient *http.Client
 	logger     *logrus.Logger
-	healthy    uint32 // 1 = last transmission successful, 0 = failed/unknown
+	healthy    uint32         // 1 = last transmission successful, 0 = failed/unknown
+	carModel   string         // ABRP car_model type code, "" = not sent
+	usableKWh  float64        // usable battery capacity, 0 = unknown
+	charge     *ChargeTracker // calibrated power and session AC/DC type for is_dcfc
+	noHVAC     bool           // don't send the hvac_power estimate
 }
 
 // ABRPTelemetry represents the telemetry data format for ABRP
@@ -85,13 +92,18 @@ type ABRPTelemetry struct {
 	TirePressureFR  *float64 `json:"tire_pressure_fr,omitempty"`  // Front right tire pressure in kPa
 	TirePressureRL  *float64 `json:"tire_pressure_rl,omitempty"`  // Rear left tire pressure in kPa
 	TirePressureRR  *float64 `json:"tire_pressure_rr,omitempty"`  // Rear right tire pressure in kPa
+
+	CarModel string `json:"car_model,omitempty"` // ABRP vehicle type code
+
+	// Hybrid (DM-i / DM-p) parameters
+	FuelPercent *float64 `json:"fuel_percent,omitempty"` // Fuel tank level (0-100), only sent by cars that report it
 }
 
 // NewABRPTransmitter creates a new ABRP transmitter
 func NewABRPTransmitter(apiKey, token string, logger *logrus.Logger) *ABRPTransmitter {
-	// Rely on the global custom DNS resolver installed in main.go.
+	// Rely on the global custom DNS resolver and proxy settings from main.go.
 	transport := &http.Transport{
-		Proxy:                 http.ProxyFromEnvironment,
+		Proxy:                 netutil.Proxy,
 		ForceAttemptHTTP2:     true,
 		MaxIdleConns:          100,
 		IdleConnTimeout:       90 * time.Second,
@@ -108,9 +120,35 @@ func NewABRPTransmitter(apiKey, token string, logger *logrus.Logger) *ABRPTransm
 			Transport: transport,
 		},
 		logger: logger,
+		charge: NewChargeTracker(sensors.NewPowerCalibration(sensors.PowerSignNormal, 1)),
 	}
 }
 
+// SetChargeTracker sets the tracker that converts EnginePower to ABRP's
+// power (kW, negative while charging) and classifies sessions for is_dcfc.
+// Share it with MQTT.
+func (t *ABRPTransmitter) SetChargeTracker(c *ChargeTracker) {
+	t.charge = c
+}
+
+// SetCarModel sets the ABRP car_model type code sent with the telemetry.
+func (t *ABRPTransmitter) SetCarModel(code string) {
+	t.carModel = code
+}
+
+// SetUsableCapacity sets the usable battery capacity (kWh) sent as capacity
+// and used for soe and soh, replacing the capacity sensor most cars report
+// as 0.
+func (t *ABRPTransmitter) SetUsableCapacity(kwh float64) {
+	t.usableKWh = kwh
+}
+
+// SetHVACPower enables or disables the hvac_power estimate (enabled by
+// default).
+func (t *ABRPTransmitter) SetHVACPower(enabled bool) {
+	t.noHVAC = !enabled
+}
+
 // TransmitWithContext sends sensor data to ABRP using the provided context.
 // If ctx is cancelled or times out, the request is aborted.
 func (t *ABRPTransmitter) TransmitWithContext(ctx context.Context, data *sensors.SensorData) error {
@@ -229,7 +267,8 @@ func (t *ABRPTransmitter) IsConnected() bool {
 // buildTelemetryData converts sensor data to ABRP telemetry format
 func (t *ABRPTransmitter) buildTelemetryData(data *sensors.SensorData) ABRPTelemetry {
 	telemetry := ABRPTelemetry{
-		Utc: data.Timestamp.Unix(),
+		Utc:      data.Timestamp.Unix(),
+		CarModel: t.carModel,
 	}
 
 	// High priority parameters - State of charge (required)
@@ -258,52 +297,46 @@ func (t *ABRPTransmitter) buildTelemetryData(data *sensors.SensorData) ABRPTelem
 		}
 	}
 
-	// High priority - Power from engine
-	if data.EnginePower != nil {
-		telemetry.Power = data.EnginePower
+	// High priority - Power from engine, in ABRP's sign convention
+	charge := t.charge.Observe(data)
+	telemetry.Power = charge.Power
+	if charge.Learned {
+		t.logger.WithField("inverted", t.charge.Inverted()).Info("Learned the power sign convention from charging")
 	}
 
-	// High priority - Charging status and DC fast-charging detection based on instantaneous power
+	// High priority - Charging status and DC fast-charging detection
 	// ABRP expects negative values for battery charge (power flowing INTO the battery).
 	// Charging detection rules:
 	//   * is_charging  = 1 when power is below -1 kW (i.e. < −1).
-	//   * is_dcfc      = 1 when power is below -50 kW (i.e. < −50).
+	//   * is_dcfc      = 1 while charging in a session the classifier has
+	//     seen DC power or current in (sticky through the end-of-charge taper).
 	// Note: "below" means numerically less (more negative).
 
-	// Determine if the charging gun is physically connected (gun state 2)
-	connected := false
-	if data.ChargeGunState != nil && int(*data.ChargeGunState) == 2 {
-		connected = true
-	}
-
-	// Initialise flags to false so they are always sent
-	isCharging := false
-	isDCFC := false
-
-	// Update flags only when the gun is connected and power thresholds are met
-	if telemetry.Power != nil && connected {
-		p := *telemetry.Power
-		if p < -1.0 {
-			isCharging = true
-		}
-		if p < -50.0 {
-			isDCFC = true
-		}
-	}
+	// Flags are always sent; they're only set while the gun is connected
+	// (gun state 2) and the power threshold is met.
+	isCharging := charge.Connected && charge.ChargeKW > 1.0
+	isDCFC := isCharging && charge.Type == charging.TypeDC
 
 	telemetry.IsCharging = &isCharging
 	telemetry.IsDCFC = &isDCFC
 
-	// Lower priority - Battery information
-	if data.BatteryCapacity != nil {
-		telemetry.Capacity = data.BatteryCapacity
+	// Lower priority - Battery information. The configured usable capacity
+	// wins; the capacity sensor is only used when it reports something.
+	capacity := t.usableKWh
+	if capacity <= 0 && data.BatteryCapacity != nil && *data.BatteryCapacity > 0 {
+		capacity = *data.BatteryCapacity
+	}
+	if capacity > 0 {
+		telemetry.Capacity = &capacity
 
 		// Calculate SOE (State of Energy) = SoC * capacity
-		if data.BatteryPercentage != nil {
-			soe := (*data.BatteryCapacity * *data.BatteryPercentage) / 100
+		if soe, ok := sensors.DeriveBatteryEnergy(data, capacity); ok {
 			telemetry.SOE = &soe
 		}
 	}
+	if soh, ok := sensors.DeriveBatteryHealth(data, t.usableKWh); ok {
+		telemetry.SOH = &soh
+	}
 
 	// Lower priority - Battery voltage and estimated current
 	if data.MaxBatteryVoltage != nil {
@@ -318,6 +351,11 @@ func (t *ABRPTransmitter) buildTelemetryData(data *sensors.SensorData) ABRPTelem
 		}
 	}
 
+	// Lower priority - Fuel level (plug-in hybrids only; pure EVs report none)
+	if data.FuelPercentage != nil && *data.FuelPercentage > 0 {
+		telemetry.FuelPercent = data.FuelPercentage
+	}
+
 	// Lower priority - Temperature data
 	if data.OutsideTemperature != nil {
 		telemetry.ExtTemp = data.OutsideTemperature
@@ -334,25 +372,8 @@ func (t *ABRPTransmitter) buildTelemetryData(data *sensors.SensorData) ABRPTelem
 		telemetry.Odometer = data.Mileage
 	}
 
-	// Lower priority - HVAC data
-	if data.ACStatus != nil && *data.ACStatus > 0 {
-		// Estimate HVAC power based on temperature difference and fan speed
-		hvacPower := 2.0 // Base HVAC power consumption in kW
-
-		if data.CabinTemperature != nil && data.OutsideTemperature != nil {
-			tempDiff := *data.CabinTemperature - *data.OutsideTemperature
-			if tempDiff < 0 {
-				tempDiff = -tempDiff // Absolute difference
-			}
-			// More temperature difference = more power needed
-			hvacPower += (tempDiff / 10.0) * 1.0 // 1kW per 10°C difference
-		}
-
-		// Adjust based on fan speed level
-		if data.FanSpeedLevel != nil {
-			fanMultiplier := *data.FanSpeedLevel / 3.0 // Assume max fan level is 3
-			hvacPower *= fanMultiplier
-		}
+	// Lower priority - HVAC data (estimated, the car doesn't report it)
+	if hvacPower, ok := sensors.EstimateHVACPower(data); ok && !t.noHVAC {
 		telemetry.HVACPower = &hvacPower
 	}
 
@@ -383,6 +404,30 @@ func (t *ABRPTransmitter) SetTimeout(timeout time.Duration) {
 	t.httpClient.Timeout = timeout
 }
 
+// Ping checks that the ABRP API is reachable and accepts the credentials
+// without sending telemetry: the send endpoint is called without a tlm
+// payload, so nothing is recorded.
+func (t *ABRPTransmitter) Ping(ctx context.Context) error {
+	apiURL := fmt.Sprintf("https://api.iternio.com/1/tlm/send?api_key=%s&token=%s", t.apiKey, t.token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create ABRP request: %w", err)
+	}
+	req.Header.Set("User-Agent", "byd-hass/1.0.0")
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("ABRP API unreachable: %w", err)
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("ABRP API rejected the API key or token (status %d)", resp.StatusCode)
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("ABRP API returned status %d: %s", resp.StatusCode, resp.Status)
+	}
+	return nil
+}
+
 // GetConnectionStatus returns detailed connection status for diagnostics
 func (t *ABRPTransmitter) GetConnectionStatus() map[string]interface{} {
 	return map[string]interface{}{
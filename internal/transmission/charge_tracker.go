@@ -0,0 +1,89 @@
+package transmission
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/charging"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// ChargeTracker reads charging from calibrated power (see
+// sensors.PowerCalibration) and classifies the session as AC or DC. ABRP
+// and MQTT share one, so is_dcfc and charging_type can't disagree and a car
+// that reports charging as positive power is handled by both. Safe for
+// concurrent use.
+type ChargeTracker struct {
+	mu         sync.Mutex
+	power      *sensors.PowerCalibration
+	classifier *charging.Classifier
+	last       ChargeReading
+	lastTS     time.Time
+}
+
+// ChargeReading is what a ChargeTracker made of one snapshot.
+type ChargeReading struct {
+	Power     *float64 // calibrated EnginePower (kW, negative while charging), nil when unknown
+	Connected bool     // charge gun connected
+	ChargeKW  float64  // power into the battery while connected, 0 otherwise
+	Type      string   // session charge type, a charging.Type* value
+	Learned   bool     // this snapshot settled the power sign
+}
+
+// NewChargeTracker returns a tracker converting EnginePower with power.
+func NewChargeTracker(power *sensors.PowerCalibration) *ChargeTracker {
+	return &ChargeTracker{power: power, classifier: charging.NewClassifier()}
+}
+
+// Observe folds in data. Each snapshot is observed once: a transmitter
+// passing the same (or an older) snapshot again gets the last reading, so
+// the sign learning and session timing don't count it twice.
+func (c *ChargeTracker) Observe(data *sensors.SensorData) ChargeReading {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.lastTS.IsZero() && !data.Timestamp.After(c.lastTS) {
+		r := c.last
+		r.Learned = false
+		return r
+	}
+
+	power, learned := c.power.Power(data)
+	r := ChargeReading{
+		Power:     power,
+		Connected: data.ChargeGunState != nil && *data.ChargeGunState == 2,
+		Learned:   learned,
+	}
+	if r.Power != nil && r.Connected {
+		r.ChargeKW = -*r.Power
+	}
+	r.Type = c.classifier.Observe(data.Timestamp, r.Connected, r.ChargeKW, data.MaxBatteryVoltage)
+	c.last, c.lastTS = r, data.Timestamp
+	return r
+}
+
+// Inverted reports whether the car reports charging as positive power.
+func (c *ChargeTracker) Inverted() bool {
+	return c.power.Inverted()
+}
+
+// Status is sensors.DeriveChargingStatus on the calibrated power.
+func (r ChargeReading) Status() string {
+	switch {
+	case !r.Connected:
+		return "disconnected"
+	case r.ChargeKW > 1:
+		return "charging"
+	}
+	return "connected"
+}
+
+// ChargerPower is sensors.DeriveChargerPower on the calibrated power.
+func (r ChargeReading) ChargerPower() (kw float64, ok bool) {
+	if r.Power == nil {
+		return 0, false
+	}
+	if r.Status() != "charging" {
+		return 0, true
+	}
+	return r.ChargeKW, true
+}
@@ -0,0 +1,44 @@
+package transmission
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jkaberg/byd-hass/internal/cloudiot"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+// CloudIoTTransmitter sends snapshots to Azure IoT Hub or AWS IoT Core. The
+// payload is the same JSON document as the MQTT state topic.
+type CloudIoTTransmitter struct {
+	client *cloudiot.Client
+	logger *logrus.Logger
+}
+
+// NewCloudIoTTransmitter wraps a connected cloud IoT client.
+func NewCloudIoTTransmitter(client *cloudiot.Client, logger *logrus.Logger) *CloudIoTTransmitter {
+	return &CloudIoTTransmitter{client: client, logger: logger}
+}
+
+// TransmitWithContext publishes the snapshot. The publish itself is bounded
+// by the client's timeout; ctx only short-circuits when already cancelled.
+func (t *CloudIoTTransmitter) TransmitWithContext(ctx context.Context, data *sensors.SensorData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !t.client.IsConnected() {
+		return fmt.Errorf("%s IoT broker not connected", t.client.Provider())
+	}
+	return t.client.PublishState(data)
+}
+
+// Transmit publishes using a background context.
+func (t *CloudIoTTransmitter) Transmit(data *sensors.SensorData) error {
+	return t.TransmitWithContext(context.Background(), data)
+}
+
+// IsConnected reports whether the broker connection is up.
+func (t *CloudIoTTransmitter) IsConnected() bool {
+	return t.client.IsConnected()
+}
@@ -0,0 +1,217 @@
+package transmission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+// Home Assistant REST integration
+//
+// For installations without an MQTT broker, states are written straight into
+// Home Assistant through its REST API with a long-lived access token:
+//
+//	POST /api/states/sensor.byd_car_<device>_<key>
+//
+// Entities created this way have no unique_id, so they can't be edited in
+// the UI and are not grouped under a device, and Home Assistant forgets them
+// on restart. Only changed states are posted; everything is re-posted every
+// haRESTResyncInterval to restore entities after a restart. The position is
+// reported through the device_tracker.see service so Home Assistant resolves
+// zones (home/not_home) itself.
+
+// haRESTResyncInterval is how often all states are posted regardless of
+// changes.
+const haRESTResyncInterval = 10 * time.Minute
+
+// haState is the body of POST /api/states/<entity_id>.
+type haState struct {
+	State      string                 `json:"state"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// HARESTTransmitter posts states to the Home Assistant REST API.
+type HARESTTransmitter struct {
+	baseURL    string
+	token      string
+	objectID   string // entity ID prefix, e.g. "byd_car_abc123"
+	httpClient *http.Client
+	logger     *logrus.Logger
+	healthy    uint32
+
+	mu         sync.Mutex
+	lastStates map[string]string // entity ID → last posted state
+	lastSync   time.Time
+}
+
+// NewHARESTTransmitter creates a transmitter for the Home Assistant instance
+// at baseURL (e.g. "http://homeassistant.local:8123") authenticated with a
+// long-lived access token.
+func NewHARESTTransmitter(baseURL, token, deviceID string, logger *logrus.Logger) (*HARESTTransmitter, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid Home Assistant URL %q", baseURL)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("Home Assistant access token must not be empty")
+	}
+	return &HARESTTransmitter{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		objectID:   "byd_car_" + entitySlug(deviceID),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		logger:     logger,
+		lastStates: make(map[string]string),
+	}, nil
+}
+
+// TransmitWithContext posts every changed state (all states on resync) and
+// the current position.
+func (t *HARESTTransmitter) TransmitWithContext(ctx context.Context, data *sensors.SensorData) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	resync := time.Since(t.lastSync) >= haRESTResyncInterval
+	states := t.buildStates(data)
+
+	var failed int
+	for entityID, st := range states {
+		if !resync && t.lastStates[entityID] == st.State {
+			continue
+		}
+		if err := t.post(ctx, "/api/states/"+entityID, st); err != nil {
+			if ctx.Err() != nil {
+				atomic.StoreUint32(&t.healthy, 0)
+				return err
+			}
+			t.logger.WithError(err).WithField("entity_id", entityID).Debug("Home Assistant state update failed")
+			failed++
+			continue
+		}
+		t.lastStates[entityID] = st.State
+	}
+
+	if hasLocationFix(data) {
+		loc := data.Location
+		see := map[string]interface{}{
+			"dev_id":       t.objectID,
+			"host_name":    "BYD Car",
+			"gps":          []float64{loc.Latitude, loc.Longitude},
+			"gps_accuracy": loc.Accuracy,
+			"source_type":  "gps",
+		}
+		if err := t.post(ctx, "/api/services/device_tracker/see", see); err != nil {
+			t.logger.WithError(err).Debug("Home Assistant device_tracker.see failed")
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		atomic.StoreUint32(&t.healthy, 0)
+		return fmt.Errorf("%d Home Assistant updates failed", failed)
+	}
+	if resync {
+		t.lastSync = time.Now()
+	}
+	atomic.StoreUint32(&t.healthy, 1)
+	return nil
+}
+
+// Transmit posts states using a background context.
+func (t *HARESTTransmitter) Transmit(data *sensors.SensorData) error {
+	return t.TransmitWithContext(context.Background(), data)
+}
+
+// IsConnected returns true when the last transmission succeeded.
+func (t *HARESTTransmitter) IsConnected() bool {
+	return atomic.LoadUint32(&t.healthy) == 1
+}
+
+// buildStates maps the published sensors of a snapshot to entity states.
+func (t *HARESTTransmitter) buildStates(data *sensors.SensorData) map[string]haState {
+	states := make(map[string]haState)
+	for _, id := range sensors.PublishedSensorIDs() {
+		def := sensors.GetSensorByID(id)
+		if def == nil {
+			continue
+		}
+		value, ok := data.ValueByID(id)
+		if !ok {
+			continue
+		}
+
+		attrs := map[string]interface{}{"friendly_name": "BYD " + def.EnglishName}
+		if def.DeviceClass != "" {
+			attrs["device_class"] = def.DeviceClass
+		}
+		state := strconv.FormatFloat(value, 'f', -1, 64)
+		if def.Category == "binary_sensor" {
+			state = "off"
+			if value != 0 {
+				state = "on"
+			}
+		} else if def.UnitOfMeasurement != "" {
+			attrs["unit_of_measurement"] = def.UnitOfMeasurement
+			attrs["state_class"] = "measurement"
+		}
+		entityID := fmt.Sprintf("%s.%s_%s", def.Category, t.objectID, sensors.ToSnakeCase(def.FieldName))
+		states[entityID] = haState{State: state, Attributes: attrs}
+	}
+
+	states["sensor."+t.objectID+"_charging_status"] = haState{
+		State:      sensors.DeriveChargingStatus(data),
+		Attributes: map[string]interface{}{"friendly_name": "BYD Charging Status", "icon": "mdi:ev-station"},
+	}
+	return states
+}
+
+func (t *HARESTTransmitter) post(ctx context.Context, path string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Home Assistant returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// entitySlug lower-cases s and replaces everything but [a-z0-9] with '_' so
+// it can be used in an entity ID.
+func entitySlug(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
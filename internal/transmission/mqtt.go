@@ -1,24 +1,65 @@
 package transmission
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"reflect"
-	"strings"
+	"math"
+	"sync"
 	"time"
 
+	"github.com/jkaberg/byd-hass/internal/charging"
+	"github.com/jkaberg/byd-hass/internal/formula"
+	"github.com/jkaberg/byd-hass/internal/geofence"
 	"github.com/jkaberg/byd-hass/internal/mqtt"
+	"github.com/jkaberg/byd-hass/internal/payload"
 	"github.com/jkaberg/byd-hass/internal/sensors"
 	"github.com/sirupsen/logrus"
 )
 
 // MQTTTransmitter transmits sensor data via MQTT
 type MQTTTransmitter struct {
-	client           *mqtt.Client
-	deviceID         string
-	discoveryPrefix  string
-	logger           *logrus.Logger
-	publishedSensors map[string]bool // Tracks published discovery configs
+	client             *mqtt.Client
+	deviceID           string
+	discoveryPrefix    string
+	logger             *logrus.Logger
+	publishedSensors   map[string]bool   // Tracks published discovery configs
+	discoveryCache     map[string][]byte // Discovery payloads by topic, for RepublishDiscovery
+	discoveryMu        sync.Mutex        // Guards publishedSensors, discoveryCache, retainedDiscovery and discoveryState
+	retainedDiscovery  map[string][]byte // Configs already on the broker at startup, see LoadRetainedDiscovery
+	discoveryStatePath string            // Persisted config hashes, see SetDiscoveryStatePath
+	discoveryState     discoveryState
+	discoveryDirty     bool
+	discoveryPublished int                // Configs published in the current pass, see logDiscoverySummary
+	discoveryUnchanged int                // Configs skipped as unchanged in the current pass
+	zones              []geofence.Zone    // Optional zones for the current_zone sensor
+	zoneTracker        *geofence.Tracker  // Zone membership shared with the zone events
+	home               *geofence.Zone     // Optional home location for the tracker state
+	sentryCommander    SentryCommander    // Optional arm/disarm backend for the alarm panel
+	driveModeCommander DriveModeCommander // Optional backend for the drive mode select
+	carSwitchCommander CarSwitchCommander // Optional backend for the head-unit setting switches
+	virtualSensors     []formula.VirtualSensor
+	fuelTankLiters     float64             // > 0 enables the fuel_level sensor
+	usableKWh          float64             // > 0 enables battery_energy and battery_health
+	hvacPower          bool                // publish the hvac_power estimate
+	legacyKeys         bool                // Also publish renamed state keys under their old names
+	stateEncoding      string              // payload.JSON (default), payload.MsgPack or payload.CBOR
+	paused             func() bool         // Suspends events and derived publishes, see SetPauseCheck
+	imageTypes         map[string]string   // Content type each image entity was registered with
+	stateKeys          map[string]struct{} // Published state keys, see publishedKeys
+	stateKeysOnce      sync.Once
+	version            string          // Reported in the device and origin blocks
+	diplusOnline       *bool           // Last published Diplus availability, nil = never
+	cellAttributes     bool            // Publish the per-cell arrays too
+	retain             map[string]bool // Retained topics, nil = DefaultRetain
+	expireAfter        time.Duration   // expire_after of state topic entities, 0 = never
+	diplusMu           sync.Mutex
+	lastState          map[string]interface{} // Previous state payload, see logStatePublish
+	lastStateMu        sync.Mutex
+	charge             *ChargeTracker // calibrated charging power and session AC/DC type
+	chargeETA          *charging.ETA  // time-to-full/target estimate
+	chargeETAMu        sync.Mutex
+	chargeTarget       float64 // SOC for charging_time_to_target, 0 = off
 }
 
 // HADiscoveryConfig represents Home Assistant MQTT discovery configuration
@@ -34,6 +75,11 @@ type HADiscoveryConfig struct {
 	Icon              string   `json:"icon,omitempty"`
 	StateClass        string   `json:"state_class,omitempty"`
 	EntityCategory    string   `json:"entity_category,omitempty"`
+	ExpireAfter       int      `json:"expire_after,omitempty"` // seconds
+	Options           []string `json:"options,omitempty"`      // enum sensors
+
+	JSONAttributesTopic    string `json:"json_attributes_topic,omitempty"`
+	JSONAttributesTemplate string `json:"json_attributes_template,omitempty"`
 }
 
 // HADevice represents the device information for Home Assistant
@@ -56,6 +102,16 @@ type SensorConfig struct {
 	StateClass  string
 	Category    string
 	ScaleFactor float64 // For unit conversion
+	// ValueTemplate overrides the default "value_json.<entity_id>" template
+	// (optional).
+	ValueTemplate string
+	// StateTopic overrides the shared state topic (optional).
+	StateTopic string
+	// AttributesTemplate exposes attributes rendered from the state topic
+	// (optional).
+	AttributesTemplate string
+	// Options lists the states of an enum sensor (optional).
+	Options []string
 }
 
 // NewMQTTTransmitter creates a new MQTT transmitter
@@ -66,6 +122,102 @@ func NewMQTTTransmitter(client *mqtt.Client, deviceID, discoveryPrefix string, l
 		discoveryPrefix:  discoveryPrefix,
 		logger:           logger,
 		publishedSensors: make(map[string]bool),
+		discoveryCache:   make(map[string][]byte),
+		version:          "dev",
+		charge:           NewChargeTracker(sensors.NewPowerCalibration(sensors.PowerSignNormal, 1)),
+		chargeETA:        charging.NewETA(),
+	}
+}
+
+// SetZones enables the current_zone sensor for the given geofence zones.
+func (t *MQTTTransmitter) SetZones(zones []geofence.Zone) {
+	t.zones = zones
+	t.zoneTracker = nil
+	if len(zones) > 0 {
+		t.zoneTracker = geofence.NewTracker(zones)
+	}
+}
+
+// ZoneTracker returns the tracker behind current_zone, nil without zones.
+// Whoever feeds it fixes also drives the zone events, so both agree on when
+// the car entered or left a zone.
+func (t *MQTTTransmitter) ZoneTracker() *geofence.Tracker {
+	return t.zoneTracker
+}
+
+// SetHome sets the home location used for the device tracker state.
+func (t *MQTTTransmitter) SetHome(home *geofence.Zone) {
+	t.home = home
+}
+
+// SetPauseCheck makes PublishEvent, PublishImage, PublishStatistics,
+// PublishChargingCost and PublishChargingCurve skip publishing while paused
+// returns true, so pausing telemetry silences more than the state topic.
+// Bridge health (status, connectivity, settings) is still published.
+func (t *MQTTTransmitter) SetPauseCheck(paused func() bool) {
+	t.paused = paused
+}
+
+// isPaused reports whether telemetry is paused (see SetPauseCheck).
+func (t *MQTTTransmitter) isPaused() bool {
+	return t.paused != nil && t.paused()
+}
+
+// trackerStateEnabled reports whether the device tracker gets an explicit
+// home/not_home/zone state.
+func (t *MQTTTransmitter) trackerStateEnabled() bool {
+	return t.home != nil || len(t.zones) > 0
+}
+
+// SetCompatibilityMode publishes renamed state keys under their previous
+// names too (see sensors.LegacyKeys).
+func (t *MQTTTransmitter) SetCompatibilityMode(enabled bool) {
+	t.legacyKeys = enabled
+}
+
+// SetStateEncoding switches the state payload to a binary encoding
+// (payload.MsgPack or payload.CBOR). Binary state is published to
+// byd_car/<id>/state/<encoding> instead of the JSON state topic, and the
+// entities reading the JSON state are not announced because Home Assistant
+// can't decode it.
+func (t *MQTTTransmitter) SetStateEncoding(encoding string) {
+	t.stateEncoding = encoding
+}
+
+// jsonState reports whether the state topic carries JSON.
+func (t *MQTTTransmitter) jsonState() bool {
+	return t.stateEncoding == "" || t.stateEncoding == payload.JSON
+}
+
+// SetVirtualSensors enables the given user-defined formula sensors.
+func (t *MQTTTransmitter) SetVirtualSensors(vs []formula.VirtualSensor) {
+	t.virtualSensors = vs
+}
+
+// virtualSensorConfigs describes the user-defined formula sensors.
+func (t *MQTTTransmitter) virtualSensorConfigs() []SensorConfig {
+	configs := make([]SensorConfig, 0, len(t.virtualSensors))
+	for _, v := range t.virtualSensors {
+		configs = append(configs, SensorConfig{
+			Name:       v.Name(),
+			EntityID:   v.Key,
+			EntityType: "sensor",
+			Unit:       v.Unit,
+			Icon:       "mdi:function-variant",
+			StateClass: "measurement",
+		})
+	}
+	return configs
+}
+
+// device returns the Home Assistant device block shared by all entities.
+func (t *MQTTTransmitter) device() HADevice {
+	return HADevice{
+		Identifiers:  []string{fmt.Sprintf("byd_car_%s", t.deviceID)},
+		Name:         "BYD Car",
+		Model:        "Car",
+		Manufacturer: "BYD",
+		SWVersion:    t.version,
 	}
 }
 
@@ -89,24 +241,59 @@ func (t *MQTTTransmitter) getSensorConfigs() []SensorConfig {
 		if _, ok := idSet[def.ID]; !ok {
 			continue // skip sensors not in the allowed MQTT list
 		}
-		configs = append(configs, SensorConfig{
+		if def.ID == SentryModeSensorID {
+			continue // exposed as an alarm_control_panel instead
+		}
+		if sensors.IsClockSensor(def.ID) {
+			continue // fused into car_clock
+		}
+		config := SensorConfig{
 			Name:        def.EnglishName,
 			EntityID:    sensors.ToSnakeCase(def.FieldName),
 			EntityType:  def.Category,          // "sensor" / "binary_sensor"
 			DeviceClass: def.DeviceClass,       // may be "" if not set
 			Unit:        def.UnitOfMeasurement, // may be "" if not set
 			ScaleFactor: 1.0,                   // default; can be refined later
-		})
+		}
+		applyModeConfig(&config, def.ID)
+		configs = append(configs, config)
 	}
 	return configs
 }
 
+// locationSensorConfigs describes the sensors derived from the GPS fix that
+// accompanies each snapshot. Values are injected by buildState.
+func locationSensorConfigs() []SensorConfig {
+	return []SensorConfig{
+		{Name: "GPS Altitude", EntityID: "gps_altitude", EntityType: "sensor", DeviceClass: "distance", Unit: "m", Icon: "mdi:image-filter-hdr", StateClass: "measurement"},
+		{Name: "GPS Heading", EntityID: "gps_heading", EntityType: "sensor", Unit: "°", Icon: "mdi:compass", StateClass: "measurement"},
+		{Name: "GPS Speed", EntityID: "gps_speed", EntityType: "sensor", DeviceClass: "speed", Unit: "m/s", StateClass: "measurement"},
+		{Name: "GPS Accuracy", EntityID: "gps_accuracy", EntityType: "sensor", DeviceClass: "distance", Unit: "m", Icon: "mdi:crosshairs-gps", Category: "diagnostic"},
+		{Name: "GPS Provider", EntityID: "gps_provider", EntityType: "sensor", Icon: "mdi:satellite-variant", Category: "diagnostic",
+			ValueTemplate: "{{ value_json.gps_provider | default('unknown') }}"},
+		{Name: "GPS Fix Age", EntityID: "gps_fix_age", EntityType: "sensor", DeviceClass: "duration", Unit: "s", Category: "diagnostic"},
+	}
+}
+
+// rejectedValuesConfig counts the readings dropped or clamped by
+// sensors.Quarantine since start.
+var rejectedValuesConfig = SensorConfig{
+	Name: "Rejected Values", EntityID: "rejected_values", EntityType: "sensor",
+	Icon: "mdi:filter-remove-outline", StateClass: "total_increasing", Category: "diagnostic",
+}
+
+// hasLocationFix reports whether data carries a real GPS fix rather than the
+// zeroed placeholder the location provider returns before the first read.
+func hasLocationFix(data *sensors.SensorData) bool {
+	return data != nil && data.Location != nil && data.Location.Provider != "default"
+}
+
 // publishDiscoveryForSensor publishes the discovery config for a single sensor.
 func (t *MQTTTransmitter) publishDiscoveryForSensor(sensor SensorConfig, device HADevice, baseTopic string) error {
 	uniqueID := fmt.Sprintf("%s_%s", t.deviceID, sensor.EntityID)
 
-	// Skip if already published
-	if t.publishedSensors[uniqueID] {
+	// Skip if already published, or if Home Assistant couldn't read it
+	if t.publishedSensors[uniqueID] || (sensor.StateTopic == "" && !t.jsonState()) {
 		return nil
 	}
 
@@ -118,6 +305,12 @@ func (t *MQTTTransmitter) publishDiscoveryForSensor(sensor SensorConfig, device
 		AvailabilityTopic: fmt.Sprintf("%s/availability", baseTopic),
 		Device:            device,
 	}
+	if sensor.ValueTemplate != "" {
+		config.ValueTemplate = sensor.ValueTemplate
+	}
+	if sensor.StateTopic != "" {
+		config.StateTopic = sensor.StateTopic
+	}
 
 	if sensor.DeviceClass != "" {
 		config.DeviceClass = sensor.DeviceClass
@@ -134,6 +327,16 @@ func (t *MQTTTransmitter) publishDiscoveryForSensor(sensor SensorConfig, device
 	if sensor.Category != "" {
 		config.EntityCategory = sensor.Category
 	}
+	if len(sensor.Options) > 0 {
+		config.Options = sensor.Options
+	}
+	if t.expireAfter > 0 && sensor.StateTopic == "" {
+		config.ExpireAfter = int(t.expireAfter.Seconds())
+	}
+	if sensor.AttributesTemplate != "" {
+		config.JSONAttributesTopic = config.StateTopic
+		config.JSONAttributesTemplate = sensor.AttributesTemplate
+	}
 
 	topic := fmt.Sprintf("%s/%s/byd_car_%s/%s/config",
 		t.discoveryPrefix, sensor.EntityType, t.deviceID, sensor.EntityID)
@@ -155,13 +358,10 @@ func (t *MQTTTransmitter) publishDiscoveryForSensor(sensor SensorConfig, device
 
 // publishDiscoveryConfigs ensures all available sensors have their discovery configs published.
 func (t *MQTTTransmitter) publishDiscoveryConfigs(data *sensors.SensorData) error {
-	device := HADevice{
-		Identifiers:  []string{fmt.Sprintf("byd_car_%s", t.deviceID)},
-		Name:         "BYD Car",
-		Model:        "Car",
-		Manufacturer: "BYD",
-		SWVersion:    "1.0.0",
-	}
+	t.discoveryMu.Lock()
+	defer t.discoveryMu.Unlock()
+
+	device := t.device()
 	baseTopic := fmt.Sprintf("byd_car/%s", t.deviceID)
 
 	// Publish device_tracker discovery first (if not already done)
@@ -189,77 +389,259 @@ func (t *MQTTTransmitter) publishDiscoveryConfigs(data *sensors.SensorData) erro
 		}
 	}
 
+	// Location sensors only make sense once we actually receive GPS fixes, so
+	// hold back their discovery until the first snapshot carrying a location.
+	if hasLocationFix(data) {
+		for _, config := range locationSensorConfigs() {
+			if err := t.publishDiscoveryForSensor(config, device, baseTopic); err != nil {
+				t.logger.WithError(err).WithField("sensor", config.Name).Error("Failed to publish discovery config")
+			}
+		}
+	}
+
+	derived := append(t.hybridSensorConfigs(data), t.batterySensorConfigs(data)...)
+	for _, config := range append(derived, t.hvacSensorConfigs(data)...) {
+		if err := t.publishDiscoveryForSensor(config, device, baseTopic); err != nil {
+			t.logger.WithError(err).WithField("sensor", config.Name).Error("Failed to publish discovery config")
+		}
+	}
+
+	for _, config := range append([]SensorConfig{t.diplusSensorConfig(), rejectedValuesConfig}, mqttHealthConfigs...) {
+		if err := t.publishDiscoveryForSensor(config, device, baseTopic); err != nil {
+			t.logger.WithError(err).WithField("sensor", config.Name).Error("Failed to publish discovery config")
+		}
+	}
+
+	for _, config := range t.cellSensorConfigs(data) {
+		if err := t.publishDiscoveryForSensor(config, device, baseTopic); err != nil {
+			t.logger.WithError(err).WithField("sensor", config.Name).Error("Failed to publish discovery config")
+		}
+	}
+
+	for _, config := range openingsSensorConfigs(data) {
+		if err := t.publishDiscoveryForSensor(config, device, baseTopic); err != nil {
+			t.logger.WithError(err).WithField("sensor", config.Name).Error("Failed to publish discovery config")
+		}
+	}
+
+	for _, config := range occupancySensorConfigs(data) {
+		if err := t.publishDiscoveryForSensor(config, device, baseTopic); err != nil {
+			t.logger.WithError(err).WithField("sensor", config.Name).Error("Failed to publish discovery config")
+		}
+	}
+
+	charger := append(chargerSensorConfigs(data), chargingTypeConfigs(data)...)
+	charger = append(charger, summarySensorConfig)
+	for _, config := range append(charger, chargeETAConfigs(data)...) {
+		if err := t.publishDiscoveryForSensor(config, device, baseTopic); err != nil {
+			t.logger.WithError(err).WithField("sensor", config.Name).Error("Failed to publish discovery config")
+		}
+	}
+
+	for _, config := range clockSensorConfigs(data) {
+		if err := t.publishDiscoveryForSensor(config, device, baseTopic); err != nil {
+			t.logger.WithError(err).WithField("sensor", config.Name).Error("Failed to publish discovery config")
+		}
+	}
+
+	for _, config := range t.virtualSensorConfigs() {
+		if err := t.publishDiscoveryForSensor(config, device, baseTopic); err != nil {
+			t.logger.WithError(err).WithField("sensor", config.Name).Error("Failed to publish discovery config")
+		}
+	}
+
+	if sentryPanelEnabled() && t.jsonState() {
+		if err := t.publishSentryAlarmPanelDiscovery(baseTopic, device); err != nil {
+			t.logger.WithError(err).Error("Failed to publish Sentry Mode discovery")
+		}
+	}
+
+	if t.driveModeCommander != nil && sensors.IsPublished(sensors.DriveModeSensorID) && t.jsonState() {
+		if err := t.publishDriveModeSelectDiscovery(baseTopic, device); err != nil {
+			t.logger.WithError(err).Error("Failed to publish Drive Mode discovery")
+		}
+	}
+
+	if t.jsonState() {
+		for _, s := range t.carSwitches() {
+			if err := t.publishCarSwitchDiscovery(baseTopic, device, s); err != nil {
+				t.logger.WithError(err).WithField("switch", s.Key).Error("Failed to publish switch discovery")
+			}
+		}
+	}
+
+	if len(t.zones) > 0 {
+		zoneSensor := SensorConfig{
+			Name:          "Current Zone",
+			EntityID:      "current_zone",
+			EntityType:    "sensor",
+			Icon:          "mdi:map-marker-radius",
+			ValueTemplate: "{{ value_json.current_zone | default('unknown') }}",
+		}
+		if err := t.publishDiscoveryForSensor(zoneSensor, device, baseTopic); err != nil {
+			t.logger.WithError(err).WithField("sensor", zoneSensor.Name).Error("Failed to publish discovery config")
+		}
+	}
+
 	// Publish Last Transmission discovery
 	if err := t.publishLastTransmissionDiscovery(baseTopic, device); err != nil {
 		t.logger.WithError(err).Error("Failed to publish Last Transmission discovery")
 	}
 
 	// Publish derived Charging Status discovery (virtual sensor)
-	if err := t.publishDerivedChargingStatusDiscovery(baseTopic, device); err != nil {
-		t.logger.WithError(err).Error("Failed to publish Charging Status discovery")
+	if t.jsonState() {
+		if err := t.publishDerivedChargingStatusDiscovery(baseTopic, device); err != nil {
+			t.logger.WithError(err).Error("Failed to publish Charging Status discovery")
+		}
 	}
 
+	t.logDiscoverySummary()
+	t.saveDiscoveryState()
 	return nil
 }
 
-// publishConfigRaw publishes a raw configuration object
+// Problems reports MQTT topics the broker appears to deny (see
+// mqtt.Client.Problems).
+func (t *MQTTTransmitter) Problems() []string {
+	return t.client.Problems()
+}
+
+// Metrics returns the broker connection metrics.
+func (t *MQTTTransmitter) Metrics() mqtt.Metrics {
+	return t.client.Metrics()
+}
+
+// LoadRetainedDiscovery reads this device's retained discovery configs from
+// the broker. Configs that come out identical afterwards aren't published
+// again, so a restart doesn't make Home Assistant reload every entity.
+// When the broker can't be read every config is published, whatever the
+// persisted hashes say. Call it before the first Transmit.
+func (t *MQTTTransmitter) LoadRetainedDiscovery(wait time.Duration) error {
+	filter := fmt.Sprintf("%s/+/byd_car_%s/#", t.discoveryPrefix, t.deviceID)
+	retained, err := t.client.Retained(filter, wait)
+	if err != nil {
+		// An empty set matches nothing and, like any loaded set, turns
+		// off the persisted hashes in knownDiscovery.
+		t.discoveryMu.Lock()
+		t.retainedDiscovery = map[string][]byte{}
+		t.discoveryMu.Unlock()
+		return fmt.Errorf("failed to read retained discovery configs: %w", err)
+	}
+	t.discoveryMu.Lock()
+	t.retainedDiscovery = retained
+	t.discoveryMu.Unlock()
+	t.logger.WithField("configs", len(retained)).Debug("Loaded retained discovery configs")
+	return nil
+}
+
+// publishConfigRaw publishes a raw configuration object. Callers hold
+// discoveryMu.
 func (t *MQTTTransmitter) publishConfigRaw(topic string, config interface{}) error {
-	payload, err := json.Marshal(config)
+	payload, err := t.decorateDiscovery(config)
 	if err != nil {
 		return fmt.Errorf("failed to marshal discovery config: %w", err)
 	}
+	t.discoveryCache[topic] = payload
+
+	if retained, ok := t.retainedDiscovery[topic]; ok {
+		// Compared once; later publishes (e.g. RepublishDiscovery) go out.
+		delete(t.retainedDiscovery, topic)
+		if bytes.Equal(retained, payload) {
+			t.logger.WithField("topic", topic).Debug("Discovery config unchanged, not republishing")
+			t.rememberDiscovery(topic, payload)
+			t.discoveryUnchanged++
+			return nil
+		}
+	}
+	if t.knownDiscovery(topic, payload) {
+		t.logger.WithField("topic", topic).Debug("Discovery config published by a previous run, not republishing")
+		t.discoveryUnchanged++
+		return nil
+	}
 
 	if err := t.client.Publish(topic, payload, true); err != nil {
 		return fmt.Errorf("failed to publish discovery config to %s: %w", topic, err)
 	}
+	t.rememberDiscovery(topic, payload)
+	t.discoveryPublished++
 
 	return nil
 }
 
-// buildStatePayload builds the JSON payload for the state topic
-func (t *MQTTTransmitter) buildStatePayload(data *sensors.SensorData) ([]byte, error) {
-	state := make(map[string]interface{})
-	// Pre-compute allowed entityIDs in snake_case for quick filtering
-	allowed := make(map[string]struct{}, len(sensors.PublishedSensorIDs()))
-	for _, id := range sensors.PublishedSensorIDs() {
-		if def := sensors.GetSensorByID(id); def != nil {
-			allowed[sensors.ToSnakeCase(def.FieldName)] = struct{}{}
+// publishedKeys returns the state keys of the published sensors. The sensor
+// selection is fixed at startup, so the set is computed once.
+func (t *MQTTTransmitter) publishedKeys() map[string]struct{} {
+	t.stateKeysOnce.Do(func() {
+		t.stateKeys = make(map[string]struct{}, len(sensors.PublishedSensorIDs()))
+		for _, id := range sensors.PublishedSensorIDs() {
+			if def := sensors.GetSensorByID(id); def != nil && !sensors.IsClockSensor(id) {
+				t.stateKeys[sensors.ToSnakeCase(def.FieldName)] = struct{}{}
+			}
 		}
-	}
-
-	v := reflect.ValueOf(data).Elem()
-	tOf := v.Type()
-
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Field(i)
+	})
+	return t.stateKeys
+}
 
-		// Skip unexported fields or fields that are nil
-		if !field.CanInterface() || (field.Kind() == reflect.Ptr && field.IsNil()) {
-			continue
+// buildState builds the state topic payload before encoding.
+func (t *MQTTTransmitter) buildState(data *sensors.SensorData) map[string]interface{} {
+	state := make(map[string]interface{})
+	allowed := t.publishedKeys()
+	data.ForEachValue(func(key string, value interface{}) {
+		if _, ok := allowed[key]; ok {
+			state[key] = value
 		}
-
-		jsonTag := tOf.Field(i).Tag.Get("json")
-		jsonKey := strings.Split(jsonTag, ",")[0]
-
-		if jsonKey == "" || jsonKey == "-" {
-			continue
+	})
+	// Inject derived/virtual sensors -------------------------------------
+	charge := t.charge.Observe(data)
+	state["charging_status"] = charge.Status()
+
+	if sentry := sensors.DeriveSentryState(data); sentry != "" {
+		state["sentry_state"] = sentry
+	}
+
+	t.addHybridState(state, data)
+	t.addBatteryState(state, data)
+	t.addHVACState(state, data)
+	addClockState(state, data)
+	addChargerState(state, charge)
+	addChargingTypeState(state, charge)
+	t.addChargeETAState(state, data, charge)
+	addOpeningsState(state, data)
+	addOccupancyState(state, data)
+	t.addCarSwitchState(state, data)
+	state["rejected_values"] = sensors.RejectedValues()
+	t.addMQTTHealthState(state)
+	t.addCellState(state, data)
+
+	// User-defined formula sensors may use unpublished sensors as inputs.
+	if len(t.virtualSensors) > 0 {
+		raw := sensors.GetNonNilFields(data)
+		lookup := func(name string) (float64, bool) {
+			v, ok := raw[name].(float64)
+			return v, ok
 		}
-
-		if _, ok := allowed[jsonKey]; !ok {
-			continue // not in MQTT allow-list
+		for key, v := range formula.EvaluateAll(t.virtualSensors, lookup) {
+			state[key] = math.Round(v*100) / 100
 		}
+	}
 
-		// Dereference pointer to get the actual value
-		var value interface{}
-		if field.Kind() == reflect.Ptr {
-			value = field.Elem().Interface()
-		} else {
-			value = field.Interface()
+	// Location-derived sensors (heading, altitude, fix quality, ...)
+	if hasLocationFix(data) {
+		loc := data.Location
+		state["gps_altitude"] = loc.Altitude
+		state["gps_heading"] = loc.Bearing
+		state["gps_speed"] = loc.Speed
+		state["gps_accuracy"] = loc.Accuracy
+		state["gps_provider"] = loc.Provider
+		if !loc.Timestamp.IsZero() {
+			state["gps_fix_age"] = int(time.Since(loc.Timestamp).Seconds())
+		}
+		if t.zoneTracker != nil {
+			if zone, ok := t.zoneTracker.Current(); ok {
+				state["current_zone"] = zone
+			}
 		}
-		state[jsonKey] = value
 	}
-	// Inject derived/virtual sensors -------------------------------------
-	state["charging_status"] = sensors.DeriveChargingStatus(data)
 
 	// Add a 'state' field for the device_tracker
 	if data.Speed != nil && *data.Speed > 0 {
@@ -271,8 +653,27 @@ func (t *MQTTTransmitter) buildStatePayload(data *sensors.SensorData) ([]byte, e
 	} else {
 		state["state"] = "parked"
 	}
+	t.addSummaryState(state, data)
+
+	// When the snapshot was polled, so consumers can tell fresh data from a
+	// retained payload left over from before the car went to sleep.
+	for key, value := range dataAge(data) {
+		state[key] = value
+	}
 
-	return json.Marshal(state)
+	if t.legacyKeys {
+		sensors.AddLegacyKeys(state)
+	}
+	state[sensors.SchemaField] = sensors.SchemaVersion
+	return state
+}
+
+// encodeState encodes a state payload in the configured encoding.
+func (t *MQTTTransmitter) encodeState(state map[string]interface{}) ([]byte, error) {
+	if t.jsonState() {
+		return json.Marshal(state)
+	}
+	return payload.Encode(t.stateEncoding, state)
 }
 
 // Transmit sends sensor data to MQTT
@@ -319,24 +720,37 @@ func (t *MQTTTransmitter) Transmit(data *sensors.SensorData) error {
 
 // publishSensorData publishes the main sensor data payload
 func (t *MQTTTransmitter) publishSensorData(data *sensors.SensorData) error {
-	payload, err := t.buildStatePayload(data)
+	state := t.buildState(data)
+	body, err := t.encodeState(state)
 	if err != nil {
 		return fmt.Errorf("failed to build state payload: %w", err)
 	}
 
 	topic := fmt.Sprintf("byd_car/%s/state", t.deviceID)
-	if err := t.client.Publish(topic, payload, true); err != nil {
+	if !t.jsonState() {
+		topic += "/" + t.stateEncoding
+	}
+	if err := t.client.Publish(topic, body, t.retained(RetainState)); err != nil {
 		return fmt.Errorf("failed to publish sensor data to %s: %w", topic, err)
 	}
 
-	t.logger.WithFields(logrus.Fields{
-		"topic":   topic,
-		"payload": string(payload),
-	}).Debug("Published sensor data")
+	t.logStatePublish(topic, state, body)
 
 	return nil
 }
 
+// dataAge returns the "timestamp" (RFC 3339, when the snapshot was polled)
+// and "data_age_seconds" keys for state and location payloads.
+func dataAge(data *sensors.SensorData) map[string]interface{} {
+	if data.Timestamp.IsZero() {
+		return nil
+	}
+	return map[string]interface{}{
+		"timestamp":        data.Timestamp.UTC().Format(time.RFC3339),
+		"data_age_seconds": int(time.Since(data.Timestamp).Seconds()),
+	}
+}
+
 // publishLocationData publishes location data to the device_tracker entity
 func (t *MQTTTransmitter) publishLocationData(data *sensors.SensorData) error {
 	if data.Location == nil {
@@ -348,16 +762,25 @@ func (t *MQTTTransmitter) publishLocationData(data *sensors.SensorData) error {
 		"latitude":     data.Location.Latitude,
 		"longitude":    data.Location.Longitude,
 		"gps_accuracy": data.Location.Accuracy,
+		"altitude":     data.Location.Altitude,
+		"heading":      data.Location.Bearing,
+		"provider":     data.Location.Provider,
 		"battery":      data.BatteryPercentage,
 		"speed":        data.Speed,
 	}
+	for key, value := range dataAge(data) {
+		payload[key] = value
+	}
+	if t.trackerStateEnabled() {
+		payload["state"] = geofence.TrackerState(t.home, t.zones, data.Location.Latitude, data.Location.Longitude)
+	}
 
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal location data: %w", err)
 	}
 
-	return t.client.Publish(topic, jsonPayload, false)
+	return t.client.Publish(topic, jsonPayload, t.retained(RetainLocation))
 }
 
 // publishDeviceTrackerDiscovery publishes the discovery config for the device tracker.
@@ -371,6 +794,14 @@ func (t *MQTTTransmitter) publishDeviceTrackerDiscovery(baseTopic string, device
 		"device":                device,
 		"availability_topic":    fmt.Sprintf("%s/availability", baseTopic),
 	}
+	// Without a state topic Home Assistant derives home/not_home from its
+	// own zones; with one, presence works from our home/zones config alone.
+	if t.trackerStateEnabled() {
+		config["state_topic"] = attributesTopic
+		config["value_template"] = "{{ value_json.state }}"
+		config["payload_home"] = geofence.TrackerHome
+		config["payload_not_home"] = geofence.TrackerNotHome
+	}
 	topic := fmt.Sprintf("%s/device_tracker/byd_car_%s/config", t.discoveryPrefix, t.deviceID)
 
 	return t.publishConfigRaw(topic, config)
@@ -428,7 +859,7 @@ func (t *MQTTTransmitter) publishLastTransmissionDiscovery(baseTopic string, dev
 func (t *MQTTTransmitter) publishLastTransmission() error {
 	topic := fmt.Sprintf("byd_car/%s/last_transmission", t.deviceID)
 	timestamp := time.Now().Format(time.RFC3339)
-	if err := t.client.Publish(topic, []byte(timestamp), true); err != nil {
+	if err := t.client.Publish(topic, []byte(timestamp), t.retained(RetainLastTransmission)); err != nil {
 		return fmt.Errorf("failed to publish last transmission timestamp to %s: %w", topic, err)
 	}
 	return nil
@@ -0,0 +1,100 @@
+package transmission
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+// Sensor IDs backing the sentry alarm panel.
+const (
+	SentryModeSensorID  = 1003
+	SentryAlarmSensorID = 1006
+)
+
+// SentryCommander switches sentry mode on the car. Implementations talk to
+// whatever write channel the head unit offers.
+type SentryCommander interface {
+	SetSentryMode(enabled bool) error
+}
+
+// SetSentryCommander enables arm/disarm commands from the alarm panel. Without
+// a commander, commands are rejected and the panel stays read-only in effect.
+func (t *MQTTTransmitter) SetSentryCommander(c SentryCommander) {
+	t.sentryCommander = c
+}
+
+// sentryPanelEnabled reports whether SentryModeStatus is published; in that
+// case it is exposed as an alarm panel instead of a raw binary sensor.
+func sentryPanelEnabled() bool {
+	return sensors.IsPublished(SentryModeSensorID)
+}
+
+// publishSentryAlarmPanelDiscovery publishes the alarm_control_panel config
+// and subscribes to its command topic.
+func (t *MQTTTransmitter) publishSentryAlarmPanelDiscovery(baseTopic string, device HADevice) error {
+	uniqueID := fmt.Sprintf("%s_sentry_mode", t.deviceID)
+	if t.publishedSensors[uniqueID] {
+		return nil
+	}
+
+	commandTopic := fmt.Sprintf("%s/sentry/set", baseTopic)
+	config := map[string]interface{}{
+		"name":                 "Sentry Mode",
+		"unique_id":            uniqueID,
+		"state_topic":          fmt.Sprintf("%s/state", baseTopic),
+		"value_template":       "{{ value_json.sentry_state | default('disarmed') }}",
+		"command_topic":        commandTopic,
+		"supported_features":   []string{"arm_away"},
+		"code_arm_required":    false,
+		"code_disarm_required": false,
+		"availability_topic":   fmt.Sprintf("%s/availability", baseTopic),
+		"device":               device,
+		"icon":                 "mdi:cctv",
+	}
+
+	if err := t.client.OnMessage(commandTopic, t.handleSentryCommand); err != nil {
+		return fmt.Errorf("failed to subscribe to sentry command topic: %w", err)
+	}
+
+	topic := fmt.Sprintf("%s/alarm_control_panel/byd_car_%s/sentry_mode/config", t.discoveryPrefix, t.deviceID)
+	if err := t.publishConfigRaw(topic, config); err != nil {
+		return fmt.Errorf("failed to publish Sentry Mode discovery config: %w", err)
+	}
+
+	t.logger.WithFields(logrus.Fields{
+		"entity_id": "sentry_mode",
+		"topic":     topic,
+	}).Debug("Published Sentry Mode discovery config")
+
+	t.publishedSensors[uniqueID] = true
+	return nil
+}
+
+// handleSentryCommand executes ARM_AWAY / DISARM commands from Home Assistant.
+func (t *MQTTTransmitter) handleSentryCommand(payload []byte) {
+	cmd := strings.ToUpper(strings.TrimSpace(string(payload)))
+
+	var enable bool
+	switch cmd {
+	case "ARM_AWAY":
+		enable = true
+	case "DISARM":
+		enable = false
+	default:
+		t.logger.WithField("command", cmd).Warn("Ignoring unsupported sentry command")
+		return
+	}
+
+	if t.sentryCommander == nil {
+		t.logger.WithField("command", cmd).Warn("Sentry command received but no Diplus write support is configured")
+		return
+	}
+	if err := t.sentryCommander.SetSentryMode(enable); err != nil {
+		t.logger.WithError(err).WithField("command", cmd).Warn("Failed to change sentry mode")
+		return
+	}
+	t.logger.WithField("command", cmd).Info("Sentry mode command executed")
+}
@@ -0,0 +1,96 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// supportURL is advertised in the discovery origin block.
+const supportURL = "https://github.com/jkaberg/byd-hass"
+
+// haOrigin is Home Assistant's discovery "origin" block, shown in the MQTT
+// device info and logs.
+type haOrigin struct {
+	Name       string `json:"name"`
+	SWVersion  string `json:"sw_version,omitempty"`
+	SupportURL string `json:"support_url,omitempty"`
+}
+
+// haAvailability is one entry of a discovery "availability" list.
+type haAvailability struct {
+	Topic string `json:"topic"`
+}
+
+// SetVersion sets the software version reported in the device and origin
+// blocks.
+func (t *MQTTTransmitter) SetVersion(version string) {
+	t.version = version
+}
+
+// diplusAvailabilityTopic carries "online"/"offline" for the Diplus link,
+// retained. It complements the bridge availability set by the MQTT LWT.
+func (t *MQTTTransmitter) diplusAvailabilityTopic() string {
+	return fmt.Sprintf("byd_car/%s/diplus/availability", t.deviceID)
+}
+
+// PublishDiplusAvailability records whether Diplus answered the last poll.
+// Only changes are published, so it is cheap to call after every poll.
+func (t *MQTTTransmitter) PublishDiplusAvailability(online bool) error {
+	t.diplusMu.Lock()
+	defer t.diplusMu.Unlock()
+	if t.diplusOnline != nil && *t.diplusOnline == online {
+		return nil
+	}
+
+	payload := "online"
+	if !online {
+		payload = "offline"
+	}
+	topic := t.diplusAvailabilityTopic()
+	if err := t.client.Publish(topic, []byte(payload), true); err != nil {
+		return fmt.Errorf("failed to publish Diplus availability to %s: %w", topic, err)
+	}
+	t.diplusOnline = &online
+	return nil
+}
+
+// diplusSensorConfig describes the diplus_connected diagnostic, read straight
+// from the Diplus availability topic.
+func (t *MQTTTransmitter) diplusSensorConfig() SensorConfig {
+	return SensorConfig{
+		Name:          "Diplus Connected",
+		EntityID:      "diplus_connected",
+		EntityType:    "binary_sensor",
+		DeviceClass:   "connectivity",
+		Category:      "diagnostic",
+		StateTopic:    t.diplusAvailabilityTopic(),
+		ValueTemplate: "{{ 'ON' if value == 'online' else 'OFF' }}",
+	}
+}
+
+// decorateDiscovery adds the origin block to a discovery payload and, for
+// entities fed by car data (the state and location topics), replaces the
+// single availability topic with the bridge AND Diplus availability so they
+// show unavailable while Diplus is down even though MQTT is up.
+func (t *MQTTTransmitter) decorateDiscovery(config interface{}) ([]byte, error) {
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+
+	m["origin"] = haOrigin{Name: "byd-hass", SWVersion: t.version, SupportURL: supportURL}
+
+	baseTopic := fmt.Sprintf("byd_car/%s", t.deviceID)
+	bridge, ok := m["availability_topic"].(string)
+	carData := m["state_topic"] == baseTopic+"/state" || m["json_attributes_topic"] == baseTopic+"/location"
+	if ok && carData {
+		delete(m, "availability_topic")
+		m["availability"] = []haAvailability{{Topic: bridge}, {Topic: t.diplusAvailabilityTopic()}}
+		m["availability_mode"] = "all"
+	}
+	return json.Marshal(m)
+}
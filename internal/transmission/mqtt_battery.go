@@ -0,0 +1,52 @@
+package transmission
+
+import (
+	"math"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// SetUsableCapacity enables the battery_energy and battery_health sensors
+// for a battery with the given usable capacity (kWh). 0 disables them.
+func (t *MQTTTransmitter) SetUsableCapacity(kwh float64) {
+	t.usableKWh = kwh
+}
+
+// batterySensorConfigs describes the sensors derived from the usable
+// capacity. battery_health is only announced once the car reports a
+// capacity, which most don't.
+func (t *MQTTTransmitter) batterySensorConfigs(data *sensors.SensorData) []SensorConfig {
+	var configs []SensorConfig
+	if _, ok := sensors.DeriveBatteryEnergy(data, t.usableKWh); ok {
+		configs = append(configs, SensorConfig{
+			Name:        "Battery Energy",
+			EntityID:    "battery_energy",
+			EntityType:  "sensor",
+			DeviceClass: "energy_storage",
+			Unit:        "kWh",
+			Icon:        "mdi:battery",
+			StateClass:  "measurement",
+		})
+	}
+	if _, ok := sensors.DeriveBatteryHealth(data, t.usableKWh); ok {
+		configs = append(configs, SensorConfig{
+			Name:       "Battery Health",
+			EntityID:   "battery_health",
+			EntityType: "sensor",
+			Unit:       "%",
+			Icon:       "mdi:battery-heart-variant",
+			StateClass: "measurement",
+		})
+	}
+	return configs
+}
+
+// addBatteryState injects the capacity-derived values into the state payload.
+func (t *MQTTTransmitter) addBatteryState(state map[string]interface{}, data *sensors.SensorData) {
+	if kwh, ok := sensors.DeriveBatteryEnergy(data, t.usableKWh); ok {
+		state["battery_energy"] = math.Round(kwh*10) / 10
+	}
+	if soh, ok := sensors.DeriveBatteryHealth(data, t.usableKWh); ok {
+		state["battery_health"] = math.Round(soh*10) / 10
+	}
+}
@@ -0,0 +1,68 @@
+package transmission
+
+import (
+	"math"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// SetCellAttributes also publishes the full per-cell arrays, as attributes
+// of the cell delta sensors.
+func (t *MQTTTransmitter) SetCellAttributes(enabled bool) {
+	t.cellAttributes = enabled
+}
+
+// cellSensorConfigs describes the min/max/delta sensors derived from the
+// per-cell battery readings. They are only announced once cells are
+// reported.
+func (t *MQTTTransmitter) cellSensorConfigs(data *sensors.SensorData) []SensorConfig {
+	if data == nil || data.Cells == nil {
+		return nil
+	}
+	var configs []SensorConfig
+	if _, ok := sensors.Spread(data.Cells.Voltages); ok {
+		delta := SensorConfig{Name: "Cell Voltage Delta", EntityID: "cell_voltage_delta", EntityType: "sensor", DeviceClass: "voltage", Unit: "mV", Icon: "mdi:battery-alert-variant-outline", StateClass: "measurement", Category: "diagnostic"}
+		if t.cellAttributes {
+			delta.AttributesTemplate = "{{ {'cells': value_json.cell_voltages} | tojson }}"
+		}
+		configs = append(configs,
+			SensorConfig{Name: "Cell Voltage Min", EntityID: "cell_voltage_min", EntityType: "sensor", DeviceClass: "voltage", Unit: "V", StateClass: "measurement", Category: "diagnostic"},
+			SensorConfig{Name: "Cell Voltage Max", EntityID: "cell_voltage_max", EntityType: "sensor", DeviceClass: "voltage", Unit: "V", StateClass: "measurement", Category: "diagnostic"},
+			delta)
+	}
+	if _, ok := sensors.Spread(data.Cells.Temperatures); ok {
+		delta := SensorConfig{Name: "Cell Temperature Delta", EntityID: "cell_temperature_delta", EntityType: "sensor", Unit: "°C", Icon: "mdi:thermometer-alert", StateClass: "measurement", Category: "diagnostic"}
+		if t.cellAttributes {
+			delta.AttributesTemplate = "{{ {'cells': value_json.cell_temperatures} | tojson }}"
+		}
+		configs = append(configs,
+			SensorConfig{Name: "Cell Temperature Min", EntityID: "cell_temperature_min", EntityType: "sensor", DeviceClass: "temperature", Unit: "°C", StateClass: "measurement", Category: "diagnostic"},
+			SensorConfig{Name: "Cell Temperature Max", EntityID: "cell_temperature_max", EntityType: "sensor", DeviceClass: "temperature", Unit: "°C", StateClass: "measurement", Category: "diagnostic"},
+			delta)
+	}
+	return configs
+}
+
+// addCellState injects the cell min/max/delta values and, with
+// SetCellAttributes, the full arrays.
+func (t *MQTTTransmitter) addCellState(state map[string]interface{}, data *sensors.SensorData) {
+	if data.Cells == nil {
+		return
+	}
+	if s, ok := sensors.Spread(data.Cells.Voltages); ok {
+		state["cell_voltage_min"] = math.Round(s.Min*1000) / 1000
+		state["cell_voltage_max"] = math.Round(s.Max*1000) / 1000
+		state["cell_voltage_delta"] = math.Round(s.Delta() * 1000)
+		if t.cellAttributes {
+			state["cell_voltages"] = data.Cells.Voltages
+		}
+	}
+	if s, ok := sensors.Spread(data.Cells.Temperatures); ok {
+		state["cell_temperature_min"] = s.Min
+		state["cell_temperature_max"] = s.Max
+		state["cell_temperature_delta"] = math.Round(s.Delta()*10) / 10
+		if t.cellAttributes {
+			state["cell_temperatures"] = data.Cells.Temperatures
+		}
+	}
+}
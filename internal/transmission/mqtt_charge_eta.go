@@ -0,0 +1,62 @@
+package transmission
+
+import (
+	"math"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// SetChargeTarget sets the SOC (%) charging_time_to_target and
+// charging_ready_at count towards.
+func (t *MQTTTransmitter) SetChargeTarget(soc float64) {
+	t.chargeTarget = soc
+}
+
+// chargeETAConfigs describes the charging time estimates. They read as
+// unknown while not charging, and are announced once the car reports SOC
+// and power.
+func chargeETAConfigs(data *sensors.SensorData) []SensorConfig {
+	if data == nil || data.BatteryPercentage == nil || data.EnginePower == nil {
+		return nil
+	}
+	return []SensorConfig{
+		{Name: "Charging Time To Full", EntityID: "charging_time_to_full", EntityType: "sensor", DeviceClass: "duration", Unit: "min", Icon: "mdi:timer-sand",
+			ValueTemplate: "{{ value_json.charging_time_to_full | default(None) }}"},
+		{Name: "Charging Time To Target", EntityID: "charging_time_to_target", EntityType: "sensor", DeviceClass: "duration", Unit: "min", Icon: "mdi:timer-sand",
+			ValueTemplate: "{{ value_json.charging_time_to_target | default(None) }}"},
+		{Name: "Charging Ready At", EntityID: "charging_ready_at", EntityType: "sensor", DeviceClass: "timestamp", Icon: "mdi:clock-check-outline",
+			ValueTemplate: "{{ value_json.charging_ready_at | default(None) }}"},
+	}
+}
+
+// addChargeETAState feeds the snapshot to the estimator and injects the
+// estimates (whole minutes) while charging.
+func (t *MQTTTransmitter) addChargeETAState(state map[string]interface{}, data *sensors.SensorData, charge ChargeReading) {
+	if data == nil || data.BatteryPercentage == nil {
+		return
+	}
+	kw, ok := charge.ChargerPower()
+	if !ok {
+		return
+	}
+	soc := *data.BatteryPercentage
+	ts := data.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	t.chargeETAMu.Lock()
+	defer t.chargeETAMu.Unlock()
+	t.chargeETA.Observe(ts, charge.Status() == "charging", kw, soc)
+	if left, ok := t.chargeETA.Remaining(soc, 100, t.usableKWh); ok {
+		state["charging_time_to_full"] = math.Ceil(left.Minutes())
+	}
+	if t.chargeTarget <= 0 {
+		return
+	}
+	if left, ok := t.chargeETA.Remaining(soc, t.chargeTarget, t.usableKWh); ok {
+		state["charging_time_to_target"] = math.Ceil(left.Minutes())
+		state["charging_ready_at"] = ts.Add(left).Truncate(time.Minute).Format(time.RFC3339)
+	}
+}
@@ -0,0 +1,59 @@
+package transmission
+
+import (
+	"math"
+
+	"github.com/jkaberg/byd-hass/internal/charging"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// chargerSensorConfigs describes charger_power, the unsigned charging power
+// for power graphs and Riemann-sum energy helpers. It is announced once the
+// car reports EnginePower.
+func chargerSensorConfigs(data *sensors.SensorData) []SensorConfig {
+	if _, ok := sensors.DeriveChargerPower(data); !ok {
+		return nil
+	}
+	return []SensorConfig{
+		{Name: "Charger Power", EntityID: "charger_power", EntityType: "sensor", DeviceClass: "power", Unit: "kW", Icon: "mdi:ev-station", StateClass: "measurement"},
+	}
+}
+
+// SetChargeTracker sets the tracker behind charging_status, charger_power,
+// charging_type and the charging estimates. Share it with ABRP.
+func (t *MQTTTransmitter) SetChargeTracker(c *ChargeTracker) {
+	t.charge = c
+}
+
+// addChargerState injects charger_power (kW, two decimals).
+func addChargerState(state map[string]interface{}, charge ChargeReading) {
+	if kw, ok := charge.ChargerPower(); ok {
+		state["charger_power"] = math.Round(kw*100) / 100
+	}
+}
+
+// chargingTypeConfigs describes charging_type, the AC/DC classification of
+// the current charging session. It is announced once the car reports
+// EnginePower.
+func chargingTypeConfigs(data *sensors.SensorData) []SensorConfig {
+	if _, ok := sensors.DeriveChargerPower(data); !ok {
+		return nil
+	}
+	return []SensorConfig{{
+		Name:        "Charging Type",
+		EntityID:    "charging_type",
+		EntityType:  "sensor",
+		DeviceClass: "enum",
+		Icon:        "mdi:ev-plug-type2",
+		Options:     []string{charging.TypeNone, charging.TypeUnknown, charging.TypeAC, charging.TypeDC},
+	}}
+}
+
+// addChargingTypeState injects charging_type, the session classification
+// of the shared ChargeTracker.
+func addChargingTypeState(state map[string]interface{}, charge ChargeReading) {
+	if charge.Power == nil {
+		return
+	}
+	state["charging_type"] = charge.Type
+}
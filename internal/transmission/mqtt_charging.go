@@ -0,0 +1,63 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/jkaberg/byd-hass/internal/charging"
+)
+
+// chargingCostTopic carries the charging energy and cost totals as retained
+// JSON.
+func (t *MQTTTransmitter) chargingCostTopic() string {
+	return fmt.Sprintf("byd_car/%s/charging_cost", t.deviceID)
+}
+
+// RegisterChargingCostSensors publishes discovery for the session and
+// monthly charging energy/cost sensors. currency is an ISO 4217 code.
+func (t *MQTTTransmitter) RegisterChargingCostSensors(currency string) error {
+	t.discoveryMu.Lock()
+	defer t.discoveryMu.Unlock()
+
+	device := t.device()
+	baseTopic := fmt.Sprintf("byd_car/%s", t.deviceID)
+	for _, sc := range []SensorConfig{
+		{Name: "Charging Session Energy", EntityID: "charging_session_energy", DeviceClass: "energy", Unit: "kWh", StateClass: "total_increasing", Icon: "mdi:battery-charging"},
+		{Name: "Charging Session Cost", EntityID: "charging_session_cost", DeviceClass: "monetary", Unit: currency, StateClass: "total", Icon: "mdi:cash"},
+		{Name: "Charging Energy This Month", EntityID: "charging_energy_this_month", DeviceClass: "energy", Unit: "kWh", StateClass: "total_increasing", Icon: "mdi:battery-charging"},
+		{Name: "Charging Cost This Month", EntityID: "charging_cost_this_month", DeviceClass: "monetary", Unit: currency, StateClass: "total", Icon: "mdi:cash-multiple"},
+	} {
+		sc.EntityType = "sensor"
+		sc.StateTopic = t.chargingCostTopic()
+		if err := t.publishDiscoveryForSensor(sc, device, baseTopic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishChargingCost publishes the tracker's totals (retained).
+func (t *MQTTTransmitter) PublishChargingCost(tracker *charging.Tracker) error {
+	if t.isPaused() {
+		return nil
+	}
+	state := map[string]interface{}{
+		"charging_energy_this_month": math.Round(tracker.MonthEnergy*100) / 100,
+		"charging_cost_this_month":   math.Round(tracker.MonthCost*100) / 100,
+	}
+	if s := tracker.Current; s != nil {
+		state["charging_session_energy"] = math.Round(s.Energy*100) / 100
+		state["charging_session_cost"] = math.Round(s.Cost*100) / 100
+		state["session_start"] = s.Start.UTC().Format("2006-01-02T15:04:05Z")
+		state["session_at_home"] = s.Counted
+	}
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal charging cost: %w", err)
+	}
+	if err := t.client.Publish(t.chargingCostTopic(), payload, true); err != nil {
+		return fmt.Errorf("failed to publish charging cost: %w", err)
+	}
+	return nil
+}
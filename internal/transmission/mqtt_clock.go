@@ -0,0 +1,43 @@
+package transmission
+
+import (
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// clockSensorConfigs describes the sensors fused from the head-unit clock.
+// Like the hybrid sensors they are only announced once the car reports a
+// complete clock.
+func clockSensorConfigs(data *sensors.SensorData) []SensorConfig {
+	if _, ok := sensors.CarClock(data, snapshotTime(data)); !ok {
+		return nil
+	}
+	return []SensorConfig{
+		{Name: "Car Clock", EntityID: "car_clock", EntityType: "sensor", DeviceClass: "timestamp", Icon: "mdi:clock-outline", Category: "diagnostic"},
+		{Name: "Clock Drift", EntityID: "clock_drift_seconds", EntityType: "sensor", DeviceClass: "duration", Unit: "s", Icon: "mdi:clock-alert-outline", StateClass: "measurement", Category: "diagnostic"},
+	}
+}
+
+// addClockState injects car_clock (RFC 3339) and clock_drift_seconds, the
+// car clock minus the time the snapshot was polled.
+func addClockState(state map[string]interface{}, data *sensors.SensorData) {
+	ref := snapshotTime(data)
+	clock, ok := sensors.CarClock(data, ref)
+	if !ok {
+		return
+	}
+	drift, _ := sensors.ClockDrift(data, ref)
+	// Normalised to the local zone; the car's may differ (-car-timezone).
+	state["car_clock"] = clock.In(time.Local).Format(time.RFC3339)
+	state["clock_drift_seconds"] = int(drift.Seconds())
+}
+
+// snapshotTime is when data was polled, falling back to now for snapshots
+// without a timestamp.
+func snapshotTime(data *sensors.SensorData) time.Time {
+	if data == nil || data.Timestamp.IsZero() {
+		return time.Now()
+	}
+	return data.Timestamp
+}
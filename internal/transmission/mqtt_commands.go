@@ -0,0 +1,70 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Command is a remote-control request received on byd_car/<device_id>/cmd,
+// e.g. {"command":"set_interval","target":"mqtt","interval":"30s"}.
+type Command struct {
+	Command  string `json:"command"`
+	Target   string `json:"target,omitempty"`
+	Interval string `json:"interval,omitempty"`
+}
+
+// SubscribeCommands subscribes to the command topic and passes every
+// well-formed command to handle. A bare command name ("pause") is accepted as
+// shorthand for {"command":"pause"}.
+func (t *MQTTTransmitter) SubscribeCommands(handle func(Command)) error {
+	topic := fmt.Sprintf("byd_car/%s/cmd", t.deviceID)
+	err := t.client.OnMessage(topic, func(payload []byte) {
+		var cmd Command
+		raw := strings.TrimSpace(string(payload))
+		if strings.HasPrefix(raw, "{") {
+			if err := json.Unmarshal(payload, &cmd); err != nil {
+				t.logger.WithError(err).Warn("Ignoring malformed MQTT command")
+				return
+			}
+		} else {
+			cmd.Command = raw
+		}
+		cmd.Command = strings.ToLower(strings.TrimSpace(cmd.Command))
+		if cmd.Command == "" {
+			t.logger.Warn("Ignoring MQTT command without a command name")
+			return
+		}
+		t.logger.WithFields(logrus.Fields{
+			"command":  cmd.Command,
+			"target":   cmd.Target,
+			"interval": cmd.Interval,
+		}).Info("Received MQTT command")
+		handle(cmd)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to command topic: %w", err)
+	}
+	return nil
+}
+
+// RepublishDiscovery re-sends every discovery config published so far, e.g.
+// after Home Assistant lost its retained configs.
+func (t *MQTTTransmitter) RepublishDiscovery() error {
+	t.discoveryMu.Lock()
+	defer t.discoveryMu.Unlock()
+
+	var failed int
+	for topic, payload := range t.discoveryCache {
+		if err := t.client.Publish(topic, payload, true); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to republish %d of %d discovery configs", failed, len(t.discoveryCache))
+	}
+	t.logger.WithField("configs", len(t.discoveryCache)).Info("Republished discovery configs")
+	return t.publishAvailability(true)
+}
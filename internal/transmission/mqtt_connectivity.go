@@ -0,0 +1,50 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// connectivitySensorConfigs describes the LAN/internet reachability
+// diagnostics published by PublishConnectivity.
+func (t *MQTTTransmitter) connectivitySensorConfigs() []SensorConfig {
+	topic := fmt.Sprintf("byd_car/%s/connectivity", t.deviceID)
+	return []SensorConfig{
+		{Name: "LAN Reachable", EntityID: "lan_reachable", EntityType: "binary_sensor", DeviceClass: "connectivity",
+			Icon: "mdi:lan-connect", Category: "diagnostic", StateTopic: topic, ValueTemplate: "{{ value_json.lan }}"},
+		{Name: "Internet Reachable", EntityID: "internet_reachable", EntityType: "binary_sensor", DeviceClass: "connectivity",
+			Icon: "mdi:web", Category: "diagnostic", StateTopic: topic, ValueTemplate: "{{ value_json.internet }}"},
+	}
+}
+
+// PublishConnectivity publishes which network paths are reachable to
+// byd_car/<id>/connectivity (retained), announcing the two diagnostic
+// binary sensors on first use.
+func (t *MQTTTransmitter) PublishConnectivity(lan, internet bool) error {
+	onOff := func(up bool) string {
+		if up {
+			return "ON"
+		}
+		return "OFF"
+	}
+
+	t.discoveryMu.Lock()
+	device := t.device()
+	baseTopic := fmt.Sprintf("byd_car/%s", t.deviceID)
+	for _, config := range t.connectivitySensorConfigs() {
+		if err := t.publishDiscoveryForSensor(config, device, baseTopic); err != nil {
+			t.logger.WithError(err).WithField("sensor", config.Name).Error("Failed to publish discovery config")
+		}
+	}
+	t.discoveryMu.Unlock()
+
+	payload, err := json.Marshal(map[string]string{"lan": onOff(lan), "internet": onOff(internet)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal connectivity: %w", err)
+	}
+	topic := fmt.Sprintf("byd_car/%s/connectivity", t.deviceID)
+	if err := t.client.Publish(topic, payload, true); err != nil {
+		return fmt.Errorf("failed to publish connectivity: %w", err)
+	}
+	return nil
+}
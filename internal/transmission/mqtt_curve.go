@@ -0,0 +1,68 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/charging"
+)
+
+// chargingCurveTopic carries the last DC charging curve as retained JSON.
+func (t *MQTTTransmitter) chargingCurveTopic() string {
+	return fmt.Sprintf("byd_car/%s/charging_curve", t.deviceID)
+}
+
+// RegisterChargingCurveSensor publishes discovery for last_dc_charge, whose
+// state is the session's peak power and whose attributes hold the curve.
+func (t *MQTTTransmitter) RegisterChargingCurveSensor() error {
+	t.discoveryMu.Lock()
+	defer t.discoveryMu.Unlock()
+
+	return t.publishDiscoveryForSensor(SensorConfig{
+		Name:               "Last DC Charge Peak Power",
+		EntityID:           "last_dc_charge",
+		EntityType:         "sensor",
+		DeviceClass:        "power",
+		Unit:               "kW",
+		Icon:               "mdi:ev-station",
+		StateTopic:         t.chargingCurveTopic(),
+		ValueTemplate:      "{{ value_json.peak_power }}",
+		AttributesTemplate: "{{ value_json | tojson }}",
+	}, t.device(), fmt.Sprintf("byd_car/%s", t.deviceID))
+}
+
+// PublishChargingCurve publishes a completed curve (retained), reduced to one
+// [soc, power_kw, battery_temp] point per SOC percent.
+func (t *MQTTTransmitter) PublishChargingCurve(curve *charging.Curve) error {
+	if t.isPaused() {
+		return nil
+	}
+	points := curve.PerPercent()
+	rows := make([][]interface{}, 0, len(points))
+	for _, p := range points {
+		var temp interface{}
+		if p.BatteryTemp != nil {
+			temp = *p.BatteryTemp
+		}
+		rows = append(rows, []interface{}{p.SOC, math.Round(p.Power*10) / 10, temp})
+	}
+	from, to := curve.SOCRange()
+	payload, err := json.Marshal(map[string]interface{}{
+		"start":        curve.Start.Format(time.RFC3339),
+		"end":          curve.End.Format(time.RFC3339),
+		"duration_min": int(curve.End.Sub(curve.Start).Minutes()),
+		"soc_from":     from,
+		"soc_to":       to,
+		"peak_power":   math.Round(curve.PeakPower()*10) / 10,
+		"curve":        rows,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal charging curve: %w", err)
+	}
+	if err := t.client.Publish(t.chargingCurveTopic(), payload, true); err != nil {
+		return fmt.Errorf("failed to publish charging curve: %w", err)
+	}
+	return nil
+}
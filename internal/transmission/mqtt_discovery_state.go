@@ -0,0 +1,131 @@
+package transmission
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DiscoveryStateFileName is the file below the state directory that records
+// the discovery configs published so far.
+const DiscoveryStateFileName = "discovery.json"
+
+// discoveryState is the on-disk record of published discovery configs. Only
+// hashes are kept; the payloads themselves are rebuilt on every start.
+// publishedSensors is deliberately not persisted: it also gates the command
+// topic subscriptions, which must be renewed on every start.
+type discoveryState struct {
+	Scope   string            `json:"scope"`   // broker and discovery prefix the hashes apply to
+	Configs map[string]string `json:"configs"` // topic → sha256 of the payload
+}
+
+// SetDiscoveryStatePath persists the hashes of published discovery configs
+// to path. Configs whose hash matches the stored one aren't published again
+// after a restart, unless LoadRetainedDiscovery ran: the broker's copies are
+// the better source, and when they couldn't be read everything is published. Changing the broker (brokerURL's host) or the
+// discovery prefix discards the stored hashes.
+func (t *MQTTTransmitter) SetDiscoveryStatePath(path, brokerURL string) {
+	// Only the host is kept; the URL may carry credentials.
+	broker := brokerURL
+	if u, err := url.Parse(brokerURL); err == nil {
+		broker = u.Host
+	}
+	scope := broker + "|" + t.discoveryPrefix
+	state := discoveryState{Scope: scope, Configs: make(map[string]string)}
+
+	raw, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		t.logger.WithError(err).Warn("Failed to read discovery state")
+	default:
+		var stored discoveryState
+		if err := json.Unmarshal(raw, &stored); err != nil {
+			t.logger.WithError(err).WithField("path", path).Warn("Ignoring unreadable discovery state")
+		} else if stored.Scope == scope && stored.Configs != nil {
+			state.Configs = stored.Configs
+		}
+	}
+
+	t.discoveryMu.Lock()
+	t.discoveryStatePath = path
+	t.discoveryState = state
+	t.discoveryMu.Unlock()
+}
+
+// knownDiscovery reports whether payload was the last config published to
+// topic according to the persisted state. Callers hold discoveryMu.
+func (t *MQTTTransmitter) knownDiscovery(topic string, payload []byte) bool {
+	if t.discoveryStatePath == "" || t.retainedDiscovery != nil {
+		return false
+	}
+	return t.discoveryState.Configs[topic] == discoveryHash(payload)
+}
+
+// rememberDiscovery records payload as published to topic. Callers hold
+// discoveryMu.
+func (t *MQTTTransmitter) rememberDiscovery(topic string, payload []byte) {
+	if t.discoveryStatePath == "" {
+		return
+	}
+	if hash := discoveryHash(payload); t.discoveryState.Configs[topic] != hash {
+		t.discoveryState.Configs[topic] = hash
+		t.discoveryDirty = true
+	}
+}
+
+// saveDiscoveryState writes the persisted state if it changed. Callers hold
+// discoveryMu.
+func (t *MQTTTransmitter) saveDiscoveryState() {
+	if !t.discoveryDirty {
+		return
+	}
+	if err := writeDiscoveryState(t.discoveryStatePath, t.discoveryState); err != nil {
+		t.logger.WithError(err).Warn("Failed to save discovery state")
+		return
+	}
+	t.discoveryDirty = false
+}
+
+// logDiscoverySummary logs one line per discovery pass that touched any
+// config, instead of one per entity. Callers hold discoveryMu.
+func (t *MQTTTransmitter) logDiscoverySummary() {
+	if t.discoveryPublished == 0 && t.discoveryUnchanged == 0 {
+		return
+	}
+	entry := t.logger.WithFields(logrus.Fields{
+		"published": t.discoveryPublished,
+		"unchanged": t.discoveryUnchanged,
+	})
+	if t.discoveryPublished > 0 {
+		entry.Info("Published discovery configs")
+	} else {
+		entry.Debug("Discovery configs up to date")
+	}
+	t.discoveryPublished, t.discoveryUnchanged = 0, 0
+}
+
+func writeDiscoveryState(path string, state discoveryState) error {
+	raw, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode discovery state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write discovery state: %w", err)
+	}
+	return nil
+}
+
+func discoveryHash(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,112 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+// EventEntity describes a Home Assistant MQTT "event" entity. Events are
+// published immediately (not on the MQTT interval) to
+// byd_car/<device_id>/event/<entity_id>.
+type EventEntity struct {
+	EntityID    string
+	Name        string
+	Icon        string
+	DeviceClass string
+	EventTypes  []string
+}
+
+// RegisterEventEntity publishes the discovery config for an event entity.
+// It is idempotent and safe to call from any goroutine.
+func (t *MQTTTransmitter) RegisterEventEntity(ev EventEntity) error {
+	t.discoveryMu.Lock()
+	defer t.discoveryMu.Unlock()
+
+	uniqueID := fmt.Sprintf("%s_%s", t.deviceID, ev.EntityID)
+	if t.publishedSensors[uniqueID] {
+		return nil
+	}
+
+	baseTopic := fmt.Sprintf("byd_car/%s", t.deviceID)
+	config := map[string]interface{}{
+		"name":               ev.Name,
+		"unique_id":          uniqueID,
+		"state_topic":        fmt.Sprintf("%s/event/%s", baseTopic, ev.EntityID),
+		"availability_topic": fmt.Sprintf("%s/availability", baseTopic),
+		"event_types":        ev.EventTypes,
+		"device":             t.device(),
+	}
+	if ev.Icon != "" {
+		config["icon"] = ev.Icon
+	}
+	if ev.DeviceClass != "" {
+		config["device_class"] = ev.DeviceClass
+	}
+
+	topic := fmt.Sprintf("%s/event/byd_car_%s/%s/config", t.discoveryPrefix, t.deviceID, ev.EntityID)
+	if err := t.publishConfigRaw(topic, config); err != nil {
+		return fmt.Errorf("failed to publish %s event discovery config: %w", ev.Name, err)
+	}
+
+	t.logger.WithFields(logrus.Fields{
+		"entity_id": ev.EntityID,
+		"topic":     topic,
+	}).Debug("Published event discovery config")
+
+	t.publishedSensors[uniqueID] = true
+	return nil
+}
+
+// EventPayload builds the JSON payload of an event: attrs merged with
+// "event_type", "timestamp" and "schema".
+func EventPayload(eventType string, attrs map[string]interface{}) ([]byte, error) {
+	payload := make(map[string]interface{}, len(attrs)+3)
+	for k, v := range attrs {
+		payload[k] = v
+	}
+	payload["event_type"] = eventType
+	payload["timestamp"] = time.Now().Format(time.RFC3339)
+	payload[sensors.SchemaField] = sensors.SchemaVersion
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return data, nil
+}
+
+// PublishEvent fires an event on the given event entity. attrs are merged
+// into the payload next to "event_type", "timestamp" and "schema" and show up
+// as event attributes in Home Assistant.
+func (t *MQTTTransmitter) PublishEvent(entityID, eventType string, attrs map[string]interface{}) error {
+	data, err := EventPayload(eventType, attrs)
+	if err != nil {
+		return err
+	}
+	return t.PublishEventPayload(entityID, eventType, data)
+}
+
+// PublishEventPayload fires an event whose payload was built by EventPayload.
+func (t *MQTTTransmitter) PublishEventPayload(entityID, eventType string, data []byte) error {
+	if t.isPaused() {
+		return nil
+	}
+	if !t.client.IsConnected() {
+		return fmt.Errorf("MQTT client not connected")
+	}
+
+	topic := fmt.Sprintf("byd_car/%s/event/%s", t.deviceID, entityID)
+	if err := t.client.Publish(topic, data, false); err != nil {
+		return fmt.Errorf("failed to publish event to %s: %w", topic, err)
+	}
+
+	t.logger.WithFields(logrus.Fields{
+		"entity_id":  entityID,
+		"event_type": eventType,
+	}).Info("Published event")
+	return nil
+}
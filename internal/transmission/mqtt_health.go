@@ -0,0 +1,31 @@
+package transmission
+
+// mqttHealthConfigs describe the broker connection, to tell broker-side
+// from cellular-side gaps. The values are those before the current publish.
+var mqttHealthConfigs = []SensorConfig{
+	{Name: "MQTT Link Quality", EntityID: "mqtt_link_quality", EntityType: "sensor", Unit: "%", Icon: "mdi:signal", StateClass: "measurement", Category: "diagnostic"},
+	{Name: "MQTT Publish Latency", EntityID: "mqtt_publish_latency", EntityType: "sensor", DeviceClass: "duration", Unit: "ms", Icon: "mdi:timer-sand", StateClass: "measurement", Category: "diagnostic"},
+	{Name: "MQTT Reconnects", EntityID: "mqtt_reconnects", EntityType: "sensor", Icon: "mdi:connection", StateClass: "total_increasing", Category: "diagnostic"},
+	{Name: "MQTT Publish Failures", EntityID: "mqtt_publish_failures", EntityType: "sensor", Icon: "mdi:alert-circle-outline", StateClass: "measurement", Category: "diagnostic"},
+}
+
+// mqttHealthKeys are the state keys of mqttHealthConfigs.
+var mqttHealthKeys = map[string]bool{
+	"mqtt_link_quality":     true,
+	"mqtt_publish_latency":  true,
+	"mqtt_reconnects":       true,
+	"mqtt_publish_failures": true,
+}
+
+// addMQTTHealthState injects the connection metrics. A transmitter without
+// a client (see RenderState) has none.
+func (t *MQTTTransmitter) addMQTTHealthState(state map[string]interface{}) {
+	if t.client == nil {
+		return
+	}
+	m := t.client.Metrics()
+	state["mqtt_link_quality"] = m.LinkQuality
+	state["mqtt_publish_latency"] = m.AvgLatency.Milliseconds()
+	state["mqtt_reconnects"] = m.Reconnects
+	state["mqtt_publish_failures"] = m.ConsecutiveFailures
+}
@@ -0,0 +1,40 @@
+package transmission
+
+import (
+	"math"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// SetHVACPower enables the hvac_power sensor, the estimated power draw of
+// the climate system.
+func (t *MQTTTransmitter) SetHVACPower(enabled bool) {
+	t.hvacPower = enabled
+}
+
+// hvacSensorConfigs describes hvac_power. It is announced once the car
+// reports its AC status.
+func (t *MQTTTransmitter) hvacSensorConfigs(data *sensors.SensorData) []SensorConfig {
+	if _, ok := sensors.EstimateHVACPower(data); !ok || !t.hvacPower {
+		return nil
+	}
+	return []SensorConfig{{
+		Name:        "HVAC Power",
+		EntityID:    "hvac_power",
+		EntityType:  "sensor",
+		DeviceClass: "power",
+		Unit:        "kW",
+		Icon:        "mdi:fan",
+		StateClass:  "measurement",
+	}}
+}
+
+// addHVACState injects the HVAC power estimate into the state payload.
+func (t *MQTTTransmitter) addHVACState(state map[string]interface{}, data *sensors.SensorData) {
+	if !t.hvacPower {
+		return
+	}
+	if kw, ok := sensors.EstimateHVACPower(data); ok {
+		state["hvac_power"] = math.Round(kw*100) / 100
+	}
+}
@@ -0,0 +1,52 @@
+package transmission
+
+import (
+	"math"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// SetFuelTankSize enables the fuel_level sensor (litres) for hybrids with a
+// tank of the given size. 0 disables it.
+func (t *MQTTTransmitter) SetFuelTankSize(liters float64) {
+	t.fuelTankLiters = liters
+}
+
+// hybridSensorConfigs describes the PHEV sensors derived from the combustion
+// side. They are only announced once data shows the car reports them, so
+// pure EVs don't get permanently empty entities.
+func (t *MQTTTransmitter) hybridSensorConfigs(data *sensors.SensorData) []SensorConfig {
+	var configs []SensorConfig
+	if _, ok := sensors.DeriveEngineRunning(data); ok {
+		configs = append(configs, SensorConfig{
+			Name:          "Engine Running",
+			EntityID:      "engine_running",
+			EntityType:    "binary_sensor",
+			DeviceClass:   "running",
+			Icon:          "mdi:engine",
+			ValueTemplate: "{{ 'ON' if value_json.engine_running else 'OFF' }}",
+		})
+	}
+	if _, ok := sensors.DeriveFuelLevel(data, t.fuelTankLiters); ok {
+		configs = append(configs, SensorConfig{
+			Name:        "Fuel Level",
+			EntityID:    "fuel_level",
+			EntityType:  "sensor",
+			DeviceClass: "volume_storage",
+			Unit:        "L",
+			Icon:        "mdi:gas-station",
+			StateClass:  "measurement",
+		})
+	}
+	return configs
+}
+
+// addHybridState injects the derived PHEV values into the state payload.
+func (t *MQTTTransmitter) addHybridState(state map[string]interface{}, data *sensors.SensorData) {
+	if running, ok := sensors.DeriveEngineRunning(data); ok {
+		state["engine_running"] = running
+	}
+	if liters, ok := sensors.DeriveFuelLevel(data, t.fuelTankLiters); ok {
+		state["fuel_level"] = math.Round(liters*10) / 10
+	}
+}
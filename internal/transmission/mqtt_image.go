@@ -0,0 +1,67 @@
+package transmission
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RegisterImageEntity publishes the discovery config for a Home Assistant
+// MQTT "image" entity fed with raw image bytes on
+// byd_car/<device_id>/image/<entity_id>. Registering it again with another
+// content type republishes the config, so call it before each PublishImage
+// whose format may differ from the last.
+func (t *MQTTTransmitter) RegisterImageEntity(entityID, name, contentType string) error {
+	t.discoveryMu.Lock()
+	defer t.discoveryMu.Unlock()
+
+	uniqueID := fmt.Sprintf("%s_%s", t.deviceID, entityID)
+	if t.publishedSensors[uniqueID] && t.imageTypes[entityID] == contentType {
+		return nil
+	}
+
+	baseTopic := fmt.Sprintf("byd_car/%s", t.deviceID)
+	config := map[string]interface{}{
+		"name":               name,
+		"unique_id":          uniqueID,
+		"image_topic":        fmt.Sprintf("%s/image/%s", baseTopic, entityID),
+		"content_type":       contentType,
+		"availability_topic": fmt.Sprintf("%s/availability", baseTopic),
+		"device":             t.device(),
+	}
+
+	topic := fmt.Sprintf("%s/image/byd_car_%s/%s/config", t.discoveryPrefix, t.deviceID, entityID)
+	if err := t.publishConfigRaw(topic, config); err != nil {
+		return fmt.Errorf("failed to publish %s image discovery config: %w", name, err)
+	}
+
+	t.logger.WithFields(logrus.Fields{
+		"entity_id":    entityID,
+		"content_type": contentType,
+		"topic":        topic,
+	}).Debug("Published image discovery config")
+
+	t.publishedSensors[uniqueID] = true
+	if t.imageTypes == nil {
+		t.imageTypes = make(map[string]string)
+	}
+	t.imageTypes[entityID] = contentType
+	return nil
+}
+
+// PublishImage publishes raw image bytes to an image entity. The message is
+// retained so Home Assistant shows the latest picture after a restart.
+func (t *MQTTTransmitter) PublishImage(entityID string, img []byte) error {
+	if t.isPaused() {
+		return nil
+	}
+	if !t.client.IsConnected() {
+		return fmt.Errorf("MQTT client not connected")
+	}
+
+	topic := fmt.Sprintf("byd_car/%s/image/%s", t.deviceID, entityID)
+	if err := t.client.Publish(topic, img, true); err != nil {
+		return fmt.Errorf("failed to publish image to %s: %w", topic, err)
+	}
+	return nil
+}
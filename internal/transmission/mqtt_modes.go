@@ -0,0 +1,107 @@
+package transmission
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+// DriveModeCommander changes the drive mode on the car. Implementations talk
+// to whatever write channel the head unit offers.
+type DriveModeCommander interface {
+	SetDriveMode(mode string) error
+}
+
+// SetDriveModeCommander enables the drive mode select entity. Without a
+// commander the drive mode is only shown as an enum sensor.
+func (t *MQTTTransmitter) SetDriveModeCommander(c DriveModeCommander) {
+	t.driveModeCommander = c
+}
+
+// modeValueTemplate renders the name of a numeric mode value, falling back
+// to "unknown" for codes without one.
+func modeValueTemplate(id int, key string) string {
+	names, _ := sensors.ModeNames(id)
+	codes := make([]int, 0, len(names))
+	for code := range names {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	pairs := make([]string, 0, len(codes))
+	for _, code := range codes {
+		pairs = append(pairs, fmt.Sprintf("%d: '%s'", code, names[code]))
+	}
+	return fmt.Sprintf("{{ {%s}.get(value_json.%s | int(-1), '%s') }}",
+		strings.Join(pairs, ", "), key, sensors.ModeUnknown)
+}
+
+// applyModeConfig turns a raw mode sensor into an enum sensor with readable
+// options. The state payload keeps the numeric code.
+func applyModeConfig(sc *SensorConfig, id int) {
+	if _, ok := sensors.ModeNames(id); !ok {
+		return
+	}
+	sc.EntityType = "sensor"
+	sc.DeviceClass = "enum"
+	sc.Unit = ""
+	sc.Options = sensors.ModeOptions(id)
+	sc.ValueTemplate = modeValueTemplate(id, sc.EntityID)
+}
+
+// publishDriveModeSelectDiscovery publishes a select entity for the drive
+// mode and subscribes to its command topic.
+func (t *MQTTTransmitter) publishDriveModeSelectDiscovery(baseTopic string, device HADevice) error {
+	uniqueID := fmt.Sprintf("%s_drive_mode_select", t.deviceID)
+	if t.publishedSensors[uniqueID] {
+		return nil
+	}
+
+	key := sensors.ToSnakeCase("VehicleRunningMode")
+	options := sensors.ModeOptions(sensors.DriveModeSensorID)
+	commandTopic := fmt.Sprintf("%s/drive_mode/set", baseTopic)
+	config := map[string]interface{}{
+		"name":               "Drive Mode",
+		"unique_id":          uniqueID,
+		"state_topic":        fmt.Sprintf("%s/state", baseTopic),
+		"value_template":     modeValueTemplate(sensors.DriveModeSensorID, key),
+		"command_topic":      commandTopic,
+		"options":            options[:len(options)-1], // "unknown" can't be selected
+		"availability_topic": fmt.Sprintf("%s/availability", baseTopic),
+		"device":             device,
+		"icon":               "mdi:car-shift-pattern",
+	}
+
+	if err := t.client.OnMessage(commandTopic, t.handleDriveModeCommand); err != nil {
+		return fmt.Errorf("failed to subscribe to drive mode command topic: %w", err)
+	}
+
+	topic := fmt.Sprintf("%s/select/byd_car_%s/drive_mode_select/config", t.discoveryPrefix, t.deviceID)
+	if err := t.publishConfigRaw(topic, config); err != nil {
+		return fmt.Errorf("failed to publish Drive Mode discovery config: %w", err)
+	}
+
+	t.logger.WithFields(logrus.Fields{
+		"entity_id": "drive_mode_select",
+		"topic":     topic,
+	}).Debug("Published Drive Mode discovery config")
+
+	t.publishedSensors[uniqueID] = true
+	return nil
+}
+
+// handleDriveModeCommand executes drive mode selections from Home Assistant.
+func (t *MQTTTransmitter) handleDriveModeCommand(payload []byte) {
+	mode := strings.TrimSpace(string(payload))
+	if _, ok := sensors.ModeCode(sensors.DriveModeSensorID, mode); !ok {
+		t.logger.WithField("mode", mode).Warn("Ignoring unknown drive mode")
+		return
+	}
+	if err := t.driveModeCommander.SetDriveMode(mode); err != nil {
+		t.logger.WithError(err).WithField("mode", mode).Warn("Failed to change drive mode")
+		return
+	}
+	t.logger.WithField("mode", mode).Info("Drive mode command executed")
+}
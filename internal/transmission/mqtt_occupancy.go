@@ -0,0 +1,35 @@
+package transmission
+
+import "github.com/jkaberg/byd-hass/internal/sensors"
+
+// occupancySensorConfigs describes occupants_detected, announced once the
+// car reports its seat belts while switched on. The occupied seats are
+// exposed as an attribute.
+func occupancySensorConfigs(data *sensors.SensorData) []SensorConfig {
+	if _, ok := sensors.DeriveOccupants(data); !ok {
+		return nil
+	}
+	return []SensorConfig{{
+		Name:               "Occupants Detected",
+		EntityID:           "occupants_detected",
+		EntityType:         "sensor",
+		Icon:               "mdi:account-group",
+		StateClass:         "measurement",
+		AttributesTemplate: "{{ {'occupied_seats': value_json.occupied_seats | default([])} | tojson }}",
+	}}
+}
+
+// addOccupancyState injects occupants_detected and occupied_seats. Both are
+// 0 / empty while the car is off so the last drive doesn't linger.
+func addOccupancyState(state map[string]interface{}, data *sensors.SensorData) {
+	seats, ok := sensors.DeriveOccupants(data)
+	if !ok {
+		if data != nil && data.PowerStatus != nil && *data.PowerStatus == 0 {
+			state["occupants_detected"] = 0
+			state["occupied_seats"] = []string{}
+		}
+		return
+	}
+	state["occupants_detected"] = len(seats)
+	state["occupied_seats"] = seats
+}
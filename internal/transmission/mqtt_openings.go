@@ -0,0 +1,59 @@
+package transmission
+
+import "github.com/jkaberg/byd-hass/internal/sensors"
+
+// openingsSensorConfigs describes any_door_open and any_window_open, each
+// announced once the car reports at least one of its sensors, and
+// windows_open_while_parked, which also needs the power state. The open
+// doors and windows are exposed as attributes so automations can name them.
+func openingsSensorConfigs(data *sensors.SensorData) []SensorConfig {
+	var configs []SensorConfig
+	if _, ok := sensors.DeriveOpenDoors(data); ok {
+		configs = append(configs, SensorConfig{
+			Name:               "Any Door Open",
+			EntityID:           "any_door_open",
+			EntityType:         "binary_sensor",
+			DeviceClass:        "door",
+			Icon:               "mdi:car-door",
+			ValueTemplate:      "{{ 'ON' if value_json.open_doors | default([]) | length > 0 else 'OFF' }}",
+			AttributesTemplate: "{{ {'open_doors': value_json.open_doors | default([])} | tojson }}",
+		})
+	}
+	if _, ok := sensors.DeriveOpenWindows(data); ok {
+		configs = append(configs, SensorConfig{
+			Name:               "Any Window Open",
+			EntityID:           "any_window_open",
+			EntityType:         "binary_sensor",
+			DeviceClass:        "window",
+			Icon:               "mdi:window-open-variant",
+			ValueTemplate:      "{{ 'ON' if value_json.open_windows | default([]) | length > 0 else 'OFF' }}",
+			AttributesTemplate: "{{ {'open_windows': value_json.open_windows | default([])} | tojson }}",
+		})
+	}
+	if _, ok := sensors.DeriveWindowsOpenWhileParked(data); ok {
+		configs = append(configs, SensorConfig{
+			Name:               "Windows Open While Parked",
+			EntityID:           "windows_open_while_parked",
+			EntityType:         "binary_sensor",
+			DeviceClass:        "window",
+			Icon:               "mdi:car-door",
+			ValueTemplate:      "{{ 'ON' if value_json.windows_open_while_parked else 'OFF' }}",
+			AttributesTemplate: "{{ {'open_windows': value_json.open_windows | default([])} | tojson }}",
+		})
+	}
+	return configs
+}
+
+// addOpeningsState injects the open_doors and open_windows lists and
+// windows_open_while_parked.
+func addOpeningsState(state map[string]interface{}, data *sensors.SensorData) {
+	if doors, ok := sensors.DeriveOpenDoors(data); ok {
+		state["open_doors"] = doors
+	}
+	if windows, ok := sensors.DeriveOpenWindows(data); ok {
+		state["open_windows"] = windows
+	}
+	if open, ok := sensors.DeriveWindowsOpenWhileParked(data); ok {
+		state["windows_open_while_parked"] = open
+	}
+}
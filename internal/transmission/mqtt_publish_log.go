@@ -0,0 +1,67 @@
+package transmission
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// volatileStateKeys change on nearly every publish without saying anything
+// about the car, so they don't count as changes in the publish summary.
+var volatileStateKeys = map[string]bool{
+	"timestamp":            true,
+	"data_age_seconds":     true,
+	"gps_fix_age":          true,
+	"mqtt_link_quality":    true,
+	"mqtt_publish_latency": true,
+}
+
+// maxLoggedChanges caps the changed keys named in the publish summary.
+const maxLoggedChanges = 10
+
+// logStatePublish logs a one-line summary of a state publish: key count,
+// payload size and the keys that changed since the previous publish. It logs
+// at INFO only when something changed; the full payload goes to DEBUG.
+func (t *MQTTTransmitter) logStatePublish(topic string, state map[string]interface{}, body []byte) {
+	t.lastStateMu.Lock()
+	changed := changedStateKeys(t.lastState, state)
+	t.lastState = state
+	t.lastStateMu.Unlock()
+
+	fields := logrus.Fields{"topic": topic, "keys": len(state), "size": len(body), "changed": len(changed)}
+	if len(changed) > maxLoggedChanges {
+		changed = append(changed[:maxLoggedChanges], "…")
+	}
+	if len(changed) > 0 {
+		fields["changed_keys"] = changed
+		t.logger.WithFields(fields).Info("Published sensor data")
+	} else {
+		t.logger.WithFields(fields).Debug("Published sensor data")
+	}
+
+	if t.jsonState() && t.logger.IsLevelEnabled(logrus.DebugLevel) {
+		t.logger.WithFields(logrus.Fields{"topic": topic, "payload": string(body)}).Debug("Sensor data payload")
+	}
+}
+
+// changedStateKeys returns the sorted keys whose value differs between two
+// state payloads, including added and removed keys.
+func changedStateKeys(prev, next map[string]interface{}) []string {
+	var changed []string
+	for key, value := range next {
+		if volatileStateKeys[key] {
+			continue
+		}
+		if old, ok := prev[key]; !ok || !reflect.DeepEqual(old, value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range prev {
+		if _, ok := next[key]; !ok && !volatileStateKeys[key] {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
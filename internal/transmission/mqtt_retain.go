@@ -0,0 +1,47 @@
+package transmission
+
+import (
+	"strings"
+	"time"
+)
+
+// Topics whose retain flag is configurable (see SetRetain).
+const (
+	RetainState            = "state"
+	RetainLocation         = "location"
+	RetainLastTransmission = "last_transmission"
+)
+
+// DefaultRetain is the retained set used unless SetRetain is called. The
+// location isn't retained, so a tracker doesn't show a week-old position as
+// current after a Home Assistant restart.
+var DefaultRetain = []string{RetainState, RetainLastTransmission}
+
+// SetRetain selects which of the RetainState, RetainLocation and
+// RetainLastTransmission topics are published retained.
+func (t *MQTTTransmitter) SetRetain(topics []string) {
+	t.retain = make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		t.retain[strings.TrimSpace(topic)] = true
+	}
+}
+
+// retained reports whether topic (one of the Retain* names) is retained.
+func (t *MQTTTransmitter) retained(topic string) bool {
+	if t.retain == nil {
+		for _, r := range DefaultRetain {
+			if r == topic {
+				return true
+			}
+		}
+		return false
+	}
+	return t.retain[topic]
+}
+
+// SetExpireAfter makes the entities on the state topic unavailable when no
+// state arrives for d (Home Assistant's expire_after; 0 = never). Pair it
+// with a shorter force update interval, since unchanged data isn't resent.
+func (t *MQTTTransmitter) SetExpireAfter(d time.Duration) {
+	t.expireAfter = d
+}
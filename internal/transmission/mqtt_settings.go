@@ -0,0 +1,182 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// IntervalNumber describes a Home Assistant MQTT "number" entity that shows a
+// runtime interval (in seconds) and changes it through the command channel
+// with a set_interval command for Target.
+type IntervalNumber struct {
+	Target string // set_interval target: poll, mqtt, abrp, traccar
+	Name   string
+	Min    int
+	Max    int
+}
+
+// settingsTopic carries the current runtime settings as retained JSON.
+func (t *MQTTTransmitter) settingsTopic() string {
+	return fmt.Sprintf("byd_car/%s/settings", t.deviceID)
+}
+
+// RegisterIntervalNumber publishes the discovery config for an interval
+// number entity. Its value is read from "<target>_interval" in the settings
+// payload (see PublishSettings).
+func (t *MQTTTransmitter) RegisterIntervalNumber(n IntervalNumber) error {
+	t.discoveryMu.Lock()
+	defer t.discoveryMu.Unlock()
+
+	entityID := n.Target + "_interval"
+	uniqueID := fmt.Sprintf("%s_%s", t.deviceID, entityID)
+	if t.publishedSensors[uniqueID] {
+		return nil
+	}
+
+	baseTopic := fmt.Sprintf("byd_car/%s", t.deviceID)
+	config := map[string]interface{}{
+		"name":                n.Name,
+		"unique_id":           uniqueID,
+		"state_topic":         t.settingsTopic(),
+		"value_template":      fmt.Sprintf("{{ value_json.%s }}", entityID),
+		"command_topic":       fmt.Sprintf("%s/cmd", baseTopic),
+		"command_template":    fmt.Sprintf(`{"command":"set_interval","target":"%s","interval":"{{ value | int }}s"}`, n.Target),
+		"min":                 n.Min,
+		"max":                 n.Max,
+		"step":                1,
+		"mode":                "box",
+		"unit_of_measurement": "s",
+		"device_class":        "duration",
+		"entity_category":     "config",
+		"icon":                "mdi:timer-cog-outline",
+		"availability_topic":  fmt.Sprintf("%s/availability", baseTopic),
+		"device":              t.device(),
+	}
+
+	topic := fmt.Sprintf("%s/number/byd_car_%s/%s/config", t.discoveryPrefix, t.deviceID, entityID)
+	if err := t.publishConfigRaw(topic, config); err != nil {
+		return fmt.Errorf("failed to publish %s number discovery config: %w", n.Name, err)
+	}
+
+	t.logger.WithFields(logrus.Fields{
+		"entity_id": entityID,
+		"topic":     topic,
+	}).Debug("Published number discovery config")
+
+	t.publishedSensors[uniqueID] = true
+	return nil
+}
+
+// PublishSettings publishes the current runtime settings (retained).
+func (t *MQTTTransmitter) PublishSettings(settings map[string]interface{}) error {
+	payload, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	if err := t.client.Publish(t.settingsTopic(), payload, true); err != nil {
+		return fmt.Errorf("failed to publish settings: %w", err)
+	}
+	return nil
+}
+
+// SettingSwitch describes a Home Assistant MQTT "switch" entity whose state is
+// read from Key ("ON"/"OFF") in the settings payload and which sends the
+// CommandOn / CommandOff commands through the command channel.
+type SettingSwitch struct {
+	Key        string
+	Name       string
+	Icon       string
+	CommandOn  string
+	CommandOff string
+}
+
+// RegisterSettingSwitch publishes the discovery config for a settings switch.
+func (t *MQTTTransmitter) RegisterSettingSwitch(s SettingSwitch) error {
+	t.discoveryMu.Lock()
+	defer t.discoveryMu.Unlock()
+
+	uniqueID := fmt.Sprintf("%s_%s", t.deviceID, s.Key)
+	if t.publishedSensors[uniqueID] {
+		return nil
+	}
+
+	baseTopic := fmt.Sprintf("byd_car/%s", t.deviceID)
+	config := map[string]interface{}{
+		"name":               s.Name,
+		"unique_id":          uniqueID,
+		"state_topic":        t.settingsTopic(),
+		"value_template":     fmt.Sprintf("{{ value_json.%s }}", s.Key),
+		"state_on":           "ON",
+		"state_off":          "OFF",
+		"command_topic":      fmt.Sprintf("%s/cmd", baseTopic),
+		"payload_on":         fmt.Sprintf(`{"command":"%s"}`, s.CommandOn),
+		"payload_off":        fmt.Sprintf(`{"command":"%s"}`, s.CommandOff),
+		"entity_category":    "config",
+		"icon":               s.Icon,
+		"availability_topic": fmt.Sprintf("%s/availability", baseTopic),
+		"device":             t.device(),
+	}
+
+	topic := fmt.Sprintf("%s/switch/byd_car_%s/%s/config", t.discoveryPrefix, t.deviceID, s.Key)
+	if err := t.publishConfigRaw(topic, config); err != nil {
+		return fmt.Errorf("failed to publish %s switch discovery config: %w", s.Name, err)
+	}
+
+	t.logger.WithFields(logrus.Fields{
+		"entity_id": s.Key,
+		"topic":     topic,
+	}).Debug("Published switch discovery config")
+
+	t.publishedSensors[uniqueID] = true
+	return nil
+}
+
+// CommandButton describes a Home Assistant MQTT "button" entity that sends
+// Command through the command channel when pressed.
+type CommandButton struct {
+	Key      string
+	Name     string
+	Icon     string
+	Command  string
+	Category string // entity_category, "" for a primary entity
+}
+
+// RegisterCommandButton publishes the discovery config for a command button.
+func (t *MQTTTransmitter) RegisterCommandButton(b CommandButton) error {
+	t.discoveryMu.Lock()
+	defer t.discoveryMu.Unlock()
+
+	uniqueID := fmt.Sprintf("%s_%s", t.deviceID, b.Key)
+	if t.publishedSensors[uniqueID] {
+		return nil
+	}
+
+	baseTopic := fmt.Sprintf("byd_car/%s", t.deviceID)
+	config := map[string]interface{}{
+		"name":               b.Name,
+		"unique_id":          uniqueID,
+		"command_topic":      fmt.Sprintf("%s/cmd", baseTopic),
+		"payload_press":      fmt.Sprintf(`{"command":"%s"}`, b.Command),
+		"icon":               b.Icon,
+		"availability_topic": fmt.Sprintf("%s/availability", baseTopic),
+		"device":             t.device(),
+	}
+	if b.Category != "" {
+		config["entity_category"] = b.Category
+	}
+
+	topic := fmt.Sprintf("%s/button/byd_car_%s/%s/config", t.discoveryPrefix, t.deviceID, b.Key)
+	if err := t.publishConfigRaw(topic, config); err != nil {
+		return fmt.Errorf("failed to publish %s button discovery config: %w", b.Name, err)
+	}
+
+	t.logger.WithFields(logrus.Fields{
+		"entity_id": b.Key,
+		"topic":     topic,
+	}).Debug("Published button discovery config")
+
+	t.publishedSensors[uniqueID] = true
+	return nil
+}
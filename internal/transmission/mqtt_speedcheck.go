@@ -0,0 +1,49 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jkaberg/byd-hass/internal/events"
+)
+
+// speedCheckTopic carries the GPS vs wheel speed verdict as retained JSON.
+func (t *MQTTTransmitter) speedCheckTopic() string {
+	return fmt.Sprintf("byd_car/%s/speed_check", t.deviceID)
+}
+
+// RegisterSpeedCheckSensor publishes discovery for the speed_mismatch problem
+// sensor. The reason and median GPS/wheel ratio are exposed as attributes.
+func (t *MQTTTransmitter) RegisterSpeedCheckSensor() error {
+	t.discoveryMu.Lock()
+	defer t.discoveryMu.Unlock()
+
+	return t.publishDiscoveryForSensor(SensorConfig{
+		Name:               "Speed Mismatch",
+		EntityID:           "speed_mismatch",
+		EntityType:         "binary_sensor",
+		DeviceClass:        "problem",
+		Icon:               "mdi:speedometer-slow",
+		Category:           "diagnostic",
+		StateTopic:         t.speedCheckTopic(),
+		ValueTemplate:      "{{ 'ON' if value_json.problem else 'OFF' }}",
+		AttributesTemplate: "{{ {'reason': value_json.reason, 'ratio': value_json.ratio, 'samples': value_json.samples} | tojson }}",
+	}, t.device(), fmt.Sprintf("byd_car/%s", t.deviceID))
+}
+
+// PublishSpeedCheck publishes the current verdict (retained).
+func (t *MQTTTransmitter) PublishSpeedCheck(res events.SpeedCheckResult) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"problem": res.Problem,
+		"reason":  res.Reason,
+		"ratio":   res.Ratio,
+		"samples": res.Samples,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal speed check: %w", err)
+	}
+	if err := t.client.Publish(t.speedCheckTopic(), payload, true); err != nil {
+		return fmt.Errorf("failed to publish speed check: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/jkaberg/byd-hass/internal/stats"
+)
+
+// statisticsTopic carries the per-period usage totals as retained JSON.
+func (t *MQTTTransmitter) statisticsTopic() string {
+	return fmt.Sprintf("byd_car/%s/statistics", t.deviceID)
+}
+
+// statisticsPeriods maps the stats periods to entity ID suffixes and names.
+var statisticsPeriods = []struct {
+	period       stats.Period
+	suffix, name string
+}{
+	{stats.Day, "today", "Today"},
+	{stats.Week, "this_week", "This Week"},
+	{stats.Month, "this_month", "This Month"},
+}
+
+// RegisterStatisticsSensors publishes discovery for the distance and energy
+// totals per day, week and month. They are total_increasing sensors that
+// reset at each period boundary, which Home Assistant's long-term statistics
+// handle natively.
+func (t *MQTTTransmitter) RegisterStatisticsSensors() error {
+	t.discoveryMu.Lock()
+	defer t.discoveryMu.Unlock()
+
+	device := t.device()
+	baseTopic := fmt.Sprintf("byd_car/%s", t.deviceID)
+	for _, p := range statisticsPeriods {
+		for _, sc := range []SensorConfig{
+			{Name: "Distance " + p.name, EntityID: "distance_" + p.suffix, DeviceClass: "distance", Unit: "km", Icon: "mdi:map-marker-distance"},
+			{Name: "Energy " + p.name, EntityID: "energy_" + p.suffix, DeviceClass: "energy", Unit: "kWh", Icon: "mdi:lightning-bolt"},
+		} {
+			sc.EntityType = "sensor"
+			sc.StateClass = "total_increasing"
+			sc.StateTopic = t.statisticsTopic()
+			if err := t.publishDiscoveryForSensor(sc, device, baseTopic); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// PublishStatistics publishes the tracker's current totals (retained).
+func (t *MQTTTransmitter) PublishStatistics(tracker *stats.Tracker) error {
+	if t.isPaused() {
+		return nil
+	}
+	state := make(map[string]interface{}, 2*len(statisticsPeriods))
+	for _, p := range statisticsPeriods {
+		totals := tracker.Get(p.period)
+		state["distance_"+p.suffix] = math.Round(totals.Distance*10) / 10
+		state["energy_"+p.suffix] = math.Round(totals.Energy*100) / 100
+	}
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal statistics: %w", err)
+	}
+	if err := t.client.Publish(t.statisticsTopic(), payload, true); err != nil {
+		return fmt.Errorf("failed to publish statistics: %w", err)
+	}
+	return nil
+}
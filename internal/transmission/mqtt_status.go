@@ -0,0 +1,26 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/jkaberg/byd-hass/internal/status"
+)
+
+// PublishStatus publishes the bridge's own health report to
+// byd_car/<id>/status (retained).
+func (t *MQTTTransmitter) PublishStatus(report status.Report) error {
+	payload, err := json.Marshal(struct {
+		Schema int `json:"schema"`
+		status.Report
+	}{sensors.SchemaVersion, report})
+	if err != nil {
+		return fmt.Errorf("failed to marshal status: %w", err)
+	}
+	topic := fmt.Sprintf("byd_car/%s/status", t.deviceID)
+	if err := t.client.Publish(topic, payload, true); err != nil {
+		return fmt.Errorf("failed to publish status: %w", err)
+	}
+	return nil
+}
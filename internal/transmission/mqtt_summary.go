@@ -0,0 +1,78 @@
+package transmission
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/geofence"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+)
+
+// summarySensorConfig describes status_summary, a one-line description of
+// what the car is doing for small dashboards and watch complications.
+var summarySensorConfig = SensorConfig{
+	Name:          "Status",
+	EntityID:      "status_summary",
+	EntityType:    "sensor",
+	Icon:          "mdi:car-info",
+	ValueTemplate: "{{ value_json.status_summary | default('unknown') }}",
+}
+
+// addSummaryState injects status_summary, e.g. "Charging 54 kW · 63% ·
+// ready 14:35" or "Parked at Home · 78%". It reads the derived keys, so it
+// must run after they are added.
+func (t *MQTTTransmitter) addSummaryState(state map[string]interface{}, data *sensors.SensorData) {
+	var parts []string
+	where := ""
+	if t.trackerStateEnabled() && hasLocationFix(data) {
+		zone := geofence.TrackerState(t.home, t.zones, data.Location.Latitude, data.Location.Longitude)
+		if zone != geofence.TrackerNotHome {
+			where = " at " + strings.ToUpper(zone[:1]) + zone[1:]
+		}
+	}
+
+	switch state["state"] {
+	case "moving":
+		parts = append(parts, fmt.Sprintf("Driving %.0f km/h", *data.Speed))
+	case "charging":
+		head := "Charging"
+		if kw, ok := state["charger_power"].(float64); ok {
+			head += " " + formatKW(kw)
+		}
+		parts = append(parts, head)
+	default:
+		if state["charging_status"] == "connected" {
+			parts = append(parts, "Plugged in"+where)
+		} else {
+			parts = append(parts, "Parked"+where)
+		}
+	}
+
+	if data.BatteryPercentage != nil {
+		parts = append(parts, fmt.Sprintf("%.0f%%", *data.BatteryPercentage))
+	}
+	if ready, ok := state["charging_ready_at"].(string); ok && state["state"] == "charging" {
+		if ts, err := time.Parse(time.RFC3339, ready); err == nil {
+			parts = append(parts, "ready "+formatClock(ts.Local(), time.Now()))
+		}
+	}
+	state["status_summary"] = strings.Join(parts, " · ")
+}
+
+// formatKW prints whole kilowatts, with one decimal below 10 kW.
+func formatKW(kw float64) string {
+	if kw < 10 {
+		return fmt.Sprintf("%.1f kW", kw)
+	}
+	return fmt.Sprintf("%.0f kW", kw)
+}
+
+// formatClock prints ts as 15:04, prefixed with the weekday unless it is
+// within the next 24 hours.
+func formatClock(ts, now time.Time) string {
+	if ts.Sub(now) >= 24*time.Hour {
+		return ts.Format("Mon 15:04")
+	}
+	return ts.Format("15:04")
+}
@@ -0,0 +1,129 @@
+package transmission
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+// CarSwitch describes a head-unit setting exposed as a Home Assistant switch.
+// Its state is read from the sensor with SensorID (non-zero = on).
+type CarSwitch struct {
+	SensorID int
+	Key      string // entity ID and state payload key
+	Name     string
+	Icon     string
+	value    func(*sensors.SensorData) *float64
+}
+
+// CarSwitches lists the settings that can be exposed as switches.
+var CarSwitches = []CarSwitch{
+	{SensorID: SentryModeSensorID, Key: "sentry_mode_switch", Name: "Sentry Mode Switch", Icon: "mdi:cctv",
+		value: func(d *sensors.SensorData) *float64 { return d.SentryModeStatus }},
+	{SensorID: 1004, Key: "power_off_recording", Name: "Power-Off Recording", Icon: "mdi:record-rec",
+		value: func(d *sensors.SensorData) *float64 { return d.PowerOffRecordingConfig }},
+	{SensorID: 1101, Key: "wireless_adb", Name: "Wireless ADB", Icon: "mdi:android-debug-bridge",
+		value: func(d *sensors.SensorData) *float64 { return d.WirelessADBSwitch }},
+}
+
+// CarSwitchCommander changes head-unit settings. SupportsCarSwitch reports
+// whether the setting behind a sensor ID can be written at all.
+type CarSwitchCommander interface {
+	SupportsCarSwitch(sensorID int) bool
+	SetCarSwitch(sensorID int, on bool) error
+}
+
+// SetCarSwitchCommander enables a switch for every CarSwitches entry the
+// commander supports. The backing sensors must be monitored.
+func (t *MQTTTransmitter) SetCarSwitchCommander(c CarSwitchCommander) {
+	t.carSwitchCommander = c
+}
+
+// carSwitches returns the switches the commander supports.
+func (t *MQTTTransmitter) carSwitches() []CarSwitch {
+	if t.carSwitchCommander == nil {
+		return nil
+	}
+	var out []CarSwitch
+	for _, s := range CarSwitches {
+		if t.carSwitchCommander.SupportsCarSwitch(s.SensorID) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// addCarSwitchState adds "ON"/"OFF" for every enabled switch whose sensor
+// has a value.
+func (t *MQTTTransmitter) addCarSwitchState(state map[string]interface{}, data *sensors.SensorData) {
+	for _, s := range t.carSwitches() {
+		v := s.value(data)
+		if v == nil {
+			continue
+		}
+		if *v != 0 {
+			state[s.Key] = "ON"
+		} else {
+			state[s.Key] = "OFF"
+		}
+	}
+}
+
+// publishCarSwitchDiscovery publishes a switch config and subscribes to its
+// command topic.
+func (t *MQTTTransmitter) publishCarSwitchDiscovery(baseTopic string, device HADevice, s CarSwitch) error {
+	uniqueID := fmt.Sprintf("%s_%s", t.deviceID, s.Key)
+	if t.publishedSensors[uniqueID] {
+		return nil
+	}
+
+	commandTopic := fmt.Sprintf("%s/%s/set", baseTopic, s.Key)
+	config := map[string]interface{}{
+		"name":               s.Name,
+		"unique_id":          uniqueID,
+		"state_topic":        fmt.Sprintf("%s/state", baseTopic),
+		"value_template":     fmt.Sprintf("{{ value_json.%s }}", s.Key),
+		"state_on":           "ON",
+		"state_off":          "OFF",
+		"command_topic":      commandTopic,
+		"entity_category":    "config",
+		"icon":               s.Icon,
+		"availability_topic": fmt.Sprintf("%s/availability", baseTopic),
+		"device":             device,
+	}
+
+	id := s.SensorID
+	handler := func(payload []byte) { t.handleCarSwitchCommand(id, s.Key, payload) }
+	if err := t.client.OnMessage(commandTopic, handler); err != nil {
+		return fmt.Errorf("failed to subscribe to %s command topic: %w", s.Key, err)
+	}
+
+	topic := fmt.Sprintf("%s/switch/byd_car_%s/%s/config", t.discoveryPrefix, t.deviceID, s.Key)
+	if err := t.publishConfigRaw(topic, config); err != nil {
+		return fmt.Errorf("failed to publish %s switch discovery config: %w", s.Name, err)
+	}
+
+	t.logger.WithFields(logrus.Fields{
+		"entity_id": s.Key,
+		"topic":     topic,
+	}).Debug("Published switch discovery config")
+
+	t.publishedSensors[uniqueID] = true
+	return nil
+}
+
+// handleCarSwitchCommand executes ON / OFF commands from Home Assistant.
+func (t *MQTTTransmitter) handleCarSwitchCommand(sensorID int, key string, payload []byte) {
+	cmd := strings.ToUpper(strings.TrimSpace(string(payload)))
+	if cmd != "ON" && cmd != "OFF" {
+		t.logger.WithFields(logrus.Fields{"switch": key, "command": cmd}).Warn("Ignoring unsupported switch command")
+		return
+	}
+	if err := t.carSwitchCommander.SetCarSwitch(sensorID, cmd == "ON"); err != nil {
+		t.logger.WithError(err).WithField("switch", key).Warn("Failed to change car setting")
+		return
+	}
+	t.logger.WithFields(logrus.Fields{"switch": key, "command": cmd}).Info("Car setting changed")
+}
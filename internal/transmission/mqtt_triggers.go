@@ -0,0 +1,57 @@
+package transmission
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RegisterDeviceTrigger publishes the device_automation discovery config for
+// a trigger so it shows up on the Home Assistant device page. Safe to call
+// from any goroutine.
+func (t *MQTTTransmitter) RegisterDeviceTrigger(triggerID, triggerType, subtype string) error {
+	t.discoveryMu.Lock()
+	defer t.discoveryMu.Unlock()
+
+	uniqueID := fmt.Sprintf("%s_trigger_%s", t.deviceID, triggerID)
+	if t.publishedSensors[uniqueID] {
+		return nil
+	}
+
+	config := map[string]interface{}{
+		"automation_type": "trigger",
+		"topic":           fmt.Sprintf("byd_car/%s/trigger/%s", t.deviceID, triggerID),
+		"type":            triggerType,
+		"subtype":         subtype,
+		"payload":         triggerID,
+		"device":          t.device(),
+	}
+
+	topic := fmt.Sprintf("%s/device_automation/byd_car_%s/%s/config", t.discoveryPrefix, t.deviceID, triggerID)
+	if err := t.publishConfigRaw(topic, config); err != nil {
+		return fmt.Errorf("failed to publish %s trigger discovery config: %w", triggerID, err)
+	}
+
+	t.logger.WithFields(logrus.Fields{
+		"trigger": triggerID,
+		"topic":   topic,
+	}).Debug("Published device trigger discovery config")
+
+	t.publishedSensors[uniqueID] = true
+	return nil
+}
+
+// FireDeviceTrigger fires a previously registered device trigger.
+func (t *MQTTTransmitter) FireDeviceTrigger(triggerID string) error {
+	if !t.client.IsConnected() {
+		return fmt.Errorf("MQTT client not connected")
+	}
+
+	topic := fmt.Sprintf("byd_car/%s/trigger/%s", t.deviceID, triggerID)
+	if err := t.client.Publish(topic, []byte(triggerID), false); err != nil {
+		return fmt.Errorf("failed to fire trigger %s: %w", triggerID, err)
+	}
+
+	t.logger.WithField("trigger", triggerID).Info("Fired device trigger")
+	return nil
+}
@@ -0,0 +1,23 @@
+package transmission
+
+import "github.com/jkaberg/byd-hass/internal/sensors"
+
+// RenderState returns the state payload Transmit would publish for data
+// without touching the broker. Keys that change on every publish regardless
+// of the car (timestamps, broker metrics) are left out, so equal snapshots
+// render equal payloads.
+func (t *MQTTTransmitter) RenderState(data *sensors.SensorData) map[string]interface{} {
+	state := t.buildState(data)
+	for key := range state {
+		if volatileStateKeys[key] || mqttHealthKeys[key] {
+			delete(state, key)
+		}
+	}
+	return state
+}
+
+// RenderTelemetry returns the telemetry TransmitWithContext would send for
+// data.
+func (t *ABRPTransmitter) RenderTelemetry(data *sensors.SensorData) ABRPTelemetry {
+	return t.buildTelemetryData(data)
+}
@@ -0,0 +1,126 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/jkaberg/byd-hass/internal/mqtt"
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+// TeslaMate compatibility
+//
+// TeslaMate publishes one retained plain-text value per topic under
+// teslamate/cars/<car_id>/, e.g. teslamate/cars/1/battery_level = 81. Many
+// dashboards, HA packages and scripts are built on that scheme, so this
+// transmitter mirrors the subset a BYD can fill on the same broker as the
+// regular MQTT output. Values are only published when they change.
+
+// TeslaMateTransmitter publishes snapshots using TeslaMate's topic scheme.
+type TeslaMateTransmitter struct {
+	client *mqtt.Client
+	prefix string
+	last   map[string]string // topic suffix → last published value
+	logger *logrus.Logger
+}
+
+// NewTeslaMateTransmitter creates a transmitter for teslamate/cars/<carID>.
+func NewTeslaMateTransmitter(client *mqtt.Client, carID int, logger *logrus.Logger) *TeslaMateTransmitter {
+	return &TeslaMateTransmitter{
+		client: client,
+		prefix: fmt.Sprintf("teslamate/cars/%d", carID),
+		last:   make(map[string]string),
+		logger: logger,
+	}
+}
+
+// Transmit publishes every value that changed since the previous call.
+func (t *TeslaMateTransmitter) Transmit(data *sensors.SensorData) error {
+	if !t.client.IsConnected() {
+		return fmt.Errorf("MQTT client not connected")
+	}
+	var failed int
+	for key, value := range t.values(data) {
+		if t.last[key] == value {
+			continue
+		}
+		if err := t.client.Publish(t.prefix+"/"+key, []byte(value), true); err != nil {
+			failed++
+			continue
+		}
+		t.last[key] = value
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to publish %d TeslaMate topics", failed)
+	}
+	return nil
+}
+
+// IsConnected reports whether the shared MQTT client is connected.
+func (t *TeslaMateTransmitter) IsConnected() bool {
+	return t.client.IsConnected()
+}
+
+// values maps a snapshot onto TeslaMate topic suffixes.
+func (t *TeslaMateTransmitter) values(data *sensors.SensorData) map[string]string {
+	v := map[string]string{"display_name": "BYD"}
+	num := func(key string, f *float64, decimals int) {
+		if f != nil {
+			v[key] = strconv.FormatFloat(*f, 'f', decimals, 64)
+		}
+	}
+
+	num("battery_level", data.BatteryPercentage, 0)
+	num("usable_battery_level", data.BatteryPercentage, 0)
+	num("odometer", data.Mileage, 2)
+	num("speed", data.Speed, 0)
+	num("power", data.EnginePower, 0)
+	num("outside_temp", data.OutsideTemperature, 1)
+	num("inside_temp", data.CabinTemperature, 1)
+	num("tpms_pressure_fl", data.LeftFrontTirePressure, 2)
+	num("tpms_pressure_fr", data.RightFrontTirePressure, 2)
+	num("tpms_pressure_rl", data.LeftRearTirePressure, 2)
+	num("tpms_pressure_rr", data.RightRearTirePressure, 2)
+
+	status := sensors.DeriveChargingStatus(data)
+	v["plugged_in"] = strconv.FormatBool(status != "disconnected")
+	switch status {
+	case "charging":
+		v["charging_state"] = "Charging"
+	case "connected":
+		v["charging_state"] = "Stopped"
+	default:
+		v["charging_state"] = "Disconnected"
+	}
+	chargerPower := 0.0
+	if status == "charging" && data.EnginePower != nil {
+		chargerPower = math.Abs(*data.EnginePower)
+	}
+	v["charger_power"] = strconv.FormatFloat(chargerPower, 'f', 0, 64)
+
+	switch {
+	case data.Speed != nil && *data.Speed > 0:
+		v["state"] = "driving"
+	case status == "charging":
+		v["state"] = "charging"
+	case data.PowerStatus != nil && *data.PowerStatus > 0:
+		v["state"] = "online"
+	default:
+		v["state"] = "asleep"
+	}
+
+	if hasLocationFix(data) {
+		loc := data.Location
+		v["latitude"] = strconv.FormatFloat(loc.Latitude, 'f', 6, 64)
+		v["longitude"] = strconv.FormatFloat(loc.Longitude, 'f', 6, 64)
+		v["heading"] = strconv.FormatFloat(loc.Bearing, 'f', 0, 64)
+		v["elevation"] = strconv.FormatFloat(loc.Altitude, 'f', 0, 64)
+		if raw, err := json.Marshal(map[string]float64{"latitude": loc.Latitude, "longitude": loc.Longitude}); err == nil {
+			v["location"] = string(raw)
+		}
+	}
+	return v
+}
@@ -0,0 +1,154 @@
+package transmission
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+// Traccar integration
+//
+// Positions are reported using the OsmAnd HTTP protocol that every Traccar
+// server listens for (port 5055 by default). Each report is a single request
+// whose query string carries the position and any number of extra
+// attributes:
+//
+//	http://traccar:5055/?id=<device>&lat=..&lon=..&timestamp=..&speed=..
+//
+// Speed is sent in knots as the protocol requires. Everything else we add
+// (batt, charge, ignition, odometer, ...) shows up as position attributes in
+// Traccar.
+
+// TraccarTransmitter reports position and key telemetry to a Traccar server.
+type TraccarTransmitter struct {
+	serverURL  string
+	deviceID   string
+	httpClient *http.Client
+	logger     *logrus.Logger
+	healthy    uint32 // 1 = last transmission successful, 0 = failed/unknown
+}
+
+// NewTraccarTransmitter creates a new Traccar transmitter. serverURL is the
+// OsmAnd endpoint, e.g. "http://traccar.example.com:5055".
+func NewTraccarTransmitter(serverURL, deviceID string, logger *logrus.Logger) *TraccarTransmitter {
+	return &TraccarTransmitter{
+		serverURL: strings.TrimRight(serverURL, "/"),
+		deviceID:  deviceID,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// TransmitWithContext sends a position report. Snapshots without a GPS fix are
+// skipped since Traccar rejects positions without coordinates.
+func (t *TraccarTransmitter) TransmitWithContext(ctx context.Context, data *sensors.SensorData) error {
+	params, ok := t.buildParams(data)
+	if !ok {
+		t.logger.Debug("Traccar: no location fix, skipping report")
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.serverURL+"/?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create Traccar request: %w", err)
+	}
+	req.Header.Set("User-Agent", "byd-hass/1.0.0")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		atomic.StoreUint32(&t.healthy, 0)
+		return fmt.Errorf("Traccar request failed: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		atomic.StoreUint32(&t.healthy, 0)
+		return fmt.Errorf("Traccar server returned status %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	if prev := atomic.SwapUint32(&t.healthy, 1); prev == 0 {
+		t.logger.Info("Traccar connection established")
+	}
+	t.logger.Debug("Successfully transmitted to Traccar")
+	return nil
+}
+
+// Transmit sends a position report using a Background context.
+func (t *TraccarTransmitter) Transmit(data *sensors.SensorData) error {
+	return t.TransmitWithContext(context.Background(), data)
+}
+
+// IsConnected returns true when the last transmission attempt succeeded.
+func (t *TraccarTransmitter) IsConnected() bool {
+	return atomic.LoadUint32(&t.healthy) == 1
+}
+
+// buildParams converts a snapshot into OsmAnd query parameters. The second
+// return value is false when the snapshot has no usable location.
+func (t *TraccarTransmitter) buildParams(data *sensors.SensorData) (url.Values, bool) {
+	if data == nil || data.Location == nil || data.Location.Provider == "default" {
+		return nil, false
+	}
+	loc := data.Location
+
+	ts := data.Timestamp
+	if !loc.Timestamp.IsZero() {
+		ts = loc.Timestamp
+	}
+
+	p := url.Values{}
+	p.Set("id", t.deviceID)
+	p.Set("lat", formatFloat(loc.Latitude))
+	p.Set("lon", formatFloat(loc.Longitude))
+	p.Set("timestamp", strconv.FormatInt(ts.Unix(), 10))
+	p.Set("accuracy", formatFloat(loc.Accuracy))
+	p.Set("altitude", formatFloat(loc.Altitude))
+	p.Set("bearing", formatFloat(loc.Bearing))
+
+	// Prefer the wheel speed from the car (km/h); fall back to GPS (m/s).
+	const kmhToKnots = 1 / 1.852
+	const msToKnots = 3.6 / 1.852
+	if data.Speed != nil {
+		p.Set("speed", formatFloat(*data.Speed*kmhToKnots))
+	} else {
+		p.Set("speed", formatFloat(loc.Speed*msToKnots))
+	}
+
+	// Extra attributes
+	if data.BatteryPercentage != nil {
+		p.Set("batt", formatFloat(*data.BatteryPercentage))
+	}
+	if data.FuelPercentage != nil {
+		p.Set("fuel", formatFloat(*data.FuelPercentage))
+	}
+	if data.Mileage != nil {
+		// Traccar expects the odometer in metres.
+		p.Set("odometer", formatFloat(*data.Mileage*1000))
+	}
+	if data.EnginePower != nil {
+		p.Set("power", formatFloat(*data.EnginePower))
+	}
+	if data.OutsideTemperature != nil {
+		p.Set("temp1", formatFloat(*data.OutsideTemperature))
+	}
+	if data.PowerStatus != nil {
+		p.Set("ignition", strconv.FormatBool(*data.PowerStatus > 0))
+	}
+	p.Set("charge", strconv.FormatBool(sensors.DeriveChargingStatus(data) == "charging"))
+
+	return p, true
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
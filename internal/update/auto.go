@@ -0,0 +1,73 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// Auto installs new releases unattended. The installed version is recorded
+// as a Trial in the state file; the new process confirms it after passing
+// its self-test or rolls it back.
+type Auto struct {
+	Channel   string
+	Interval  time.Duration // between checks
+	Current   string        // running version
+	Target    string        // binary to replace
+	Mirror    string        // shared storage copy, see RefreshMirror
+	StatePath string
+
+	updater *Updater
+}
+
+// NewAuto returns an auto-updater for the running binary that keeps its
+// state, and looks for the binary's mirror, in stateDir.
+func NewAuto(channel string, interval time.Duration, current, stateDir string) (*Auto, error) {
+	target, err := Executable()
+	if err != nil {
+		return nil, err
+	}
+	return &Auto{
+		Channel:   channel,
+		Interval:  interval,
+		Current:   current,
+		Target:    target,
+		Mirror:    filepath.Join(stateDir, "byd-hass"),
+		StatePath: filepath.Join(stateDir, StateFileName),
+		updater:   New(),
+	}, nil
+}
+
+// Check installs the channel's latest release unless it is running already
+// or was rolled back before. It returns the installed tag, "" if nothing was
+// installed; the caller must restart to run it.
+func (a *Auto) Check(ctx context.Context) (string, error) {
+	st, err := LoadState(a.StatePath)
+	if err != nil {
+		return "", err
+	}
+	rel, err := a.updater.Latest(ctx, a.Channel)
+	if err != nil {
+		return "", err
+	}
+	if rel.Tag == a.Current || st.Skipped(rel.Tag) {
+		return "", nil
+	}
+	st.Pending = &Trial{From: a.Current, To: rel.Tag, Target: a.Target}
+	if err := st.Save(a.StatePath); err != nil {
+		// Without the trial record a broken release would never be rolled back.
+		return "", err
+	}
+	if err := a.updater.Apply(ctx, rel, a.Target); err != nil {
+		st.Pending = nil
+		if saveErr := st.Save(a.StatePath); saveErr != nil {
+			return "", fmt.Errorf("%v; failed to clear trial: %w", err, saveErr)
+		}
+		return "", err
+	}
+	// Best effort: the keep-alive script only uses the mirror when the
+	// binary itself went missing.
+	_ = RefreshMirror(a.Target, a.Mirror)
+	return rel.Tag, nil
+}
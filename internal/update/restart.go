@@ -0,0 +1,38 @@
+package update
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// StopRunning sends SIGTERM to every other process executing target, so the
+// supervisor (the keep-alive script or Termux:Boot loop) starts the new
+// binary. It returns the number of processes signalled.
+func StopRunning(target string) int {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0
+	}
+	var stopped int
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		exe, err := os.Readlink(filepath.Join("/proc", e.Name(), "exe"))
+		if err != nil {
+			continue
+		}
+		// A replaced binary shows up as "path (deleted)".
+		if strings.TrimSuffix(exe, " (deleted)") != target {
+			continue
+		}
+		if p, err := os.FindProcess(pid); err == nil && p.Signal(syscall.SIGTERM) == nil {
+			stopped++
+		}
+	}
+	return stopped
+}
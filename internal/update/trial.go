@@ -0,0 +1,75 @@
+package update
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// StateFileName is the file below the state directory that tracks
+// automatic updates.
+const StateFileName = "update.json"
+
+// MaxTrialStarts is how often an automatically installed version may start
+// without passing its self-test before it is rolled back, so a version that
+// crashes during the test doesn't loop forever.
+const MaxTrialStarts = 2
+
+// Trial is an automatically installed version that hasn't passed its
+// self-test yet.
+type Trial struct {
+	From   string `json:"from"`   // version that installed it
+	To     string `json:"to"`     // installed release tag
+	Target string `json:"target"` // path of the replaced binary
+	Starts int    `json:"starts"` // starts of the new version so far
+}
+
+// State is the persisted automatic update state.
+type State struct {
+	Pending    *Trial   `json:"pending,omitempty"`
+	RolledBack []string `json:"rolled_back,omitempty"` // tags that failed their self-test
+}
+
+// LoadState reads the update state from path. A missing file yields an
+// empty state.
+func LoadState(path string) (*State, error) {
+	st := &State{}
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return st, fmt.Errorf("failed to read update state: %w", err)
+	}
+	if err := json.Unmarshal(raw, st); err != nil {
+		return &State{}, fmt.Errorf("failed to parse update state %s: %w", path, err)
+	}
+	return st, nil
+}
+
+// Save writes the state to path, creating its directory.
+func (s *State) Save(path string) error {
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode update state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write update state: %w", err)
+	}
+	return nil
+}
+
+// Skipped reports whether tag was rolled back before and must not be
+// installed again.
+func (s *State) Skipped(tag string) bool {
+	for _, t := range s.RolledBack {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
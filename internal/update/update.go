@@ -0,0 +1,280 @@
+// Package update replaces the running binary with the latest GitHub release.
+//
+// Each release carries the binary as byd-hass-<arch> and its SHA-256 as
+// byd-hass-<arch>.sha256 (sha256sum format). A download is only installed
+// when the checksum matches and the new binary runs.
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// releasesAPI lists the project's releases.
+const releasesAPI = "https://api.github.com/repos/jkaberg/byd-hass/releases"
+
+// Update channels: stable follows the latest release, beta also takes
+// pre-releases.
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+)
+
+// PreviousSuffix is appended to the binary path to keep the version an
+// update replaced.
+const PreviousSuffix = ".old"
+
+// Release is a published build for this architecture.
+type Release struct {
+	Tag         string
+	Prerelease  bool
+	BinaryURL   string
+	ChecksumURL string
+}
+
+// AssetName is the release asset holding the binary for this architecture.
+func AssetName() string { return "byd-hass-" + runtime.GOARCH }
+
+// Updater talks to the GitHub releases API.
+type Updater struct {
+	client *http.Client
+}
+
+// New returns an updater.
+func New() *Updater {
+	return &Updater{client: &http.Client{Timeout: 5 * time.Minute}}
+}
+
+type githubRelease struct {
+	TagName    string `json:"tag_name"`
+	Prerelease bool   `json:"prerelease"`
+	Draft      bool   `json:"draft"`
+	Assets     []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// Latest returns the newest release of channel with a binary for this
+// architecture.
+func (u *Updater) Latest(ctx context.Context, channel string) (*Release, error) {
+	if channel != ChannelBeta {
+		var rel githubRelease
+		if err := u.getJSON(ctx, releasesAPI+"/latest", &rel); err != nil {
+			return nil, err
+		}
+		return toRelease(rel)
+	}
+	// The list is newest first and includes pre-releases.
+	var rels []githubRelease
+	if err := u.getJSON(ctx, releasesAPI+"?per_page=10", &rels); err != nil {
+		return nil, err
+	}
+	for _, rel := range rels {
+		if !rel.Draft {
+			return toRelease(rel)
+		}
+	}
+	return nil, fmt.Errorf("no published releases")
+}
+
+func toRelease(rel githubRelease) (*Release, error) {
+	out := &Release{Tag: rel.TagName, Prerelease: rel.Prerelease}
+	for _, a := range rel.Assets {
+		switch a.Name {
+		case AssetName():
+			out.BinaryURL = a.URL
+		case AssetName() + ".sha256":
+			out.ChecksumURL = a.URL
+		}
+	}
+	if out.BinaryURL == "" {
+		return nil, fmt.Errorf("release %s has no %s asset", rel.TagName, AssetName())
+	}
+	if out.ChecksumURL == "" {
+		return nil, fmt.Errorf("release %s has no checksum for %s", rel.TagName, AssetName())
+	}
+	return out, nil
+}
+
+// Download fetches the release binary into dir and verifies its checksum.
+// It returns the path of the executable temporary file.
+func (u *Updater) Download(ctx context.Context, rel *Release, dir string) (string, error) {
+	sumRaw, err := u.get(ctx, rel.ChecksumURL, 1<<10)
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksum: %w", err)
+	}
+	fields := strings.Fields(string(sumRaw))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum file")
+	}
+	want := strings.ToLower(fields[0])
+
+	f, err := os.CreateTemp(dir, ".byd-hass-update-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create download file: %w", err)
+	}
+	path := f.Name()
+	fail := func(err error) (string, error) {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rel.BinaryURL, nil)
+	if err != nil {
+		return fail(err)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return fail(fmt.Errorf("failed to download %s: %w", AssetName(), err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fail(fmt.Errorf("failed to download %s: %s", AssetName(), resp.Status))
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), resp.Body); err != nil {
+		return fail(fmt.Errorf("failed to download %s: %w", AssetName(), err))
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fail(fmt.Errorf("checksum mismatch: got %s, want %s", got, want))
+	}
+	if err := f.Chmod(0o755); err != nil {
+		return fail(fmt.Errorf("failed to make download executable: %w", err))
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("failed to write download: %w", err)
+	}
+	return path, nil
+}
+
+// Apply downloads rel next to target and installs it.
+func (u *Updater) Apply(ctx context.Context, rel *Release, target string) error {
+	path, err := u.Download(ctx, rel, filepath.Dir(target))
+	if err != nil {
+		return err
+	}
+	if err := Install(path, target); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// Rollback restores the binary Install kept.
+func Rollback(target string) error {
+	if err := os.Rename(target+PreviousSuffix, target); err != nil {
+		return fmt.Errorf("failed to restore previous binary: %w", err)
+	}
+	return nil
+}
+
+// Install checks that the binary at path runs, keeps the current target as
+// target+PreviousSuffix and renames path over target. path must be on the
+// same file system as target.
+func Install(path, target string) error {
+	if err := Verify(path); err != nil {
+		return err
+	}
+	if err := copyFile(target, target+PreviousSuffix); err != nil {
+		return fmt.Errorf("failed to keep previous binary: %w", err)
+	}
+	if err := os.Rename(path, target); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", target, err)
+	}
+	return nil
+}
+
+// Verify runs "path -version", catching binaries for the wrong
+// architecture or truncated downloads before they replace a working one.
+func Verify(path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, path, "-version").CombinedOutput(); err != nil {
+		return fmt.Errorf("new binary does not run: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Executable returns the resolved path of the running binary.
+func Executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the running binary: %w", err)
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+func (u *Updater) getJSON(ctx context.Context, url string, v interface{}) error {
+	raw, err := u.get(ctx, url, 1<<20)
+	if err != nil {
+		return fmt.Errorf("failed to query releases: %w", err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("failed to parse releases: %w", err)
+	}
+	return nil
+}
+
+func (u *Updater) get(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "byd-hass/1.0.0")
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, limit))
+}
+
+// RefreshMirror copies target over mirror, the copy in shared storage that
+// the keep-alive script restores /data/local/tmp from. A missing mirror is
+// left alone.
+func RefreshMirror(target, mirror string) error {
+	if mirror == "" || mirror == target {
+		return nil
+	}
+	if _, err := os.Stat(mirror); err != nil {
+		return nil
+	}
+	if err := copyFile(target, mirror); err != nil {
+		return fmt.Errorf("failed to update %s: %w", mirror, err)
+	}
+	return nil
+}
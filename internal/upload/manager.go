@@ -0,0 +1,220 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jkaberg/byd-hass/internal/sensors"
+	"github.com/sirupsen/logrus"
+)
+
+// SensorIDs lists the Diplus sensors the manager needs to see.
+var SensorIDs = []int{1014}
+
+// maxQueue bounds the number of pending videos kept in memory.
+const maxQueue = 20
+
+// A failed upload stays queued and is retried after retryMinBackoff,
+// doubling up to retryMaxBackoff. Only MaxAge or a vanished file drop it.
+const (
+	retryMinBackoff = time.Minute
+	retryMaxBackoff = time.Hour
+)
+
+// NotifyFunc receives upload lifecycle notifications ("started", "progress",
+// "completed", "failed") together with descriptive attributes.
+type NotifyFunc func(eventType string, attrs map[string]interface{})
+
+// Options tune the Manager behaviour.
+type Options struct {
+	// MaxAge drops queued videos older than this (0 = keep all).
+	MaxAge time.Duration
+	// DeleteAfterUpload removes the local file once uploaded.
+	DeleteAfterUpload bool
+	// CanUpload gates uploads, e.g. to home WiFi only. nil = always.
+	CanUpload func(ctx context.Context) bool
+	// Notify receives progress notifications (optional).
+	Notify NotifyFunc
+}
+
+// Manager watches LastVideoPath and uploads new recordings in the
+// background.
+type Manager struct {
+	uploader Uploader
+	opts     Options
+	logger   *logrus.Logger
+
+	mu       sync.Mutex
+	queue    []*pending
+	lastPath string
+	started  bool
+}
+
+// pending is a queued video and its retry state.
+type pending struct {
+	path    string
+	backoff time.Duration
+	retryAt time.Time
+}
+
+// NewManager returns a Manager using the given uploader.
+func NewManager(uploader Uploader, opts Options, logger *logrus.Logger) *Manager {
+	return &Manager{uploader: uploader, opts: opts, logger: logger}
+}
+
+// Observe queues the video referenced by the snapshot if it is new. The first
+// path seen only establishes the baseline.
+func (m *Manager) Observe(data *sensors.SensorData) {
+	if data == nil || data.LastVideoPath == nil || *data.LastVideoPath == "" {
+		return
+	}
+	path := *data.LastVideoPath
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.started {
+		m.started = true
+		m.lastPath = path
+		return
+	}
+	if path == m.lastPath {
+		return
+	}
+	m.lastPath = path
+
+	if len(m.queue) >= maxQueue {
+		m.logger.WithField("dropped", m.queue[0].path).Warn("upload: queue full, dropping oldest video")
+		m.queue = m.queue[1:]
+	}
+	m.queue = append(m.queue, &pending{path: path})
+	m.logger.WithField("path", path).Debug("upload: video queued")
+}
+
+// Run processes the queue every interval until ctx is cancelled.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			m.drain(ctx)
+		}
+	}
+}
+
+// drain uploads queued videos in order. A failure keeps the video at the
+// head of the queue and pauses the queue until its retry time, since the
+// next upload would most likely fail the same way.
+func (m *Manager) drain(ctx context.Context) {
+	for {
+		m.mu.Lock()
+		if len(m.queue) == 0 || time.Now().Before(m.queue[0].retryAt) {
+			m.mu.Unlock()
+			return
+		}
+		item := m.queue[0]
+		m.mu.Unlock()
+
+		if m.opts.CanUpload != nil && !m.opts.CanUpload(ctx) {
+			return
+		}
+
+		err := m.uploadFile(ctx, item.path)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			item.backoff = nextBackoff(item.backoff)
+			item.retryAt = time.Now().Add(item.backoff)
+			m.logger.WithError(err).WithFields(logrus.Fields{
+				"path":     item.path,
+				"retry_in": item.backoff,
+			}).Warn("upload: video upload failed")
+			m.notify("failed", map[string]interface{}{"file": filepath.Base(item.path), "error": err.Error(), "retry_in": item.backoff.String()})
+			return
+		}
+
+		// Observe may have dropped the item from a full queue meanwhile.
+		m.mu.Lock()
+		if len(m.queue) > 0 && m.queue[0] == item {
+			m.queue = m.queue[1:]
+		}
+		m.mu.Unlock()
+
+		if err != nil {
+			m.logger.WithError(err).WithField("path", item.path).Warn("upload: video no longer exists, dropping it")
+			m.notify("failed", map[string]interface{}{"file": filepath.Base(item.path), "error": err.Error()})
+		}
+	}
+}
+
+// nextBackoff doubles the retry delay within retryMinBackoff..retryMaxBackoff.
+func nextBackoff(cur time.Duration) time.Duration {
+	if cur < retryMinBackoff {
+		return retryMinBackoff
+	}
+	if cur*2 > retryMaxBackoff {
+		return retryMaxBackoff
+	}
+	return cur * 2
+}
+
+func (m *Manager) uploadFile(ctx context.Context, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("cannot stat video: %w", err)
+	}
+	if m.opts.MaxAge > 0 && time.Since(info.ModTime()) > m.opts.MaxAge {
+		m.logger.WithField("path", path).Debug("upload: video older than retention window, skipping")
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot open video: %w", err)
+	}
+	defer f.Close()
+
+	name := filepath.Base(path)
+	size := info.Size()
+	m.notify("started", map[string]interface{}{"file": name, "size": size})
+
+	reader := WithProgress(f, size, func(sent, total int64) {
+		m.notify("progress", map[string]interface{}{"file": name, "percent": sent * 100 / total})
+	})
+
+	start := time.Now()
+	if err := m.uploader.Upload(ctx, name, reader, size); err != nil {
+		return err
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"file":     name,
+		"size":     size,
+		"duration": time.Since(start).Round(time.Second),
+		"target":   m.uploader.String(),
+	}).Info("upload: video uploaded")
+	m.notify("completed", map[string]interface{}{"file": name, "size": size})
+
+	if m.opts.DeleteAfterUpload {
+		if err := os.Remove(path); err != nil {
+			m.logger.WithError(err).WithField("path", path).Warn("upload: failed to delete local video")
+		}
+	}
+	return nil
+}
+
+func (m *Manager) notify(eventType string, attrs map[string]interface{}) {
+	if m.opts.Notify != nil {
+		m.opts.Notify(eventType, attrs)
+	}
+}
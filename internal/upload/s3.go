@@ -0,0 +1,144 @@
+package upload
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// s3Uploader implements a minimal S3 PutObject client with AWS Signature V4.
+// The payload is sent unsigned (UNSIGNED-PAYLOAD) so large videos can be
+// streamed without hashing them first. Works with AWS and S3-compatible
+// stores (MinIO, Garage, ...) using path-style addressing.
+type s3Uploader struct {
+	accessKey  string
+	secretKey  string
+	bucket     string
+	prefix     string
+	region     string
+	endpoint   *url.URL
+	httpClient *http.Client
+}
+
+func newS3(u *url.URL) (*s3Uploader, error) {
+	if u.User == nil {
+		return nil, fmt.Errorf("s3 URL must include ACCESS_KEY:SECRET credentials")
+	}
+	secret, _ := u.User.Password()
+	if u.Host == "" {
+		return nil, fmt.Errorf("s3 URL must include a bucket name")
+	}
+
+	q := u.Query()
+	region := q.Get("region")
+	if region == "" {
+		region = "us-east-1"
+	}
+	endpoint := q.Get("endpoint")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	ep, err := url.Parse(endpoint)
+	if err != nil || ep.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", endpoint)
+	}
+
+	return &s3Uploader{
+		accessKey:  u.User.Username(),
+		secretKey:  secret,
+		bucket:     u.Host,
+		prefix:     strings.Trim(u.Path, "/"),
+		region:     region,
+		endpoint:   ep,
+		httpClient: &http.Client{Timeout: 30 * time.Minute},
+	}, nil
+}
+
+// Upload performs a single PutObject request.
+func (s *s3Uploader) Upload(ctx context.Context, name string, r io.Reader, size int64) error {
+	key := joinPath(s.prefix, name)
+	target := *s.endpoint
+	target.Path = "/" + joinPath(s.bucket, key)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), r)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	s.sign(req, time.Now().UTC())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("S3 upload failed: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("S3 returned status %d: %s", resp.StatusCode, resp.Status)
+	}
+	return nil
+}
+
+// sign adds AWS Signature V4 headers to req.
+func (s *s3Uploader) sign(req *http.Request, now time.Time) {
+	const payloadHash = "UNSIGNED-PAYLOAD"
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n",
+		req.Header.Get("Content-Type"), req.URL.Host, payloadHash, amzDate)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", day, s.region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), day)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+func (s *s3Uploader) String() string {
+	return fmt.Sprintf("s3 %s/%s/%s", s.endpoint.Host, s.bucket, s.prefix)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
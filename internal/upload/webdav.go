@@ -0,0 +1,66 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type webdavUploader struct {
+	base       url.URL
+	user       string
+	pass       string
+	httpClient *http.Client
+}
+
+func newWebDAV(u *url.URL) *webdavUploader {
+	base := *u
+	base.Scheme = "http"
+	if u.Scheme == "webdavs" {
+		base.Scheme = "https"
+	}
+	w := &webdavUploader{
+		httpClient: &http.Client{Timeout: 30 * time.Minute},
+	}
+	if u.User != nil {
+		w.user = u.User.Username()
+		w.pass, _ = u.User.Password()
+	}
+	base.User = nil
+	w.base = base
+	return w
+}
+
+// Upload PUTs the file below the base path.
+func (w *webdavUploader) Upload(ctx context.Context, name string, r io.Reader, size int64) error {
+	target := w.base
+	target.Path = "/" + joinPath(w.base.Path, name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), r)
+	if err != nil {
+		return fmt.Errorf("failed to create WebDAV request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if w.user != "" {
+		req.SetBasicAuth(w.user, w.pass)
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("WebDAV upload failed: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("WebDAV server returned status %d: %s", resp.StatusCode, resp.Status)
+	}
+	return nil
+}
+
+func (w *webdavUploader) String() string {
+	return "webdav " + w.base.String()
+}
@@ -0,0 +1,119 @@
+// Package vehicle identifies the car model, which Diplus doesn't report:
+// neither the VIN nor a model code is exposed. The model is taken from
+// -car-model or guessed from the head unit's Android product properties,
+// which DiLink units fill with the car's name on many builds.
+package vehicle
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Model is a known BYD model variant.
+type Model struct {
+	Key       string  // -car-model value, e.g. "atto3-60"
+	Family    string  // model family shared by the battery variants, see familyAliases
+	Name      string  // display name
+	ABRPModel string  // ABRP car_model type code
+	UsableKWh float64 // usable battery capacity
+}
+
+// Models lists the known variants. Variants of one family differ only in
+// battery size, which the head unit doesn't reveal; for those families
+// -car-model has to pick the variant.
+//
+// The ABRP codes follow ABRP's make:model:year:battery scheme; if ABRP shows
+// the wrong car, set -abrp-car-model to the code from its car list.
+var Models = []Model{
+	{Key: "atto3-60", Family: "atto3", Name: "BYD Atto 3 60.5 kWh", ABRPModel: "byd:atto3:22:60", UsableKWh: 60.5},
+	{Key: "atto3-50", Family: "atto3", Name: "BYD Atto 3 49.9 kWh", ABRPModel: "byd:atto3:22:50", UsableKWh: 49.9},
+	{Key: "dolphin-60", Family: "dolphin", Name: "BYD Dolphin 60.4 kWh", ABRPModel: "byd:dolphin:23:60", UsableKWh: 60.4},
+	{Key: "dolphin-45", Family: "dolphin", Name: "BYD Dolphin 44.9 kWh", ABRPModel: "byd:dolphin:23:45", UsableKWh: 44.9},
+	{Key: "seal-82", Family: "seal", Name: "BYD Seal 82.5 kWh", ABRPModel: "byd:seal:23:82", UsableKWh: 82.5},
+	{Key: "seal-61", Family: "seal", Name: "BYD Seal 61.4 kWh", ABRPModel: "byd:seal:23:61", UsableKWh: 61.4},
+	{Key: "sealu-87", Family: "sealu", Name: "BYD Seal U 87 kWh", ABRPModel: "byd:sealu:24:87", UsableKWh: 87},
+	{Key: "sealu-72", Family: "sealu", Name: "BYD Seal U 71.8 kWh", ABRPModel: "byd:sealu:24:72", UsableKWh: 71.8},
+	{Key: "sealu-dmi", Family: "sealu-dmi", Name: "BYD Seal U DM-i 26.6 kWh", ABRPModel: "byd:sealudmi:24:27", UsableKWh: 26.6},
+	{Key: "han-85", Family: "han", Name: "BYD Han 85.4 kWh", ABRPModel: "byd:han:22:85", UsableKWh: 85.4},
+	{Key: "tang-86", Family: "tang", Name: "BYD Tang 86.4 kWh", ABRPModel: "byd:tang:22:86", UsableKWh: 86.4},
+	{Key: "dolphin-surf", Family: "dolphin-surf", Name: "BYD Dolphin Surf 43.2 kWh", ABRPModel: "byd:dolphinsurf:25:43", UsableKWh: 43.2},
+}
+
+// familyAliases are the lower-case names matched against head-unit
+// properties, by model family.
+var familyAliases = map[string][]string{
+	"atto3":        {"atto 3", "atto3", "yuan plus"},
+	"dolphin":      {"dolphin"},
+	"seal":         {"seal"},
+	"sealu":        {"seal u", "song plus ev"},
+	"sealu-dmi":    {"seal u dm-i", "song plus dm-i"},
+	"han":          {"han"},
+	"tang":         {"tang"},
+	"dolphin-surf": {"dolphin surf", "seagull"},
+}
+
+// ByKey returns the model with the given -car-model key.
+func ByKey(key string) (Model, bool) {
+	for _, m := range Models {
+		if strings.EqualFold(m.Key, key) {
+			return m, true
+		}
+	}
+	return Model{}, false
+}
+
+// Keys returns the -car-model keys.
+func Keys() []string {
+	keys := make([]string, len(Models))
+	for i, m := range Models {
+		keys[i] = m.Key
+	}
+	return keys
+}
+
+// Detect matches hints (e.g. head-unit properties) against the family
+// aliases and returns the variants of the matching family. The longest
+// matching alias wins, so "seal u" beats "seal". More than one variant
+// means the hints don't tell the battery size.
+func Detect(hints []string) []Model {
+	var family string
+	var bestLen int
+	for _, hint := range hints {
+		hint = strings.ToLower(hint)
+		for f, aliases := range familyAliases {
+			for _, alias := range aliases {
+				if len(alias) > bestLen && strings.Contains(hint, alias) {
+					family, bestLen = f, len(alias)
+				}
+			}
+		}
+	}
+	var variants []Model
+	for _, m := range Models {
+		if family != "" && m.Family == family {
+			variants = append(variants, m)
+		}
+	}
+	return variants
+}
+
+// headUnitProps are the Android properties that name the car on DiLink
+// head units.
+var headUnitProps = []string{"ro.product.model", "ro.product.name", "ro.product.device"}
+
+// HeadUnitHints reads the head unit's product properties. Properties that
+// can't be read are skipped.
+func HeadUnitHints(ctx context.Context) []string {
+	var hints []string
+	for _, prop := range headUnitProps {
+		pctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		out, err := exec.CommandContext(pctx, "getprop", prop).Output()
+		cancel()
+		if v := strings.TrimSpace(string(out)); err == nil && v != "" {
+			hints = append(hints, v)
+		}
+	}
+	return hints
+}
@@ -2,6 +2,7 @@ package wifi
 
 import (
 	"context"
+	"encoding/json"
 	"os/exec"
 	"strings"
 	"time"
@@ -55,6 +56,36 @@ func (w *WiFiManager) EnableWiFi(ctx context.Context) error {
 	return nil
 }
 
+// CurrentSSID returns the SSID of the connected WiFi network using
+// termux-wifi-connectioninfo. An empty string means not connected.
+func (w *WiFiManager) CurrentSSID(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "termux-wifi-connectioninfo").Output()
+	if err != nil {
+		return "", err
+	}
+
+	var info struct {
+		SSID            string `json:"ssid"`
+		SupplicantState string `json:"supplicant_state"`
+	}
+	if err := json.Unmarshal(output, &info); err != nil {
+		return "", err
+	}
+	if info.SupplicantState != "" && info.SupplicantState != "COMPLETED" {
+		return "", nil
+	}
+	// Android reports "<unknown ssid>" when not connected or when location
+	// permission is missing.
+	ssid := strings.Trim(info.SSID, "\"")
+	if ssid == "<unknown ssid>" {
+		return "", nil
+	}
+	return ssid, nil
+}
+
 // CheckAndReenable checks if WiFi is disabled and re-enables it if needed
 // Returns true if WiFi was re-enabled, false otherwise
 func (w *WiFiManager) CheckAndReenable(ctx context.Context) (bool, error) {